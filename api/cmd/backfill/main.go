@@ -0,0 +1,181 @@
+// Command backfill brings records stored before a feature existed up to
+// date. It reads the same environment as the server and is safe to run more
+// than once.
+//
+// Usage:
+//
+//	go run ./cmd/backfill -task photo-hashes
+//
+// Tasks:
+//
+//	photo-hashes    hash unhashed missing and homeless photos and rewrite the
+//	                photo hash index used by duplicate detection
+//	blurred-photos  store the blurred copies of cases shown blurred to
+//	                anonymous visitors that have none, such as children's
+//	                cases registered before blurring existed
+//	age-progression-requests
+//	                record when each case's age progression was last
+//	                requested, which the scheduled refresh orders cases by
+//	candidate-index index the faces of missing and homeless records that
+//	                are not in the candidate index, or were indexed from an
+//	                older photo
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/l3co/traceo-api/internal/config"
+	"github.com/l3co/traceo-api/internal/domain/homeless"
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/internal/domain/media"
+	"github.com/l3co/traceo-api/internal/domain/missing"
+	"github.com/l3co/traceo-api/internal/infrastructure/embedding"
+	"github.com/l3co/traceo-api/internal/infrastructure/firebase"
+	"github.com/l3co/traceo-api/internal/infrastructure/imaging"
+	"github.com/l3co/traceo-api/internal/infrastructure/photohash"
+	"github.com/l3co/traceo-api/internal/infrastructure/storage"
+	"github.com/l3co/traceo-api/pkg/safefetch"
+)
+
+type options struct {
+	task string
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.task, "task", "", "what to backfill: photo-hashes | blurred-photos | age-progression-requests | candidate-index")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		fmt.Fprintln(os.Stderr, "backfill:", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts options) error {
+	if opts.task == "" {
+		return errors.New("-task is required")
+	}
+
+	cfg := config.Load()
+	ctx := context.Background()
+
+	fbClient, err := firebase.NewClient(ctx, cfg.FirebaseProjectID, cfg.StorageBucket)
+	if err != nil {
+		return fmt.Errorf("initializing firebase: %w", err)
+	}
+	defer fbClient.Close()
+
+	photoSources := safefetch.URLList(cfg.PhotoSources())
+	if len(photoSources) == 0 {
+		return errors.New("no photo source configured: set STORAGE_BUCKET, PHOTO_URL_HOSTS or STORAGE_BACKEND=local")
+	}
+	photoFetcher := safefetch.New(safefetch.Config{
+		AllowedHosts: photoSources.Hosts(),
+		AllowedURLs:  photoSources,
+		AllowPrivate: cfg.StorageBackend == "local",
+		MaxBytes:     int64(cfg.UploadMaxBytes),
+	})
+
+	switch opts.task {
+	case "photo-hashes":
+		return backfillPhotoHashes(ctx, fbClient, photoFetcher)
+	case "blurred-photos":
+		return backfillBlurredPhotos(ctx, cfg, fbClient, photoFetcher)
+	case "age-progression-requests":
+		missingService := missing.NewService(firebase.NewMissingRepository(fbClient.Firestore), nil)
+		n, err := missingService.BackfillAgeProgressionRequests(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("missing cases backfilled", "updated", n)
+		return nil
+	case "candidate-index":
+		return backfillCandidateIndex(ctx, cfg, fbClient, photoFetcher)
+	default:
+		return fmt.Errorf("unknown -task %q", opts.task)
+	}
+}
+
+func backfillPhotoHashes(ctx context.Context, fbClient *firebase.Client, fetcher *safefetch.Fetcher) error {
+	hasher := photohash.NewPerceptualHasher(fetcher)
+
+	missingService := missing.NewService(firebase.NewMissingRepository(fbClient.Firestore), nil,
+		missing.WithPerceptualHasher(hasher),
+	)
+	n, err := missingService.BackfillPhotoHashes(ctx)
+	if err != nil {
+		return fmt.Errorf("missing: %w", err)
+	}
+	slog.Info("missing cases backfilled", "updated", n)
+
+	homelessService := homeless.NewService(firebase.NewHomelessRepository(fbClient.Firestore), nil, nil,
+		homeless.WithPerceptualHasher(hasher),
+	)
+	n, err = homelessService.BackfillPhotoHashes(ctx)
+	if err != nil {
+		return fmt.Errorf("homeless: %w", err)
+	}
+	slog.Info("homeless records backfilled", "updated", n)
+	return nil
+}
+
+func backfillBlurredPhotos(ctx context.Context, cfg *config.Config, fbClient *firebase.Client, fetcher *safefetch.Fetcher) error {
+	mediaStorage, err := newMediaStorage(cfg, fbClient)
+	if err != nil {
+		return fmt.Errorf("initializing media storage: %w", err)
+	}
+	if mediaStorage == nil {
+		return errors.New("blurred photos need STORAGE_BUCKET or STORAGE_BACKEND=local")
+	}
+
+	missingService := missing.NewService(firebase.NewMissingRepository(fbClient.Firestore), nil,
+		missing.WithPhotoBlurrer(imaging.NewBlurrer(fetcher, mediaStorage)),
+	)
+	n, err := missingService.BackfillBlurredPhotos(ctx)
+	if err != nil {
+		return err
+	}
+	slog.Info("missing cases backfilled", "updated", n)
+	return nil
+}
+
+func backfillCandidateIndex(ctx context.Context, cfg *config.Config, fbClient *firebase.Client, fetcher *safefetch.Fetcher) error {
+	if cfg.CandidateIndex != "firestore" {
+		return fmt.Errorf("only the firestore candidate index outlives the process, CANDIDATE_INDEX is %q", cfg.CandidateIndex)
+	}
+
+	matchingService := matching.NewService(
+		firebase.NewMissingRepository(fbClient.Firestore),
+		firebase.NewHomelessRepository(fbClient.Firestore),
+		firebase.NewMatchRepository(fbClient.Firestore),
+		nil, nil, nil,
+		matching.WithCandidateIndex(embedding.NewHOGEmbedder(fetcher), firebase.NewCandidateIndex(fbClient.Firestore)),
+	)
+	n, err := matchingService.BackfillCandidateIndex(ctx)
+	if err != nil {
+		return err
+	}
+	slog.Info("candidate index backfilled", "indexed", n)
+	return nil
+}
+
+// newMediaStorage mirrors the server's storage selection.
+func newMediaStorage(cfg *config.Config, fbClient *firebase.Client) (media.Storage, error) {
+	if cfg.StorageBackend == "local" {
+		return storage.NewLocalStorage(cfg.StorageLocalDir, cfg.StorageLocalURL), nil
+	}
+	if cfg.StorageBucket == "" {
+		return nil, nil
+	}
+	bucket, err := fbClient.Storage.Bucket(cfg.StorageBucket)
+	if err != nil {
+		return nil, err
+	}
+	return storage.NewFirebaseStorage(bucket, cfg.StorageBucket), nil
+}
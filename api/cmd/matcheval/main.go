@@ -0,0 +1,281 @@
+// Command matcheval measures face-matching accuracy on a labelled dataset of
+// photo pairs, so that the matching policy's thresholds can be tuned offline.
+//
+// Usage:
+//
+//	go run ./cmd/matcheval -pairs dataset/pairs.csv -comparer local -out eval-out
+//
+// pairs.csv has the columns photo1_url, photo2_url, same and optionally id.
+// Photos may be URLs or paths relative to the CSV file; local files are served
+// to the comparer over a loopback HTTP server.
+//
+// -record saves every response so a later run can -replay it without calling
+// the comparer again (useful for Gemini, which costs money per call).
+//
+// Output (JSON and/or CSV, see -format):
+//
+//	summary                 AUC, best-F1 threshold and metrics at the policy thresholds
+//	roc                     false/true positive rate per threshold
+//	precision_recall        confusion counts, precision, recall and F1 per threshold
+//	confusion_save          misclassified pairs at MATCH_SAVE_THRESHOLD
+//	confusion_notify        misclassified pairs at MATCH_NOTIFY_THRESHOLD
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/l3co/traceo-api/internal/config"
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/internal/evaluation"
+	"github.com/l3co/traceo-api/internal/infrastructure/ai"
+	"github.com/l3co/traceo-api/internal/infrastructure/embedding"
+	"github.com/l3co/traceo-api/pkg/resilience"
+	"github.com/l3co/traceo-api/pkg/safefetch"
+)
+
+type options struct {
+	pairs       string
+	comparer    string
+	replay      string
+	record      string
+	out         string
+	format      string
+	step        float64
+	examples    int
+	concurrency int
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.pairs, "pairs", "", "CSV file with labelled photo pairs")
+	flag.StringVar(&opts.comparer, "comparer", "local", "comparer to evaluate: local | gemini | cascade")
+	flag.StringVar(&opts.replay, "replay", "", "replay responses from a recording instead of running -comparer")
+	flag.StringVar(&opts.record, "record", "", "save the comparer's responses to this file")
+	flag.StringVar(&opts.out, "out", "matcheval-out", "output directory")
+	flag.StringVar(&opts.format, "format", "both", "output format: json | csv | both")
+	flag.Float64Var(&opts.step, "step", 0.01, "threshold step")
+	flag.IntVar(&opts.examples, "examples", 20, "misclassified pairs listed per kind")
+	flag.IntVar(&opts.concurrency, "concurrency", 4, "concurrent comparisons")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		fmt.Fprintln(os.Stderr, "matcheval:", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts options) error {
+	if opts.pairs == "" {
+		return errors.New("-pairs is required")
+	}
+	if opts.format != "json" && opts.format != "csv" && opts.format != "both" {
+		return fmt.Errorf("unknown -format %q", opts.format)
+	}
+
+	f, err := os.Open(opts.pairs)
+	if err != nil {
+		return err
+	}
+	pairs, err := evaluation.LoadPairs(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("loading pairs: %w", err)
+	}
+
+	cfg := config.Load()
+	ctx := context.Background()
+
+	comparer, cleanup, err := newComparer(ctx, cfg, opts, filepath.Dir(opts.pairs))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var recorder *evaluation.Recorder
+	if opts.record != "" {
+		recorder = evaluation.NewRecorder(comparer)
+		comparer = recorder
+	}
+
+	version := matching.ComparerVersion(comparer)
+	slog.Info("evaluating comparer", "version", version, "pairs", len(pairs))
+	start := time.Now()
+	results := evaluation.Run(ctx, comparer, pairs, opts.concurrency)
+	failed := evaluation.Failures(results)
+	if failed > 0 {
+		slog.Warn("pairs failed and were excluded", "failed", failed, "example", firstError(results))
+	}
+	slog.Info("evaluation finished", "duration", time.Since(start).Round(time.Millisecond).String())
+
+	if recorder != nil {
+		if err := saveRecording(recorder, opts.record); err != nil {
+			return err
+		}
+	}
+
+	points := evaluation.PrecisionRecall(results, evaluation.Thresholds(opts.step))
+	saveAt := evaluation.ConfusionAt(results, cfg.MatchSaveThreshold)
+	notifyAt := evaluation.ConfusionAt(results, cfg.MatchNotifyThreshold)
+
+	summary := summary{
+		Comparer: version,
+		Pairs:    len(pairs),
+		Failed:   failed,
+		AUC:      evaluation.AUC(points),
+		BestF1:   evaluation.BestF1(points),
+		AtSave:   saveAt.Point,
+		AtNotify: notifyAt.Point,
+	}
+
+	w := reportWriter{dir: opts.out, json: opts.format != "csv", csv: opts.format != "json"}
+	if err := os.MkdirAll(opts.out, 0o755); err != nil {
+		return err
+	}
+	if err := w.summary(summary); err != nil {
+		return err
+	}
+	if err := w.roc(points); err != nil {
+		return err
+	}
+	if err := w.precisionRecall(points); err != nil {
+		return err
+	}
+	if err := w.confusion("confusion_save", saveAt, opts.examples); err != nil {
+		return err
+	}
+	if err := w.confusion("confusion_notify", notifyAt, opts.examples); err != nil {
+		return err
+	}
+
+	fmt.Printf("comparer %s: AUC %.3f, best F1 %.3f at %.2f\n", version, summary.AUC, summary.BestF1.F1, summary.BestF1.Threshold)
+	fmt.Printf("at save threshold %.2f: precision %.3f, recall %.3f\n", cfg.MatchSaveThreshold, saveAt.Precision, saveAt.Recall)
+	fmt.Printf("at notify threshold %.2f: precision %.3f, recall %.3f\n", cfg.MatchNotifyThreshold, notifyAt.Precision, notifyAt.Recall)
+	fmt.Printf("reports written to %s\n", opts.out)
+	return nil
+}
+
+// newComparer builds the comparer under evaluation. Live comparers get local
+// photo paths rewritten to URLs on a loopback file server rooted at baseDir.
+func newComparer(ctx context.Context, cfg *config.Config, opts options, baseDir string) (matching.FaceComparer, func(), error) {
+	if opts.replay != "" {
+		f, err := os.Open(opts.replay)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+		c, err := evaluation.LoadRecording(f)
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, func() {}, nil
+	}
+
+	// The dataset is trusted and its local photos are served over loopback.
+	fetcher := safefetch.New(safefetch.Config{AllowPrivate: true})
+	local := embedding.NewComparer(embedding.NewHOGEmbedder(fetcher), cfg.LocalMatchBase)
+	var (
+		inner   matching.FaceComparer
+		closers []func()
+	)
+	switch opts.comparer {
+	case "local":
+		inner = local
+	case "gemini", "cascade":
+		if cfg.GeminiAPIKey == "" {
+			return nil, nil, errors.New("GEMINI_API_KEY is not set")
+		}
+		client, err := ai.NewGeminiClient(ctx, cfg.GeminiAPIKey, fetcher)
+		if err != nil {
+			return nil, nil, err
+		}
+		closers = append(closers, func() { client.Close() })
+		gemini := ai.NewResilientComparer(
+			ai.NewGeminiComparer(client),
+			nil,
+			ai.DefaultBackoff,
+			resilience.NewBreaker("gemini", 5, time.Minute),
+		)
+		inner = gemini
+		if opts.comparer == "cascade" {
+			inner = matching.NewCascadeComparer(local, gemini, cfg.PrefilterMinScore)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown -comparer %q", opts.comparer)
+	}
+
+	base, shutdown, err := serveDir(baseDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	closers = append(closers, shutdown)
+
+	cleanup := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+	return &servedComparer{inner: inner, base: base}, cleanup, nil
+}
+
+func serveDir(dir string) (string, func(), error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, fmt.Errorf("starting photo server: %w", err)
+	}
+	srv := &http.Server{Handler: http.FileServer(http.Dir(dir)), ReadHeaderTimeout: 5 * time.Second}
+	go srv.Serve(ln)
+	return "http://" + ln.Addr().String(), func() { srv.Close() }, nil
+}
+
+// servedComparer maps dataset-relative photo paths to the loopback server.
+// Recordings keep the original paths, so they replay on any machine.
+type servedComparer struct {
+	inner matching.FaceComparer
+	base  string
+}
+
+func (s *servedComparer) Version() string {
+	return matching.ComparerVersion(s.inner)
+}
+
+func (s *servedComparer) CompareFaces(ctx context.Context, photo1URL, photo2URL string) (*matching.FaceComparisonResult, error) {
+	return s.inner.CompareFaces(ctx, s.resolve(photo1URL), s.resolve(photo2URL))
+}
+
+func (s *servedComparer) resolve(p string) string {
+	if strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://") {
+		return p
+	}
+	return s.base + "/" + strings.TrimPrefix(filepath.ToSlash(p), "/")
+}
+
+func saveRecording(r *evaluation.Recorder, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.Save(f); err != nil {
+		f.Close()
+		return fmt.Errorf("saving recording: %w", err)
+	}
+	return f.Close()
+}
+
+func firstError(results []evaluation.Result) string {
+	for _, r := range results {
+		if r.Err != nil {
+			return r.Err.Error()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/l3co/traceo-api/internal/evaluation"
+)
+
+type summary struct {
+	Comparer string           `json:"comparer"`
+	Pairs    int              `json:"pairs"`
+	Failed   int              `json:"failed"`
+	AUC      float64          `json:"auc"`
+	BestF1   evaluation.Point `json:"best_f1"`
+	AtSave   evaluation.Point `json:"at_save_threshold"`
+	AtNotify evaluation.Point `json:"at_notify_threshold"`
+}
+
+type rocPoint struct {
+	Threshold float64 `json:"threshold"`
+	FPR       float64 `json:"fpr"`
+	TPR       float64 `json:"tpr"`
+}
+
+type example struct {
+	Kind      string  `json:"kind"`
+	ID        string  `json:"id"`
+	Photo1URL string  `json:"photo1_url"`
+	Photo2URL string  `json:"photo2_url"`
+	Score     float64 `json:"score"`
+	Analysis  string  `json:"analysis,omitempty"`
+}
+
+type confusionReport struct {
+	evaluation.Point
+	Examples []example `json:"examples"`
+}
+
+// reportWriter writes each report as <dir>/<name>.json and/or .csv.
+type reportWriter struct {
+	dir  string
+	json bool
+	csv  bool
+}
+
+func (w reportWriter) summary(s summary) error {
+	if w.json {
+		if err := w.writeJSON("summary", s); err != nil {
+			return err
+		}
+	}
+	if !w.csv {
+		return nil
+	}
+	rows := [][]string{
+		{"comparer", "pairs", "failed", "auc", "best_f1_threshold", "best_f1"},
+		{s.Comparer, strconv.Itoa(s.Pairs), strconv.Itoa(s.Failed), ff(s.AUC), ff(s.BestF1.Threshold), ff(s.BestF1.F1)},
+	}
+	return w.writeCSV("summary", rows)
+}
+
+func (w reportWriter) roc(points []evaluation.Point) error {
+	roc := make([]rocPoint, 0, len(points))
+	rows := [][]string{{"threshold", "fpr", "tpr"}}
+	for _, p := range points {
+		roc = append(roc, rocPoint{Threshold: p.Threshold, FPR: p.FPR, TPR: p.Recall})
+		rows = append(rows, []string{ff(p.Threshold), ff(p.FPR), ff(p.Recall)})
+	}
+	return w.write("roc", roc, rows)
+}
+
+func (w reportWriter) precisionRecall(points []evaluation.Point) error {
+	rows := [][]string{{"threshold", "tp", "fp", "tn", "fn", "precision", "recall", "f1"}}
+	for _, p := range points {
+		rows = append(rows, []string{
+			ff(p.Threshold),
+			strconv.Itoa(p.TP), strconv.Itoa(p.FP), strconv.Itoa(p.TN), strconv.Itoa(p.FN),
+			ff(p.Precision), ff(p.Recall), ff(p.F1),
+		})
+	}
+	return w.write("precision_recall", points, rows)
+}
+
+func (w reportWriter) confusion(name string, c evaluation.Confusion, limit int) error {
+	report := confusionReport{Point: c.Point, Examples: []example{}}
+	add := func(kind string, results []evaluation.Result) {
+		for i, r := range results {
+			if i == limit {
+				break
+			}
+			report.Examples = append(report.Examples, example{
+				Kind:      kind,
+				ID:        r.ID,
+				Photo1URL: r.Photo1URL,
+				Photo2URL: r.Photo2URL,
+				Score:     r.Score,
+				Analysis:  r.Analysis,
+			})
+		}
+	}
+	add("false_positive", c.FalsePositives)
+	add("false_negative", c.FalseNegatives)
+
+	rows := [][]string{{"kind", "id", "photo1_url", "photo2_url", "score", "analysis"}}
+	for _, e := range report.Examples {
+		rows = append(rows, []string{e.Kind, e.ID, e.Photo1URL, e.Photo2URL, ff(e.Score), e.Analysis})
+	}
+	return w.write(name, report, rows)
+}
+
+func (w reportWriter) write(name string, v any, rows [][]string) error {
+	if w.json {
+		if err := w.writeJSON(name, v); err != nil {
+			return err
+		}
+	}
+	if w.csv {
+		return w.writeCSV(name, rows)
+	}
+	return nil
+}
+
+func (w reportWriter) writeJSON(name string, v any) error {
+	f, err := os.Create(filepath.Join(w.dir, name+".json"))
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (w reportWriter) writeCSV(name string, rows [][]string) error {
+	f, err := os.Create(filepath.Join(w.dir, name+".csv"))
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(f)
+	if err := cw.WriteAll(rows); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func ff(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
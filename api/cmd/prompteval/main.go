@@ -0,0 +1,110 @@
+// Command prompteval replays a labelled set of photo pairs against two
+// versions of the Gemini face comparison prompt and prints precision and
+// recall for each at every score threshold.
+//
+// Usage:
+//
+//	GEMINI_API_KEY=... go run ./cmd/prompteval -pairs pairs.csv -baseline v1 -candidate v2
+//
+// pairs.csv has the columns photo1_url, photo2_url, same and optionally id.
+// Draft prompts not yet embedded in the binary can be loaded with
+// -prompts <dir>, laid out as <dir>/face_compare/<version>.tmpl.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/l3co/traceo-api/internal/config"
+	"github.com/l3co/traceo-api/internal/evaluation"
+	"github.com/l3co/traceo-api/internal/infrastructure/ai"
+	"github.com/l3co/traceo-api/pkg/resilience"
+	"github.com/l3co/traceo-api/pkg/safefetch"
+)
+
+func main() {
+	pairsPath := flag.String("pairs", "", "CSV file with labelled photo pairs")
+	baseline := flag.String("baseline", ai.DefaultFaceCompareVersion, "baseline prompt version")
+	candidate := flag.String("candidate", "", "candidate prompt version")
+	promptDir := flag.String("prompts", "", "directory with extra prompt versions")
+	step := flag.Float64("step", 0.05, "threshold step")
+	concurrency := flag.Int("concurrency", 2, "concurrent Gemini calls")
+	flag.Parse()
+
+	if err := run(*pairsPath, *baseline, *candidate, *promptDir, *step, *concurrency); err != nil {
+		fmt.Fprintln(os.Stderr, "prompteval:", err)
+		os.Exit(1)
+	}
+}
+
+func run(pairsPath, baseline, candidate, promptDir string, step float64, concurrency int) error {
+	if pairsPath == "" || candidate == "" {
+		return fmt.Errorf("-pairs and -candidate are required")
+	}
+
+	cfg := config.Load()
+	if cfg.GeminiAPIKey == "" {
+		return fmt.Errorf("GEMINI_API_KEY is not set")
+	}
+
+	f, err := os.Open(pairsPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	pairs, err := evaluation.LoadPairs(f)
+	if err != nil {
+		return fmt.Errorf("loading pairs: %w", err)
+	}
+
+	prompts := ai.DefaultPrompts()
+	if promptDir != "" {
+		if err := prompts.Add(os.DirFS(promptDir), "."); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	client, err := ai.NewGeminiClient(ctx, cfg.GeminiAPIKey, safefetch.New(safefetch.Config{}))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	thresholds := evaluation.Thresholds(step)
+	versions := []string{baseline, candidate}
+	curves := make([][]evaluation.Point, len(versions))
+	for i, version := range versions {
+		prompt, err := prompts.Get(ai.PromptFaceCompare, version)
+		if err != nil {
+			return err
+		}
+
+		comparer := ai.NewResilientComparer(
+			ai.NewGeminiComparer(client.WithComparePrompt(prompt)),
+			nil,
+			ai.DefaultBackoff,
+			resilience.NewBreaker("gemini", 5, time.Minute),
+		)
+
+		slog.Info("evaluating prompt", "prompt", prompt.Ref(), "pairs", len(pairs))
+		results := evaluation.Run(ctx, comparer, pairs, concurrency)
+		if n := evaluation.Failures(results); n > 0 {
+			slog.Warn("pairs failed and were excluded", "prompt", prompt.Ref(), "failed", n)
+		}
+		curves[i] = evaluation.PrecisionRecall(results, thresholds)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "threshold\tP(%s)\tR(%s)\tP(%s)\tR(%s)\t\n", baseline, baseline, candidate, candidate)
+	for i, t := range thresholds {
+		a, b := curves[0][i], curves[1][i]
+		fmt.Fprintf(w, "%.2f\t%.3f\t%.3f\t%.3f\t%.3f\t\n", t, a.Precision, a.Recall, b.Precision, b.Recall)
+	}
+	return w.Flush()
+}
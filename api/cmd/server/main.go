@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -16,18 +18,31 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/l3co/traceo-api/internal/config"
+	"github.com/l3co/traceo-api/internal/domain/event"
 	"github.com/l3co/traceo-api/internal/domain/homeless"
+	"github.com/l3co/traceo-api/internal/domain/job"
 	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/internal/domain/media"
 	"github.com/l3co/traceo-api/internal/domain/missing"
 	"github.com/l3co/traceo-api/internal/domain/sighting"
+	"github.com/l3co/traceo-api/internal/domain/timeline"
 	"github.com/l3co/traceo-api/internal/domain/user"
 	"github.com/l3co/traceo-api/internal/handler"
 	"github.com/l3co/traceo-api/internal/handler/middleware"
 	"github.com/l3co/traceo-api/internal/i18n"
+	"github.com/l3co/traceo-api/internal/infrastructure/aging"
 	"github.com/l3co/traceo-api/internal/infrastructure/ai"
+	"github.com/l3co/traceo-api/internal/infrastructure/embedding"
 	"github.com/l3co/traceo-api/internal/infrastructure/firebase"
+	"github.com/l3co/traceo-api/internal/infrastructure/imaging"
+	"github.com/l3co/traceo-api/internal/infrastructure/memory"
 	"github.com/l3co/traceo-api/internal/infrastructure/notification"
+	"github.com/l3co/traceo-api/internal/infrastructure/photohash"
+	"github.com/l3co/traceo-api/internal/infrastructure/storage"
+	"github.com/l3co/traceo-api/internal/scheduler"
 	"github.com/l3co/traceo-api/internal/worker"
+	"github.com/l3co/traceo-api/pkg/resilience"
+	"github.com/l3co/traceo-api/pkg/safefetch"
 
 	_ "github.com/l3co/traceo-api/docs/swagger"
 )
@@ -55,7 +70,7 @@ func main() {
 	}
 
 	ctx := context.Background()
-	fbClient, err := firebase.NewClient(ctx, cfg.FirebaseProjectID)
+	fbClient, err := firebase.NewClient(ctx, cfg.FirebaseProjectID, cfg.StorageBucket)
 	if err != nil {
 		slog.Error("failed to initialize firebase", slog.String("error", err.Error()))
 		os.Exit(1)
@@ -67,7 +82,6 @@ func main() {
 	userService := user.NewService(userRepo, authService)
 
 	missingRepo := firebase.NewMissingRepository(fbClient.Firestore)
-	missingService := missing.NewService(missingRepo)
 
 	var emailSender *notification.EmailSender
 	if cfg.ResendAPIKey != "" {
@@ -79,37 +93,205 @@ func main() {
 	}
 	notifier := notification.NewService(emailSender, telegramSender)
 
+	timelineService := timeline.NewService(firebase.NewTimelineRepository(fbClient.Firestore))
+
 	sightingRepo := firebase.NewSightingRepository(fbClient.Firestore)
-	sightingService := sighting.NewService(sightingRepo, missingRepo, notifier)
+	sightingService := sighting.NewService(sightingRepo, missingRepo, notifier, sighting.WithTimeline(timelineService))
 
 	homelessRepo := firebase.NewHomelessRepository(fbClient.Firestore)
-	homelessService := homeless.NewService(homelessRepo, notifier)
-
 	matchRepo := firebase.NewMatchRepository(fbClient.Firestore)
 
-	var faceComparer matching.FaceComparer
-	var aiWorker *worker.AIWorker
-	if cfg.GeminiAPIKey != "" {
-		geminiClient, err := ai.NewGeminiClient(ctx, cfg.GeminiAPIKey)
-		if err != nil {
-			slog.Error("failed to initialize gemini client", slog.String("error", err.Error()))
+	// Photo URLs come from users; only our storage may be fetched. Local
+	// storage is served by this process, on a loopback address.
+	photoSources := safefetch.URLList(cfg.PhotoSources())
+	if len(photoSources) == 0 {
+		slog.Error("no photo source configured: set STORAGE_BUCKET, PHOTO_URL_HOSTS or STORAGE_BACKEND=local")
+		os.Exit(1)
+	}
+	photoFetcher := safefetch.New(safefetch.Config{
+		AllowedHosts: photoSources.Hosts(),
+		AllowedURLs:  photoSources,
+		AllowPrivate: cfg.StorageBackend == "local",
+		MaxBytes:     int64(cfg.UploadMaxBytes),
+	})
+
+	faceEmbedder := embedding.NewHOGEmbedder(photoFetcher)
+	localComparer := embedding.NewComparer(faceEmbedder, cfg.LocalMatchBase)
+
+	// Every Gemini call goes through the breaker and aiBudget; cache hits
+	// (below) and the demo fake do not use the budget.
+	var (
+		aiBudget      *matching.Budget
+		geminiBreaker *resilience.Breaker
+		geminiMatcher matching.FaceComparer
+		faceDescriber matching.FaceDescriber
+	)
+	geminiClient, err := newGeminiClient(ctx, cfg, photoFetcher)
+	if err != nil {
+		slog.Error("failed to initialize gemini client", slog.String("error", err.Error()))
+		if cfg.FaceComparer == "demo" {
+			os.Exit(1)
+		}
+	}
+	if geminiClient != nil {
+		defer geminiClient.Close()
+		geminiComparer := ai.NewGeminiComparer(geminiClient)
+		var (
+			comparer  matching.FaceComparer  = geminiComparer
+			describer matching.FaceDescriber = geminiComparer
+		)
+
+		// The demo fake costs nothing, so it neither spends nor waits for
+		// the budget of the real API.
+		if cfg.FaceComparer != "demo" {
+			var usage matching.UsageCounter = firebase.NewUsageCounter(fbClient.Firestore)
+			if cfg.AIUsageStore == "memory" {
+				usage = memory.NewUsageCounter()
+			}
+			aiBudget = matching.NewBudget(matching.BudgetConfig{
+				CallsPerMinute: cfg.AICallsPerMinute,
+				Burst:          cfg.AIMaxConcurrency,
+				MaxConcurrency: cfg.AIMaxConcurrency,
+				DailyLimit:     cfg.AIDailyCallBudget,
+				MonthlyLimit:   cfg.AIMonthlyCallBudget,
+			}, usage)
+			comparer = matching.NewBudgetedComparer(geminiComparer, aiBudget, nil)
+			describer = matching.NewBudgetedDescriber(geminiComparer, aiBudget)
+		}
+
+		backoff := ai.DefaultBackoff
+		backoff.MaxAttempts = cfg.AIRetryAttempts
+		geminiBreaker = resilience.NewBreaker("gemini", cfg.AIBreakerThreshold, time.Duration(cfg.AIBreakerCooldown)*time.Second)
+		resilient := ai.NewResilientComparer(comparer, describer, backoff, geminiBreaker)
+		geminiMatcher = resilient
+		faceDescriber = resilient
+	}
+
+	faceComparer, err := newFaceComparer(cfg, localComparer, geminiMatcher)
+	if err != nil {
+		slog.Error("invalid face comparer", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if faceComparer == nil {
+		slog.Warn("face matching disabled: set GEMINI_API_KEY, or FACE_COMPARER=demo in development")
+	} else {
+		switch cfg.ComparisonCache {
+		case "firestore":
+			faceComparer = matching.NewCachingComparer(faceComparer, firebase.NewComparisonCache(fbClient.Firestore), photohash.NewHasher(photoFetcher))
+		case "memory":
+			faceComparer = matching.NewCachingComparer(faceComparer, memory.NewComparisonCache(), photohash.NewHasher(photoFetcher))
+		}
+	}
+	matchingPolicy := matching.Policy{
+		SaveThreshold:      cfg.MatchSaveThreshold,
+		NotifyThreshold:    cfg.MatchNotifyThreshold,
+		CandidateLimit:     cfg.MatchCandidateLimit,
+		GenderFilter:       matching.FilterMode(cfg.MatchGenderFilter),
+		SkinFilter:         matching.FilterMode(cfg.MatchSkinFilter),
+		SoftFilterPenalty:  cfg.MatchSoftFilterPenalty,
+		AgeTolerance:       cfg.MatchAgeTolerance,
+		AgeToleranceGrowth: cfg.MatchAgeToleranceGrowth,
+		MaxAgeTolerance:    cfg.MatchMaxAgeTolerance,
+	}
+	if err := matchingPolicy.Validate(); err != nil {
+		slog.Error("invalid matching policy", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	slog.Info("matching policy loaded", slog.String("version", matchingPolicy.Version()))
+
+	mediaStorage, err := newMediaStorage(cfg, fbClient)
+	if err != nil {
+		slog.Error("failed to initialize media storage", slog.String("error", err.Error()))
+	}
+
+	// Admins can moderate as well.
+	moderators := append(append([]string{}, cfg.ModeratorUserIDs...), cfg.AdminUserIDs...)
+	matchingOpts := []matching.Option{
+		matching.WithPolicy(matchingPolicy),
+		matching.WithModerators(moderators),
+		matching.WithTimeline(timelineService),
+	}
+	if cfg.AgeProgressionGenerator == "placeholder" {
+		if mediaStorage == nil {
+			slog.Warn("age progression images need STORAGE_BUCKET or STORAGE_BACKEND=local, storing descriptions only")
 		} else {
-			defer geminiClient.Close()
-			faceComparer = newGeminiComparer(geminiClient)
+			matchingOpts = append(matchingOpts, matching.WithAgeProgression(aging.NewPlaceholderAger(photoFetcher), mediaStorage, cfg.AgeProgressionOffsets))
 		}
 	}
+	switch cfg.CandidateIndex {
+	case "firestore":
+		matchingOpts = append(matchingOpts, matching.WithCandidateIndex(faceEmbedder, firebase.NewCandidateIndex(fbClient.Firestore)))
+	case "memory":
+		matchingOpts = append(matchingOpts, matching.WithCandidateIndex(faceEmbedder, memory.NewCandidateIndex()))
+	}
+	var jobQueue job.Queue
+	if cfg.JobQueueBackend == "memory" {
+		jobQueue = memory.NewJobQueue()
+	} else {
+		jobQueue = firebase.NewJobQueue(fbClient.Firestore)
+	}
+	retryPolicy := job.DefaultRetryPolicy()
+	retryPolicy.MaxAttempts = cfg.JobMaxAttempts
+	if err := retryPolicy.Validate(); err != nil {
+		slog.Error("invalid job retry policy", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	jobService := job.NewService(jobQueue, retryPolicy)
 
-	var faceDescriber matching.FaceDescriber
 	if faceComparer != nil {
-		faceDescriber = faceComparer.(*geminiComparer)
+		// The sweep enqueues pair jobs straight into the queue; the worker
+		// picks them up on its next poll.
+		switch cfg.PairLedger {
+		case "firestore":
+			matchingOpts = append(matchingOpts, matching.WithPairLedger(firebase.NewPairLedger(fbClient.Firestore), worker.NewDispatcher(jobService)))
+		case "memory":
+			matchingOpts = append(matchingOpts, matching.WithPairLedger(memory.NewPairLedger(), worker.NewDispatcher(jobService)))
+		}
+		if aiBudget != nil {
+			matchingOpts = append(matchingOpts, matching.WithSweepBudget(aiBudget, cfg.MatchSweepBudgetShare))
+		}
 	}
-	matchingService := matching.NewService(missingRepo, homelessRepo, matchRepo, faceComparer, faceDescriber, notifier)
+	matchingService := matching.NewService(missingRepo, homelessRepo, matchRepo, faceComparer, faceDescriber, notifier, matchingOpts...)
 
+	var publisher event.Publisher
 	if faceComparer != nil {
-		aiWorker = worker.NewAIWorker(matchingService, 3)
+		var workerOpts []worker.Option
+		if geminiBreaker != nil {
+			workerOpts = append(workerOpts, worker.WithBreaker(geminiBreaker))
+		}
+		aiWorker := worker.NewAIWorker(jobService, matchingService, 3, workerOpts...)
 		defer aiWorker.Shutdown()
+		publisher = worker.NewDispatcher(aiWorker)
+	}
+
+	photoHasher := photohash.NewPerceptualHasher(photoFetcher)
+	missingOpts := []missing.Option{
+		missing.WithPerceptualHasher(photoHasher),
+		missing.WithModerators(moderators),
+		missing.WithPhotoSources(photoSources),
+		missing.WithTimeline(timelineService),
+	}
+	if mediaStorage != nil {
+		missingOpts = append(missingOpts, missing.WithPhotoBlurrer(imaging.NewBlurrer(photoFetcher, mediaStorage)))
+	} else {
+		slog.Warn("blurred photos need STORAGE_BUCKET or STORAGE_BACKEND=local, restricted photos are hidden from anonymous visitors")
+	}
+	missingService := missing.NewService(missingRepo, publisher, missingOpts...)
+	homelessService := homeless.NewService(homelessRepo, notifier, publisher,
+		homeless.WithPerceptualHasher(photoHasher),
+		homeless.WithModerators(moderators),
+		homeless.WithPhotoSources(photoSources),
+	)
+
+	if cfg.SchedulerBackend != "none" {
+		sched, err := newScheduler(cfg, fbClient, missingService, matchingService, jobService)
+		if err != nil {
+			slog.Error("invalid scheduler configuration", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		sched.Start()
+		defer sched.Shutdown()
 	}
-	_ = aiWorker
 
 	userHandler := handler.NewUserHandler(userService)
 	authHandler := handler.NewAuthHandler(userService)
@@ -117,11 +299,23 @@ func main() {
 	sightingHandler := handler.NewSightingHandler(sightingService)
 	homelessHandler := handler.NewHomelessHandler(homelessService)
 	matchHandler := handler.NewMatchHandler(matchingService)
+	timelineHandler := handler.NewTimelineHandler(timelineService, missingService)
 	metaHandler := handler.NewMetaHandler(missingService)
 	sitemapHandler := handler.NewSitemapHandler(missingService, homelessService)
-	healthHandler := handler.NewHealthHandler(fbClient.Firestore, "1.0.0")
+	var breakers []*resilience.Breaker
+	if geminiBreaker != nil {
+		breakers = append(breakers, geminiBreaker)
+	}
+	healthHandler := handler.NewHealthHandler(fbClient.Firestore, "1.0.0", breakers...)
+	jobHandler := handler.NewJobHandler(jobService, moderators)
+	aiUsageHandler := handler.NewAIUsageHandler(aiBudget)
+	var uploader *media.Uploader
+	if mediaStorage != nil {
+		uploader = media.NewUploader(mediaStorage, imaging.NewProcessor())
+	}
+	uploadHandler := handler.NewUploadHandler(uploader, int64(cfg.UploadMaxBytes))
 
-	r := setupRouter(cfg, authService, userHandler, authHandler, missingHandler, sightingHandler, homelessHandler, matchHandler, metaHandler, sitemapHandler, healthHandler)
+	r := setupRouter(cfg, authService, userHandler, authHandler, missingHandler, sightingHandler, timelineHandler, homelessHandler, matchHandler, metaHandler, sitemapHandler, healthHandler, jobHandler, aiUsageHandler, uploadHandler)
 
 	server := &http.Server{
 		Addr:         ":" + cfg.Port,
@@ -158,30 +352,131 @@ func main() {
 	slog.Info("server stopped gracefully")
 }
 
-type geminiComparer struct {
-	client *ai.GeminiClient
-}
+// newScheduler registers the periodic tasks. A schedule of "off" disables a
+// task.
+func newScheduler(cfg *config.Config, fbClient *firebase.Client, missingService *missing.Service, matchingService *matching.Service, jobService *job.Service) (*scheduler.Scheduler, error) {
+	var leases scheduler.Leases = firebase.NewSchedulerLeases(fbClient.Firestore)
+	if cfg.SchedulerBackend == "memory" {
+		leases = memory.NewSchedulerLeases()
+	}
+	sched := scheduler.New(leases)
 
-func newGeminiComparer(client *ai.GeminiClient) *geminiComparer {
-	return &geminiComparer{client: client}
-}
+	if cfg.AgeProgressionRefreshSchedule != "off" && !matchingService.GeneratesAgeProgressions() {
+		slog.Warn("no face describer or age progression generator, age progression refresh disabled")
+	} else if cfg.AgeProgressionRefreshSchedule != "off" {
+		schedule, err := scheduler.Parse(cfg.AgeProgressionRefreshSchedule)
+		if err != nil {
+			return nil, err
+		}
+		maxAge := time.Duration(cfg.AgeProgressionRefreshAfterDays) * 24 * time.Hour
+		err = sched.Add(&scheduler.Task{
+			Name:     "age_progression_refresh",
+			Schedule: schedule,
+			Run: func(ctx context.Context) error {
+				_, err := missingService.RefreshAgeProgressions(ctx, maxAge, cfg.AgeProgressionRefreshBatch)
+				return err
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.MatchSweepSchedule != "off" {
+		schedule, err := scheduler.Parse(cfg.MatchSweepSchedule)
+		if err != nil {
+			return nil, err
+		}
+		err = sched.Add(&scheduler.Task{
+			Name:     "match_sweep",
+			Schedule: schedule,
+			Run: func(ctx context.Context) error {
+				_, err := matchingService.Sweep(ctx, cfg.MatchSweepMaxPairs)
+				return err
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.JobPurgeSchedule != "off" {
+		schedule, err := scheduler.Parse(cfg.JobPurgeSchedule)
+		if err != nil {
+			return nil, err
+		}
+		retention := time.Duration(cfg.JobRetentionDays) * 24 * time.Hour
+		err = sched.Add(&scheduler.Task{
+			Name:     "job_purge",
+			Schedule: schedule,
+			Run: func(ctx context.Context) error {
+				_, err := jobService.PurgeSucceeded(ctx, retention)
+				return err
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 
-func (g *geminiComparer) DescribeFace(ctx context.Context, photoURL string, currentAge int, gender string) (string, error) {
-	return g.client.DescribeFace(ctx, photoURL, currentAge, gender)
+	return sched, nil
 }
 
-func (g *geminiComparer) CompareFaces(ctx context.Context, photo1URL, photo2URL string) (*matching.FaceComparisonResult, error) {
-	result, err := g.client.CompareFaces(ctx, photo1URL, photo2URL)
+// newMediaStorage returns the media.Storage named by STORAGE_BACKEND, or nil
+// when Firebase Storage has no bucket configured.
+func newMediaStorage(cfg *config.Config, fbClient *firebase.Client) (media.Storage, error) {
+	if cfg.StorageBackend == "local" {
+		return storage.NewLocalStorage(cfg.StorageLocalDir, cfg.StorageLocalURL), nil
+	}
+	if cfg.StorageBucket == "" {
+		return nil, nil
+	}
+	bucket, err := fbClient.Storage.Bucket(cfg.StorageBucket)
 	if err != nil {
 		return nil, err
 	}
-	return &matching.FaceComparisonResult{
-		SimilarityScore:   result.SimilarityScore,
-		Analysis:          result.Analysis,
-		MatchingFeatures:  result.MatchingFeatures,
-		DifferentFeatures: result.DifferentFeatures,
-		Confidence:        result.Confidence,
-	}, nil
+	return storage.NewFirebaseStorage(bucket, cfg.StorageBucket), nil
+}
+
+// newGeminiClient returns the client for FACE_COMPARER, or nil when Gemini
+// is not configured. "demo" swaps the API for a deterministic fake and is
+// refused outside development.
+func newGeminiClient(ctx context.Context, cfg *config.Config, fetcher *safefetch.Fetcher) (*ai.GeminiClient, error) {
+	if cfg.FaceComparer == "demo" {
+		if !cfg.IsDevelopment() {
+			return nil, errors.New("FACE_COMPARER=demo is only allowed in development")
+		}
+		slog.Warn("using demo face comparer, match scores are synthetic")
+		return ai.NewDemoClient(fetcher), nil
+	}
+	if cfg.GeminiAPIKey == "" {
+		return nil, nil
+	}
+	return ai.NewGeminiClient(ctx, cfg.GeminiAPIKey, fetcher)
+}
+
+// newFaceComparer picks the matching.FaceComparer named by FACE_COMPARER:
+// "gemini" or "cascade" (local pre-filter in front of Gemini); "demo" uses
+// the fake Gemini client. The local embeddings are not a face recogniser, so
+// they only ever filter pairs out and never decide a match on their own; a
+// local comparer that works without Gemini waits for a face-recognition
+// model (see docs/FASE_06_INTELIGENCIA.md) and "local" is refused until
+// then. It returns nil when no Gemini client is configured.
+func newFaceComparer(cfg *config.Config, local, gemini matching.FaceComparer) (matching.FaceComparer, error) {
+	switch cfg.FaceComparer {
+	case "gemini", "cascade", "demo":
+	case "local":
+		return nil, errors.New("FACE_COMPARER=local needs a face-recognition model, which is not bundled yet; use gemini, cascade or demo")
+	default:
+		return nil, fmt.Errorf("unknown FACE_COMPARER %q", cfg.FaceComparer)
+	}
+	if gemini == nil {
+		return nil, nil
+	}
+	if cfg.FaceComparer == "cascade" {
+		return matching.NewCascadeComparer(local, gemini, cfg.PrefilterMinScore), nil
+	}
+	return gemini, nil
 }
 
 func setupLogger(cfg *config.Config) {
@@ -201,11 +496,15 @@ func setupRouter(
 	authHandler *handler.AuthHandler,
 	missingHandler *handler.MissingHandler,
 	sightingHandler *handler.SightingHandler,
+	timelineHandler *handler.TimelineHandler,
 	homelessHandler *handler.HomelessHandler,
 	matchHandler *handler.MatchHandler,
 	metaHandler *handler.MetaHandler,
 	sitemapHandler *handler.SitemapHandler,
 	healthHandler *handler.HealthHandler,
+	jobHandler *handler.JobHandler,
+	aiUsageHandler *handler.AIUsageHandler,
+	uploadHandler *handler.UploadHandler,
 ) *chi.Mux {
 	r := chi.NewRouter()
 
@@ -214,7 +513,7 @@ func setupRouter(
 	r.Use(chimiddleware.Logger)
 	r.Use(chimiddleware.Recoverer)
 	r.Use(middleware.SecurityHeaders)
-	r.Use(middleware.BodyLimit(1 << 20)) // 1 MB
+	r.Use(middleware.BodyLimit(1<<20, "/api/v1/photos")) // 1 MB; uploads use UPLOAD_MAX_BYTES
 	r.Use(i18n.Middleware)
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   cfg.AllowedOrigins,
@@ -227,11 +526,15 @@ func setupRouter(
 
 	globalLimiter := middleware.NewRateLimiter(rate.Every(time.Second/4), 50) // ~200 req/min
 	r.Use(globalLimiter.Handler)
+	uploadLimiter := middleware.NewRateLimiter(rate.Every(6*time.Second), 5) // ~10 uploads/min
 
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
 	r.Get("/robots.txt", metaHandler.RobotsTxt)
 	r.Get("/sitemap.xml", sitemapHandler.Serve)
 	r.Get("/share/missing/{id}", metaHandler.ServeMissingMeta)
+	if cfg.StorageBackend == "local" {
+		r.Handle("/media/*", http.StripPrefix("/media/", http.FileServer(http.Dir(cfg.StorageLocalDir))))
+	}
 
 	r.Route("/api/v1", func(r chi.Router) {
 		r.Get("/health", healthHandler.Check)
@@ -239,22 +542,38 @@ func setupRouter(
 		r.Post("/users", userHandler.Create)
 		r.Post("/auth/forgot-password", authHandler.ForgotPassword)
 
-		r.Get("/missing", missingHandler.List)
-		r.Get("/missing/search", missingHandler.Search)
+		r.Group(func(r chi.Router) {
+			// Signed-in users see the photos of restricted cases; owners
+			// and moderators also see match reviews and the full timeline,
+			// and moderators the errors of AI jobs.
+			r.Use(middleware.OptionalAuth(authService))
+
+			r.Get("/missing", missingHandler.List)
+			r.Get("/missing/search", missingHandler.Search)
+			r.Get("/missing/{id}", missingHandler.FindByID)
+			r.Get("/missing/{id}/age-progression", missingHandler.GetAgeProgression)
+			r.Get("/missing/{id}/photos", missingHandler.ListPhotos)
+			r.Get("/missing/{id}/timeline", timelineHandler.List)
+			r.Get("/missing/{id}/jobs", jobHandler.FindByMissingID)
+			r.Get("/jobs/{id}", jobHandler.FindByID)
+
+			r.Get("/homeless/{id}/matches", matchHandler.FindByHomelessID)
+			r.Get("/missing/{id}/matches", matchHandler.FindByMissingID)
+		})
 		r.Get("/missing/stats", missingHandler.Stats)
 		r.Get("/missing/locations", missingHandler.Locations)
-		r.Get("/missing/{id}", missingHandler.FindByID)
-		r.Get("/missing/{id}/age-progression", missingHandler.GetAgeProgression)
 		r.Get("/missing/{id}/sightings", sightingHandler.FindByMissingID)
 		r.Get("/sightings/{sightingId}", sightingHandler.FindByID)
 
 		r.Get("/homeless", homelessHandler.List)
 		r.Get("/homeless/stats", homelessHandler.Stats)
 		r.Get("/homeless/{id}", homelessHandler.FindByID)
-		r.Post("/homeless", homelessHandler.Create)
-
-		r.Get("/homeless/{id}/matches", matchHandler.FindByHomelessID)
-		r.Get("/missing/{id}/matches", matchHandler.FindByMissingID)
+		r.Get("/homeless/{id}/photos", homelessHandler.ListPhotos)
+		// Creating a report downloads and hashes its photos, so it shares
+		// the upload budget.
+		r.With(uploadLimiter.Handler).Post("/homeless", homelessHandler.Create)
+		// Public like POST /homeless, whose reports use the returned URL.
+		r.With(uploadLimiter.Handler).Post("/photos", uploadHandler.Upload)
 
 		r.Group(func(r chi.Router) {
 			r.Use(middleware.Auth(authService))
@@ -271,7 +590,24 @@ func setupRouter(
 
 			r.Post("/missing/{id}/sightings", sightingHandler.Create)
 			r.Patch("/missing/{id}/status", missingHandler.UpdateStatus)
-			r.Patch("/matches/{id}", matchHandler.UpdateStatus)
+			r.Post("/missing/{id}/photos", missingHandler.AddPhoto)
+			r.Patch("/missing/{id}/photos/{photoId}", missingHandler.UpdatePhoto)
+			r.Delete("/missing/{id}/photos/{photoId}", missingHandler.DeletePhoto)
+			r.Post("/homeless/{id}/photos", homelessHandler.AddPhoto)
+			r.Patch("/homeless/{id}/photos/{photoId}", homelessHandler.UpdatePhoto)
+			r.Delete("/homeless/{id}/photos/{photoId}", homelessHandler.DeletePhoto)
+			r.Patch("/matches/{id}", matchHandler.Review)
+
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireAdmin(cfg.AdminUserIDs))
+
+				r.Get("/admin/jobs/dead", jobHandler.ListDead)
+				r.Delete("/admin/jobs/dead", jobHandler.PurgeAllDead)
+				r.Post("/admin/jobs/dead/{id}/retry", jobHandler.RetryDead)
+				r.Delete("/admin/jobs/dead/{id}", jobHandler.PurgeDead)
+
+				r.Get("/admin/ai/usage", aiUsageHandler.Usage)
+			})
 		})
 	})
 
@@ -2,7 +2,9 @@ package config
 
 import (
 	"log/slog"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -17,6 +19,54 @@ type Config struct {
 	GeminiAPIKey      string
 	TelegramBotToken  string
 	TelegramChatID    string
+	AdminUserIDs      []string
+	ModeratorUserIDs  []string
+	JobQueueBackend   string
+	JobMaxAttempts    int
+	FaceComparer      string
+	LocalMatchBase    float64
+	PrefilterMinScore float64
+	CandidateIndex    string
+	ComparisonCache   string
+
+	AICallsPerMinute    int
+	AIMaxConcurrency    int
+	AIDailyCallBudget   int64
+	AIMonthlyCallBudget int64
+	AIUsageStore        string
+	AIRetryAttempts     int
+	AIBreakerThreshold  int
+	AIBreakerCooldown   int
+
+	StorageBackend          string
+	StorageBucket           string
+	StorageLocalDir         string
+	StorageLocalURL         string
+	UploadMaxBytes          int
+	PhotoURLHosts           []string
+	AgeProgressionGenerator string
+	AgeProgressionOffsets   []int
+
+	SchedulerBackend               string
+	AgeProgressionRefreshSchedule  string
+	AgeProgressionRefreshAfterDays int
+	AgeProgressionRefreshBatch     int
+	PairLedger                     string
+	MatchSweepSchedule             string
+	MatchSweepMaxPairs             int
+	MatchSweepBudgetShare          float64
+	JobPurgeSchedule               string
+	JobRetentionDays               int
+
+	MatchSaveThreshold      float64
+	MatchNotifyThreshold    float64
+	MatchCandidateLimit     int
+	MatchGenderFilter       string
+	MatchSkinFilter         string
+	MatchSoftFilterPenalty  float64
+	MatchAgeTolerance       int
+	MatchAgeToleranceGrowth float64
+	MatchMaxAgeTolerance    int
 }
 
 func Load() *Config {
@@ -31,6 +81,54 @@ func Load() *Config {
 		GeminiAPIKey:      getEnv("GEMINI_API_KEY", ""),
 		TelegramBotToken:  getEnv("TELEGRAM_BOT_TOKEN", ""),
 		TelegramChatID:    getEnv("TELEGRAM_CHAT_ID", ""),
+		AdminUserIDs:      getEnvList("ADMIN_USER_IDS"),
+		ModeratorUserIDs:  getEnvList("MODERATOR_USER_IDS"),
+		JobQueueBackend:   getEnv("JOB_QUEUE_BACKEND", "firestore"),
+		JobMaxAttempts:    getEnvInt("JOB_MAX_ATTEMPTS", 5),
+		FaceComparer:      getEnv("FACE_COMPARER", "gemini"),
+		LocalMatchBase:    getEnvFloat("LOCAL_MATCH_BASELINE", 0.5),
+		PrefilterMinScore: getEnvFloat("PREFILTER_MIN_SCORE", 0.3),
+		CandidateIndex:    getEnv("CANDIDATE_INDEX", "none"),
+		ComparisonCache:   getEnv("COMPARISON_CACHE", "firestore"),
+
+		AICallsPerMinute:    getEnvInt("AI_CALLS_PER_MINUTE", 30),
+		AIMaxConcurrency:    getEnvInt("AI_MAX_CONCURRENCY", 4),
+		AIDailyCallBudget:   int64(getEnvInt("AI_DAILY_CALL_BUDGET", 2000)),
+		AIMonthlyCallBudget: int64(getEnvInt("AI_MONTHLY_CALL_BUDGET", 40000)),
+		AIUsageStore:        getEnv("AI_USAGE_STORE", "firestore"),
+		AIRetryAttempts:     getEnvInt("AI_RETRY_ATTEMPTS", 3),
+		AIBreakerThreshold:  getEnvInt("AI_BREAKER_THRESHOLD", 5),
+		AIBreakerCooldown:   getEnvInt("AI_BREAKER_COOLDOWN_SECONDS", 60),
+
+		StorageBackend:          getEnv("STORAGE_BACKEND", "firebase"),
+		StorageBucket:           getEnv("STORAGE_BUCKET", ""),
+		StorageLocalDir:         getEnv("STORAGE_LOCAL_DIR", "./data/media"),
+		StorageLocalURL:         getEnv("STORAGE_LOCAL_URL", "http://localhost:8080/media"),
+		UploadMaxBytes:          getEnvInt("UPLOAD_MAX_BYTES", 10<<20),
+		PhotoURLHosts:           getEnvList("PHOTO_URL_HOSTS"),
+		AgeProgressionGenerator: getEnv("AGE_PROGRESSION_GENERATOR", "none"),
+		AgeProgressionOffsets:   getEnvIntList("AGE_PROGRESSION_OFFSETS", []int{0, 5, 10}),
+
+		SchedulerBackend:               getEnv("SCHEDULER_BACKEND", "firestore"),
+		AgeProgressionRefreshSchedule:  getEnv("AGE_PROGRESSION_REFRESH_SCHEDULE", "0 4 * * *"),
+		AgeProgressionRefreshAfterDays: getEnvInt("AGE_PROGRESSION_REFRESH_AFTER_DAYS", 730),
+		AgeProgressionRefreshBatch:     getEnvInt("AGE_PROGRESSION_REFRESH_BATCH", 50),
+		PairLedger:                     getEnv("PAIR_LEDGER", "firestore"),
+		MatchSweepSchedule:             getEnv("MATCH_SWEEP_SCHEDULE", "30 2 * * *"),
+		MatchSweepMaxPairs:             getEnvInt("MATCH_SWEEP_MAX_PAIRS", 500),
+		MatchSweepBudgetShare:          getEnvFloat("MATCH_SWEEP_BUDGET_SHARE", 0.5),
+		JobPurgeSchedule:               getEnv("JOB_PURGE_SCHEDULE", "0 5 * * *"),
+		JobRetentionDays:               getEnvInt("JOB_RETENTION_DAYS", 7),
+
+		MatchSaveThreshold:      getEnvFloat("MATCH_SAVE_THRESHOLD", 0.6),
+		MatchNotifyThreshold:    getEnvFloat("MATCH_NOTIFY_THRESHOLD", 0.8),
+		MatchCandidateLimit:     getEnvInt("MATCH_CANDIDATE_LIMIT", 20),
+		MatchGenderFilter:       getEnv("MATCH_GENDER_FILTER", "hard"),
+		MatchSkinFilter:         getEnv("MATCH_SKIN_FILTER", "hard"),
+		MatchSoftFilterPenalty:  getEnvFloat("MATCH_SOFT_FILTER_PENALTY", 0.1),
+		MatchAgeTolerance:       getEnvInt("MATCH_AGE_TOLERANCE", 15),
+		MatchAgeToleranceGrowth: getEnvFloat("MATCH_AGE_TOLERANCE_GROWTH", 0.5),
+		MatchMaxAgeTolerance:    getEnvInt("MATCH_MAX_AGE_TOLERANCE", 25),
 	}
 }
 
@@ -38,6 +136,30 @@ func (c *Config) IsDevelopment() bool {
 	return c.Environment == "development"
 }
 
+// PhotoSources lists where photo URLs may point at, as safefetch.URLList
+// entries ("host/path/prefix/"): PHOTO_URL_HOSTS when set, else the bucket
+// or directory the configured storage backend serves from. It is empty when
+// Firebase Storage has no bucket configured; callers must then refuse to run
+// rather than accept photos from anywhere.
+func (c *Config) PhotoSources() []string {
+	if len(c.PhotoURLHosts) > 0 {
+		return c.PhotoURLHosts
+	}
+	if c.StorageBackend == "local" {
+		if u, err := url.Parse(c.StorageLocalURL); err == nil && u.Host != "" {
+			return []string{u.Host + u.Path + "/"}
+		}
+		return nil
+	}
+	if c.StorageBucket == "" {
+		return nil
+	}
+	return []string{
+		"firebasestorage.googleapis.com/v0/b/" + c.StorageBucket + "/",
+		"storage.googleapis.com/" + c.StorageBucket + "/",
+	}
+}
+
 func getEnv(key, fallback string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
@@ -45,6 +167,68 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+func getEnvInt(key string, fallback int) int {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		slog.Warn("invalid integer environment variable, using default",
+			slog.String("key", key),
+			slog.Int("default", fallback),
+		)
+		return fallback
+	}
+	return n
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		slog.Warn("invalid float environment variable, using default",
+			slog.String("key", key),
+			slog.Float64("default", fallback),
+		)
+		return fallback
+	}
+	return f
+}
+
+func getEnvList(key string) []string {
+	var result []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func getEnvIntList(key string, fallback []int) []int {
+	items := getEnvList(key)
+	if len(items) == 0 {
+		return fallback
+	}
+	result := make([]int, 0, len(items))
+	for _, item := range items {
+		n, err := strconv.Atoi(item)
+		if err != nil {
+			slog.Warn("invalid integer list environment variable, using default",
+				slog.String("key", key),
+				slog.Any("default", fallback),
+			)
+			return fallback
+		}
+		result = append(result, n)
+	}
+	return result
+}
+
 func requireEnv(key string) string {
 	val := os.Getenv(key)
 	if val == "" {
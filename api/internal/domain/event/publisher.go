@@ -0,0 +1,52 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrDuplicate is returned by Publish when the work an event asks for, as
+// identified by its Key, has already been requested.
+var ErrDuplicate = errors.New("duplicate event")
+
+type Type string
+
+const (
+	HomelessCreated     Type = "homeless.created"
+	MissingCreated      Type = "missing.created"
+	MissingPhotoChanged Type = "missing.photo_changed"
+	// MissingPhotoAdded is sent when a photo other than the primary one is
+	// added, which may give matching a better pair to compare.
+	MissingPhotoAdded Type = "missing.photo_added"
+	// HomelessPhotoChanged is sent when a homeless record gains a photo or
+	// its primary photo changes.
+	HomelessPhotoChanged Type = "homeless.photo_changed"
+	// MissingAgeProgressionDue asks for a fresh age progression of a
+	// long-running case.
+	MissingAgeProgressionDue Type = "missing.age_progression_due"
+	// MatchPairDue asks for one homeless/missing pair to be compared. The
+	// aggregate is the missing case and CandidateID the homeless record.
+	MatchPairDue Type = "match.pair_due"
+	// MissingDeleted is sent after a missing case is deleted.
+	MissingDeleted Type = "missing.deleted"
+)
+
+// Event is a domain fact emitted by a service after its state has been
+// persisted. Subscribers (e.g. the AI job dispatcher) must not assume the
+// event is delivered more than once.
+type Event struct {
+	Type        Type
+	AggregateID string
+	CandidateID string
+	// Key, when set, identifies the work requested so that repeated events
+	// for the same work are processed once.
+	Key        string
+	PhotoURL   string
+	BirthDate  time.Time
+	OccurredAt time.Time
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
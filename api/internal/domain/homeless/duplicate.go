@@ -0,0 +1,68 @@
+package homeless
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/l3co/traceo-api/internal/domain/shared"
+)
+
+// duplicateCandidateLimit bounds the records fetched per hash band for one
+// duplicate check.
+const duplicateCandidateLimit = 50
+
+// checkDuplicates returns a *shared.DuplicateError listing the records whose
+// photos resemble h's primary photo, closest first. Lookup failures are
+// logged and let the registration through.
+func (s *Service) checkDuplicates(ctx context.Context, h *Homeless) error {
+	p := h.Photos.Primary()
+	if p == nil || p.Hash == 0 {
+		return nil
+	}
+
+	found, err := s.repo.FindByPhotoHash(ctx, p.Hash, duplicateCandidateLimit)
+	if err != nil {
+		slog.Warn("duplicate photo check failed", "error", err)
+		return nil
+	}
+
+	candidates := make([]shared.DuplicateCandidate, 0, len(found))
+	for _, c := range found {
+		candidates = append(candidates, shared.DuplicateCandidate{
+			Duplicate: shared.Duplicate{ID: c.ID, Name: c.Name, Slug: c.Slug},
+			Photos:    c.Photos,
+		})
+	}
+	return shared.FindDuplicates(p.Hash, candidates)
+}
+
+// BackfillPhotoHashes hashes the photos of every record that were stored
+// without a hash and rewrites each record's photo hash index, so that
+// records registered before duplicate detection, or before the index last
+// changed, are found by it. It returns how many records were rewritten.
+func (s *Service) BackfillPhotoHashes(ctx context.Context) (int, error) {
+	if s.hasher == nil {
+		return 0, nil
+	}
+
+	all, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("listing homeless: %w", err)
+	}
+
+	updated := 0
+	for _, h := range all {
+		if len(h.Photos) == 0 {
+			continue
+		}
+		shared.HashUnhashedPhotos(ctx, s.hasher, h.Photos)
+		if err := s.repo.UpdatePhotos(ctx, h.ID, h.Photos); err != nil {
+			return updated, fmt.Errorf("updating photos of %s: %w", h.ID, err)
+		}
+		updated++
+	}
+
+	slog.Info("homeless photo hash backfill finished", "updated", updated)
+	return updated, nil
+}
@@ -18,7 +18,9 @@ type Homeless struct {
 	Eyes      shared.EyeColor
 	Hair      shared.HairColor
 	Skin      shared.SkinColor
+	// PhotoURL mirrors the primary entry of Photos.
 	PhotoURL  string
+	Photos    shared.Photos
 	Location  shared.GeoPoint
 	Slug      string
 	CreatedAt time.Time
@@ -26,12 +28,16 @@ type Homeless struct {
 }
 
 func (h *Homeless) Age() int {
+	return h.AgeAt(time.Now())
+}
+
+// AgeAt returns the person's age on t, or 0 when the birth date is unknown.
+func (h *Homeless) AgeAt(t time.Time) int {
 	if h.BirthDate.IsZero() {
 		return 0
 	}
-	now := time.Now()
-	age := now.Year() - h.BirthDate.Year()
-	if now.YearDay() < h.BirthDate.YearDay() {
+	age := t.Year() - h.BirthDate.Year()
+	if t.YearDay() < h.BirthDate.YearDay() {
 		age--
 	}
 	return age
@@ -64,6 +70,11 @@ func (h *Homeless) Validate() error {
 	if !h.BirthDate.IsZero() && h.BirthDate.After(time.Now()) {
 		return fmt.Errorf("%w: birth date cannot be in the future", ErrInvalidHomeless)
 	}
+	if h.PhotoURL != "" {
+		if err := shared.ValidatePhotoURL(h.PhotoURL); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidHomeless, err)
+		}
+	}
 	return nil
 }
 
@@ -81,4 +92,7 @@ type CreateInput struct {
 	Lat       float64
 	Lng       float64
 	Address   string
+	// AllowDuplicate skips the duplicate photo check, once the reporter has
+	// seen the possible duplicates and confirmed this is someone else.
+	AllowDuplicate bool
 }
@@ -5,4 +5,5 @@ import "errors"
 var (
 	ErrHomelessNotFound = errors.New("homeless not found")
 	ErrInvalidHomeless  = errors.New("invalid homeless")
+	ErrForbidden        = errors.New("only moderators can change the photos of a homeless record")
 )
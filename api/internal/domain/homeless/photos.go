@@ -0,0 +1,103 @@
+package homeless
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/l3co/traceo-api/internal/domain/event"
+	"github.com/l3co/traceo-api/internal/domain/shared"
+)
+
+// AddPhoto adds p to the record on behalf of moderator userID. The age at
+// the photo is derived from TakenAt when it is not given.
+func (s *Service) AddPhoto(ctx context.Context, id, userID string, p shared.Photo) (*shared.Photo, error) {
+	h, err := s.findForPhotoChange(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := shared.CheckPhotoSource(s.sources, p.URL); err != nil {
+		return nil, err
+	}
+
+	p = shared.HashPhoto(ctx, s.hasher, newPhoto(h, p))
+	if err := h.Photos.Add(p); err != nil {
+		return nil, err
+	}
+
+	if err := s.savePhotos(ctx, h, true); err != nil {
+		return nil, err
+	}
+	return h.Photos.Find(p.ID), nil
+}
+
+func (s *Service) UpdatePhoto(ctx context.Context, id, userID, photoID string, u shared.PhotoUpdate) (*shared.Photo, error) {
+	h, err := s.findForPhotoChange(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := h.PhotoURL
+	if _, err := h.Photos.Update(photoID, u); err != nil {
+		return nil, err
+	}
+
+	if err := s.savePhotos(ctx, h, h.Photos.PrimaryURL() != previous); err != nil {
+		return nil, err
+	}
+	return h.Photos.Find(photoID), nil
+}
+
+func (s *Service) RemovePhoto(ctx context.Context, id, userID, photoID string) error {
+	h, err := s.findForPhotoChange(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	previous := h.PhotoURL
+	if err := h.Photos.Remove(photoID); err != nil {
+		return err
+	}
+
+	return s.savePhotos(ctx, h, h.Photos.PrimaryURL() != previous)
+}
+
+// findForPhotoChange loads the record whose photos userID wants to change.
+// Homeless records have no owner, so only moderators may change them.
+func (s *Service) findForPhotoChange(ctx context.Context, id, userID string) (*Homeless, error) {
+	h, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !s.moderators[userID] {
+		return nil, ErrForbidden
+	}
+	return h, nil
+}
+
+func newPhoto(h *Homeless, p shared.Photo) shared.Photo {
+	p.ID = uuid.NewString()
+	p.AddedAt = time.Now()
+	if p.AgeAtPhoto == 0 && !p.TakenAt.IsZero() {
+		p.AgeAtPhoto = max(h.AgeAt(p.TakenAt), 0)
+	}
+	return p
+}
+
+// savePhotos persists h's photos and, when changed is set, asks for matching
+// to run again.
+func (s *Service) savePhotos(ctx context.Context, h *Homeless, changed bool) error {
+	h.PhotoURL = h.Photos.PrimaryURL()
+	h.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdatePhotos(ctx, h.ID, h.Photos); err != nil {
+		return fmt.Errorf("updating photos: %w", err)
+	}
+
+	if changed {
+		s.publish(ctx, event.HomelessPhotoChanged, h)
+	}
+	return nil
+}
@@ -11,10 +11,51 @@ type GenderStat struct {
 	Count  int64
 }
 
+// CandidateFilter selects homeless records that may match a missing person.
+// An empty Gender or Skin matches any value. Records without a birth date are
+// never excluded by the age window. Limit caps the number of matching records
+// returned, counted after every other criterion is applied. Records come in
+// ID order, and a non-empty After resumes the listing after that ID.
+type CandidateFilter struct {
+	Gender shared.Gender
+	Skin   shared.SkinColor
+	MinAge int
+	MaxAge int
+	Limit  int
+	After  string
+}
+
+// Matches reports whether h satisfies every criterion of the filter except
+// Limit and After.
+func (f CandidateFilter) Matches(h *Homeless) bool {
+	if (f.Gender != "" && h.Gender != f.Gender) || (f.Skin != "" && h.Skin != f.Skin) {
+		return false
+	}
+	if h.BirthDate.IsZero() {
+		return true
+	}
+	age := h.Age()
+	if f.MinAge > 0 && age < f.MinAge {
+		return false
+	}
+	if f.MaxAge > 0 && age > f.MaxAge {
+		return false
+	}
+	return true
+}
+
 type Repository interface {
 	Create(ctx context.Context, h *Homeless) error
 	FindByID(ctx context.Context, id string) (*Homeless, error)
 	FindAll(ctx context.Context) ([]*Homeless, error)
 	Count(ctx context.Context) (int64, error)
 	CountByGender(ctx context.Context) ([]GenderStat, error)
+	FindCandidates(ctx context.Context, filter CandidateFilter) ([]*Homeless, error)
+	// UpdatePhotos replaces the photo list and sets PhotoURL to its primary
+	// entry, leaving other fields untouched.
+	UpdatePhotos(ctx context.Context, id string, photos shared.Photos) error
+	// FindByPhotoHash returns records with a photo that may be within
+	// DuplicateMaxDistance of h, up to limit per hash band. Callers check the
+	// actual distance.
+	FindByPhotoHash(ctx context.Context, h shared.PerceptualHash, limit int) ([]*Homeless, error)
 }
@@ -9,22 +9,58 @@ import (
 	"github.com/google/uuid"
 	"github.com/microcosm-cc/bluemonday"
 
+	"github.com/l3co/traceo-api/internal/domain/event"
 	"github.com/l3co/traceo-api/internal/domain/notification"
 	"github.com/l3co/traceo-api/internal/domain/shared"
 )
 
 type Service struct {
-	repo      Repository
-	notifier  notification.Notifier
-	sanitizer *bluemonday.Policy
+	repo       Repository
+	notifier   notification.Notifier
+	publisher  event.Publisher
+	sanitizer  *bluemonday.Policy
+	hasher     shared.PerceptualHasher
+	moderators map[string]bool
+	sources    shared.PhotoSources
 }
 
-func NewService(repo Repository, notifier notification.Notifier) *Service {
-	return &Service{
-		repo:      repo,
-		notifier:  notifier,
-		sanitizer: bluemonday.StrictPolicy(),
+type Option func(*Service)
+
+// WithPhotoSources refuses photo URLs that do not point at sources, checked
+// before anything is downloaded from them.
+func WithPhotoSources(sources shared.PhotoSources) Option {
+	return func(s *Service) { s.sources = sources }
+}
+
+// WithModerators lets the given user IDs add, change and remove the photos
+// of homeless records. Nobody else can.
+func WithModerators(ids []string) Option {
+	return func(s *Service) {
+		for _, id := range ids {
+			s.moderators[id] = true
+		}
+	}
+}
+
+// WithPerceptualHasher makes the service hash every new photo and refuse
+// registrations whose photo resembles an existing record's, unless the
+// duplicate check is overridden.
+func WithPerceptualHasher(h shared.PerceptualHasher) Option {
+	return func(s *Service) { s.hasher = h }
+}
+
+func NewService(repo Repository, notifier notification.Notifier, publisher event.Publisher, opts ...Option) *Service {
+	s := &Service{
+		repo:       repo,
+		notifier:   notifier,
+		publisher:  publisher,
+		sanitizer:  bluemonday.StrictPolicy(),
+		moderators: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 func (s *Service) Create(ctx context.Context, input CreateInput) (*Homeless, error) {
@@ -39,7 +75,6 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (*Homeless, err
 		Eyes:      input.Eyes,
 		Hair:      input.Hair,
 		Skin:      input.Skin,
-		PhotoURL:  input.PhotoURL,
 		Location: shared.GeoPoint{
 			Lat:     input.Lat,
 			Lng:     input.Lng,
@@ -49,16 +84,33 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (*Homeless, err
 		UpdatedAt: now,
 	}
 
+	if input.PhotoURL != "" {
+		if err := shared.CheckPhotoSource(s.sources, input.PhotoURL); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidHomeless, err)
+		}
+		if err := h.Photos.Add(shared.HashPhoto(ctx, s.hasher, newPhoto(h, shared.Photo{URL: input.PhotoURL}))); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidHomeless, err)
+		}
+		h.PhotoURL = h.Photos.PrimaryURL()
+	}
+
 	h.GenerateSlug()
 
 	if err := h.Validate(); err != nil {
 		return nil, err
 	}
+	if !input.AllowDuplicate {
+		if err := s.checkDuplicates(ctx, h); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := s.repo.Create(ctx, h); err != nil {
 		return nil, fmt.Errorf("creating homeless: %w", err)
 	}
 
+	s.publish(ctx, event.HomelessCreated, h)
+
 	if s.notifier != nil {
 		go func() {
 			bgCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
@@ -86,6 +138,27 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (*Homeless, err
 	return h, nil
 }
 
+func (s *Service) publish(ctx context.Context, t event.Type, h *Homeless) {
+	if s.publisher == nil {
+		return
+	}
+
+	err := s.publisher.Publish(ctx, event.Event{
+		Type:        t,
+		AggregateID: h.ID,
+		PhotoURL:    h.PhotoURL,
+		BirthDate:   h.BirthDate,
+		OccurredAt:  time.Now(),
+	})
+	if err != nil {
+		slog.Error("failed to publish homeless event",
+			"type", string(t),
+			"id", h.ID,
+			"error", err,
+		)
+	}
+}
+
 func (s *Service) FindByID(ctx context.Context, id string) (*Homeless, error) {
 	if id == "" {
 		return nil, fmt.Errorf("%w: id is required", ErrInvalidHomeless)
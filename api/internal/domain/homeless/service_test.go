@@ -2,6 +2,7 @@ package homeless_test
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -9,8 +10,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/l3co/traceo-api/internal/domain/event"
 	"github.com/l3co/traceo-api/internal/domain/homeless"
 	"github.com/l3co/traceo-api/internal/domain/shared"
+	"github.com/l3co/traceo-api/pkg/safefetch"
 )
 
 // --- Mock Notifier ---
@@ -39,6 +42,17 @@ func (m *mockNotifier) CallCount() int {
 	return len(m.calls)
 }
 
+// --- Mock Publisher ---
+
+type mockPublisher struct {
+	events []event.Event
+}
+
+func (m *mockPublisher) Publish(_ context.Context, e event.Event) error {
+	m.events = append(m.events, e)
+	return nil
+}
+
 // --- Mock Repository ---
 
 type mockRepo struct {
@@ -79,6 +93,43 @@ func (m *mockRepo) CountByGender(_ context.Context) ([]homeless.GenderStat, erro
 	return result, nil
 }
 
+func (m *mockRepo) FindCandidates(_ context.Context, _ homeless.CandidateFilter) ([]*homeless.Homeless, error) {
+	return m.items, nil
+}
+
+func (m *mockRepo) UpdatePhotos(_ context.Context, id string, photos shared.Photos) error {
+	for _, item := range m.items {
+		if item.ID == id {
+			item.Photos = photos
+			item.PhotoURL = photos.PrimaryURL()
+			return nil
+		}
+	}
+	return homeless.ErrHomelessNotFound
+}
+
+func (m *mockRepo) FindByPhotoHash(_ context.Context, h shared.PerceptualHash, limit int) ([]*homeless.Homeless, error) {
+	var result []*homeless.Homeless
+	for _, item := range m.items {
+		if item.Photos.HashDistance(h) >= 0 && len(result) < limit {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// --- Mock PerceptualHasher ---
+
+type mockHasher map[string]shared.PerceptualHash
+
+func (m mockHasher) PerceptualHash(_ context.Context, url string) (shared.PerceptualHash, error) {
+	h, ok := m[url]
+	if !ok {
+		return 0, errors.New("not found")
+	}
+	return h, nil
+}
+
 // --- Helpers ---
 
 func validInput() homeless.CreateInput {
@@ -101,7 +152,7 @@ func validInput() homeless.CreateInput {
 func TestCreate_Success(t *testing.T) {
 	repo := &mockRepo{}
 	notifier := &mockNotifier{}
-	svc := homeless.NewService(repo, notifier)
+	svc := homeless.NewService(repo, notifier, nil)
 
 	result, err := svc.Create(context.Background(), validInput())
 
@@ -115,7 +166,7 @@ func TestCreate_Success(t *testing.T) {
 
 func TestCreate_SanitizesInput(t *testing.T) {
 	repo := &mockRepo{}
-	svc := homeless.NewService(repo, nil)
+	svc := homeless.NewService(repo, nil, nil)
 
 	input := validInput()
 	input.Name = "<script>xss</script>Carlos"
@@ -128,7 +179,7 @@ func TestCreate_SanitizesInput(t *testing.T) {
 
 func TestCreate_MissingName(t *testing.T) {
 	repo := &mockRepo{}
-	svc := homeless.NewService(repo, nil)
+	svc := homeless.NewService(repo, nil, nil)
 
 	input := validInput()
 	input.Name = ""
@@ -140,7 +191,7 @@ func TestCreate_MissingName(t *testing.T) {
 
 func TestCreate_InvalidGender(t *testing.T) {
 	repo := &mockRepo{}
-	svc := homeless.NewService(repo, nil)
+	svc := homeless.NewService(repo, nil, nil)
 
 	input := validInput()
 	input.Gender = "invalid"
@@ -150,10 +201,23 @@ func TestCreate_InvalidGender(t *testing.T) {
 	assert.ErrorIs(t, err, homeless.ErrInvalidHomeless)
 }
 
+func TestCreate_ForeignPhotoHost(t *testing.T) {
+	svc := homeless.NewService(&mockRepo{}, nil, nil,
+		homeless.WithPhotoSources(safefetch.URLList{"firebasestorage.googleapis.com/v0/b/traceo/"}))
+
+	input := validInput()
+	input.PhotoURL = "http://169.254.169.254/latest/meta-data/"
+
+	_, err := svc.Create(context.Background(), input)
+
+	assert.ErrorIs(t, err, homeless.ErrInvalidHomeless)
+	assert.ErrorIs(t, err, shared.ErrInvalidPhoto)
+}
+
 func TestCreate_NotifierCalled(t *testing.T) {
 	repo := &mockRepo{}
 	notifier := &mockNotifier{}
-	svc := homeless.NewService(repo, notifier)
+	svc := homeless.NewService(repo, notifier, nil)
 
 	_, err := svc.Create(context.Background(), validInput())
 	require.NoError(t, err)
@@ -163,11 +227,123 @@ func TestCreate_NotifierCalled(t *testing.T) {
 	assert.Equal(t, 1, notifier.CallCount())
 }
 
+func TestCreate_PublishesEvent(t *testing.T) {
+	publisher := &mockPublisher{}
+	svc := homeless.NewService(&mockRepo{}, nil, publisher)
+
+	result, err := svc.Create(context.Background(), validInput())
+
+	require.NoError(t, err)
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, event.HomelessCreated, publisher.events[0].Type)
+	assert.Equal(t, result.ID, publisher.events[0].AggregateID)
+	assert.Equal(t, result.PhotoURL, publisher.events[0].PhotoURL)
+}
+
+func TestCreate_AddsPrimaryPhoto(t *testing.T) {
+	svc := homeless.NewService(&mockRepo{}, nil, nil)
+
+	result, err := svc.Create(context.Background(), validInput())
+
+	require.NoError(t, err)
+	require.Len(t, result.Photos, 1)
+	assert.True(t, result.Photos[0].Primary)
+}
+
+// --- Tests: Duplicates ---
+
+func TestCreate_PossibleDuplicate(t *testing.T) {
+	hasher := mockHasher{
+		"https://example.com/photo.jpg": 0xF0F0F0F0F0F0F0F0,
+		"https://example.com/again.jpg": 0xF0F0F0F0F0F0F0F3, // 2 bits apart
+		"https://example.com/other.jpg": 0x0F0F0F0F0F0F0F0F,
+	}
+	repo := &mockRepo{}
+	svc := homeless.NewService(repo, nil, nil, homeless.WithPerceptualHasher(hasher))
+	first, err := svc.Create(context.Background(), validInput())
+	require.NoError(t, err)
+	assert.Equal(t, shared.PerceptualHash(0xF0F0F0F0F0F0F0F0), first.Photos[0].Hash)
+
+	input := validInput()
+	input.PhotoURL = "https://example.com/again.jpg"
+	_, err = svc.Create(context.Background(), input)
+
+	require.ErrorIs(t, err, shared.ErrPossibleDuplicate)
+	var dupErr *shared.DuplicateError
+	require.ErrorAs(t, err, &dupErr)
+	require.Len(t, dupErr.Duplicates, 1)
+	assert.Equal(t, first.ID, dupErr.Duplicates[0].ID)
+	assert.Equal(t, 2, dupErr.Duplicates[0].Distance)
+	assert.Len(t, repo.items, 1)
+
+	input.AllowDuplicate = true
+	_, err = svc.Create(context.Background(), input)
+	require.NoError(t, err)
+
+	input = validInput()
+	input.PhotoURL = "https://example.com/other.jpg"
+	_, err = svc.Create(context.Background(), input)
+	require.NoError(t, err)
+	assert.Len(t, repo.items, 3)
+}
+
+func TestCreate_HashFailure_SkipsDuplicateCheck(t *testing.T) {
+	svc := homeless.NewService(&mockRepo{}, nil, nil, homeless.WithPerceptualHasher(mockHasher{}))
+
+	result, err := svc.Create(context.Background(), validInput())
+
+	require.NoError(t, err)
+	assert.Zero(t, result.Photos[0].Hash)
+}
+
+// --- Tests: Photos ---
+
+func TestAddPhoto_PublishesPhotoChanged(t *testing.T) {
+	publisher := &mockPublisher{}
+	svc := homeless.NewService(&mockRepo{}, nil, publisher, homeless.WithModerators([]string{"mod-1"}))
+	created, err := svc.Create(context.Background(), validInput())
+	require.NoError(t, err)
+
+	_, err = svc.AddPhoto(context.Background(), created.ID, "mod-1", shared.Photo{URL: "https://example.com/side.jpg"})
+
+	require.NoError(t, err)
+	require.Len(t, publisher.events, 2)
+	assert.Equal(t, event.HomelessPhotoChanged, publisher.events[1].Type)
+	assert.Equal(t, "https://example.com/photo.jpg", publisher.events[1].PhotoURL)
+}
+
+func TestUpdatePhoto_NotFound(t *testing.T) {
+	svc := homeless.NewService(&mockRepo{}, nil, nil, homeless.WithModerators([]string{"mod-1"}))
+	created, err := svc.Create(context.Background(), validInput())
+	require.NoError(t, err)
+
+	_, err = svc.UpdatePhoto(context.Background(), created.ID, "mod-1", "nope", shared.PhotoUpdate{})
+
+	assert.ErrorIs(t, err, shared.ErrPhotoNotFound)
+}
+
+func TestPhotoChanges_OnlyModerators(t *testing.T) {
+	repo := &mockRepo{}
+	svc := homeless.NewService(repo, nil, nil, homeless.WithModerators([]string{"mod-1"}))
+	created, err := svc.Create(context.Background(), validInput())
+	require.NoError(t, err)
+	photoID := created.Photos[0].ID
+
+	_, err = svc.AddPhoto(context.Background(), created.ID, "user-1", shared.Photo{URL: "https://example.com/side.jpg"})
+	assert.ErrorIs(t, err, homeless.ErrForbidden)
+
+	_, err = svc.UpdatePhoto(context.Background(), created.ID, "user-1", photoID, shared.PhotoUpdate{})
+	assert.ErrorIs(t, err, homeless.ErrForbidden)
+
+	assert.ErrorIs(t, svc.RemovePhoto(context.Background(), created.ID, "", photoID), homeless.ErrForbidden)
+	assert.Len(t, repo.items[0].Photos, 1)
+}
+
 // --- Tests: FindByID ---
 
 func TestFindByID_Success(t *testing.T) {
 	repo := &mockRepo{}
-	svc := homeless.NewService(repo, nil)
+	svc := homeless.NewService(repo, nil, nil)
 
 	created, _ := svc.Create(context.Background(), validInput())
 
@@ -178,7 +354,7 @@ func TestFindByID_Success(t *testing.T) {
 }
 
 func TestFindByID_EmptyID(t *testing.T) {
-	svc := homeless.NewService(&mockRepo{}, nil)
+	svc := homeless.NewService(&mockRepo{}, nil, nil)
 
 	_, err := svc.FindByID(context.Background(), "")
 
@@ -186,7 +362,7 @@ func TestFindByID_EmptyID(t *testing.T) {
 }
 
 func TestFindByID_NotFound(t *testing.T) {
-	svc := homeless.NewService(&mockRepo{}, nil)
+	svc := homeless.NewService(&mockRepo{}, nil, nil)
 
 	_, err := svc.FindByID(context.Background(), "nonexistent")
 
@@ -197,7 +373,7 @@ func TestFindByID_NotFound(t *testing.T) {
 
 func TestFindAll_Success(t *testing.T) {
 	repo := &mockRepo{}
-	svc := homeless.NewService(repo, nil)
+	svc := homeless.NewService(repo, nil, nil)
 
 	svc.Create(context.Background(), validInput())
 	svc.Create(context.Background(), validInput())
@@ -212,7 +388,7 @@ func TestFindAll_Success(t *testing.T) {
 
 func TestCount_Success(t *testing.T) {
 	repo := &mockRepo{}
-	svc := homeless.NewService(repo, nil)
+	svc := homeless.NewService(repo, nil, nil)
 
 	svc.Create(context.Background(), validInput())
 
@@ -241,3 +417,23 @@ func TestEntity_Validate(t *testing.T) {
 	}
 	assert.NoError(t, h.Validate())
 }
+
+func TestCandidateFilter_Matches(t *testing.T) {
+	filter := homeless.CandidateFilter{
+		Gender: shared.GenderMale,
+		Skin:   shared.SkinBrown,
+		MinAge: 20,
+		MaxAge: 40,
+	}
+	h := &homeless.Homeless{Gender: shared.GenderMale, Skin: shared.SkinBrown}
+	assert.True(t, filter.Matches(h), "records without birth date are kept")
+
+	h.BirthDate = time.Now().AddDate(-60, 0, 0)
+	assert.False(t, filter.Matches(h))
+
+	h.BirthDate = time.Now().AddDate(-30, 0, 0)
+	assert.True(t, filter.Matches(h))
+
+	h.Skin = shared.SkinWhite
+	assert.False(t, filter.Matches(h))
+}
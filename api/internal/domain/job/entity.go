@@ -0,0 +1,125 @@
+package job
+
+import (
+	"fmt"
+	"time"
+)
+
+type Type string
+
+const (
+	TypeAgeProgression  Type = "age_progression"
+	TypeFaceMatching    Type = "face_matching"
+	TypeMissingMatching Type = "missing_matching"
+	// TypePairMatching compares a single pair: TargetID is the missing case
+	// and CandidateID the homeless record.
+	TypePairMatching Type = "pair_matching"
+	// TypeCandidateRemoval drops a deleted missing case, the TargetID, from
+	// the candidate index.
+	TypeCandidateRemoval Type = "candidate_removal"
+)
+
+func (t Type) IsValid() bool {
+	switch t {
+	case TypeAgeProgression, TypeFaceMatching, TypeMissingMatching, TypePairMatching, TypeCandidateRemoval:
+		return true
+	}
+	return false
+}
+
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+type Job struct {
+	ID          string
+	Type        Type
+	TargetID    string
+	CandidateID string
+	PhotoURL    string
+	BirthDate   time.Time
+	Status      Status
+	Attempts    int
+	LastError   string
+	RunAt       time.Time
+	LeaseOwner  string
+	LeasedUntil time.Time
+	StartedAt   time.Time
+	FinishedAt  time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Duration is the wall time between the first lease and completion (or
+// now, for jobs still in flight). It is zero for jobs that never started.
+func (j *Job) Duration() time.Duration {
+	if j.StartedAt.IsZero() {
+		return 0
+	}
+	if j.FinishedAt.IsZero() {
+		return time.Since(j.StartedAt)
+	}
+	return j.FinishedAt.Sub(j.StartedAt)
+}
+
+func (j *Job) Validate() error {
+	if !j.Type.IsValid() {
+		return fmt.Errorf("%w: invalid type %q", ErrInvalidJob, j.Type)
+	}
+	if j.TargetID == "" {
+		return fmt.Errorf("%w: target_id is required", ErrInvalidJob)
+	}
+	if j.Type == TypePairMatching && j.CandidateID == "" {
+		return fmt.Errorf("%w: candidate_id is required", ErrInvalidJob)
+	}
+	return nil
+}
+
+// RetryPolicy controls how failed jobs are rescheduled before being moved
+// to the dead-letter queue.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    30 * time.Minute,
+	}
+}
+
+// Validate rejects a policy that would bury every job before it ran, or
+// never back off.
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 {
+		return fmt.Errorf("%w: max attempts must be at least 1", ErrInvalidPolicy)
+	}
+	if p.BaseDelay <= 0 || p.MaxDelay < p.BaseDelay {
+		return fmt.Errorf("%w: delays must be positive, with max delay at least the base delay", ErrInvalidPolicy)
+	}
+	return nil
+}
+
+// Backoff returns the delay before the next attempt, doubling from
+// BaseDelay for every attempt already made and capped at MaxDelay.
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := p.BaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,17 @@
+package job
+
+import "errors"
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrInvalidJob  = errors.New("invalid job")
+	// ErrDuplicateJob is returned by Enqueue when a job with the same ID is
+	// already queued, running or kept as a status record.
+	ErrDuplicateJob = errors.New("duplicate job")
+	// ErrLeaseLost is returned by Complete, Retry, Defer and Bury when the
+	// job is no longer leased by the caller, as when its lease expired and
+	// another worker took it.
+	ErrLeaseLost = errors.New("job lease lost")
+	// ErrInvalidPolicy is returned by RetryPolicy.Validate.
+	ErrInvalidPolicy = errors.New("invalid retry policy")
+)
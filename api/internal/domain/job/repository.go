@@ -0,0 +1,34 @@
+package job
+
+import (
+	"context"
+	"time"
+)
+
+// Queue is a durable, lease-based job queue. Lease returns (nil, nil) when
+// no job is ready to run. Completed jobs are kept as status records until
+// PurgeSucceeded removes them; jobs
+// that exhaust their retries are moved to the dead-letter store with
+// StatusFailed. Enqueue returns ErrDuplicateJob, leaving the existing job
+// untouched, when the ID is already taken. Complete, Retry, Defer and Bury
+// only act on a job still leased by owner, and return ErrLeaseLost otherwise.
+type Queue interface {
+	Enqueue(ctx context.Context, j *Job) error
+	FindByID(ctx context.Context, id string) (*Job, error)
+	FindByTargetID(ctx context.Context, targetID string, limit int) ([]*Job, error)
+	Lease(ctx context.Context, owner string, ttl time.Duration) (*Job, error)
+	Complete(ctx context.Context, id, owner string) error
+	Retry(ctx context.Context, id, owner, lastError string, runAt time.Time) error
+	// Defer requeues a leased job for runAt without counting the lease as an
+	// attempt.
+	Defer(ctx context.Context, id, owner, reason string, runAt time.Time) error
+	Bury(ctx context.Context, id, owner, lastError string) error
+	// PurgeSucceeded deletes the status records of jobs that succeeded
+	// before the given time and returns how many it deleted.
+	PurgeSucceeded(ctx context.Context, before time.Time) (int, error)
+
+	ListDead(ctx context.Context, limit int) ([]*Job, error)
+	RequeueDead(ctx context.Context, id string) error
+	PurgeDead(ctx context.Context, id string) error
+	PurgeAllDead(ctx context.Context) (int, error)
+}
@@ -0,0 +1,152 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	queue  Queue
+	policy RetryPolicy
+}
+
+func NewService(queue Queue, policy RetryPolicy) *Service {
+	return &Service{queue: queue, policy: policy}
+}
+
+func (s *Service) Enqueue(ctx context.Context, j *Job) error {
+	if err := j.Validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if j.ID == "" {
+		j.ID = uuid.NewString()
+	}
+	j.Status = StatusQueued
+	j.Attempts = 0
+	j.RunAt = now
+	j.CreatedAt = now
+	j.UpdatedAt = now
+
+	if err := s.queue.Enqueue(ctx, j); err != nil {
+		return fmt.Errorf("enqueueing job: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) FindByID(ctx context.Context, id string) (*Job, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: id is required", ErrInvalidJob)
+	}
+	return s.queue.FindByID(ctx, id)
+}
+
+func (s *Service) FindByTargetID(ctx context.Context, targetID string, limit int) ([]*Job, error) {
+	if targetID == "" {
+		return nil, fmt.Errorf("%w: target_id is required", ErrInvalidJob)
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.queue.FindByTargetID(ctx, targetID, limit)
+}
+
+// Lease takes the next runnable job. A job whose lease keeps expiring, as
+// when it crashes its worker, is re-leased with one more attempt each time;
+// once that goes past the policy's MaxAttempts it is moved to the
+// dead-letter queue instead of being run again.
+func (s *Service) Lease(ctx context.Context, owner string, ttl time.Duration) (*Job, error) {
+	for {
+		j, err := s.queue.Lease(ctx, owner, ttl)
+		if err != nil || j == nil || j.Attempts <= s.policy.MaxAttempts {
+			return j, err
+		}
+
+		slog.Warn("ai job lease expired too often, moving to dead letter",
+			"job_id", j.ID,
+			"type", string(j.Type),
+			"attempts", j.Attempts,
+		)
+		msg := fmt.Sprintf("lease expired after %d attempts", j.Attempts-1)
+		if j.LastError != "" {
+			msg += ": " + j.LastError
+		}
+		if err := s.queue.Bury(ctx, j.ID, owner, msg); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Complete marks j, as returned by Lease, as succeeded.
+func (s *Service) Complete(ctx context.Context, j *Job) error {
+	return s.queue.Complete(ctx, j.ID, j.LeaseOwner)
+}
+
+// Fail records a failed attempt. The job is rescheduled with exponential
+// backoff until it reaches the policy's MaxAttempts, after which it is moved
+// to the dead-letter queue.
+func (s *Service) Fail(ctx context.Context, j *Job, cause error) error {
+	msg := cause.Error()
+
+	if j.Attempts >= s.policy.MaxAttempts {
+		slog.Warn("ai job exhausted retries, moving to dead letter",
+			"job_id", j.ID,
+			"type", string(j.Type),
+			"attempts", j.Attempts,
+		)
+		return s.queue.Bury(ctx, j.ID, j.LeaseOwner, msg)
+	}
+
+	runAt := time.Now().Add(s.policy.Backoff(j.Attempts))
+	return s.queue.Retry(ctx, j.ID, j.LeaseOwner, msg, runAt)
+}
+
+// Defer puts a job back in the queue until runAt without using up one of its
+// attempts. It is meant for jobs that could not run for reasons outside their
+// control, such as an exhausted API budget.
+func (s *Service) Defer(ctx context.Context, j *Job, cause error, runAt time.Time) error {
+	return s.queue.Defer(ctx, j.ID, j.LeaseOwner, cause.Error(), runAt)
+}
+
+// PurgeSucceeded deletes the status records of jobs that succeeded more than
+// maxAge ago. Failed jobs stay in the dead-letter queue for an admin.
+func (s *Service) PurgeSucceeded(ctx context.Context, maxAge time.Duration) (int, error) {
+	n, err := s.queue.PurgeSucceeded(ctx, time.Now().Add(-maxAge))
+	if err != nil {
+		return n, fmt.Errorf("purging succeeded jobs: %w", err)
+	}
+	if n > 0 {
+		slog.Info("succeeded jobs purged", "count", n)
+	}
+	return n, nil
+}
+
+func (s *Service) ListDead(ctx context.Context, limit int) ([]*Job, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	return s.queue.ListDead(ctx, limit)
+}
+
+func (s *Service) RetryDead(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("%w: id is required", ErrInvalidJob)
+	}
+	return s.queue.RequeueDead(ctx, id)
+}
+
+func (s *Service) PurgeDead(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("%w: id is required", ErrInvalidJob)
+	}
+	return s.queue.PurgeDead(ctx, id)
+}
+
+func (s *Service) PurgeAllDead(ctx context.Context) (int, error) {
+	return s.queue.PurgeAllDead(ctx)
+}
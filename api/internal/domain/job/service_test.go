@@ -0,0 +1,328 @@
+package job_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/job"
+)
+
+// --- Mock Queue ---
+
+type mockQueue struct {
+	enqueued []*job.Job
+	retried  map[string]time.Time
+	deferred map[string]time.Time
+	buried   map[string]string
+	dead     map[string]*job.Job
+	ready    []*job.Job
+	purged   time.Time
+}
+
+func newMockQueue() *mockQueue {
+	return &mockQueue{
+		retried:  make(map[string]time.Time),
+		deferred: make(map[string]time.Time),
+		buried:   make(map[string]string),
+		dead:     make(map[string]*job.Job),
+	}
+}
+
+func (m *mockQueue) Enqueue(_ context.Context, j *job.Job) error {
+	m.enqueued = append(m.enqueued, j)
+	return nil
+}
+
+func (m *mockQueue) FindByID(_ context.Context, id string) (*job.Job, error) {
+	for _, j := range m.enqueued {
+		if j.ID == id {
+			return j, nil
+		}
+	}
+	return nil, job.ErrJobNotFound
+}
+
+func (m *mockQueue) FindByTargetID(_ context.Context, targetID string, limit int) ([]*job.Job, error) {
+	var result []*job.Job
+	for _, j := range m.enqueued {
+		if j.TargetID == targetID && len(result) < limit {
+			result = append(result, j)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockQueue) Lease(_ context.Context, _ string, _ time.Duration) (*job.Job, error) {
+	if len(m.ready) == 0 {
+		return nil, nil
+	}
+	j := m.ready[0]
+	m.ready = m.ready[1:]
+	return j, nil
+}
+
+func (m *mockQueue) Complete(_ context.Context, _, _ string) error { return nil }
+
+func (m *mockQueue) Retry(_ context.Context, id, _, _ string, runAt time.Time) error {
+	m.retried[id] = runAt
+	return nil
+}
+
+func (m *mockQueue) Defer(_ context.Context, id, _, _ string, runAt time.Time) error {
+	m.deferred[id] = runAt
+	return nil
+}
+
+func (m *mockQueue) Bury(_ context.Context, id, _, lastError string) error {
+	m.buried[id] = lastError
+	return nil
+}
+
+func (m *mockQueue) PurgeSucceeded(_ context.Context, before time.Time) (int, error) {
+	m.purged = before
+	return 2, nil
+}
+
+func (m *mockQueue) ListDead(_ context.Context, limit int) ([]*job.Job, error) {
+	var result []*job.Job
+	for _, j := range m.dead {
+		result = append(result, j)
+	}
+	return result, nil
+}
+
+func (m *mockQueue) RequeueDead(_ context.Context, id string) error {
+	if _, ok := m.dead[id]; !ok {
+		return job.ErrJobNotFound
+	}
+	delete(m.dead, id)
+	return nil
+}
+
+func (m *mockQueue) PurgeDead(_ context.Context, id string) error {
+	if _, ok := m.dead[id]; !ok {
+		return job.ErrJobNotFound
+	}
+	delete(m.dead, id)
+	return nil
+}
+
+func (m *mockQueue) PurgeAllDead(_ context.Context) (int, error) {
+	n := len(m.dead)
+	m.dead = make(map[string]*job.Job)
+	return n, nil
+}
+
+// --- Tests: Enqueue ---
+
+func TestEnqueue_AssignsIDAndStatus(t *testing.T) {
+	q := newMockQueue()
+	svc := job.NewService(q, job.DefaultRetryPolicy())
+
+	j := &job.Job{Type: job.TypeFaceMatching, TargetID: "h1"}
+	err := svc.Enqueue(context.Background(), j)
+
+	require.NoError(t, err)
+	require.Len(t, q.enqueued, 1)
+	assert.NotEmpty(t, j.ID)
+	assert.Equal(t, job.StatusQueued, j.Status)
+	assert.False(t, j.RunAt.IsZero())
+}
+
+func TestEnqueue_InvalidType(t *testing.T) {
+	svc := job.NewService(newMockQueue(), job.DefaultRetryPolicy())
+
+	err := svc.Enqueue(context.Background(), &job.Job{Type: "unknown", TargetID: "h1"})
+
+	assert.ErrorIs(t, err, job.ErrInvalidJob)
+}
+
+func TestEnqueue_MissingTarget(t *testing.T) {
+	svc := job.NewService(newMockQueue(), job.DefaultRetryPolicy())
+
+	err := svc.Enqueue(context.Background(), &job.Job{Type: job.TypeFaceMatching})
+
+	assert.ErrorIs(t, err, job.ErrInvalidJob)
+}
+
+func TestEnqueue_PairMatchingRequiresCandidate(t *testing.T) {
+	svc := job.NewService(newMockQueue(), job.DefaultRetryPolicy())
+
+	err := svc.Enqueue(context.Background(), &job.Job{Type: job.TypePairMatching, TargetID: "m1"})
+
+	assert.ErrorIs(t, err, job.ErrInvalidJob)
+}
+
+// --- Tests: Status ---
+
+func TestFindByID_Success(t *testing.T) {
+	q := newMockQueue()
+	svc := job.NewService(q, job.DefaultRetryPolicy())
+
+	j := &job.Job{Type: job.TypeAgeProgression, TargetID: "m1"}
+	require.NoError(t, svc.Enqueue(context.Background(), j))
+
+	found, err := svc.FindByID(context.Background(), j.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, job.StatusQueued, found.Status)
+}
+
+func TestFindByID_EmptyID(t *testing.T) {
+	svc := job.NewService(newMockQueue(), job.DefaultRetryPolicy())
+
+	_, err := svc.FindByID(context.Background(), "")
+
+	assert.ErrorIs(t, err, job.ErrInvalidJob)
+}
+
+func TestFindByTargetID_DefaultLimit(t *testing.T) {
+	q := newMockQueue()
+	svc := job.NewService(q, job.DefaultRetryPolicy())
+	for range 25 {
+		require.NoError(t, svc.Enqueue(context.Background(), &job.Job{Type: job.TypeAgeProgression, TargetID: "m1"}))
+	}
+
+	items, err := svc.FindByTargetID(context.Background(), "m1", 0)
+
+	require.NoError(t, err)
+	assert.Len(t, items, 20)
+}
+
+func TestJob_Duration(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	j := &job.Job{StartedAt: start, FinishedAt: start.Add(90 * time.Second)}
+
+	assert.Equal(t, 90*time.Second, j.Duration())
+	assert.Zero(t, (&job.Job{}).Duration())
+}
+
+// --- Tests: Fail ---
+
+func TestFail_RetriesWithBackoff(t *testing.T) {
+	q := newMockQueue()
+	policy := job.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	svc := job.NewService(q, policy)
+
+	before := time.Now()
+	err := svc.Fail(context.Background(), &job.Job{ID: "j1", Attempts: 2}, errors.New("boom"))
+
+	require.NoError(t, err)
+	require.Contains(t, q.retried, "j1")
+	assert.WithinDuration(t, before.Add(2*time.Minute), q.retried["j1"], time.Second)
+	assert.Empty(t, q.buried)
+}
+
+func TestFail_MaxAttempts_MovesToDeadLetter(t *testing.T) {
+	q := newMockQueue()
+	policy := job.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	svc := job.NewService(q, policy)
+
+	err := svc.Fail(context.Background(), &job.Job{ID: "j1", Attempts: 3}, errors.New("boom"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "boom", q.buried["j1"])
+	assert.Empty(t, q.retried)
+}
+
+func TestDefer_DoesNotRetryOrBury(t *testing.T) {
+	q := newMockQueue()
+	policy := job.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	svc := job.NewService(q, policy)
+
+	runAt := time.Now().Add(30 * time.Minute)
+	err := svc.Defer(context.Background(), &job.Job{ID: "j1", Attempts: 3}, errors.New("budget"), runAt)
+
+	require.NoError(t, err)
+	assert.Equal(t, runAt, q.deferred["j1"])
+	assert.Empty(t, q.retried)
+	assert.Empty(t, q.buried)
+}
+
+func TestLease_BuriesJobsPastMaxAttempts(t *testing.T) {
+	q := newMockQueue()
+	// Attempts already counts this lease: j1's lease expired three times.
+	q.ready = []*job.Job{{ID: "j1", Attempts: 4, LastError: "boom"}, {ID: "j2", Attempts: 3}}
+	policy := job.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	svc := job.NewService(q, policy)
+
+	leased, err := svc.Lease(context.Background(), "worker-1", time.Minute)
+
+	require.NoError(t, err)
+	require.NotNil(t, leased)
+	assert.Equal(t, "j2", leased.ID)
+	assert.Equal(t, "lease expired after 3 attempts: boom", q.buried["j1"])
+
+	leased, err = svc.Lease(context.Background(), "worker-1", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, leased)
+}
+
+func TestRetryPolicy_Validate(t *testing.T) {
+	assert.NoError(t, job.DefaultRetryPolicy().Validate())
+
+	p := job.DefaultRetryPolicy()
+	p.MaxAttempts = 0
+	assert.ErrorIs(t, p.Validate(), job.ErrInvalidPolicy)
+
+	p = job.DefaultRetryPolicy()
+	p.MaxDelay = p.BaseDelay / 2
+	assert.ErrorIs(t, p.Validate(), job.ErrInvalidPolicy)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := job.RetryPolicy{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	assert.Equal(t, time.Second, p.Backoff(0))
+	assert.Equal(t, time.Second, p.Backoff(1))
+	assert.Equal(t, 2*time.Second, p.Backoff(2))
+	assert.Equal(t, 8*time.Second, p.Backoff(4))
+	assert.Equal(t, 10*time.Second, p.Backoff(5))
+	assert.Equal(t, 10*time.Second, p.Backoff(50))
+}
+
+func TestPurgeSucceeded(t *testing.T) {
+	q := newMockQueue()
+	svc := job.NewService(q, job.DefaultRetryPolicy())
+
+	n, err := svc.PurgeSucceeded(context.Background(), 7*24*time.Hour)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.WithinDuration(t, time.Now().Add(-7*24*time.Hour), q.purged, time.Second)
+}
+
+// --- Tests: Dead letter ---
+
+func TestRetryDead_NotFound(t *testing.T) {
+	svc := job.NewService(newMockQueue(), job.DefaultRetryPolicy())
+
+	err := svc.RetryDead(context.Background(), "missing")
+
+	assert.ErrorIs(t, err, job.ErrJobNotFound)
+}
+
+func TestPurgeDead_EmptyID(t *testing.T) {
+	svc := job.NewService(newMockQueue(), job.DefaultRetryPolicy())
+
+	err := svc.PurgeDead(context.Background(), "")
+
+	assert.ErrorIs(t, err, job.ErrInvalidJob)
+}
+
+func TestPurgeAllDead(t *testing.T) {
+	q := newMockQueue()
+	q.dead["a"] = &job.Job{ID: "a"}
+	q.dead["b"] = &job.Job{ID: "b"}
+	svc := job.NewService(q, job.DefaultRetryPolicy())
+
+	n, err := svc.PurgeAllDead(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
@@ -0,0 +1,195 @@
+package matching
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/l3co/traceo-api/internal/domain/missing"
+	"github.com/l3co/traceo-api/internal/domain/timeline"
+)
+
+// maxTargetAge bounds the ages AgeTargets asks a FaceAger for.
+const maxTargetAge = 100
+
+// AgeRequest describes one image a FaceAger should render.
+type AgeRequest struct {
+	// FromAge is the person's age in the source photo.
+	FromAge   int
+	TargetAge int
+	Gender    string
+	// Description is the FaceDescriber output, if any. Generators may use
+	// it to keep distinguishing features.
+	Description string
+}
+
+type AgedFace struct {
+	Data        []byte
+	ContentType string
+}
+
+// FaceAger renders a photo's face at an older age.
+type FaceAger interface {
+	AgeFace(ctx context.Context, photoURL string, req AgeRequest) (*AgedFace, error)
+}
+
+// AgeTargets returns the ages to render for someone who was fromAge in the
+// photo and is currentAge today: currentAge plus each offset, skipping ages
+// the photo already shows and ages above maxTargetAge. It returns nil when
+// the age is unknown.
+func AgeTargets(fromAge, currentAge int, offsets []int) []int {
+	if currentAge <= 0 {
+		return nil
+	}
+	seen := make(map[int]bool, len(offsets))
+	var targets []int
+	for _, off := range offsets {
+		age := currentAge + off
+		if age <= fromAge || age > maxTargetAge || seen[age] {
+			continue
+		}
+		seen[age] = true
+		targets = append(targets, age)
+	}
+	sort.Ints(targets)
+	return targets
+}
+
+// GeneratesAgeProgressions reports whether a describer or an ager is
+// configured, without which ProcessAgeProgression does nothing.
+func (s *Service) GeneratesAgeProgressions() bool {
+	return s.describer != nil || s.ager != nil
+}
+
+// ProcessAgeProgression describes the face in photoURL and, when a FaceAger
+// is configured, renders it at the AgeTargets ages. The result replaces the
+// record's previous age progression. The job is skipped when photoURL is no
+// longer the case's primary photo: changing it queues a job of its own.
+func (s *Service) ProcessAgeProgression(ctx context.Context, missingID, photoURL string, birthDate time.Time) error {
+	if photoURL == "" {
+		slog.Warn("no photo for age progression", "missing_id", missingID)
+		return nil
+	}
+
+	if !s.GeneratesAgeProgressions() {
+		slog.Warn("face describer not configured, skipping age progression", "missing_id", missingID)
+		return nil
+	}
+
+	m, err := s.missingRepo.FindByID(ctx, missingID)
+	if err != nil {
+		if errors.Is(err, missing.ErrMissingNotFound) {
+			slog.Warn("missing deleted before age progression", "missing_id", missingID)
+			return nil
+		}
+		return fmt.Errorf("finding missing %s: %w", missingID, err)
+	}
+	if m.PhotoURL != photoURL {
+		slog.Info("primary photo changed, skipping stale age progression", "missing_id", missingID)
+		return nil
+	}
+	if m.BirthDate.IsZero() {
+		m.BirthDate = birthDate
+	}
+
+	now := time.Now()
+	currentAge := m.AgeAt(now)
+	fromAge := currentAge
+	if !m.DateOfDisappearance.IsZero() {
+		fromAge = m.AgeAt(m.DateOfDisappearance)
+	}
+	gender := string(m.Gender)
+	if gender == "" {
+		gender = "unknown"
+	}
+
+	progression := &missing.AgeProgression{
+		SourcePhotoURL: photoURL,
+		GeneratedAt:    now,
+	}
+
+	if s.describer != nil {
+		description, err := s.describer.DescribeFace(ctx, photoURL, currentAge, gender)
+		if err != nil {
+			slog.Error("age progression describe failed",
+				"missing_id", missingID,
+				"error", err.Error(),
+			)
+			return fmt.Errorf("describing face: %w", err)
+		}
+		progression.Description = description
+	}
+
+	if s.ager != nil && s.media != nil {
+		if v, ok := s.ager.(Versioned); ok {
+			progression.Generator = v.Version()
+		}
+		for _, age := range AgeTargets(fromAge, currentAge, s.ageOffsets) {
+			url, err := s.renderAge(ctx, missingID, photoURL, AgeRequest{
+				FromAge:     fromAge,
+				TargetAge:   age,
+				Gender:      gender,
+				Description: progression.Description,
+			})
+			if err != nil {
+				return err
+			}
+			progression.Images = append(progression.Images, missing.AgedImage{Age: age, URL: url})
+		}
+	}
+
+	if err := s.missingRepo.UpdateAgeProgression(ctx, missingID, progression); err != nil {
+		if errors.Is(err, missing.ErrMissingNotFound) {
+			return nil
+		}
+		return fmt.Errorf("saving age progression: %w", err)
+	}
+
+	details := map[string]string{"images": strconv.Itoa(len(progression.Images))}
+	if progression.Generator != "" {
+		details["generator"] = progression.Generator
+	}
+	timeline.RecordOrLog(ctx, s.timeline, &timeline.Event{
+		MissingID:  missingID,
+		Type:       timeline.TypeAgeProgressionGenerated,
+		OccurredAt: progression.GeneratedAt,
+		Details:    details,
+	})
+
+	slog.Info("age progression generated",
+		"missing_id", missingID,
+		"description_length", len(progression.Description),
+		"images", len(progression.Images),
+	)
+
+	return nil
+}
+
+func (s *Service) renderAge(ctx context.Context, missingID, photoURL string, req AgeRequest) (string, error) {
+	face, err := s.ager.AgeFace(ctx, photoURL, req)
+	if err != nil {
+		return "", fmt.Errorf("aging face to %d: %w", req.TargetAge, err)
+	}
+
+	key := fmt.Sprintf("age-progression/%s/%d%s", missingID, req.TargetAge, extensionFor(face.ContentType))
+	url, err := s.media.Put(ctx, key, face.Data, face.ContentType)
+	if err != nil {
+		return "", fmt.Errorf("storing aged face %s: %w", key, err)
+	}
+	return url, nil
+}
+
+func extensionFor(contentType string) string {
+	switch contentType {
+	case "image/png":
+		return ".png"
+	case "image/webp":
+		return ".webp"
+	default:
+		return ".jpg"
+	}
+}
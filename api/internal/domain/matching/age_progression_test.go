@@ -0,0 +1,155 @@
+package matching_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/internal/domain/missing"
+	"github.com/l3co/traceo-api/internal/domain/shared"
+	"github.com/l3co/traceo-api/internal/domain/timeline"
+)
+
+// --- Mock FaceDescriber ---
+
+type mockDescriber struct {
+	gender string
+	age    int
+	err    error
+}
+
+func (m *mockDescriber) DescribeFace(_ context.Context, _ string, currentAge int, gender string) (string, error) {
+	m.age, m.gender = currentAge, gender
+	if m.err != nil {
+		return "", m.err
+	}
+	return "rosto oval", nil
+}
+
+// --- Mock FaceAger ---
+
+type mockAger struct {
+	requests []matching.AgeRequest
+}
+
+func (m *mockAger) Version() string { return "mock/v1" }
+
+func (m *mockAger) AgeFace(_ context.Context, _ string, req matching.AgeRequest) (*matching.AgedFace, error) {
+	m.requests = append(m.requests, req)
+	return &matching.AgedFace{Data: []byte("img"), ContentType: "image/png"}, nil
+}
+
+// --- Mock Storage ---
+
+type mockStorage struct {
+	keys []string
+}
+
+func (m *mockStorage) Put(_ context.Context, key string, _ []byte, _ string) (string, error) {
+	m.keys = append(m.keys, key)
+	return "https://cdn/" + key, nil
+}
+
+func (m *mockStorage) Delete(_ context.Context, _ string) error { return nil }
+
+func TestAgeTargets(t *testing.T) {
+	assert.Equal(t, []int{30, 35, 40}, matching.AgeTargets(10, 30, []int{0, 5, 10}))
+	// Recent case: today's age is the photo's, so only later ages are drawn.
+	assert.Equal(t, []int{35, 40}, matching.AgeTargets(30, 30, []int{0, 5, 10}))
+	assert.Equal(t, []int{98}, matching.AgeTargets(90, 98, []int{0, 5, 0}))
+	assert.Nil(t, matching.AgeTargets(0, 0, []int{0, 5}))
+}
+
+func TestProcessAgeProgression_StoresDescriptionAndImages(t *testing.T) {
+	born := time.Now().AddDate(-30, 0, -1)
+	mRepo := &mockMissingRepo{items: []*missing.Missing{{
+		ID:                  "m1",
+		PhotoURL:            "http://photo.jpg",
+		Gender:              shared.GenderFemale,
+		BirthDate:           born,
+		DateOfDisappearance: born.AddDate(20, 0, 0),
+	}}}
+	describer := &mockDescriber{}
+	ager := &mockAger{}
+	store := &mockStorage{}
+	svc := matching.NewService(mRepo, &mockHomelessRepo{}, &mockMatchRepo{}, nil, describer, nil,
+		matching.WithAgeProgression(ager, store, []int{0, 5}))
+
+	err := svc.ProcessAgeProgression(context.Background(), "m1", "http://photo.jpg", time.Time{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "female", describer.gender)
+	assert.Equal(t, 30, describer.age)
+	require.Len(t, ager.requests, 2)
+	assert.Equal(t, 20, ager.requests[0].FromAge)
+	assert.Equal(t, "rosto oval", ager.requests[0].Description)
+	assert.Equal(t, []string{"age-progression/m1/30.png", "age-progression/m1/35.png"}, store.keys)
+
+	p := mRepo.progression["m1"]
+	require.NotNil(t, p)
+	assert.Equal(t, "rosto oval", p.Description)
+	assert.Equal(t, "mock/v1", p.Generator)
+	assert.Equal(t, "http://photo.jpg", p.SourcePhotoURL)
+	assert.Equal(t, []missing.AgedImage{
+		{Age: 30, URL: "https://cdn/age-progression/m1/30.png"},
+		{Age: 35, URL: "https://cdn/age-progression/m1/35.png"},
+	}, p.Images)
+}
+
+func TestProcessAgeProgression_DescriptionOnlyWithoutAger(t *testing.T) {
+	mRepo := &mockMissingRepo{items: []*missing.Missing{{ID: "m1", PhotoURL: "http://photo.jpg"}}}
+	describer := &mockDescriber{}
+	svc := matching.NewService(mRepo, &mockHomelessRepo{}, &mockMatchRepo{}, nil, describer, nil)
+
+	require.NoError(t, svc.ProcessAgeProgression(context.Background(), "m1", "http://photo.jpg", time.Time{}))
+
+	assert.Equal(t, "unknown", describer.gender)
+	p := mRepo.progression["m1"]
+	require.NotNil(t, p)
+	assert.Equal(t, "rosto oval", p.Description)
+	assert.Empty(t, p.Images)
+}
+
+func TestProcessAgeProgression_DescribeFailureIsReturned(t *testing.T) {
+	mRepo := &mockMissingRepo{items: []*missing.Missing{{ID: "m1", PhotoURL: "http://photo.jpg"}}}
+	svc := matching.NewService(mRepo, &mockHomelessRepo{}, &mockMatchRepo{}, nil, &mockDescriber{err: matching.ErrBudgetExhausted}, nil)
+
+	err := svc.ProcessAgeProgression(context.Background(), "m1", "http://photo.jpg", time.Time{})
+	assert.ErrorIs(t, err, matching.ErrBudgetExhausted)
+	assert.Nil(t, mRepo.progression["m1"])
+}
+
+func TestProcessAgeProgression_RecordsTimeline(t *testing.T) {
+	mRepo := &mockMissingRepo{items: []*missing.Missing{{ID: "m1", PhotoURL: "http://photo.jpg", BirthDate: time.Now().AddDate(-30, 0, -1)}}}
+	tl := &mockTimeline{}
+	svc := matching.NewService(mRepo, &mockHomelessRepo{}, &mockMatchRepo{}, nil, &mockDescriber{}, nil,
+		matching.WithAgeProgression(&mockAger{}, &mockStorage{}, []int{0, 5}), matching.WithTimeline(tl))
+
+	require.NoError(t, svc.ProcessAgeProgression(context.Background(), "m1", "http://photo.jpg", time.Time{}))
+
+	require.Len(t, tl.events, 1)
+	assert.Equal(t, timeline.TypeAgeProgressionGenerated, tl.events[0].Type)
+	assert.Equal(t, map[string]string{"images": "1", "generator": "mock/v1"}, tl.events[0].Details)
+	assert.Equal(t, mRepo.progression["m1"].GeneratedAt, tl.events[0].OccurredAt)
+}
+
+func TestProcessAgeProgression_DeletedMissingIsSkipped(t *testing.T) {
+	tl := &mockTimeline{}
+	svc := matching.NewService(&mockMissingRepo{}, &mockHomelessRepo{}, &mockMatchRepo{}, nil, &mockDescriber{}, nil, matching.WithTimeline(tl))
+
+	assert.NoError(t, svc.ProcessAgeProgression(context.Background(), "gone", "http://photo.jpg", time.Time{}))
+	assert.Empty(t, tl.events)
+}
+
+func TestProcessAgeProgression_ChangedPhotoIsSkipped(t *testing.T) {
+	mRepo := &mockMissingRepo{items: []*missing.Missing{{ID: "m1", PhotoURL: "http://new.jpg"}}}
+	describer := &mockDescriber{}
+	svc := matching.NewService(mRepo, &mockHomelessRepo{}, &mockMatchRepo{}, nil, describer, nil)
+
+	require.NoError(t, svc.ProcessAgeProgression(context.Background(), "m1", "http://old.jpg", time.Time{}))
+	assert.Nil(t, mRepo.progression["m1"])
+}
@@ -0,0 +1,217 @@
+package matching
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// UsageCounter persists AI call counts per UTC day and month so that the
+// budget is shared by every instance and survives restarts.
+type UsageCounter interface {
+	// Reserve counts one call against day and month unless that would take
+	// either past its limit. A limit of zero means unlimited. It reports
+	// whether the call was counted.
+	Reserve(ctx context.Context, day, month string, dailyLimit, monthlyLimit int64) (bool, error)
+	Counts(ctx context.Context, day, month string) (dayCalls, monthCalls int64, err error)
+}
+
+type BudgetConfig struct {
+	// CallsPerMinute and Burst configure the token bucket. Zero calls per
+	// minute disables rate limiting.
+	CallsPerMinute int
+	Burst          int
+	// MaxConcurrency caps in-flight calls. Zero means no cap.
+	MaxConcurrency int
+	// DailyLimit and MonthlyLimit cap calls per UTC day and month. Zero
+	// means unlimited.
+	DailyLimit   int64
+	MonthlyLimit int64
+}
+
+type Usage struct {
+	Day            string
+	DayCalls       int64
+	DailyLimit     int64
+	Month          string
+	MonthCalls     int64
+	MonthlyLimit   int64
+	CallsPerMinute int
+	MaxConcurrency int
+	InFlight       int
+}
+
+// Exhausted reports whether either ceiling has been reached.
+func (u *Usage) Exhausted() bool {
+	return (u.DailyLimit > 0 && u.DayCalls >= u.DailyLimit) ||
+		(u.MonthlyLimit > 0 && u.MonthCalls >= u.MonthlyLimit)
+}
+
+// Remaining is the number of calls left before the tighter of the daily and
+// monthly ceilings is reached, or -1 if neither is set.
+func (u *Usage) Remaining() int64 {
+	remaining := int64(-1)
+	if u.DailyLimit > 0 {
+		remaining = max(u.DailyLimit-u.DayCalls, 0)
+	}
+	if u.MonthlyLimit > 0 {
+		left := max(u.MonthlyLimit-u.MonthCalls, 0)
+		if remaining < 0 || left < remaining {
+			remaining = left
+		}
+	}
+	return remaining
+}
+
+// Budget gates calls to a paid AI API: a token bucket smooths bursts, a
+// semaphore caps concurrency, and a UsageCounter enforces the daily and
+// monthly ceilings.
+type Budget struct {
+	cfg     BudgetConfig
+	limiter *rate.Limiter
+	slots   chan struct{}
+	counter UsageCounter
+	now     func() time.Time
+}
+
+func NewBudget(cfg BudgetConfig, counter UsageCounter) *Budget {
+	b := &Budget{cfg: cfg, counter: counter, now: time.Now}
+	if cfg.CallsPerMinute > 0 {
+		burst := cfg.Burst
+		if burst <= 0 {
+			burst = 1
+		}
+		b.limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(cfg.CallsPerMinute)), burst)
+	}
+	if cfg.MaxConcurrency > 0 {
+		b.slots = make(chan struct{}, cfg.MaxConcurrency)
+	}
+	return b
+}
+
+// Acquire blocks until a call may be made and counts it against the budget.
+// The returned release func must be called once the call finishes. It fails
+// with ErrBudgetExhausted when a ceiling has been reached.
+func (b *Budget) Acquire(ctx context.Context) (func(), error) {
+	if b.slots != nil {
+		select {
+		case b.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release := func() {
+		if b.slots != nil {
+			<-b.slots
+		}
+	}
+
+	if b.limiter != nil {
+		if err := b.limiter.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	day, month := periodKeys(b.now())
+	ok, err := b.counter.Reserve(ctx, day, month, b.cfg.DailyLimit, b.cfg.MonthlyLimit)
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("reserving ai call: %w", err)
+	}
+	if !ok {
+		release()
+		return nil, ErrBudgetExhausted
+	}
+	return release, nil
+}
+
+// Usage reports the calls counted in the current UTC day and month.
+func (b *Budget) Usage(ctx context.Context) (*Usage, error) {
+	day, month := periodKeys(b.now())
+	dayCalls, monthCalls, err := b.counter.Counts(ctx, day, month)
+	if err != nil {
+		return nil, fmt.Errorf("reading ai usage: %w", err)
+	}
+
+	return &Usage{
+		Day:            day,
+		DayCalls:       dayCalls,
+		DailyLimit:     b.cfg.DailyLimit,
+		Month:          month,
+		MonthCalls:     monthCalls,
+		MonthlyLimit:   b.cfg.MonthlyLimit,
+		CallsPerMinute: b.cfg.CallsPerMinute,
+		MaxConcurrency: b.cfg.MaxConcurrency,
+		InFlight:       len(b.slots),
+	}, nil
+}
+
+func periodKeys(t time.Time) (day, month string) {
+	t = t.UTC()
+	return t.Format("2006-01-02"), t.Format("2006-01")
+}
+
+// BudgetedComparer spends budget on every call to inner. Once the budget is
+// exhausted it answers from fallback, if set, marking the result Degraded;
+// otherwise it returns ErrBudgetExhausted.
+type BudgetedComparer struct {
+	inner    FaceComparer
+	budget   *Budget
+	fallback FaceComparer
+}
+
+func NewBudgetedComparer(inner FaceComparer, budget *Budget, fallback FaceComparer) *BudgetedComparer {
+	return &BudgetedComparer{inner: inner, budget: budget, fallback: fallback}
+}
+
+// Version is the wrapped comparer's, so that cached results are shared with
+// unbudgeted runs.
+func (c *BudgetedComparer) Version() string {
+	return ComparerVersion(c.inner)
+}
+
+func (c *BudgetedComparer) CompareFaces(ctx context.Context, photo1URL, photo2URL string) (*FaceComparisonResult, error) {
+	release, err := c.budget.Acquire(ctx)
+	if errors.Is(err, ErrBudgetExhausted) && c.fallback != nil {
+		slog.Warn("ai budget exhausted, using fallback comparer")
+		result, err := c.fallback.CompareFaces(ctx, photo1URL, photo2URL)
+		if err != nil {
+			return nil, err
+		}
+		result.Degraded = true
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return c.inner.CompareFaces(ctx, photo1URL, photo2URL)
+}
+
+// BudgetedDescriber spends budget on every call to inner. There is no
+// fallback for descriptions, so an exhausted budget is returned as
+// ErrBudgetExhausted.
+type BudgetedDescriber struct {
+	inner  FaceDescriber
+	budget *Budget
+}
+
+func NewBudgetedDescriber(inner FaceDescriber, budget *Budget) *BudgetedDescriber {
+	return &BudgetedDescriber{inner: inner, budget: budget}
+}
+
+func (d *BudgetedDescriber) DescribeFace(ctx context.Context, photoURL string, currentAge int, gender string) (string, error) {
+	release, err := d.budget.Acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	return d.inner.DescribeFace(ctx, photoURL, currentAge, gender)
+}
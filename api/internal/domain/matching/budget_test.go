@@ -0,0 +1,117 @@
+package matching_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/homeless"
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/internal/domain/missing"
+	"github.com/l3co/traceo-api/internal/domain/shared"
+)
+
+// --- Mock UsageCounter ---
+
+type mockUsage struct {
+	day, month int64
+}
+
+func (m *mockUsage) Reserve(_ context.Context, _, _ string, dailyLimit, monthlyLimit int64) (bool, error) {
+	if (dailyLimit > 0 && m.day >= dailyLimit) || (monthlyLimit > 0 && m.month >= monthlyLimit) {
+		return false, nil
+	}
+	m.day++
+	m.month++
+	return true, nil
+}
+
+func (m *mockUsage) Counts(_ context.Context, _, _ string) (int64, int64, error) {
+	return m.day, m.month, nil
+}
+
+func TestBudget_DailyLimit(t *testing.T) {
+	b := matching.NewBudget(matching.BudgetConfig{DailyLimit: 2}, &mockUsage{})
+	ctx := context.Background()
+
+	for range 2 {
+		release, err := b.Acquire(ctx)
+		require.NoError(t, err)
+		release()
+	}
+
+	_, err := b.Acquire(ctx)
+	assert.ErrorIs(t, err, matching.ErrBudgetExhausted)
+
+	u, err := b.Usage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), u.DayCalls)
+	assert.Equal(t, int64(2), u.MonthCalls)
+	assert.True(t, u.Exhausted())
+}
+
+func TestBudget_ConcurrencyCap(t *testing.T) {
+	b := matching.NewBudget(matching.BudgetConfig{MaxConcurrency: 1}, &mockUsage{})
+
+	release, err := b.Acquire(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = b.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release()
+	release, err = b.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+}
+
+func TestBudgetedComparer_FallsBackWhenExhausted(t *testing.T) {
+	b := matching.NewBudget(matching.BudgetConfig{DailyLimit: 1}, &mockUsage{})
+	primary := &countingComparer{score: 0.9}
+	fallback := &countingComparer{score: 0.4}
+	c := matching.NewBudgetedComparer(primary, b, fallback)
+
+	r, err := c.CompareFaces(context.Background(), "a", "b")
+	require.NoError(t, err)
+	assert.False(t, r.Degraded)
+
+	r, err = c.CompareFaces(context.Background(), "a", "b")
+	require.NoError(t, err)
+	assert.True(t, r.Degraded)
+	assert.Equal(t, 0.4, r.SimilarityScore)
+	assert.Equal(t, 1, primary.calls)
+}
+
+func TestCachingComparer_DoesNotCacheDegraded(t *testing.T) {
+	b := matching.NewBudget(matching.BudgetConfig{DailyLimit: 1}, &mockUsage{})
+	_, _ = b.Acquire(context.Background())
+	inner := matching.NewBudgetedComparer(&countingComparer{score: 0.9}, b, &countingComparer{score: 0.4})
+	cache := &mockCache{entries: map[string]*matching.FaceComparisonResult{}}
+	hasher := &mockHasher{hashes: map[string]string{"a": "ha", "b": "hb"}}
+
+	_, err := matching.NewCachingComparer(inner, cache, hasher).CompareFaces(context.Background(), "a", "b")
+	require.NoError(t, err)
+	assert.Empty(t, cache.entries)
+}
+
+func TestProcessFaceMatching_BudgetExhausted_ReturnsError(t *testing.T) {
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
+		{ID: "h1", Name: "Carlos", PhotoURL: "http://photo1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", Name: "João", PhotoURL: "http://photo2.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	b := matching.NewBudget(matching.BudgetConfig{MonthlyLimit: 1}, &mockUsage{})
+	_, _ = b.Acquire(context.Background())
+	comparer := matching.NewBudgetedComparer(&mockComparer{score: 0.9}, b, nil)
+
+	svc := matching.NewService(mRepo, hRepo, &mockMatchRepo{}, comparer, nil, nil)
+
+	err := svc.ProcessFaceMatching(context.Background(), "h1")
+	assert.ErrorIs(t, err, matching.ErrBudgetExhausted)
+}
@@ -0,0 +1,134 @@
+package matching
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"sync/atomic"
+)
+
+// cacheStatsEvery is how many lookups pass between hit-rate log lines.
+const cacheStatsEvery = 100
+
+// PhotoHasher returns a content hash of the photo at url, so that the same
+// image under two URLs (or re-uploaded) shares cache entries.
+type PhotoHasher interface {
+	HashPhoto(ctx context.Context, url string) (string, error)
+}
+
+// ComparisonCache stores comparison results by CacheKey. Get returns nil
+// without error on a miss.
+type ComparisonCache interface {
+	Get(ctx context.Context, key string) (*FaceComparisonResult, error)
+	Put(ctx context.Context, key string, r *FaceComparisonResult) error
+}
+
+// Versioned is implemented by comparers whose results depend on a model or
+// prompt version. Cached results are only reused under the same version.
+type Versioned interface {
+	Version() string
+}
+
+// ComparerVersion returns c's version, or "unversioned" if it does not report
+// one.
+func ComparerVersion(c FaceComparer) string {
+	if v, ok := c.(Versioned); ok {
+		return v.Version()
+	}
+	return "unversioned"
+}
+
+// CacheKey is symmetric in the two photo hashes.
+func CacheKey(hashA, hashB, version string) string {
+	if hashB < hashA {
+		hashA, hashB = hashB, hashA
+	}
+	sum := sha256.Sum256([]byte(version + "\x00" + hashA + "\x00" + hashB))
+	return hex.EncodeToString(sum[:])
+}
+
+// CachingComparer serves repeated comparisons of the same two photos under
+// the same comparer version from a ComparisonCache.
+type CachingComparer struct {
+	inner   FaceComparer
+	cache   ComparisonCache
+	hasher  PhotoHasher
+	version string
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func NewCachingComparer(inner FaceComparer, cache ComparisonCache, hasher PhotoHasher) *CachingComparer {
+	return &CachingComparer{
+		inner:   inner,
+		cache:   cache,
+		hasher:  hasher,
+		version: ComparerVersion(inner),
+	}
+}
+
+func (c *CachingComparer) Version() string {
+	return c.version
+}
+
+func (c *CachingComparer) CompareFaces(ctx context.Context, photo1URL, photo2URL string) (*FaceComparisonResult, error) {
+	hash1, err := c.hasher.HashPhoto(ctx, photo1URL)
+	if err != nil {
+		slog.Warn("hashing photo for comparison cache failed", "url", photo1URL, "error", err.Error())
+		return c.inner.CompareFaces(ctx, photo1URL, photo2URL)
+	}
+	hash2, err := c.hasher.HashPhoto(ctx, photo2URL)
+	if err != nil {
+		slog.Warn("hashing photo for comparison cache failed", "url", photo2URL, "error", err.Error())
+		return c.inner.CompareFaces(ctx, photo1URL, photo2URL)
+	}
+	key := CacheKey(hash1, hash2, c.version)
+
+	cached, err := c.cache.Get(ctx, key)
+	if err != nil {
+		slog.Warn("comparison cache lookup failed", "error", err.Error())
+	}
+	if cached != nil {
+		c.record(true)
+		return cached, nil
+	}
+	c.record(false)
+
+	result, err := c.inner.CompareFaces(ctx, photo1URL, photo2URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Degraded {
+		return result, nil
+	}
+	if err := c.cache.Put(ctx, key, result); err != nil {
+		slog.Warn("comparison cache store failed", "error", err.Error())
+	}
+	return result, nil
+}
+
+// Stats returns the number of cache hits and misses since start-up.
+func (c *CachingComparer) Stats() (hits, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+func (c *CachingComparer) record(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+
+	hits, misses := c.Stats()
+	if total := hits + misses; total%cacheStatsEvery == 0 {
+		slog.Info("comparison cache stats",
+			"hits", hits,
+			"misses", misses,
+			"hit_rate", float64(hits)/float64(total),
+			"version", c.version,
+		)
+	}
+}
@@ -0,0 +1,115 @@
+package matching_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+)
+
+// --- Mock PhotoHasher ---
+
+type mockHasher struct {
+	hashes map[string]string
+}
+
+func (m *mockHasher) HashPhoto(_ context.Context, url string) (string, error) {
+	h, ok := m.hashes[url]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return h, nil
+}
+
+// --- Mock ComparisonCache ---
+
+type mockCache struct {
+	entries map[string]*matching.FaceComparisonResult
+}
+
+func (m *mockCache) Get(_ context.Context, key string) (*matching.FaceComparisonResult, error) {
+	return m.entries[key], nil
+}
+
+func (m *mockCache) Put(_ context.Context, key string, r *matching.FaceComparisonResult) error {
+	m.entries[key] = r
+	return nil
+}
+
+type versionedComparer struct {
+	countingComparer
+	version string
+}
+
+func (v *versionedComparer) Version() string { return v.version }
+
+func TestCacheKey_Symmetric(t *testing.T) {
+	assert.Equal(t, matching.CacheKey("a", "b", "v1"), matching.CacheKey("b", "a", "v1"))
+	assert.NotEqual(t, matching.CacheKey("a", "b", "v1"), matching.CacheKey("a", "b", "v2"))
+}
+
+func TestCachingComparer_HitsOnSamePhotos(t *testing.T) {
+	inner := &versionedComparer{countingComparer: countingComparer{score: 0.7}, version: "v1"}
+	hasher := &mockHasher{hashes: map[string]string{
+		"http://a.jpg":      "hash-a",
+		"http://b.jpg":      "hash-b",
+		"http://copy-a.jpg": "hash-a",
+	}}
+	cache := &mockCache{entries: map[string]*matching.FaceComparisonResult{}}
+	c := matching.NewCachingComparer(inner, cache, hasher)
+	ctx := context.Background()
+
+	first, err := c.CompareFaces(ctx, "http://a.jpg", "http://b.jpg")
+	require.NoError(t, err)
+	second, err := c.CompareFaces(ctx, "http://b.jpg", "http://copy-a.jpg")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, first.SimilarityScore, second.SimilarityScore)
+	hits, misses := c.Stats()
+	assert.Equal(t, int64(1), hits)
+	assert.Equal(t, int64(1), misses)
+	assert.Equal(t, "v1", c.Version())
+}
+
+func TestCachingComparer_VersionChangeMisses(t *testing.T) {
+	hasher := &mockHasher{hashes: map[string]string{"a": "1", "b": "2"}}
+	cache := &mockCache{entries: map[string]*matching.FaceComparisonResult{}}
+	ctx := context.Background()
+
+	v1 := &versionedComparer{countingComparer: countingComparer{score: 0.7}, version: "v1"}
+	_, err := matching.NewCachingComparer(v1, cache, hasher).CompareFaces(ctx, "a", "b")
+	require.NoError(t, err)
+
+	v2 := &versionedComparer{countingComparer: countingComparer{score: 0.4}, version: "v2"}
+	res, err := matching.NewCachingComparer(v2, cache, hasher).CompareFaces(ctx, "a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, 1, v2.calls)
+	assert.Equal(t, 0.4, res.SimilarityScore)
+}
+
+func TestCachingComparer_HashFailureBypassesCache(t *testing.T) {
+	inner := &countingComparer{score: 0.5}
+	cache := &mockCache{entries: map[string]*matching.FaceComparisonResult{}}
+	c := matching.NewCachingComparer(inner, cache, &mockHasher{})
+
+	_, err := c.CompareFaces(context.Background(), "a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.calls)
+	assert.Empty(t, cache.entries)
+}
+
+func TestCachingComparer_ErrorsAreNotCached(t *testing.T) {
+	inner := &countingComparer{err: errors.New("quota")}
+	hasher := &mockHasher{hashes: map[string]string{"a": "1", "b": "2"}}
+	cache := &mockCache{entries: map[string]*matching.FaceComparisonResult{}}
+	c := matching.NewCachingComparer(inner, cache, hasher)
+
+	_, err := c.CompareFaces(context.Background(), "a", "b")
+	assert.Error(t, err)
+	assert.Empty(t, cache.entries)
+}
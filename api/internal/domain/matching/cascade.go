@@ -0,0 +1,39 @@
+package matching
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// CascadeComparer runs a cheap comparer first and only calls the expensive
+// one for pairs that score at least minScore. Rejected pairs return the
+// prefilter result, so minScore should sit below the save threshold.
+type CascadeComparer struct {
+	prefilter FaceComparer
+	primary   FaceComparer
+	minScore  float64
+}
+
+func NewCascadeComparer(prefilter, primary FaceComparer, minScore float64) *CascadeComparer {
+	return &CascadeComparer{prefilter: prefilter, primary: primary, minScore: minScore}
+}
+
+func (c *CascadeComparer) Version() string {
+	return fmt.Sprintf("cascade(%s>=%.2f,%s)", ComparerVersion(c.prefilter), c.minScore, ComparerVersion(c.primary))
+}
+
+func (c *CascadeComparer) CompareFaces(ctx context.Context, photo1URL, photo2URL string) (*FaceComparisonResult, error) {
+	pre, err := c.prefilter.CompareFaces(ctx, photo1URL, photo2URL)
+	if err != nil {
+		slog.Warn("prefilter comparison failed, falling back to primary comparer", "error", err.Error())
+		return c.primary.CompareFaces(ctx, photo1URL, photo2URL)
+	}
+
+	if pre.SimilarityScore < c.minScore {
+		pre.Analysis = fmt.Sprintf("Descartado pelo pré-filtro local (%.2f < %.2f).", pre.SimilarityScore, c.minScore)
+		return pre, nil
+	}
+
+	return c.primary.CompareFaces(ctx, photo1URL, photo2URL)
+}
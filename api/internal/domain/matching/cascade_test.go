@@ -0,0 +1,66 @@
+package matching_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+)
+
+type countingComparer struct {
+	score float64
+	err   error
+	calls int
+}
+
+func (c *countingComparer) CompareFaces(_ context.Context, _, _ string) (*matching.FaceComparisonResult, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &matching.FaceComparisonResult{SimilarityScore: c.score}, nil
+}
+
+func TestCascadeComparer_RejectedByPrefilter(t *testing.T) {
+	pre := &countingComparer{score: 0.2}
+	primary := &countingComparer{score: 0.9}
+	c := matching.NewCascadeComparer(pre, primary, 0.3)
+
+	res, err := c.CompareFaces(context.Background(), "a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, 0.2, res.SimilarityScore)
+	assert.Equal(t, 0, primary.calls)
+}
+
+func TestCascadeComparer_PassesToPrimary(t *testing.T) {
+	pre := &countingComparer{score: 0.5}
+	primary := &countingComparer{score: 0.9}
+	c := matching.NewCascadeComparer(pre, primary, 0.3)
+
+	res, err := c.CompareFaces(context.Background(), "a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, 0.9, res.SimilarityScore)
+	assert.Equal(t, 1, primary.calls)
+}
+
+func TestCascadeComparer_PrefilterErrorFallsBack(t *testing.T) {
+	pre := &countingComparer{err: errors.New("decode failed")}
+	primary := &countingComparer{score: 0.7}
+	c := matching.NewCascadeComparer(pre, primary, 0.3)
+
+	res, err := c.CompareFaces(context.Background(), "a", "b")
+	require.NoError(t, err)
+	assert.Equal(t, 0.7, res.SimilarityScore)
+}
+
+func TestEmbedding_Cosine(t *testing.T) {
+	a := matching.Embedding{1, 0, 0}
+	assert.InDelta(t, 1.0, a.Cosine(matching.Embedding{2, 0, 0}), 1e-9)
+	assert.InDelta(t, 0.0, a.Cosine(matching.Embedding{0, 1, 0}), 1e-9)
+	assert.Equal(t, 0.0, a.Cosine(matching.Embedding{1, 0}))
+	assert.Equal(t, 0.0, a.Cosine(matching.Embedding{0, 0, 0}))
+}
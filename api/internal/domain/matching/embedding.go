@@ -0,0 +1,33 @@
+package matching
+
+import (
+	"context"
+	"math"
+)
+
+// Embedding is a fixed-length face descriptor. Two embeddings produced by the
+// same FaceEmbedder can be compared with Cosine.
+type Embedding []float32
+
+type FaceEmbedder interface {
+	EmbedFace(ctx context.Context, photoURL string) (Embedding, error)
+}
+
+// Cosine returns the cosine similarity between e and other, or 0 when the
+// lengths differ or either vector is all zeros.
+func (e Embedding) Cosine(other Embedding) float64 {
+	if len(e) == 0 || len(e) != len(other) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range e {
+		a, b := float64(e[i]), float64(other[i])
+		dot += a * b
+		na += a * a
+		nb += b * b
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
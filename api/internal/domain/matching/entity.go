@@ -21,6 +21,51 @@ func (s MatchStatus) IsValid() bool {
 	return false
 }
 
+// maxComparisonHistory bounds how many past comparisons are kept per pair.
+const maxComparisonHistory = 20
+
+// Comparison is one run of the face comparer over a homeless/missing pair.
+// Score is RawScore after the policy's adjustments. Degraded comparisons were
+// answered by the fallback comparer because the AI budget was exhausted.
+type Comparison struct {
+	Score         float64
+	RawScore      float64
+	Analysis      string
+	PolicyVersion string
+	PromptID      string
+	PromptVersion string
+	Degraded      bool
+	// HomelessPhoto and MissingPhoto are the URLs of the photos compared.
+	HomelessPhoto string
+	MissingPhoto  string
+	ComparedAt    time.Time
+}
+
+// maxReviewNotesLength bounds the free-text notes of a single review.
+const maxReviewNotesLength = 2000
+
+// Review is one reviewer decision on a match. Every decision is kept, so
+// the history shows who confirmed, rejected or reopened a match and why.
+type Review struct {
+	ReviewerID string
+	Decision   MatchStatus
+	Notes      string
+	ReviewedAt time.Time
+}
+
+func (r *Review) Validate() error {
+	if r.ReviewerID == "" {
+		return fmt.Errorf("%w: reviewer_id is required", ErrInvalidMatch)
+	}
+	if !r.Decision.IsValid() {
+		return fmt.Errorf("%w: invalid status %q", ErrInvalidMatch, r.Decision)
+	}
+	if len(r.Notes) > maxReviewNotesLength {
+		return fmt.Errorf("%w: notes must be at most %d characters", ErrInvalidMatch, maxReviewNotesLength)
+	}
+	return nil
+}
+
 type Match struct {
 	ID             string
 	HomelessID     string
@@ -28,8 +73,58 @@ type Match struct {
 	Score          float64
 	Status         MatchStatus
 	GeminiAnalysis string
-	CreatedAt      time.Time
-	ReviewedAt     time.Time
+	Comparisons    []Comparison
+	Reviews        []Review
+	// PolicyVersion and Policy describe the policy of the latest comparison.
+	PolicyVersion string
+	Policy        Policy
+	// PromptID and PromptVersion identify the prompt of the latest
+	// comparison, if it came from an LLM.
+	PromptID      string
+	PromptVersion string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	ReviewedAt    time.Time
+	ReviewedBy    string
+}
+
+// PairID is the deterministic Match ID for a homeless/missing pair, so that
+// comparing the same two people again always addresses the same record.
+func PairID(homelessID, missingID string) string {
+	return homelessID + "_" + missingID
+}
+
+// RecordComparison makes c the current score and analysis of the match and
+// appends it to the history. Status and review data are left untouched, so a
+// reviewer's decision survives later comparisons.
+func (m *Match) RecordComparison(c Comparison) {
+	m.Score = c.Score
+	m.GeminiAnalysis = c.Analysis
+	m.PromptID = c.PromptID
+	m.PromptVersion = c.PromptVersion
+	m.UpdatedAt = c.ComparedAt
+	m.Comparisons = append(m.Comparisons, c)
+	if len(m.Comparisons) > maxComparisonHistory {
+		m.Comparisons = m.Comparisons[len(m.Comparisons)-maxComparisonHistory:]
+	}
+}
+
+// MergeComparisons records the comparisons of a freshly built match for the
+// same pair on m and adopts its policy.
+func (m *Match) MergeComparisons(latest *Match) {
+	for _, c := range latest.Comparisons {
+		m.RecordComparison(c)
+	}
+	m.PolicyVersion = latest.PolicyVersion
+	m.Policy = latest.Policy
+}
+
+// ApplyReview makes r the current decision and appends it to the history.
+func (m *Match) ApplyReview(r Review) {
+	m.Status = r.Decision
+	m.ReviewedAt = r.ReviewedAt
+	m.ReviewedBy = r.ReviewerID
+	m.Reviews = append(m.Reviews, r)
 }
 
 func (m *Match) Validate() error {
@@ -5,4 +5,20 @@ import "errors"
 var (
 	ErrMatchNotFound = errors.New("match not found")
 	ErrInvalidMatch  = errors.New("invalid match")
+	ErrInvalidPolicy = errors.New("invalid matching policy")
+	ErrForbidden     = errors.New("only the case owner or a moderator can review this match")
+
+	// ErrBudgetExhausted is returned when the daily or monthly AI call budget
+	// has been spent. Jobs failing with it should be deferred, not retried.
+	ErrBudgetExhausted = errors.New("ai call budget exhausted")
+
+	// ErrComparerUnavailable is returned by comparers whose backing service is
+	// temporarily failing. Matching jobs stop and are retried later rather
+	// than skipping the remaining pairs.
+	ErrComparerUnavailable = errors.New("face comparer unavailable")
+
+	// errComparisonFailed marks a comparison that failed for this pair only.
+	// Batch runs log it and move on to the next pair; pair jobs return it so
+	// the job is retried.
+	errComparisonFailed = errors.New("face comparison failed")
 )
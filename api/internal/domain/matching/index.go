@@ -0,0 +1,59 @@
+package matching
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// IndexKind separates the two populations stored in a CandidateIndex.
+type IndexKind string
+
+const (
+	IndexMissing  IndexKind = "missing"
+	IndexHomeless IndexKind = "homeless"
+)
+
+type IndexEntry struct {
+	Kind      IndexKind
+	ID        string
+	PhotoURL  string
+	Embedding Embedding
+	UpdatedAt time.Time
+}
+
+type Neighbor struct {
+	ID         string
+	Similarity float64
+}
+
+// CandidateIndex stores one face embedding per missing/homeless record and
+// answers nearest-neighbour queries over one kind at a time.
+type CandidateIndex interface {
+	Upsert(ctx context.Context, e IndexEntry) error
+	Remove(ctx context.Context, kind IndexKind, id string) error
+	Nearest(ctx context.Context, kind IndexKind, query Embedding, k int) ([]Neighbor, error)
+	// Indexed returns the photo URL each record of kind was indexed from,
+	// keyed by record ID.
+	Indexed(ctx context.Context, kind IndexKind) (map[string]string, error)
+}
+
+// RankNearest is the brute-force search shared by CandidateIndex
+// implementations: it scores every entry against query and returns the k most
+// similar, best first.
+func RankNearest(entries []IndexEntry, query Embedding, k int) []Neighbor {
+	result := make([]Neighbor, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, Neighbor{ID: e.ID, Similarity: query.Cosine(e.Embedding)})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Similarity != result[j].Similarity {
+			return result[i].Similarity > result[j].Similarity
+		}
+		return result[i].ID < result[j].ID
+	})
+	if k > 0 && len(result) > k {
+		result = result[:k]
+	}
+	return result
+}
@@ -0,0 +1,223 @@
+package matching_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/homeless"
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/internal/domain/missing"
+	"github.com/l3co/traceo-api/internal/domain/shared"
+)
+
+// --- Mock FaceEmbedder ---
+
+type mockEmbedder struct {
+	vectors map[string]matching.Embedding
+}
+
+func (m *mockEmbedder) EmbedFace(_ context.Context, url string) (matching.Embedding, error) {
+	e, ok := m.vectors[url]
+	if !ok {
+		return nil, fmt.Errorf("no embedding for %s", url)
+	}
+	return e, nil
+}
+
+// --- Mock CandidateIndex ---
+
+type mockIndex struct {
+	mu      sync.Mutex
+	entries map[string]matching.IndexEntry
+}
+
+func newMockIndex() *mockIndex {
+	return &mockIndex{entries: make(map[string]matching.IndexEntry)}
+}
+
+func (m *mockIndex) Upsert(_ context.Context, e matching.IndexEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[string(e.Kind)+"/"+e.ID] = e
+	return nil
+}
+
+func (m *mockIndex) Remove(_ context.Context, kind matching.IndexKind, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, string(kind)+"/"+id)
+	return nil
+}
+
+func (m *mockIndex) Nearest(_ context.Context, kind matching.IndexKind, q matching.Embedding, k int) ([]matching.Neighbor, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var entries []matching.IndexEntry
+	for _, e := range m.entries {
+		if e.Kind == kind {
+			entries = append(entries, e)
+		}
+	}
+	return matching.RankNearest(entries, q, k), nil
+}
+
+func (m *mockIndex) Indexed(_ context.Context, kind matching.IndexKind) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := map[string]string{}
+	for _, e := range m.entries {
+		if e.Kind == kind {
+			result[e.ID] = e.PhotoURL
+		}
+	}
+	return result, nil
+}
+
+func policyWithLimit(limit int) matching.Policy {
+	p := matching.DefaultPolicy()
+	p.CandidateLimit = limit
+	return p
+}
+
+// --- Recording FaceComparer ---
+
+type recordingComparer struct {
+	mu       sync.Mutex
+	compared []string
+}
+
+func (r *recordingComparer) CompareFaces(_ context.Context, _, photo2URL string) (*matching.FaceComparisonResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.compared = append(r.compared, photo2URL)
+	return &matching.FaceComparisonResult{SimilarityScore: 0.1}, nil
+}
+
+func TestProcessFaceMatching_WithIndex_ComparesNearestFirst(t *testing.T) {
+	target := &homeless.Homeless{ID: "h1", PhotoURL: "h1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown}
+	embedder := &mockEmbedder{vectors: map[string]matching.Embedding{"h1.jpg": {1, 0}}}
+	idx := newMockIndex()
+
+	var items []*missing.Missing
+	for i := 0; i < 4; i++ {
+		id := fmt.Sprintf("m%d", i)
+		items = append(items, &missing.Missing{
+			ID: id, PhotoURL: id + ".jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown, Status: missing.StatusDisappeared,
+		})
+		// m3 is the closest face, m2 the second closest.
+		vec := matching.Embedding{float32(i), 10}
+		require.NoError(t, idx.Upsert(context.Background(), matching.IndexEntry{Kind: matching.IndexMissing, ID: id, Embedding: vec}))
+	}
+
+	comparer := &recordingComparer{}
+	svc := matching.NewService(
+		&mockMissingRepo{items: items},
+		&mockHomelessRepo{items: []*homeless.Homeless{target}},
+		&mockMatchRepo{},
+		comparer, nil, nil,
+		matching.WithCandidateIndex(embedder, idx),
+	)
+
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+	assert.Equal(t, []string{"m3.jpg", "m2.jpg", "m1.jpg", "m0.jpg"}, comparer.compared,
+		"every candidate should be compared, nearest first")
+
+	_, indexed := idx.entries["homeless/h1"]
+	assert.True(t, indexed, "target face should be indexed")
+}
+
+func TestProcessFaceMatching_WithIndex_UnindexedComparedLast(t *testing.T) {
+	target := &homeless.Homeless{ID: "h1", PhotoURL: "h1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown}
+	embedder := &mockEmbedder{vectors: map[string]matching.Embedding{"h1.jpg": {1, 0}}}
+	idx := newMockIndex()
+	require.NoError(t, idx.Upsert(context.Background(), matching.IndexEntry{Kind: matching.IndexMissing, ID: "gone", Embedding: matching.Embedding{1, 0}}))
+	require.NoError(t, idx.Upsert(context.Background(), matching.IndexEntry{Kind: matching.IndexMissing, ID: "m2", Embedding: matching.Embedding{1, 0}}))
+
+	items := []*missing.Missing{
+		{ID: "m1", PhotoURL: "m1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown, Status: missing.StatusDisappeared},
+		{ID: "m2", PhotoURL: "m2.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown, Status: missing.StatusDisappeared},
+	}
+
+	comparer := &recordingComparer{}
+	svc := matching.NewService(
+		&mockMissingRepo{items: items},
+		&mockHomelessRepo{items: []*homeless.Homeless{target}},
+		&mockMatchRepo{},
+		comparer, nil, nil,
+		matching.WithCandidateIndex(embedder, idx),
+	)
+
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+	assert.Equal(t, []string{"m2.jpg", "m1.jpg"}, comparer.compared)
+}
+
+func TestProcessFaceMatching_WithIndex_EmbedFailureFallsBack(t *testing.T) {
+	target := &homeless.Homeless{ID: "h1", PhotoURL: "h1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown}
+	items := []*missing.Missing{
+		{ID: "m1", PhotoURL: "m1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown, Status: missing.StatusDisappeared},
+	}
+
+	comparer := &recordingComparer{}
+	svc := matching.NewService(
+		&mockMissingRepo{items: items},
+		&mockHomelessRepo{items: []*homeless.Homeless{target}},
+		&mockMatchRepo{},
+		comparer, nil, nil,
+		matching.WithCandidateIndex(&mockEmbedder{}, newMockIndex()),
+	)
+
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+	assert.Equal(t, []string{"m1.jpg"}, comparer.compared)
+}
+
+func TestBackfillCandidateIndex_IndexesUnindexedFaces(t *testing.T) {
+	embedder := &mockEmbedder{vectors: map[string]matching.Embedding{
+		"m1.jpg": {1, 0}, "m2-new.jpg": {0, 1}, "h1.jpg": {1, 1},
+	}}
+	idx := newMockIndex()
+	ctx := context.Background()
+	require.NoError(t, idx.Upsert(ctx, matching.IndexEntry{Kind: matching.IndexMissing, ID: "m1", PhotoURL: "m1.jpg", Embedding: matching.Embedding{1, 0}}))
+	require.NoError(t, idx.Upsert(ctx, matching.IndexEntry{Kind: matching.IndexMissing, ID: "m2", PhotoURL: "m2-old.jpg", Embedding: matching.Embedding{0, 0}}))
+
+	svc := matching.NewService(
+		&mockMissingRepo{items: []*missing.Missing{
+			{ID: "m1", PhotoURL: "m1.jpg"},
+			{ID: "m2", PhotoURL: "m2-new.jpg"},
+			{ID: "m3"},
+			{ID: "m4", PhotoURL: "unreadable.jpg"},
+		}},
+		&mockHomelessRepo{items: []*homeless.Homeless{{ID: "h1", PhotoURL: "h1.jpg"}}},
+		&mockMatchRepo{},
+		&recordingComparer{}, nil, nil,
+		matching.WithCandidateIndex(embedder, idx),
+	)
+
+	n, err := svc.BackfillCandidateIndex(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, "m2-new.jpg", idx.entries["missing/m2"].PhotoURL)
+	assert.Contains(t, idx.entries, "homeless/h1")
+	assert.NotContains(t, idx.entries, "missing/m3")
+	assert.NotContains(t, idx.entries, "missing/m4")
+
+	n, err = svc.BackfillCandidateIndex(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestForgetMissing_RemovesFromIndex(t *testing.T) {
+	idx := newMockIndex()
+	ctx := context.Background()
+	require.NoError(t, idx.Upsert(ctx, matching.IndexEntry{Kind: matching.IndexMissing, ID: "m1", Embedding: matching.Embedding{1, 0}}))
+	svc := matching.NewService(&mockMissingRepo{}, &mockHomelessRepo{}, &mockMatchRepo{}, &recordingComparer{}, nil, nil,
+		matching.WithCandidateIndex(&mockEmbedder{}, idx),
+	)
+
+	require.NoError(t, svc.ForgetMissing(ctx, "m1"))
+	assert.Empty(t, idx.entries)
+}
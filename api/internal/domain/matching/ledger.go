@@ -0,0 +1,25 @@
+package matching
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// PairLedger remembers which homeless/missing pairs have been compared, keyed
+// by PairKey, so that the sweep only enqueues comparisons that have never
+// run for the current photos and comparer version.
+type PairLedger interface {
+	// Compared reports which of keys have been recorded. Keys that have not
+	// are absent from the result.
+	Compared(ctx context.Context, keys []string) (map[string]bool, error)
+	Record(ctx context.Context, key string, comparedAt time.Time) error
+}
+
+// PairKey identifies one comparison of a pair: it changes whenever either
+// photo or the comparer version changes, so such pairs are compared again.
+func PairKey(homelessID, homelessPhotoURL, missingID, missingPhotoURL, version string) string {
+	sum := sha256.Sum256([]byte(version + "\x00" + homelessID + "\x00" + homelessPhotoURL + "\x00" + missingID + "\x00" + missingPhotoURL))
+	return hex.EncodeToString(sum[:])
+}
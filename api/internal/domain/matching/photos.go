@@ -0,0 +1,97 @@
+package matching
+
+import (
+	"time"
+
+	"github.com/l3co/traceo-api/internal/domain/homeless"
+	"github.com/l3co/traceo-api/internal/domain/missing"
+	"github.com/l3co/traceo-api/internal/domain/shared"
+)
+
+// bestPhotoPair picks the two photos to compare. Only the missing person's
+// photos are ranked by age: the one taken at the age closest to the homeless
+// person's estimated age wins, since that is the face the comparer has to
+// recognise. The homeless record's primary (else most recent) photo is used
+// as is, as it shows the person as they look now. Records without a photo
+// list fall back to PhotoURL. Photo.FaceDetected is not considered: it is
+// set by whoever adds the photo, so it cannot be trusted to rank them.
+func bestPhotoPair(h *homeless.Homeless, m *missing.Missing) (homelessURL, missingURL string) {
+	homelessURL = h.PhotoURL
+	if len(h.Photos) > 0 {
+		homelessURL = latestPhoto(h.Photos).URL
+	}
+
+	missingURL = m.PhotoURL
+	if len(m.Photos) > 0 {
+		target := h.Age()
+		if h.BirthDate.IsZero() {
+			target = m.Age()
+		}
+		missingURL = closestInAge(m.Photos, m, target).URL
+	}
+	return homelessURL, missingURL
+}
+
+// latestPhoto returns the primary photo if present, else the one taken (or
+// added) last.
+func latestPhoto(photos shared.Photos) shared.Photo {
+	if p := photos.Primary(); p != nil {
+		return *p
+	}
+	best := photos[0]
+	for _, p := range photos[1:] {
+		if photoTime(p).After(photoTime(best)) {
+			best = p
+		}
+	}
+	return best
+}
+
+func photoTime(p shared.Photo) time.Time {
+	if !p.TakenAt.IsZero() {
+		return p.TakenAt
+	}
+	return p.AddedAt
+}
+
+// closestInAge returns the photo whose age is nearest target. Photos of
+// unknown age only win when no photo has a known one; ties go to the primary
+// photo, then to list order.
+func closestInAge(photos shared.Photos, m *missing.Missing, target int) shared.Photo {
+	best := latestPhoto(photos)
+	if target <= 0 {
+		return best
+	}
+
+	bestDiff := -1
+	if age := photoAge(best, m); age > 0 {
+		bestDiff = abs(age - target)
+	}
+	for _, p := range photos {
+		age := photoAge(p, m)
+		if age <= 0 {
+			continue
+		}
+		if diff := abs(age - target); bestDiff < 0 || diff < bestDiff {
+			best, bestDiff = p, diff
+		}
+	}
+	return best
+}
+
+func photoAge(p shared.Photo, m *missing.Missing) int {
+	if p.AgeAtPhoto > 0 {
+		return p.AgeAtPhoto
+	}
+	if !p.TakenAt.IsZero() {
+		return m.AgeAt(p.TakenAt)
+	}
+	return 0
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,64 @@
+package matching_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/homeless"
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/internal/domain/missing"
+	"github.com/l3co/traceo-api/internal/domain/shared"
+)
+
+func TestProcessFaceMatching_ComparesPhotoClosestInAge(t *testing.T) {
+	birth := time.Now().AddDate(-30, 0, 0)
+	target := &homeless.Homeless{ID: "h1", PhotoURL: "h1.jpg", BirthDate: birth, Gender: shared.GenderMale, Skin: shared.SkinBrown}
+	m := &missing.Missing{
+		ID: "m1", PhotoURL: "child.jpg", BirthDate: birth,
+		Gender: shared.GenderMale, Skin: shared.SkinBrown, Status: missing.StatusDisappeared,
+		Photos: shared.Photos{
+			{ID: "p1", URL: "child.jpg", AgeAtPhoto: 8, Primary: true},
+			{ID: "p2", URL: "adult.jpg", AgeAtPhoto: 27},
+			{ID: "p3", URL: "teen.jpg", AgeAtPhoto: 16},
+		},
+	}
+
+	comparer := &recordingComparer{}
+	svc := matching.NewService(
+		&mockMissingRepo{items: []*missing.Missing{m}},
+		&mockHomelessRepo{items: []*homeless.Homeless{target}},
+		&mockMatchRepo{},
+		comparer, nil, nil,
+	)
+
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+	assert.Equal(t, []string{"adult.jpg"}, comparer.compared)
+}
+
+func TestProcessFaceMatching_IgnoresClientFaceFlag(t *testing.T) {
+	birth := time.Now().AddDate(-30, 0, 0)
+	target := &homeless.Homeless{ID: "h1", PhotoURL: "h1.jpg", BirthDate: birth, Gender: shared.GenderMale, Skin: shared.SkinBrown}
+	m := &missing.Missing{
+		ID: "m1", PhotoURL: "child.jpg", BirthDate: birth,
+		Gender: shared.GenderMale, Skin: shared.SkinBrown, Status: missing.StatusDisappeared,
+		Photos: shared.Photos{
+			{ID: "p1", URL: "child.jpg", AgeAtPhoto: 8, Primary: true, FaceDetected: true},
+			{ID: "p2", URL: "adult.jpg", AgeAtPhoto: 29},
+		},
+	}
+
+	comparer := &recordingComparer{}
+	svc := matching.NewService(
+		&mockMissingRepo{items: []*missing.Missing{m}},
+		&mockHomelessRepo{items: []*homeless.Homeless{target}},
+		&mockMatchRepo{},
+		comparer, nil, nil,
+	)
+
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+	assert.Equal(t, []string{"adult.jpg"}, comparer.compared)
+}
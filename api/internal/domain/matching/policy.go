@@ -0,0 +1,112 @@
+package matching
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// FilterMode says how an attribute (gender, skin) constrains candidates.
+type FilterMode string
+
+const (
+	// FilterHard excludes candidates whose attribute differs.
+	FilterHard FilterMode = "hard"
+	// FilterSoft compares them anyway and subtracts SoftFilterPenalty from
+	// the score for each differing attribute.
+	FilterSoft FilterMode = "soft"
+)
+
+func (f FilterMode) IsValid() bool {
+	return f == FilterHard || f == FilterSoft
+}
+
+// Policy holds every tunable that decides which pairs are compared and what
+// happens with the score. A snapshot is stored on each Match so that results
+// can be reproduced later.
+type Policy struct {
+	SaveThreshold   float64    `json:"save_threshold"`
+	NotifyThreshold float64    `json:"notify_threshold"`
+	CandidateLimit  int        `json:"candidate_limit"`
+	GenderFilter    FilterMode `json:"gender_filter"`
+	SkinFilter      FilterMode `json:"skin_filter"`
+	// SoftFilterPenalty is subtracted once per attribute that differs under
+	// FilterSoft.
+	SoftFilterPenalty float64 `json:"soft_filter_penalty"`
+	// AgeTolerance is the ± years allowed between the two current ages. It
+	// grows by AgeToleranceGrowth per year since the disappearance, up to
+	// MaxAgeTolerance, because both the family's reference photo and the age
+	// estimate of an unidentified person get less reliable over time.
+	AgeTolerance       int     `json:"age_tolerance"`
+	AgeToleranceGrowth float64 `json:"age_tolerance_growth"`
+	MaxAgeTolerance    int     `json:"max_age_tolerance"`
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		SaveThreshold:      0.6,
+		NotifyThreshold:    0.8,
+		CandidateLimit:     20,
+		GenderFilter:       FilterHard,
+		SkinFilter:         FilterHard,
+		SoftFilterPenalty:  0.1,
+		AgeTolerance:       15,
+		AgeToleranceGrowth: 0.5,
+		MaxAgeTolerance:    25,
+	}
+}
+
+func (p Policy) Validate() error {
+	if p.SaveThreshold < 0 || p.SaveThreshold > 1 {
+		return fmt.Errorf("%w: save threshold must be between 0 and 1", ErrInvalidPolicy)
+	}
+	if p.NotifyThreshold < p.SaveThreshold || p.NotifyThreshold > 1 {
+		return fmt.Errorf("%w: notify threshold must be between the save threshold and 1", ErrInvalidPolicy)
+	}
+	if p.CandidateLimit <= 0 {
+		return fmt.Errorf("%w: candidate limit must be positive", ErrInvalidPolicy)
+	}
+	if !p.GenderFilter.IsValid() || !p.SkinFilter.IsValid() {
+		return fmt.Errorf("%w: filters must be %q or %q", ErrInvalidPolicy, FilterHard, FilterSoft)
+	}
+	if p.SoftFilterPenalty < 0 || p.SoftFilterPenalty > 1 {
+		return fmt.Errorf("%w: soft filter penalty must be between 0 and 1", ErrInvalidPolicy)
+	}
+	if p.AgeTolerance < 0 || p.AgeToleranceGrowth < 0 || p.MaxAgeTolerance < p.AgeTolerance {
+		return fmt.Errorf("%w: age tolerance must be non-negative and not above the maximum", ErrInvalidPolicy)
+	}
+	return nil
+}
+
+// Version identifies the policy by its content, so two deployments with the
+// same settings report the same version.
+func (p Policy) Version() string {
+	data, _ := json.Marshal(p)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// AgeToleranceFor returns the age tolerance for a case that disappeared at
+// disappearedAt. A zero time gets the base tolerance.
+func (p Policy) AgeToleranceFor(disappearedAt, now time.Time) int {
+	if disappearedAt.IsZero() || !now.After(disappearedAt) {
+		return p.AgeTolerance
+	}
+	years := now.Sub(disappearedAt).Hours() / (24 * 365.25)
+	tolerance := p.AgeTolerance + int(math.Floor(years*p.AgeToleranceGrowth))
+	return min(tolerance, p.MaxAgeTolerance)
+}
+
+// AdjustScore applies the soft-filter penalty for the attributes that differ.
+func (p Policy) AdjustScore(score float64, genderDiffers, skinDiffers bool) float64 {
+	if genderDiffers && p.GenderFilter == FilterSoft {
+		score -= p.SoftFilterPenalty
+	}
+	if skinDiffers && p.SkinFilter == FilterSoft {
+		score -= p.SoftFilterPenalty
+	}
+	return max(score, 0)
+}
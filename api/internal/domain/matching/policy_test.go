@@ -0,0 +1,130 @@
+package matching_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/homeless"
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/internal/domain/missing"
+	"github.com/l3co/traceo-api/internal/domain/shared"
+)
+
+func TestPolicy_DefaultIsValid(t *testing.T) {
+	assert.NoError(t, matching.DefaultPolicy().Validate())
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*matching.Policy)
+	}{
+		{"save above 1", func(p *matching.Policy) { p.SaveThreshold = 1.2 }},
+		{"notify below save", func(p *matching.Policy) { p.NotifyThreshold = 0.5 }},
+		{"zero limit", func(p *matching.Policy) { p.CandidateLimit = 0 }},
+		{"unknown filter", func(p *matching.Policy) { p.SkinFilter = "maybe" }},
+		{"max below base", func(p *matching.Policy) { p.MaxAgeTolerance = 5 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := matching.DefaultPolicy()
+			tt.modify(&p)
+			assert.ErrorIs(t, p.Validate(), matching.ErrInvalidPolicy)
+		})
+	}
+}
+
+func TestPolicy_VersionTracksContent(t *testing.T) {
+	a := matching.DefaultPolicy()
+	b := matching.DefaultPolicy()
+	assert.Equal(t, a.Version(), b.Version())
+
+	b.SaveThreshold = 0.65
+	assert.NotEqual(t, a.Version(), b.Version())
+}
+
+func TestPolicy_AgeToleranceGrows(t *testing.T) {
+	p := matching.DefaultPolicy()
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 15, p.AgeToleranceFor(time.Time{}, now))
+	assert.Equal(t, 15, p.AgeToleranceFor(now.AddDate(-1, 0, 0), now))
+	assert.Equal(t, 20, p.AgeToleranceFor(now.AddDate(-10, 0, 0), now))
+	assert.Equal(t, 25, p.AgeToleranceFor(now.AddDate(-40, 0, 0), now))
+}
+
+func TestPolicy_AdjustScore(t *testing.T) {
+	p := matching.DefaultPolicy()
+	assert.Equal(t, 0.9, p.AdjustScore(0.9, true, true), "hard filters never penalise")
+
+	p.GenderFilter = matching.FilterSoft
+	p.SkinFilter = matching.FilterSoft
+	assert.InDelta(t, 0.8, p.AdjustScore(0.9, true, false), 1e-9)
+	assert.InDelta(t, 0.7, p.AdjustScore(0.9, true, true), 1e-9)
+	assert.Equal(t, 0.0, p.AdjustScore(0.05, true, true))
+}
+
+func TestProcessFaceMatching_UsesPolicyThresholds(t *testing.T) {
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
+		{ID: "h1", PhotoURL: "h1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", PhotoURL: "m1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	matchRepo := &mockMatchRepo{}
+	policy := matching.DefaultPolicy()
+	policy.SaveThreshold = 0.5
+
+	svc := matching.NewService(mRepo, hRepo, matchRepo, &mockComparer{score: 0.55}, nil, nil, matching.WithPolicy(policy))
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+
+	require.Len(t, matchRepo.items, 1)
+	assert.Equal(t, policy.Version(), matchRepo.items[0].PolicyVersion)
+	assert.Equal(t, policy, matchRepo.items[0].Policy)
+}
+
+func TestProcessFaceMatching_SoftGenderFilterPenalises(t *testing.T) {
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
+		{ID: "h1", PhotoURL: "h1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", PhotoURL: "m1.jpg", Gender: shared.GenderFemale, Skin: shared.SkinBrown},
+	}}
+	matchRepo := &mockMatchRepo{}
+	policy := matching.DefaultPolicy()
+	policy.GenderFilter = matching.FilterSoft
+
+	svc := matching.NewService(mRepo, hRepo, matchRepo, &mockComparer{score: 0.85}, nil, nil, matching.WithPolicy(policy))
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+
+	require.Len(t, matchRepo.items, 1)
+	c := matchRepo.items[0].Comparisons[0]
+	assert.InDelta(t, 0.75, c.Score, 1e-9)
+	assert.Equal(t, 0.85, c.RawScore)
+}
+
+func TestProcessFaceMatching_AgeToleranceDependsOnDisappearance(t *testing.T) {
+	now := time.Now()
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
+		{ID: "h1", PhotoURL: "h1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown, BirthDate: now.AddDate(-50, 0, 0)},
+	}}
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		// 18 years apart: outside the base ±15, inside the ±20 earned by
+		// disappearing 10 years ago.
+		{ID: "old-case", PhotoURL: "m1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown,
+			BirthDate: now.AddDate(-32, 0, 0), DateOfDisappearance: now.AddDate(-10, 0, -1)},
+		{ID: "new-case", PhotoURL: "m2.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown,
+			BirthDate: now.AddDate(-32, 0, 0), DateOfDisappearance: now.AddDate(0, -1, 0)},
+	}}
+	matchRepo := &mockMatchRepo{}
+
+	svc := matching.NewService(mRepo, hRepo, matchRepo, &mockComparer{score: 0.7}, nil, nil)
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+
+	require.Len(t, matchRepo.items, 1)
+	assert.Equal(t, "old-case", matchRepo.items[0].MissingID)
+}
@@ -3,9 +3,18 @@ package matching
 import "context"
 
 type Repository interface {
-	Create(ctx context.Context, m *Match) error
+	// Upsert stores m under its pair ID. If a Match for the same pair already
+	// exists, m's latest comparison is recorded on it instead and the existing
+	// status and review are kept, even when that Match predates pair IDs and
+	// is stored under another ID. It returns the stored record.
+	Upsert(ctx context.Context, m *Match) (*Match, error)
 	FindByID(ctx context.Context, id string) (*Match, error)
+	// FindByPair returns the Match of a homeless/missing pair whatever ID it
+	// is stored under, or ErrMatchNotFound.
+	FindByPair(ctx context.Context, homelessID, missingID string) (*Match, error)
 	FindByHomelessID(ctx context.Context, homelessID string) ([]*Match, error)
 	FindByMissingID(ctx context.Context, missingID string) ([]*Match, error)
-	UpdateStatus(ctx context.Context, id string, status MatchStatus) error
+	// AddReview applies r to the match and appends it to its review history
+	// atomically, returning the updated match.
+	AddReview(ctx context.Context, id string, r Review) (*Match, error)
 }
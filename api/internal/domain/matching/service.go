@@ -2,17 +2,26 @@ package matching
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
 
+	"github.com/l3co/traceo-api/internal/domain/event"
 	"github.com/l3co/traceo-api/internal/domain/homeless"
+	"github.com/l3co/traceo-api/internal/domain/media"
 	"github.com/l3co/traceo-api/internal/domain/missing"
 	"github.com/l3co/traceo-api/internal/domain/notification"
+	"github.com/l3co/traceo-api/internal/domain/timeline"
 )
 
+var sanitizer = bluemonday.StrictPolicy()
+
 type FaceComparer interface {
 	CompareFaces(ctx context.Context, photo1URL, photo2URL string) (*FaceComparisonResult, error)
 }
@@ -27,6 +36,13 @@ type FaceComparisonResult struct {
 	MatchingFeatures  []string
 	DifferentFeatures []string
 	Confidence        string
+	// PromptID and PromptVersion identify the prompt behind an LLM-based
+	// result; they are empty for other comparers.
+	PromptID      string
+	PromptVersion string
+	// Degraded is set when the result comes from a fallback comparer used in
+	// place of the configured one. Degraded results are not cached.
+	Degraded bool
 }
 
 type Service struct {
@@ -36,6 +52,87 @@ type Service struct {
 	comparer     FaceComparer
 	describer    FaceDescriber
 	notifier     notification.Notifier
+	embedder     FaceEmbedder
+	index        CandidateIndex
+	moderators   map[string]bool
+	ager         FaceAger
+	media        media.Storage
+	ageOffsets   []int
+	ledger       PairLedger
+	publisher    event.Publisher
+	budget       *Budget
+	budgetShare  float64
+	policy       Policy
+	version      string
+	timeline     timeline.Recorder
+}
+
+type Option func(*Service)
+
+// WithModerators lets the given user IDs review any match, not only those on
+// cases they own.
+func WithModerators(ids []string) Option {
+	return func(s *Service) {
+		for _, id := range ids {
+			s.moderators[id] = true
+		}
+	}
+}
+
+// WithCandidateIndex makes matching compare the candidates returned by
+// FindCandidates nearest face first, as ranked by index. The target's face is
+// embedded and indexed on every matching run.
+func WithCandidateIndex(embedder FaceEmbedder, index CandidateIndex) Option {
+	return func(s *Service) {
+		s.embedder = embedder
+		s.index = index
+	}
+}
+
+// WithPolicy replaces DefaultPolicy. The caller is expected to have validated
+// p.
+func WithPolicy(p Policy) Option {
+	return func(s *Service) {
+		s.policy = p
+	}
+}
+
+// WithAgeProgression makes ProcessAgeProgression render aged images with
+// ager and upload them to store. offsets are years added to the person's
+// current age; see AgeTargets.
+func WithAgeProgression(ager FaceAger, store media.Storage, offsets []int) Option {
+	return func(s *Service) {
+		s.ager = ager
+		s.media = store
+		s.ageOffsets = offsets
+	}
+}
+
+// WithPairLedger records every completed comparison in ledger and enables
+// Sweep, which enqueues the pairs missing from it through publisher.
+func WithPairLedger(ledger PairLedger, publisher event.Publisher) Option {
+	return func(s *Service) {
+		s.ledger = ledger
+		s.publisher = publisher
+	}
+}
+
+// WithSweepBudget caps each Sweep at share of the calls left in budget, so
+// that re-matching never starves comparisons for newly registered records.
+func WithSweepBudget(budget *Budget, share float64) Option {
+	return func(s *Service) {
+		s.budget = budget
+		s.budgetShare = share
+	}
+}
+
+// WithTimeline records proposed and reviewed matches, cases marked found
+// through a review and generated age progressions in the timeline of the
+// missing person's case.
+func WithTimeline(r timeline.Recorder) Option {
+	return func(s *Service) {
+		s.timeline = r
+	}
 }
 
 func NewService(
@@ -45,15 +142,28 @@ func NewService(
 	comparer FaceComparer,
 	describer FaceDescriber,
 	notifier notification.Notifier,
+	opts ...Option,
 ) *Service {
-	return &Service{
+	s := &Service{
 		missingRepo:  missingRepo,
 		homelessRepo: homelessRepo,
 		matchRepo:    matchRepo,
 		comparer:     comparer,
 		describer:    describer,
 		notifier:     notifier,
+		moderators:   make(map[string]bool),
+		policy:       DefaultPolicy(),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.version = s.policy.Version()
+	return s
+}
+
+// Policy returns the matching policy in effect.
+func (s *Service) Policy() Policy {
+	return s.policy
 }
 
 func (s *Service) ProcessFaceMatching(ctx context.Context, homelessID string) error {
@@ -67,75 +177,455 @@ func (s *Service) ProcessFaceMatching(ctx context.Context, homelessID string) er
 		return nil
 	}
 
-	candidates, err := s.missingRepo.FindCandidates(ctx, missing.CandidateFilter{
-		Gender: h.Gender,
-		Skin:   h.Skin,
-		MinAge: h.Age() - 15,
-		MaxAge: h.Age() + 15,
-		Status: missing.StatusDisappeared,
-		Limit:  20,
-	})
+	filter := missing.CandidateFilter{
+		Statuses: missing.OpenStatuses(),
+		Limit:    s.policy.CandidateLimit,
+	}
+	if s.policy.GenderFilter == FilterHard {
+		filter.Gender = h.Gender
+	}
+	if s.policy.SkinFilter == FilterHard {
+		filter.Skin = h.Skin
+	}
+	if !h.BirthDate.IsZero() {
+		// The exact tolerance depends on each candidate's disappearance date,
+		// so query with the widest one and narrow down per candidate below.
+		filter.MinAge = h.Age() - s.policy.MaxAgeTolerance
+		filter.MaxAge = h.Age() + s.policy.MaxAgeTolerance
+	}
+
+	candidates, err := s.missingCandidates(ctx, h, filter)
 	if err != nil {
 		return fmt.Errorf("finding candidates: %w", err)
 	}
 
+	existing, err := s.matchRepo.FindByHomelessID(ctx, homelessID)
+	if err != nil {
+		return fmt.Errorf("finding existing matches: %w", err)
+	}
+	known := make(map[string]bool, len(existing))
+	for _, m := range existing {
+		known[m.MissingID] = true
+	}
+
 	slog.Info("face matching started",
 		"homeless_id", homelessID,
 		"candidates", len(candidates),
 	)
 
 	for _, candidate := range candidates {
-		if candidate.PhotoURL == "" {
+		if candidate.PhotoURL == "" || !s.withinAgeTolerance(h, candidate) {
 			continue
 		}
+		if err := s.comparePair(ctx, h, candidate, known[candidate.ID]); err != nil && !errors.Is(err, errComparisonFailed) {
+			return err
+		}
+	}
+
+	return nil
+}
 
-		comparison, err := s.comparer.CompareFaces(ctx, h.PhotoURL, candidate.PhotoURL)
+// ProcessMissingMatching is the reverse of ProcessFaceMatching: it compares a
+// newly registered (or re-photographed) missing person against the homeless
+// records already in the system.
+func (s *Service) ProcessMissingMatching(ctx context.Context, missingID string) error {
+	m, err := s.missingRepo.FindByID(ctx, missingID)
+	if err != nil {
+		return fmt.Errorf("finding missing %s: %w", missingID, err)
+	}
+
+	if m.PhotoURL == "" {
+		slog.Warn("missing has no photo, skipping face matching", "id", missingID)
+		return nil
+	}
+
+	filter := s.homelessFilter(m)
+	filter.Limit = s.policy.CandidateLimit
+
+	candidates, err := s.homelessCandidates(ctx, m, filter)
+	if err != nil {
+		return fmt.Errorf("finding homeless candidates: %w", err)
+	}
+
+	existing, err := s.matchRepo.FindByMissingID(ctx, missingID)
+	if err != nil {
+		return fmt.Errorf("finding existing matches: %w", err)
+	}
+	known := make(map[string]bool, len(existing))
+	for _, match := range existing {
+		known[match.HomelessID] = true
+	}
+
+	slog.Info("reverse face matching started",
+		"missing_id", missingID,
+		"candidates", len(candidates),
+	)
+
+	for _, candidate := range candidates {
+		if candidate.PhotoURL == "" || !s.withinAgeTolerance(candidate, m) {
+			continue
+		}
+		if err := s.comparePair(ctx, candidate, m, known[candidate.ID]); err != nil && !errors.Is(err, errComparisonFailed) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// homelessFilter applies the policy's hard filters and m's age tolerance. It
+// sets no limit.
+func (s *Service) homelessFilter(m *missing.Missing) homeless.CandidateFilter {
+	var filter homeless.CandidateFilter
+	if s.policy.GenderFilter == FilterHard {
+		filter.Gender = m.Gender
+	}
+	if s.policy.SkinFilter == FilterHard {
+		filter.Skin = m.Skin
+	}
+	if !m.BirthDate.IsZero() {
+		tolerance := s.policy.AgeToleranceFor(m.DateOfDisappearance, time.Now())
+		filter.MinAge = m.Age() - tolerance
+		filter.MaxAge = m.Age() + tolerance
+	}
+	return filter
+}
+
+// missingCandidates returns the missing persons to compare with h; see
+// rankedCandidates.
+func (s *Service) missingCandidates(ctx context.Context, h *homeless.Homeless, filter missing.CandidateFilter) ([]*missing.Missing, error) {
+	candidates, err := s.missingRepo.FindCandidates(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return rankedCandidates(ctx, s, IndexHomeless, h.ID, h.PhotoURL, IndexMissing, candidates,
+		func(m *missing.Missing) string { return m.ID }), nil
+}
+
+// homelessCandidates mirrors missingCandidates for reverse matching.
+func (s *Service) homelessCandidates(ctx context.Context, m *missing.Missing, filter homeless.CandidateFilter) ([]*homeless.Homeless, error) {
+	candidates, err := s.homelessRepo.FindCandidates(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return rankedCandidates(ctx, s, IndexMissing, m.ID, m.PhotoURL, IndexHomeless, candidates,
+		func(h *homeless.Homeless) string { return h.ID }), nil
+}
+
+// rankedCandidates orders candidates, which are of kind, by how close their
+// indexed face is to that of the target at targetKind/targetID, nearest
+// first, with candidates that have not been indexed last. The index only
+// orders the filtered candidates and never drops one: its vectors are not a
+// face recogniser, so a true match may rank low, but an earlier comparison
+// is still more likely to be done before the AI budget runs out. Without an
+// index, or if the target's photo cannot be embedded, candidates are returned
+// as they are.
+func rankedCandidates[T any](ctx context.Context, s *Service, targetKind IndexKind, targetID, photoURL string, kind IndexKind, candidates []T, id func(T) string) []T {
+	if s.index == nil || len(candidates) < 2 {
+		return candidates
+	}
+
+	query, err := s.indexFace(ctx, targetKind, targetID, photoURL)
+	if err != nil {
+		slog.Warn("embedding face failed, candidates left unranked",
+			"kind", string(targetKind),
+			"id", targetID,
+			"error", err.Error(),
+		)
+		return candidates
+	}
+
+	neighbors, err := s.index.Nearest(ctx, kind, query, 0)
+	if err != nil {
+		slog.Warn("querying candidate index failed, candidates left unranked",
+			"kind", string(kind),
+			"error", err.Error(),
+		)
+		return candidates
+	}
+
+	rank := make(map[string]int, len(neighbors))
+	for i, n := range neighbors {
+		rank[n.ID] = i
+	}
+	position := func(c T) int {
+		if r, ok := rank[id(c)]; ok {
+			return r
+		}
+		return len(neighbors)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return position(candidates[i]) < position(candidates[j])
+	})
+	return candidates
+}
+
+// indexFace embeds photoURL and stores it in the candidate index under
+// kind/id. An index write failure is logged; the embedding is still returned
+// so the current run can use it.
+func (s *Service) indexFace(ctx context.Context, kind IndexKind, id, photoURL string) (Embedding, error) {
+	e, err := s.embedder.EmbedFace(ctx, photoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.index.Upsert(ctx, IndexEntry{
+		Kind:      kind,
+		ID:        id,
+		PhotoURL:  photoURL,
+		Embedding: e,
+		UpdatedAt: time.Now(),
+	}); err != nil {
+		slog.Warn("indexing face failed",
+			"kind", string(kind),
+			"id", id,
+			"error", err.Error(),
+		)
+	}
+	return e, nil
+}
+
+// indexBackfillPageSize is how many missing cases BackfillCandidateIndex
+// loads at a time.
+const indexBackfillPageSize = 100
+
+// BackfillCandidateIndex indexes the faces of the missing and homeless records
+// stored before the candidate index existed, or whose photo changed without a
+// matching run since. Records already indexed from their current photo are
+// skipped, and a photo that cannot be embedded is logged and left out. It
+// returns how many records were indexed.
+func (s *Service) BackfillCandidateIndex(ctx context.Context) (int, error) {
+	if s.index == nil {
+		return 0, nil
+	}
+
+	indexed := 0
+	add := func(kind IndexKind, id, photoURL string, current map[string]string) error {
+		if photoURL == "" || current[id] == photoURL {
+			return nil
+		}
+		e, err := s.embedder.EmbedFace(ctx, photoURL)
 		if err != nil {
-			slog.Error("face comparison failed",
-				"homeless_id", homelessID,
-				"missing_id", candidate.ID,
+			slog.Warn("embedding face failed, not indexed",
+				"kind", string(kind),
+				"id", id,
 				"error", err.Error(),
 			)
-			continue
+			return nil
+		}
+		if err := s.index.Upsert(ctx, IndexEntry{
+			Kind:      kind,
+			ID:        id,
+			PhotoURL:  photoURL,
+			Embedding: e,
+			UpdatedAt: time.Now(),
+		}); err != nil {
+			return fmt.Errorf("indexing %s %s: %w", kind, id, err)
+		}
+		indexed++
+		return nil
+	}
+
+	current, err := s.index.Indexed(ctx, IndexMissing)
+	if err != nil {
+		return 0, fmt.Errorf("reading candidate index: %w", err)
+	}
+	cursor := ""
+	for {
+		page, next, err := s.missingRepo.FindAll(ctx, missing.ListOptions{PageSize: indexBackfillPageSize, After: cursor})
+		if err != nil {
+			return indexed, fmt.Errorf("listing missing: %w", err)
+		}
+		for _, m := range page {
+			if err := add(IndexMissing, m.ID, m.PhotoURL, current); err != nil {
+				return indexed, err
+			}
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	current, err = s.index.Indexed(ctx, IndexHomeless)
+	if err != nil {
+		return indexed, fmt.Errorf("reading candidate index: %w", err)
+	}
+	all, err := s.homelessRepo.FindAll(ctx)
+	if err != nil {
+		return indexed, fmt.Errorf("listing homeless: %w", err)
+	}
+	for _, h := range all {
+		if err := add(IndexHomeless, h.ID, h.PhotoURL, current); err != nil {
+			return indexed, err
 		}
+	}
+
+	slog.Info("candidate index backfill finished", "indexed", indexed)
+	return indexed, nil
+}
+
+// ForgetMissing removes a deleted missing case from the candidate index, so
+// it stops taking up nearest-neighbour slots in reverse matching.
+func (s *Service) ForgetMissing(ctx context.Context, missingID string) error {
+	if s.index == nil {
+		return nil
+	}
+	if err := s.index.Remove(ctx, IndexMissing, missingID); err != nil {
+		return fmt.Errorf("removing missing %s from candidate index: %w", missingID, err)
+	}
+	return nil
+}
+
+// withinAgeTolerance applies the policy's age tolerance for m's
+// disappearance date. Pairs where either age is unknown are kept.
+func (s *Service) withinAgeTolerance(h *homeless.Homeless, m *missing.Missing) bool {
+	if h.BirthDate.IsZero() || m.BirthDate.IsZero() {
+		return true
+	}
+	diff := h.Age() - m.Age()
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= s.policy.AgeToleranceFor(m.DateOfDisappearance, time.Now())
+}
 
-		slog.Info("face comparison result",
-			"homeless_id", homelessID,
-			"missing_id", candidate.ID,
-			"score", comparison.SimilarityScore,
+// comparePair runs the face comparison for the best photo pair (see
+// bestPhotoPair) of one homeless/missing pair, adjusts the score with the
+// policy and upserts the pair's Match when a non-degraded score reaches the
+// save threshold, or when the pair already has a Match (so its score history
+// stays current). A notification is sent the first time a pending pair reaches
+// the notify threshold. A failed comparison is logged and returned wrapped in
+// errComparisonFailed, which batch runs skip; other errors
+// (ErrBudgetExhausted, ErrComparerUnavailable) abort the batch so the job is
+// deferred or retried rather than skipping the pair for good, and so does a
+// failure to save the Match. Comparisons that were not degraded are recorded
+// in the pair ledger, if any, once their outcome has been stored.
+func (s *Service) comparePair(ctx context.Context, h *homeless.Homeless, m *missing.Missing, known bool) error {
+	homelessPhoto, missingPhoto := bestPhotoPair(h, m)
+	result, err := s.comparer.CompareFaces(ctx, homelessPhoto, missingPhoto)
+	if errors.Is(err, ErrBudgetExhausted) || errors.Is(err, ErrComparerUnavailable) {
+		return fmt.Errorf("comparing homeless %s with missing %s: %w", h.ID, m.ID, err)
+	}
+	if err != nil {
+		slog.Error("face comparison failed",
+			"homeless_id", h.ID,
+			"missing_id", m.ID,
+			"error", err.Error(),
 		)
+		return fmt.Errorf("%w: homeless %s with missing %s: %v", errComparisonFailed, h.ID, m.ID, err)
+	}
 
-		if comparison.SimilarityScore >= 0.6 {
-			match := &Match{
-				ID:             uuid.NewString(),
-				HomelessID:     homelessID,
-				MissingID:      candidate.ID,
-				Score:          comparison.SimilarityScore,
-				Status:         MatchStatusPending,
-				GeminiAnalysis: comparison.Analysis,
-				CreatedAt:      time.Now(),
-			}
+	score := s.policy.AdjustScore(result.SimilarityScore, h.Gender != m.Gender, h.Skin != m.Skin)
 
-			if err := s.matchRepo.Create(ctx, match); err != nil {
-				slog.Error("saving match failed", "error", err.Error())
-				continue
-			}
+	slog.Info("face comparison result",
+		"homeless_id", h.ID,
+		"missing_id", m.ID,
+		"raw_score", result.SimilarityScore,
+		"score", score,
+		"policy_version", s.version,
+		"degraded", result.Degraded,
+	)
 
-			if comparison.SimilarityScore >= 0.8 && s.notifier != nil {
-				go func(missingName string, score float64, analysis string) {
-					bgCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-					defer cancel()
-					if err := s.notifier.NotifyPotentialMatch(bgCtx, missingName, score, analysis); err != nil {
-						slog.Error("match notification failed", "error", err.Error())
-					}
-				}(candidate.Name, comparison.SimilarityScore, comparison.Analysis)
-			}
+	// A degraded score comes from a fallback that cannot tell faces apart
+	// reliably: it only adds to the history of a match that already exists.
+	if (score < s.policy.SaveThreshold || result.Degraded) && !known {
+		if !result.Degraded {
+			s.recordCompared(ctx, h, m)
 		}
+		return nil
+	}
+
+	now := time.Now()
+	match := &Match{
+		ID:            PairID(h.ID, m.ID),
+		HomelessID:    h.ID,
+		MissingID:     m.ID,
+		Status:        MatchStatusPending,
+		PolicyVersion: s.version,
+		Policy:        s.policy,
+		CreatedAt:     now,
+	}
+	match.RecordComparison(Comparison{
+		Score:         score,
+		RawScore:      result.SimilarityScore,
+		Analysis:      result.Analysis,
+		PolicyVersion: s.version,
+		PromptID:      result.PromptID,
+		PromptVersion: result.PromptVersion,
+		Degraded:      result.Degraded,
+		HomelessPhoto: homelessPhoto,
+		MissingPhoto:  missingPhoto,
+		ComparedAt:    now,
+	})
+
+	stored, err := s.matchRepo.Upsert(ctx, match)
+	if err != nil {
+		slog.Error("saving match failed",
+			"homeless_id", h.ID,
+			"missing_id", m.ID,
+			"error", err.Error(),
+		)
+		return fmt.Errorf("saving match of homeless %s with missing %s: %w", h.ID, m.ID, err)
+	}
+	if !result.Degraded {
+		s.recordCompared(ctx, h, m)
+	}
+	if len(stored.Comparisons) == 1 {
+		timeline.RecordOrLog(ctx, s.timeline, &timeline.Event{
+			MissingID:  m.ID,
+			Type:       timeline.TypeMatchProposed,
+			OccurredAt: now,
+			Details: map[string]string{
+				"match_id":    stored.ID,
+				"homeless_id": h.ID,
+				"score":       strconv.FormatFloat(score, 'f', 2, 64),
+			},
+		})
 	}
 
+	if s.notifier != nil && s.shouldNotify(stored) {
+		go func(missingName string, score float64, analysis string) {
+			bgCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := s.notifier.NotifyPotentialMatch(bgCtx, missingName, score, analysis); err != nil {
+				slog.Error("match notification failed", "error", err.Error())
+			}
+		}(m.Name, score, result.Analysis)
+	}
 	return nil
 }
 
+// recordCompared notes in the pair ledger, if any, that h and m have been
+// compared, so the sweep does not enqueue them again.
+func (s *Service) recordCompared(ctx context.Context, h *homeless.Homeless, m *missing.Missing) {
+	if s.ledger == nil {
+		return
+	}
+	if err := s.ledger.Record(ctx, s.pairKey(h, m), time.Now()); err != nil {
+		slog.Warn("recording compared pair failed",
+			"homeless_id", h.ID,
+			"missing_id", m.ID,
+			"error", err.Error(),
+		)
+	}
+}
+
+// shouldNotify reports whether the latest comparison on a pending match is the
+// first one to reach the notify threshold. Degraded comparisons never notify.
+func (s *Service) shouldNotify(m *Match) bool {
+	latest := m.Comparisons[len(m.Comparisons)-1]
+	if m.Status != MatchStatusPending || latest.Degraded || m.Score < s.policy.NotifyThreshold {
+		return false
+	}
+	for _, c := range m.Comparisons[:len(m.Comparisons)-1] {
+		if !c.Degraded && c.Score >= s.policy.NotifyThreshold {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *Service) FindByID(ctx context.Context, id string) (*Match, error) {
 	if id == "" {
 		return nil, fmt.Errorf("%w: id is required", ErrInvalidMatch)
@@ -151,42 +641,143 @@ func (s *Service) FindByMissingID(ctx context.Context, missingID string) ([]*Mat
 	return s.matchRepo.FindByMissingID(ctx, missingID)
 }
 
-func (s *Service) UpdateStatus(ctx context.Context, id string, status MatchStatus) error {
-	if !status.IsValid() {
-		return fmt.Errorf("%w: invalid status %q", ErrInvalidMatch, status)
+type ReviewInput struct {
+	ReviewerID string
+	Decision   MatchStatus
+	Notes      string
+	// MarkMissingFound also moves the missing person to found. Only allowed
+	// together with a confirmed decision, and requires FoundAt.
+	MarkMissingFound bool
+	// FoundAt is when the person was found, which is usually before the
+	// match was reviewed.
+	FoundAt time.Time
+}
+
+// ReviewResult is what Review did. The review is saved even when the case
+// cannot be marked found; StatusError then says why.
+type ReviewResult struct {
+	Match *Match
+	// MissingFound reports whether the review moved the case to found.
+	MissingFound bool
+	StatusError  error
+}
+
+// CanReview reports whether userID may review the matches of the given case,
+// that is whether they own it or are a moderator. Anonymous users never can.
+func (s *Service) CanReview(ctx context.Context, missingID, userID string) (bool, error) {
+	if userID == "" {
+		return false, nil
+	}
+	if s.moderators[userID] {
+		return true, nil
+	}
+	m, err := s.missingRepo.FindByID(ctx, missingID)
+	if errors.Is(err, missing.ErrMissingNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("finding missing %s: %w", missingID, err)
 	}
-	return s.matchRepo.UpdateStatus(ctx, id, status)
+	return m.UserID == userID, nil
 }
 
-func (s *Service) ProcessAgeProgression(ctx context.Context, missingID, photoURL string, birthDate time.Time) error {
-	if photoURL == "" {
-		slog.Warn("no photo for age progression", "missing_id", missingID)
-		return nil
+// Review records a reviewer's decision on a match. Only the owner of the
+// missing person's case or a moderator may review. The case is marked found,
+// when asked, only after the review is saved, so a case the lifecycle cannot
+// close this way keeps the review and reports the refusal in the result.
+func (s *Service) Review(ctx context.Context, id string, input ReviewInput) (*ReviewResult, error) {
+	now := time.Now()
+	review := Review{
+		ReviewerID: input.ReviewerID,
+		Decision:   input.Decision,
+		Notes:      sanitizer.Sanitize(strings.TrimSpace(input.Notes)),
+		ReviewedAt: now,
+	}
+	if err := review.Validate(); err != nil {
+		return nil, err
+	}
+	if input.MarkMissingFound && input.Decision != MatchStatusConfirmed {
+		return nil, fmt.Errorf("%w: mark_missing_found requires a confirmed decision", ErrInvalidMatch)
+	}
+	if input.MarkMissingFound && input.FoundAt.IsZero() {
+		return nil, fmt.Errorf("%w: mark_missing_found requires found_at", ErrInvalidMatch)
 	}
 
-	if s.describer == nil {
-		slog.Warn("face describer not configured, skipping age progression", "missing_id", missingID)
-		return nil
+	match, err := s.matchRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 
-	currentAge := 0
-	if !birthDate.IsZero() {
-		currentAge = time.Now().Year() - birthDate.Year()
+	m, err := s.missingRepo.FindByID(ctx, match.MissingID)
+	if err != nil {
+		return nil, fmt.Errorf("finding missing %s: %w", match.MissingID, err)
+	}
+
+	role := missing.RoleOwner
+	if m.UserID != input.ReviewerID {
+		if !s.moderators[input.ReviewerID] {
+			return nil, ErrForbidden
+		}
+		role = missing.RoleModerator
 	}
 
-	description, err := s.describer.DescribeFace(ctx, photoURL, currentAge, "unknown")
+	updated, err := s.matchRepo.AddReview(ctx, id, review)
 	if err != nil {
-		slog.Error("age progression describe failed",
-			"missing_id", missingID,
-			"error", err.Error(),
-		)
-		return fmt.Errorf("describing face: %w", err)
+		return nil, fmt.Errorf("saving review: %w", err)
 	}
 
-	slog.Info("age progression description generated",
-		"missing_id", missingID,
-		"description_length", len(description),
+	slog.Info("match reviewed",
+		"match_id", id,
+		"reviewer_id", input.ReviewerID,
+		"decision", string(input.Decision),
 	)
 
+	timeline.RecordOrLog(ctx, s.timeline, &timeline.Event{
+		MissingID:  m.ID,
+		Type:       timeline.TypeMatchReviewed,
+		ActorID:    input.ReviewerID,
+		OccurredAt: now,
+		Details:    map[string]string{"match_id": id, "decision": string(input.Decision)},
+	})
+
+	result := &ReviewResult{Match: updated}
+	if input.MarkMissingFound && !m.Status.IsFound() {
+		if err := s.markFound(ctx, m, match, missing.StatusChange{
+			To:        missing.StatusFoundAlive,
+			ChangedBy: input.ReviewerID,
+			Role:      role,
+			ChangedAt: now,
+			FoundAt:   input.FoundAt,
+			Note:      "confirmed match " + match.ID,
+		}); err != nil {
+			slog.Warn("match reviewed but missing not marked found",
+				"match_id", id,
+				"missing_id", m.ID,
+				"error", err,
+			)
+			result.StatusError = err
+		} else {
+			result.MissingFound = true
+		}
+	}
+
+	return result, nil
+}
+
+// markFound moves m to found at the location of the match's homeless
+// record.
+func (s *Service) markFound(ctx context.Context, m *missing.Missing, match *Match, c missing.StatusChange) error {
+	h, err := s.homelessRepo.FindByID(ctx, match.HomelessID)
+	if err != nil {
+		return fmt.Errorf("finding homeless %s: %w", match.HomelessID, err)
+	}
+	c.FoundLocation = h.Location
+	if err := m.ChangeStatus(c); err != nil {
+		return err
+	}
+	if err := s.missingRepo.Update(ctx, m); err != nil {
+		return fmt.Errorf("marking missing %s as found: %w", m.ID, err)
+	}
+	timeline.RecordOrLog(ctx, s.timeline, missing.StatusChangedEvent(m.ID, m.StatusHistory[len(m.StatusHistory)-1]))
 	return nil
 }
@@ -2,6 +2,8 @@ package matching_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -13,16 +15,19 @@ import (
 	"github.com/l3co/traceo-api/internal/domain/matching"
 	"github.com/l3co/traceo-api/internal/domain/missing"
 	"github.com/l3co/traceo-api/internal/domain/shared"
+	"github.com/l3co/traceo-api/internal/domain/timeline"
 )
 
 // --- Mock FaceComparer ---
 
 type mockComparer struct {
-	score float64
+	score    float64
+	degraded bool
 }
 
 func (m *mockComparer) CompareFaces(_ context.Context, _, _ string) (*matching.FaceComparisonResult, error) {
 	return &matching.FaceComparisonResult{
+		Degraded:          m.degraded,
 		SimilarityScore:   m.score,
 		Analysis:          "test analysis",
 		MatchingFeatures:  []string{"eyes", "nose"},
@@ -52,12 +57,22 @@ func (m *mockNotifier) NotifyPotentialMatch(_ context.Context, _ string, _ float
 // --- Mock MatchRepository ---
 
 type mockMatchRepo struct {
-	items []*matching.Match
+	items     []*matching.Match
+	upsertErr error
 }
 
-func (m *mockMatchRepo) Create(_ context.Context, match *matching.Match) error {
+func (m *mockMatchRepo) Upsert(_ context.Context, match *matching.Match) (*matching.Match, error) {
+	if m.upsertErr != nil {
+		return nil, m.upsertErr
+	}
+	for _, item := range m.items {
+		if item.HomelessID == match.HomelessID && item.MissingID == match.MissingID {
+			item.MergeComparisons(match)
+			return item, nil
+		}
+	}
 	m.items = append(m.items, match)
-	return nil
+	return match, nil
 }
 
 func (m *mockMatchRepo) FindByID(_ context.Context, id string) (*matching.Match, error) {
@@ -69,6 +84,15 @@ func (m *mockMatchRepo) FindByID(_ context.Context, id string) (*matching.Match,
 	return nil, matching.ErrMatchNotFound
 }
 
+func (m *mockMatchRepo) FindByPair(_ context.Context, hid, mid string) (*matching.Match, error) {
+	for _, item := range m.items {
+		if item.HomelessID == hid && item.MissingID == mid {
+			return item, nil
+		}
+	}
+	return nil, matching.ErrMatchNotFound
+}
+
 func (m *mockMatchRepo) FindByHomelessID(_ context.Context, hid string) ([]*matching.Match, error) {
 	var result []*matching.Match
 	for _, item := range m.items {
@@ -89,14 +113,28 @@ func (m *mockMatchRepo) FindByMissingID(_ context.Context, mid string) ([]*match
 	return result, nil
 }
 
-func (m *mockMatchRepo) UpdateStatus(_ context.Context, id string, status matching.MatchStatus) error {
+func (m *mockMatchRepo) AddReview(_ context.Context, id string, r matching.Review) (*matching.Match, error) {
 	for _, item := range m.items {
 		if item.ID == id {
-			item.Status = status
-			return nil
+			item.ApplyReview(r)
+			return item, nil
 		}
 	}
-	return matching.ErrMatchNotFound
+	return nil, matching.ErrMatchNotFound
+}
+
+// --- Mock Timeline ---
+
+type mockTimeline struct {
+	mu     sync.Mutex
+	events []*timeline.Event
+}
+
+func (m *mockTimeline) Record(_ context.Context, e *timeline.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, e)
+	return nil
 }
 
 // --- Mock HomelessRepo ---
@@ -114,29 +152,71 @@ func (m *mockHomelessRepo) FindByID(_ context.Context, id string) (*homeless.Hom
 	}
 	return nil, homeless.ErrHomelessNotFound
 }
-func (m *mockHomelessRepo) FindAll(_ context.Context) ([]*homeless.Homeless, error) { return nil, nil }
-func (m *mockHomelessRepo) Count(_ context.Context) (int64, error)                  { return 0, nil }
+func (m *mockHomelessRepo) FindAll(_ context.Context) ([]*homeless.Homeless, error) {
+	return m.items, nil
+}
+func (m *mockHomelessRepo) Count(_ context.Context) (int64, error) { return 0, nil }
 func (m *mockHomelessRepo) CountByGender(_ context.Context) ([]homeless.GenderStat, error) {
 	return nil, nil
 }
+func (m *mockHomelessRepo) UpdatePhotos(_ context.Context, _ string, _ shared.Photos) error {
+	return nil
+}
+func (m *mockHomelessRepo) FindByPhotoHash(_ context.Context, _ shared.PerceptualHash, _ int) ([]*homeless.Homeless, error) {
+	return nil, nil
+}
+func (m *mockHomelessRepo) FindCandidates(_ context.Context, filter homeless.CandidateFilter) ([]*homeless.Homeless, error) {
+	var result []*homeless.Homeless
+	for _, item := range m.items {
+		if filter.After != "" && item.ID <= filter.After {
+			continue
+		}
+		if filter.Limit > 0 && len(result) == filter.Limit {
+			break
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
 
 // --- Mock MissingRepo ---
 
 type mockMissingRepo struct {
-	items []*missing.Missing
+	items       []*missing.Missing
+	updated     []*missing.Missing
+	progression map[string]*missing.AgeProgression
 }
 
 func (m *mockMissingRepo) Create(_ context.Context, mi *missing.Missing) error { return nil }
 func (m *mockMissingRepo) FindByID(_ context.Context, id string) (*missing.Missing, error) {
-	return nil, nil
+	for _, item := range m.items {
+		if item.ID == id {
+			return item, nil
+		}
+	}
+	return nil, missing.ErrMissingNotFound
 }
-func (m *mockMissingRepo) Update(_ context.Context, mi *missing.Missing) error { return nil }
-func (m *mockMissingRepo) Delete(_ context.Context, id string) error           { return nil }
+func (m *mockMissingRepo) Update(_ context.Context, mi *missing.Missing) error {
+	m.updated = append(m.updated, mi)
+	return nil
+}
+func (m *mockMissingRepo) Delete(_ context.Context, id string) error { return nil }
 func (m *mockMissingRepo) FindByUserID(_ context.Context, uid string) ([]*missing.Missing, error) {
 	return nil, nil
 }
 func (m *mockMissingRepo) FindAll(_ context.Context, opts missing.ListOptions) ([]*missing.Missing, string, error) {
-	return nil, "", nil
+	start := 0
+	for i, item := range m.items {
+		if item.ID == opts.After {
+			start = i + 1
+		}
+	}
+	end := min(start+opts.PageSize, len(m.items))
+	var next string
+	if end-start == opts.PageSize {
+		next = m.items[end-1].ID
+	}
+	return m.items[start:end], next, nil
 }
 func (m *mockMissingRepo) Count(_ context.Context) (int64, error) { return 0, nil }
 func (m *mockMissingRepo) Search(_ context.Context, q string, l int) ([]*missing.Missing, error) {
@@ -149,12 +229,31 @@ func (m *mockMissingRepo) CountByYear(_ context.Context) ([]missing.YearStat, er
 	return nil, nil
 }
 func (m *mockMissingRepo) CountChildren(_ context.Context) (int64, error) { return 0, nil }
+func (m *mockMissingRepo) CountByStatus(_ context.Context) ([]missing.StatusStat, error) {
+	return nil, nil
+}
 func (m *mockMissingRepo) FindLocations(_ context.Context, l int) ([]missing.LocationPoint, error) {
 	return nil, nil
 }
-func (m *mockMissingRepo) UpdateAgeProgressionURLs(_ context.Context, _ string, _ []string) error {
+func (m *mockMissingRepo) FindAgeProgressionDue(_ context.Context, _ time.Time, _ int) ([]*missing.Missing, error) {
+	return nil, nil
+}
+func (m *mockMissingRepo) MarkAgeProgressionRequested(_ context.Context, _ string, _ time.Time) error {
+	return nil
+}
+func (m *mockMissingRepo) UpdateAgeProgression(_ context.Context, id string, p *missing.AgeProgression) error {
+	if m.progression == nil {
+		m.progression = map[string]*missing.AgeProgression{}
+	}
+	m.progression[id] = p
+	return nil
+}
+func (m *mockMissingRepo) UpdatePhotos(_ context.Context, _ *missing.Missing) error {
 	return nil
 }
+func (m *mockMissingRepo) FindByPhotoHash(_ context.Context, _ shared.PerceptualHash, _ int) ([]*missing.Missing, error) {
+	return nil, nil
+}
 func (m *mockMissingRepo) FindCandidates(_ context.Context, _ missing.CandidateFilter) ([]*missing.Missing, error) {
 	return m.items, nil
 }
@@ -204,6 +303,26 @@ func TestProcessFaceMatching_Score06_SavesMatch(t *testing.T) {
 	assert.Equal(t, matching.MatchStatusPending, matchRepo.items[0].Status)
 }
 
+func TestProcessFaceMatching_RecordsProposedMatch(t *testing.T) {
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
+		{ID: "h1", Name: "Carlos", PhotoURL: "http://photo1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown, BirthDate: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}}
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", Name: "João", PhotoURL: "http://photo2.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	tl := &mockTimeline{}
+
+	svc := matching.NewService(mRepo, hRepo, &mockMatchRepo{}, &mockComparer{score: 0.7}, nil, nil, matching.WithTimeline(tl))
+
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+	require.Len(t, tl.events, 1)
+	e := tl.events[0]
+	assert.Equal(t, timeline.TypeMatchProposed, e.Type)
+	assert.Equal(t, "m1", e.MissingID)
+	assert.Equal(t, "h1", e.Details["homeless_id"])
+	assert.Equal(t, "0.70", e.Details["score"])
+}
+
 func TestProcessFaceMatching_Score08_NotifiesAndSaves(t *testing.T) {
 	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
 		{ID: "h1", Name: "Carlos", PhotoURL: "http://photo1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown, BirthDate: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)},
@@ -234,22 +353,354 @@ func TestProcessFaceMatching_HomelessNotFound(t *testing.T) {
 	assert.Error(t, err)
 }
 
-func TestUpdateStatus_Valid(t *testing.T) {
+func TestProcessFaceMatching_RerunDoesNotDuplicatePair(t *testing.T) {
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
+		{ID: "h1", Name: "Carlos", PhotoURL: "http://photo1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown, BirthDate: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}}
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", Name: "João", PhotoURL: "http://photo2.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	matchRepo := &mockMatchRepo{}
+
+	svc := matching.NewService(mRepo, hRepo, matchRepo, &mockComparer{score: 0.7}, nil, nil)
+
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+	assert.Len(t, matchRepo.items, 1)
+}
+
+func TestProcessMissingMatching_SavesMatch(t *testing.T) {
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
+		{ID: "h1", Name: "Carlos", PhotoURL: "http://photo1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+		{ID: "h2", Name: "Sem foto", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", Name: "João", PhotoURL: "http://photo2.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	matchRepo := &mockMatchRepo{}
+
+	svc := matching.NewService(mRepo, hRepo, matchRepo, &mockComparer{score: 0.7}, nil, nil)
+
+	err := svc.ProcessMissingMatching(context.Background(), "m1")
+	require.NoError(t, err)
+	require.Len(t, matchRepo.items, 1)
+	assert.Equal(t, "h1", matchRepo.items[0].HomelessID)
+	assert.Equal(t, "m1", matchRepo.items[0].MissingID)
+	assert.Equal(t, matching.MatchStatusPending, matchRepo.items[0].Status)
+}
+
+func TestProcessMissingMatching_ReusesPairFromForwardMatching(t *testing.T) {
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
+		{ID: "h1", Name: "Carlos", PhotoURL: "http://photo1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", Name: "João", PhotoURL: "http://photo2.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	matchRepo := &mockMatchRepo{}
+
+	svc := matching.NewService(mRepo, hRepo, matchRepo, &mockComparer{score: 0.7}, nil, nil)
+
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+	require.NoError(t, svc.ProcessMissingMatching(context.Background(), "m1"))
+	assert.Len(t, matchRepo.items, 1)
+}
+
+func TestProcessFaceMatching_RerunRecordsHistory(t *testing.T) {
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
+		{ID: "h1", Name: "Carlos", PhotoURL: "http://photo1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", Name: "João", PhotoURL: "http://photo2.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	matchRepo := &mockMatchRepo{}
+	comparer := &mockComparer{score: 0.7}
+
+	svc := matching.NewService(mRepo, hRepo, matchRepo, comparer, nil, nil)
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+
+	comparer.score = 0.4
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+
+	require.Len(t, matchRepo.items, 1)
+	match := matchRepo.items[0]
+	assert.Equal(t, matching.PairID("h1", "m1"), match.ID)
+	assert.Equal(t, 0.4, match.Score)
+	require.Len(t, match.Comparisons, 2)
+	assert.Equal(t, 0.7, match.Comparisons[0].Score)
+}
+
+func TestProcessFaceMatching_KeepsReviewDecision(t *testing.T) {
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
+		{ID: "h1", Name: "Carlos", PhotoURL: "http://photo1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", Name: "João", PhotoURL: "http://photo2.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	reviewedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	matchRepo := &mockMatchRepo{items: []*matching.Match{
+		{ID: matching.PairID("h1", "m1"), HomelessID: "h1", MissingID: "m1", Score: 0.7, Status: matching.MatchStatusRejected, ReviewedAt: reviewedAt},
+	}}
+
+	svc := matching.NewService(mRepo, hRepo, matchRepo, &mockComparer{score: 0.9}, nil, nil)
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+
+	require.Len(t, matchRepo.items, 1)
+	assert.Equal(t, matching.MatchStatusRejected, matchRepo.items[0].Status)
+	assert.Equal(t, reviewedAt, matchRepo.items[0].ReviewedAt)
+	assert.Equal(t, 0.9, matchRepo.items[0].Score)
+}
+
+func TestProcessFaceMatching_NotifiesOncePerPair(t *testing.T) {
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
+		{ID: "h1", Name: "Carlos", PhotoURL: "http://photo1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", Name: "João", PhotoURL: "http://photo2.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	notifier := &mockNotifier{}
+
+	svc := matching.NewService(mRepo, hRepo, &mockMatchRepo{}, &mockComparer{score: 0.85}, nil, notifier)
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+
+	time.Sleep(100 * time.Millisecond)
+	notifier.mu.Lock()
+	assert.Equal(t, 1, notifier.calls)
+	notifier.mu.Unlock()
+}
+
+func TestProcessFaceMatching_DegradedNeitherSavesNorNotifies(t *testing.T) {
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
+		{ID: "h1", Name: "Carlos", PhotoURL: "http://photo1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", Name: "João", PhotoURL: "http://photo2.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	matchRepo := &mockMatchRepo{}
+	notifier := &mockNotifier{}
+	tl := &mockTimeline{}
+	comparer := &mockComparer{score: 0.95, degraded: true}
+
+	svc := matching.NewService(mRepo, hRepo, matchRepo, comparer, nil, notifier, matching.WithTimeline(tl))
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+	assert.Empty(t, matchRepo.items)
+	assert.Empty(t, tl.events)
+
+	// A known pair keeps its history, still without notifying.
+	comparer.degraded = false
+	comparer.score = 0.65
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+	comparer.degraded = true
+	comparer.score = 0.95
+	require.NoError(t, svc.ProcessFaceMatching(context.Background(), "h1"))
+	require.Len(t, matchRepo.items, 1)
+	assert.Len(t, matchRepo.items[0].Comparisons, 2)
+
+	time.Sleep(100 * time.Millisecond)
+	notifier.mu.Lock()
+	assert.Zero(t, notifier.calls)
+	notifier.mu.Unlock()
+}
+
+func TestProcessMissingMatching_NoPhoto(t *testing.T) {
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", Name: "João", Gender: shared.GenderMale},
+	}}
+	matchRepo := &mockMatchRepo{}
+	svc := matching.NewService(mRepo, &mockHomelessRepo{}, matchRepo, &mockComparer{score: 0.9}, nil, nil)
+
+	err := svc.ProcessMissingMatching(context.Background(), "m1")
+	require.NoError(t, err)
+	assert.Len(t, matchRepo.items, 0)
+}
+
+func TestProcessMissingMatching_MissingNotFound(t *testing.T) {
+	svc := matching.NewService(&mockMissingRepo{}, &mockHomelessRepo{}, &mockMatchRepo{}, &mockComparer{}, nil, nil)
+
+	err := svc.ProcessMissingMatching(context.Background(), "nonexistent")
+	assert.ErrorIs(t, err, missing.ErrMissingNotFound)
+}
+
+func newReviewFixture() (*mockMissingRepo, *mockMatchRepo) {
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", UserID: "owner", Status: missing.StatusDisappeared},
+	}}
 	matchRepo := &mockMatchRepo{items: []*matching.Match{
-		{ID: "match-1", Status: matching.MatchStatusPending},
+		{ID: "match-1", HomelessID: "h1", MissingID: "m1", Status: matching.MatchStatusPending},
+	}}
+	return mRepo, matchRepo
+}
+
+func TestReview_OwnerConfirms(t *testing.T) {
+	mRepo, matchRepo := newReviewFixture()
+	svc := matching.NewService(mRepo, nil, matchRepo, nil, nil, nil)
+
+	result, err := svc.Review(context.Background(), "match-1", matching.ReviewInput{
+		ReviewerID: "owner",
+		Decision:   matching.MatchStatusConfirmed,
+		Notes:      "  Reconheci a cicatriz  ",
+	})
+	require.NoError(t, err)
+	updated := result.Match
+	assert.Equal(t, matching.MatchStatusConfirmed, updated.Status)
+	assert.Equal(t, "owner", updated.ReviewedBy)
+	assert.False(t, updated.ReviewedAt.IsZero())
+	require.Len(t, updated.Reviews, 1)
+	assert.Equal(t, "Reconheci a cicatriz", updated.Reviews[0].Notes)
+	assert.Empty(t, mRepo.updated, "missing status must not change unless requested")
+}
+
+func TestReview_KeepsHistory(t *testing.T) {
+	mRepo, matchRepo := newReviewFixture()
+	svc := matching.NewService(mRepo, nil, matchRepo, nil, nil, nil, matching.WithModerators([]string{"mod"}))
+
+	_, err := svc.Review(context.Background(), "match-1", matching.ReviewInput{ReviewerID: "owner", Decision: matching.MatchStatusConfirmed})
+	require.NoError(t, err)
+	result, err := svc.Review(context.Background(), "match-1", matching.ReviewInput{ReviewerID: "mod", Decision: matching.MatchStatusRejected, Notes: "foto errada"})
+	require.NoError(t, err)
+	updated := result.Match
+
+	assert.Equal(t, matching.MatchStatusRejected, updated.Status)
+	assert.Equal(t, "mod", updated.ReviewedBy)
+	require.Len(t, updated.Reviews, 2)
+	assert.Equal(t, matching.MatchStatusConfirmed, updated.Reviews[0].Decision)
+	assert.Equal(t, "owner", updated.Reviews[0].ReviewerID)
+}
+
+func TestReview_Forbidden(t *testing.T) {
+	mRepo, matchRepo := newReviewFixture()
+	svc := matching.NewService(mRepo, nil, matchRepo, nil, nil, nil, matching.WithModerators([]string{"mod"}))
+
+	_, err := svc.Review(context.Background(), "match-1", matching.ReviewInput{ReviewerID: "stranger", Decision: matching.MatchStatusConfirmed})
+	assert.ErrorIs(t, err, matching.ErrForbidden)
+	assert.Equal(t, matching.MatchStatusPending, matchRepo.items[0].Status)
+}
+
+func TestCanReview(t *testing.T) {
+	mRepo, matchRepo := newReviewFixture()
+	svc := matching.NewService(mRepo, nil, matchRepo, nil, nil, nil, matching.WithModerators([]string{"mod"}))
+	ctx := context.Background()
+
+	for userID, want := range map[string]bool{"owner": true, "mod": true, "stranger": false, "": false} {
+		got, err := svc.CanReview(ctx, "m1", userID)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, userID)
+	}
+
+	got, err := svc.CanReview(ctx, "gone", "owner")
+	require.NoError(t, err)
+	assert.False(t, got)
+}
+
+func TestReview_MarkMissingFound(t *testing.T) {
+	mRepo, matchRepo := newReviewFixture()
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
+		{ID: "h1", Location: shared.GeoPoint{Lat: -23.5, Lng: -46.6, Address: "Praça da Sé"}},
 	}}
-	svc := matching.NewService(nil, nil, matchRepo, nil, nil, nil)
+	svc := matching.NewService(mRepo, hRepo, matchRepo, nil, nil, nil, matching.WithModerators([]string{"mod"}))
+
+	foundAt := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	result, err := svc.Review(context.Background(), "match-1", matching.ReviewInput{
+		ReviewerID:       "mod",
+		Decision:         matching.MatchStatusConfirmed,
+		MarkMissingFound: true,
+		FoundAt:          foundAt,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.MissingFound)
+	assert.NoError(t, result.StatusError)
+	require.Len(t, mRepo.updated, 1)
+	m := mRepo.updated[0]
+	assert.Equal(t, missing.StatusFoundAlive, m.Status)
+	assert.Equal(t, foundAt, m.FoundAt)
+	assert.Equal(t, "Praça da Sé", m.FoundLocation.Address)
+	require.Len(t, m.StatusHistory, 1)
+	assert.Equal(t, missing.RoleModerator, m.StatusHistory[0].Role)
+	assert.Equal(t, "confirmed match match-1", m.StatusHistory[0].Note)
+}
+
+func TestReview_RecordsTimeline(t *testing.T) {
+	mRepo, matchRepo := newReviewFixture()
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{{ID: "h1", Location: shared.GeoPoint{Address: "Praça da Sé"}}}}
+	tl := &mockTimeline{}
+	svc := matching.NewService(mRepo, hRepo, matchRepo, nil, nil, nil, matching.WithTimeline(tl))
+
+	_, err := svc.Review(context.Background(), "match-1", matching.ReviewInput{
+		ReviewerID:       "owner",
+		Decision:         matching.MatchStatusConfirmed,
+		MarkMissingFound: true,
+		FoundAt:          time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
 
-	err := svc.UpdateStatus(context.Background(), "match-1", matching.MatchStatusConfirmed)
+	require.Len(t, tl.events, 2)
+	assert.Equal(t, timeline.TypeMatchReviewed, tl.events[0].Type)
+	assert.Equal(t, "owner", tl.events[0].ActorID)
+	assert.Equal(t, map[string]string{"match_id": "match-1", "decision": "confirmed"}, tl.events[0].Details)
+	assert.Equal(t, timeline.TypeStatusChanged, tl.events[1].Type)
+	assert.Equal(t, "found_alive", tl.events[1].Details["to"])
+	assert.Equal(t, "m1", tl.events[1].MissingID)
+}
+
+func TestReview_MarkMissingFoundOnArchivedCase(t *testing.T) {
+	mRepo, matchRepo := newReviewFixture()
+	mRepo.items[0].Status = missing.StatusArchived
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{{ID: "h1", Location: shared.GeoPoint{Address: "Praça da Sé"}}}}
+	svc := matching.NewService(mRepo, hRepo, matchRepo, nil, nil, nil)
+
+	result, err := svc.Review(context.Background(), "match-1", matching.ReviewInput{
+		ReviewerID:       "owner",
+		Decision:         matching.MatchStatusConfirmed,
+		MarkMissingFound: true,
+		FoundAt:          time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC),
+	})
 	require.NoError(t, err)
-	assert.Equal(t, matching.MatchStatusConfirmed, matchRepo.items[0].Status)
+	assert.False(t, result.MissingFound)
+	assert.ErrorIs(t, result.StatusError, missing.ErrInvalidTransition)
+	assert.Empty(t, mRepo.updated)
+	assert.Equal(t, matching.MatchStatusConfirmed, matchRepo.items[0].Status, "the review is kept")
+}
+
+func TestReview_MarkMissingFoundRequiresFoundAt(t *testing.T) {
+	mRepo, matchRepo := newReviewFixture()
+	svc := matching.NewService(mRepo, nil, matchRepo, nil, nil, nil)
+
+	_, err := svc.Review(context.Background(), "match-1", matching.ReviewInput{
+		ReviewerID:       "owner",
+		Decision:         matching.MatchStatusConfirmed,
+		MarkMissingFound: true,
+	})
+	assert.ErrorIs(t, err, matching.ErrInvalidMatch)
+	assert.Equal(t, matching.MatchStatusPending, matchRepo.items[0].Status)
 }
 
-func TestUpdateStatus_InvalidStatus(t *testing.T) {
-	svc := matching.NewService(nil, nil, &mockMatchRepo{}, nil, nil, nil)
+func TestReview_MarkMissingFoundRequiresConfirmation(t *testing.T) {
+	mRepo, matchRepo := newReviewFixture()
+	svc := matching.NewService(mRepo, nil, matchRepo, nil, nil, nil)
 
-	err := svc.UpdateStatus(context.Background(), "match-1", "invalid")
+	_, err := svc.Review(context.Background(), "match-1", matching.ReviewInput{
+		ReviewerID:       "owner",
+		Decision:         matching.MatchStatusRejected,
+		MarkMissingFound: true,
+	})
 	assert.ErrorIs(t, err, matching.ErrInvalidMatch)
+	assert.Empty(t, mRepo.updated)
+}
+
+func TestReview_InvalidStatus(t *testing.T) {
+	mRepo, matchRepo := newReviewFixture()
+	svc := matching.NewService(mRepo, nil, matchRepo, nil, nil, nil)
+
+	_, err := svc.Review(context.Background(), "match-1", matching.ReviewInput{ReviewerID: "owner", Decision: "invalid"})
+	assert.ErrorIs(t, err, matching.ErrInvalidMatch)
+}
+
+func TestReview_MatchNotFound(t *testing.T) {
+	mRepo, _ := newReviewFixture()
+	svc := matching.NewService(mRepo, nil, &mockMatchRepo{}, nil, nil, nil)
+
+	_, err := svc.Review(context.Background(), "nope", matching.ReviewInput{ReviewerID: "owner", Decision: matching.MatchStatusConfirmed})
+	assert.ErrorIs(t, err, matching.ErrMatchNotFound)
 }
 
 func TestEntity_Validate(t *testing.T) {
@@ -266,3 +717,54 @@ func TestEntity_Validate_InvalidScore(t *testing.T) {
 	m := &matching.Match{HomelessID: "h1", MissingID: "m1", Score: 1.5}
 	assert.ErrorIs(t, m.Validate(), matching.ErrInvalidMatch)
 }
+
+// --- Tests: transient comparer failures ---
+
+type unavailableComparer struct{}
+
+func (unavailableComparer) CompareFaces(_ context.Context, _, _ string) (*matching.FaceComparisonResult, error) {
+	return nil, fmt.Errorf("%w: gemini 503", matching.ErrComparerUnavailable)
+}
+
+func TestProcessMissingMatching_ComparerUnavailable_ReturnsError(t *testing.T) {
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
+		{ID: "h1", Name: "Carlos", PhotoURL: "http://photo1.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", Name: "João", PhotoURL: "http://photo2.jpg", Gender: shared.GenderMale, Skin: shared.SkinBrown},
+	}}
+
+	svc := matching.NewService(mRepo, hRepo, &mockMatchRepo{}, unavailableComparer{}, nil, nil)
+
+	err := svc.ProcessMissingMatching(context.Background(), "m1")
+	assert.ErrorIs(t, err, matching.ErrComparerUnavailable)
+}
+
+type brokenComparer struct{}
+
+func (brokenComparer) CompareFaces(_ context.Context, _, _ string) (*matching.FaceComparisonResult, error) {
+	return nil, errors.New("photo could not be decoded")
+}
+
+func TestProcessMissingMatching_ComparisonFailure_SkipsPair(t *testing.T) {
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
+		{ID: "h1", Name: "Carlos", PhotoURL: "http://photo1.jpg"},
+	}}
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", Name: "João", PhotoURL: "http://photo2.jpg"},
+	}}
+
+	svc := matching.NewService(mRepo, hRepo, &mockMatchRepo{}, brokenComparer{}, nil, nil)
+
+	assert.NoError(t, svc.ProcessMissingMatching(context.Background(), "m1"))
+}
+
+func TestProcessPairMatching_ComparisonFailure_ReturnsError(t *testing.T) {
+	mRepo, hRepo := sweepFixtures()
+	ledger := &mockLedger{keys: map[string]bool{}}
+	svc := matching.NewService(mRepo, hRepo, &mockMatchRepo{}, brokenComparer{}, nil, nil,
+		matching.WithPairLedger(ledger, &mockPublisher{}))
+
+	assert.Error(t, svc.ProcessPairMatching(context.Background(), "h1", "m1"))
+	assert.Empty(t, ledger.keys)
+}
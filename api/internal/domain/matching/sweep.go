@@ -0,0 +1,211 @@
+package matching
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/l3co/traceo-api/internal/domain/event"
+	"github.com/l3co/traceo-api/internal/domain/homeless"
+	"github.com/l3co/traceo-api/internal/domain/missing"
+)
+
+// sweepPageSize is how many missing cases Sweep loads per page.
+const sweepPageSize = 100
+
+// Sweep walks every open case with a photo and enqueues, through the
+// publisher, a comparison for each homeless candidate the pair ledger has no
+// record of under the current comparer version. It stops after maxPairs
+// pairs, or after the sweep's share of the remaining AI budget when
+// WithSweepBudget is set, and returns how many pairs were enqueued. Without a
+// pair ledger it does nothing.
+func (s *Service) Sweep(ctx context.Context, maxPairs int) (int, error) {
+	if s.ledger == nil || s.publisher == nil {
+		return 0, nil
+	}
+
+	limit := maxPairs
+	if s.budget != nil {
+		usage, err := s.budget.Usage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if remaining := usage.Remaining(); remaining >= 0 {
+			limit = min(limit, int(float64(remaining)*s.budgetShare))
+		}
+	}
+	if limit <= 0 {
+		slog.Info("match sweep skipped, no ai budget left")
+		return 0, nil
+	}
+
+	var enqueued, cases int
+	cursor := ""
+	for enqueued < limit {
+		page, next, err := s.missingRepo.FindAll(ctx, missing.ListOptions{PageSize: sweepPageSize, After: cursor})
+		if err != nil {
+			return enqueued, fmt.Errorf("listing missing: %w", err)
+		}
+
+		for _, m := range page {
+			if !m.Status.IsOpen() || m.PhotoURL == "" {
+				continue
+			}
+			cases++
+			n, err := s.sweepCase(ctx, m, limit-enqueued)
+			enqueued += n
+			if err != nil {
+				return enqueued, err
+			}
+			if enqueued == limit {
+				break
+			}
+		}
+
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	slog.Info("match sweep finished",
+		"cases", cases,
+		"pairs_enqueued", enqueued,
+		"limit", limit,
+	)
+	return enqueued, nil
+}
+
+// sweepCase enqueues up to limit uncompared pairs between m and its homeless
+// candidates, and no more than the policy's CandidateLimit, and returns how
+// many it enqueued. Candidates are read a page of CandidateLimit at a time
+// until enough uncompared pairs are found, so pairs already in the ledger do
+// not hide the candidates after them.
+func (s *Service) sweepCase(ctx context.Context, m *missing.Missing, limit int) (int, error) {
+	limit = min(limit, s.policy.CandidateLimit)
+	filter := s.homelessFilter(m)
+	filter.Limit = s.policy.CandidateLimit
+
+	enqueued := 0
+	for enqueued < limit {
+		candidates, err := s.homelessRepo.FindCandidates(ctx, filter)
+		if err != nil {
+			return enqueued, fmt.Errorf("finding homeless candidates for %s: %w", m.ID, err)
+		}
+
+		n, err := s.enqueueUncompared(ctx, m, candidates, limit-enqueued)
+		enqueued += n
+		if err != nil {
+			return enqueued, err
+		}
+		if len(candidates) < filter.Limit {
+			break
+		}
+		filter.After = candidates[len(candidates)-1].ID
+	}
+	return enqueued, nil
+}
+
+// enqueueUncompared enqueues up to limit of the pairs between m and
+// candidates that the ledger has no record of.
+func (s *Service) enqueueUncompared(ctx context.Context, m *missing.Missing, candidates []*homeless.Homeless, limit int) (int, error) {
+	pairs := make([]*homeless.Homeless, 0, len(candidates))
+	keys := make([]string, 0, len(candidates))
+	for _, h := range candidates {
+		if h.PhotoURL == "" || !s.withinAgeTolerance(h, m) {
+			continue
+		}
+		pairs = append(pairs, h)
+		keys = append(keys, s.pairKey(h, m))
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	compared, err := s.ledger.Compared(ctx, keys)
+	if err != nil {
+		return 0, fmt.Errorf("reading pair ledger: %w", err)
+	}
+
+	enqueued := 0
+	for i, h := range pairs {
+		if enqueued == limit {
+			break
+		}
+		if compared[keys[i]] {
+			continue
+		}
+		err := s.publisher.Publish(ctx, event.Event{
+			Type:        event.MatchPairDue,
+			AggregateID: m.ID,
+			CandidateID: h.ID,
+			Key:         keys[i],
+			PhotoURL:    m.PhotoURL,
+			OccurredAt:  time.Now(),
+		})
+		if errors.Is(err, event.ErrDuplicate) {
+			// Already queued or attempted today; it does not use up the limit.
+			continue
+		}
+		if err != nil {
+			return enqueued, fmt.Errorf("enqueueing pair %s/%s: %w", h.ID, m.ID, err)
+		}
+		enqueued++
+	}
+	return enqueued, nil
+}
+
+// ProcessPairMatching compares one homeless/missing pair enqueued by Sweep.
+// Pairs that no longer qualify, or that were compared by another matching
+// run since, are skipped. A failed comparison is returned so the job is
+// retried and, once out of attempts, dead-lettered.
+func (s *Service) ProcessPairMatching(ctx context.Context, homelessID, missingID string) error {
+	h, err := s.homelessRepo.FindByID(ctx, homelessID)
+	if errors.Is(err, homeless.ErrHomelessNotFound) {
+		slog.Warn("homeless not found, skipping pair matching", "id", homelessID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("finding homeless %s: %w", homelessID, err)
+	}
+
+	m, err := s.missingRepo.FindByID(ctx, missingID)
+	if errors.Is(err, missing.ErrMissingNotFound) {
+		slog.Warn("missing not found, skipping pair matching", "id", missingID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("finding missing %s: %w", missingID, err)
+	}
+
+	if h.PhotoURL == "" || m.PhotoURL == "" || !m.Status.IsOpen() {
+		return nil
+	}
+
+	if s.ledger != nil {
+		key := s.pairKey(h, m)
+		compared, err := s.ledger.Compared(ctx, []string{key})
+		if err != nil {
+			return fmt.Errorf("reading pair ledger: %w", err)
+		}
+		if compared[key] {
+			return nil
+		}
+	}
+
+	_, err = s.matchRepo.FindByPair(ctx, h.ID, m.ID)
+	if err != nil && !errors.Is(err, ErrMatchNotFound) {
+		return fmt.Errorf("finding existing match: %w", err)
+	}
+
+	return s.comparePair(ctx, h, m, err == nil)
+}
+
+// pairKey covers the photos bestPhotoPair would compare, so adding a better
+// photo to either record makes the pair due again.
+func (s *Service) pairKey(h *homeless.Homeless, m *missing.Missing) string {
+	homelessPhoto, missingPhoto := bestPhotoPair(h, m)
+	return PairKey(h.ID, homelessPhoto, m.ID, missingPhoto, ComparerVersion(s.comparer))
+}
@@ -0,0 +1,267 @@
+package matching_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/event"
+	"github.com/l3co/traceo-api/internal/domain/homeless"
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/internal/domain/missing"
+)
+
+// --- Mock PairLedger ---
+
+type mockLedger struct {
+	keys map[string]bool
+}
+
+func (m *mockLedger) Compared(_ context.Context, keys []string) (map[string]bool, error) {
+	result := map[string]bool{}
+	for _, k := range keys {
+		if m.keys[k] {
+			result[k] = true
+		}
+	}
+	return result, nil
+}
+
+func (m *mockLedger) Record(_ context.Context, key string, _ time.Time) error {
+	m.keys[key] = true
+	return nil
+}
+
+// --- Mock Publisher ---
+
+type mockPublisher struct {
+	events []event.Event
+	queued map[string]bool
+}
+
+func (m *mockPublisher) Publish(_ context.Context, e event.Event) error {
+	if m.queued[e.Key] {
+		return event.ErrDuplicate
+	}
+	m.events = append(m.events, e)
+	return nil
+}
+
+func sweepFixtures() (*mockMissingRepo, *mockHomelessRepo) {
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", PhotoURL: "http://m1.jpg", Status: missing.StatusDisappeared},
+		{ID: "m2", PhotoURL: "http://m2.jpg", Status: missing.StatusFound},
+		{ID: "m3", Status: missing.StatusDisappeared},
+		{ID: "m4", PhotoURL: "http://m4.jpg", Status: missing.StatusDisappeared},
+	}}
+	hRepo := &mockHomelessRepo{items: []*homeless.Homeless{
+		{ID: "h1", PhotoURL: "http://h1.jpg"},
+		{ID: "h2", PhotoURL: "http://h2.jpg"},
+		{ID: "h3"},
+	}}
+	return mRepo, hRepo
+}
+
+func TestSweep_EnqueuesUncomparedOpenPairs(t *testing.T) {
+	mRepo, hRepo := sweepFixtures()
+	comparer := &versionedComparer{countingComparer: countingComparer{score: 0.3}, version: "v1"}
+	ledger := &mockLedger{keys: map[string]bool{
+		matching.PairKey("h1", "http://h1.jpg", "m1", "http://m1.jpg", "v1"): true,
+	}}
+	pub := &mockPublisher{}
+	svc := matching.NewService(mRepo, hRepo, &mockMatchRepo{}, comparer, nil, nil,
+		matching.WithPairLedger(ledger, pub))
+
+	n, err := svc.Sweep(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	var pairs []string
+	for _, e := range pub.events {
+		assert.Equal(t, event.MatchPairDue, e.Type)
+		assert.NotEmpty(t, e.Key)
+		pairs = append(pairs, e.CandidateID+"/"+e.AggregateID)
+	}
+	assert.Equal(t, []string{"h2/m1", "h1/m4", "h2/m4"}, pairs)
+	assert.Zero(t, comparer.calls)
+}
+
+func TestSweep_StopsAtMaxPairs(t *testing.T) {
+	mRepo, hRepo := sweepFixtures()
+	pub := &mockPublisher{}
+	svc := matching.NewService(mRepo, hRepo, &mockMatchRepo{}, &mockComparer{}, nil, nil,
+		matching.WithPairLedger(&mockLedger{keys: map[string]bool{}}, pub))
+
+	n, err := svc.Sweep(context.Background(), 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Len(t, pub.events, 3)
+}
+
+func TestSweep_AlreadyQueuedPairsDoNotCount(t *testing.T) {
+	mRepo, hRepo := sweepFixtures()
+	comparer := &versionedComparer{countingComparer: countingComparer{score: 0.3}, version: "v1"}
+	pub := &mockPublisher{queued: map[string]bool{
+		matching.PairKey("h1", "http://h1.jpg", "m1", "http://m1.jpg", "v1"): true,
+	}}
+	svc := matching.NewService(mRepo, hRepo, &mockMatchRepo{}, comparer, nil, nil,
+		matching.WithPairLedger(&mockLedger{keys: map[string]bool{}}, pub))
+
+	n, err := svc.Sweep(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	var pairs []string
+	for _, e := range pub.events {
+		pairs = append(pairs, e.CandidateID+"/"+e.AggregateID)
+	}
+	assert.Equal(t, []string{"h2/m1", "h1/m4"}, pairs)
+}
+
+func TestSweep_CappedByBudgetShare(t *testing.T) {
+	mRepo, hRepo := sweepFixtures()
+	pub := &mockPublisher{}
+	budget := matching.NewBudget(matching.BudgetConfig{DailyLimit: 10}, &mockUsage{day: 6, month: 6})
+	svc := matching.NewService(mRepo, hRepo, &mockMatchRepo{}, &mockComparer{}, nil, nil,
+		matching.WithPairLedger(&mockLedger{keys: map[string]bool{}}, pub),
+		matching.WithSweepBudget(budget, 0.5))
+
+	n, err := svc.Sweep(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	budget = matching.NewBudget(matching.BudgetConfig{DailyLimit: 10}, &mockUsage{day: 10, month: 10})
+	svc = matching.NewService(mRepo, hRepo, &mockMatchRepo{}, &mockComparer{}, nil, nil,
+		matching.WithPairLedger(&mockLedger{keys: map[string]bool{}}, pub),
+		matching.WithSweepBudget(budget, 0.5))
+
+	n, err = svc.Sweep(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestSweep_PagesPastComparedCandidates(t *testing.T) {
+	mRepo := &mockMissingRepo{items: []*missing.Missing{
+		{ID: "m1", PhotoURL: "http://m1.jpg", Status: missing.StatusDisappeared},
+	}}
+	hRepo := &mockHomelessRepo{}
+	for i := 0; i < 5; i++ {
+		id := fmt.Sprintf("h%d", i)
+		hRepo.items = append(hRepo.items, &homeless.Homeless{ID: id, PhotoURL: "http://" + id + ".jpg"})
+	}
+	comparer := &versionedComparer{countingComparer: countingComparer{score: 0.1}, version: "v1"}
+	ledger := &mockLedger{keys: map[string]bool{}}
+	for _, h := range hRepo.items[:2] {
+		ledger.keys[matching.PairKey(h.ID, h.PhotoURL, "m1", "http://m1.jpg", "v1")] = true
+	}
+	pub := &mockPublisher{}
+	svc := matching.NewService(mRepo, hRepo, &mockMatchRepo{}, comparer, nil, nil,
+		matching.WithPairLedger(ledger, pub),
+		matching.WithPolicy(policyWithLimit(2)))
+
+	n, err := svc.Sweep(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n, "capped at the candidate limit")
+	require.Len(t, pub.events, 2)
+	assert.Equal(t, "h2", pub.events[0].CandidateID)
+	assert.Equal(t, "h3", pub.events[1].CandidateID)
+}
+
+func TestSweep_WithoutLedgerDoesNothing(t *testing.T) {
+	mRepo, hRepo := sweepFixtures()
+	svc := matching.NewService(mRepo, hRepo, &mockMatchRepo{}, &mockComparer{}, nil, nil)
+
+	n, err := svc.Sweep(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestProcessPairMatching_ComparesOnceAndRecords(t *testing.T) {
+	mRepo, hRepo := sweepFixtures()
+	comparer := &versionedComparer{countingComparer: countingComparer{score: 0.7}, version: "v1"}
+	ledger := &mockLedger{keys: map[string]bool{}}
+	pub := &mockPublisher{}
+	matchRepo := &mockMatchRepo{}
+	svc := matching.NewService(mRepo, hRepo, matchRepo, comparer, nil, nil,
+		matching.WithPairLedger(ledger, pub))
+	ctx := context.Background()
+
+	require.NoError(t, svc.ProcessPairMatching(ctx, "h1", "m1"))
+	require.NoError(t, svc.ProcessPairMatching(ctx, "h1", "m1"))
+	assert.Equal(t, 1, comparer.calls)
+	require.Len(t, matchRepo.items, 1)
+	assert.Equal(t, matching.PairID("h1", "m1"), matchRepo.items[0].ID)
+	assert.True(t, ledger.keys[matching.PairKey("h1", "http://h1.jpg", "m1", "http://m1.jpg", "v1")])
+}
+
+func TestProcessPairMatching_SaveFailureIsRetried(t *testing.T) {
+	mRepo, hRepo := sweepFixtures()
+	comparer := &versionedComparer{countingComparer: countingComparer{score: 0.7}, version: "v1"}
+	ledger := &mockLedger{keys: map[string]bool{}}
+	matchRepo := &mockMatchRepo{upsertErr: errors.New("firestore unavailable")}
+	svc := matching.NewService(mRepo, hRepo, matchRepo, comparer, nil, nil,
+		matching.WithPairLedger(ledger, &mockPublisher{}))
+	ctx := context.Background()
+
+	assert.Error(t, svc.ProcessPairMatching(ctx, "h1", "m1"))
+	assert.Empty(t, ledger.keys, "a pair whose match was not saved must stay due")
+
+	matchRepo.upsertErr = nil
+	require.NoError(t, svc.ProcessPairMatching(ctx, "h1", "m1"))
+	assert.Equal(t, 2, comparer.calls)
+	assert.Len(t, matchRepo.items, 1)
+	assert.Len(t, ledger.keys, 1)
+}
+
+func TestProcessPairMatching_UpdatesMatchStoredUnderOldID(t *testing.T) {
+	mRepo, hRepo := sweepFixtures()
+	comparer := &countingComparer{score: 0.1}
+	legacy := &matching.Match{ID: "legacy-uuid", HomelessID: "h1", MissingID: "m1", Status: matching.MatchStatusPending}
+	legacy.RecordComparison(matching.Comparison{Score: 0.8})
+	matchRepo := &mockMatchRepo{items: []*matching.Match{legacy}}
+	svc := matching.NewService(mRepo, hRepo, matchRepo, comparer, nil, nil)
+
+	require.NoError(t, svc.ProcessPairMatching(context.Background(), "h1", "m1"))
+	require.Len(t, matchRepo.items, 1)
+	assert.Equal(t, "legacy-uuid", matchRepo.items[0].ID)
+	assert.Len(t, matchRepo.items[0].Comparisons, 2)
+}
+
+func TestProcessPairMatching_SkipsClosedOrDeleted(t *testing.T) {
+	mRepo, hRepo := sweepFixtures()
+	comparer := &countingComparer{score: 0.7}
+	svc := matching.NewService(mRepo, hRepo, &mockMatchRepo{}, comparer, nil, nil)
+	ctx := context.Background()
+
+	require.NoError(t, svc.ProcessPairMatching(ctx, "h1", "m2"))
+	require.NoError(t, svc.ProcessPairMatching(ctx, "h1", "gone"))
+	require.NoError(t, svc.ProcessPairMatching(ctx, "gone", "m1"))
+	assert.Zero(t, comparer.calls)
+}
+
+func TestSweep_ComparerVersionChangeRequeues(t *testing.T) {
+	mRepo, hRepo := sweepFixtures()
+	ledger := &mockLedger{keys: map[string]bool{}}
+	pub := &mockPublisher{}
+	v1 := &versionedComparer{countingComparer: countingComparer{score: 0.3}, version: "v1"}
+	svc := matching.NewService(mRepo, hRepo, &mockMatchRepo{}, v1, nil, nil,
+		matching.WithPairLedger(ledger, pub))
+	ctx := context.Background()
+
+	require.NoError(t, svc.ProcessMissingMatching(ctx, "m1"))
+	n, err := svc.Sweep(ctx, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n, "only m4's pairs are left under v1")
+
+	v2 := &versionedComparer{countingComparer: countingComparer{score: 0.3}, version: "v2"}
+	svc = matching.NewService(mRepo, hRepo, &mockMatchRepo{}, v2, nil, nil,
+		matching.WithPairLedger(ledger, pub))
+	n, err = svc.Sweep(ctx, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+}
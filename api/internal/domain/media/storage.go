@@ -0,0 +1,12 @@
+package media
+
+import "context"
+
+// Storage keeps binary objects such as photos and generated images. Keys
+// are slash-separated paths chosen by the caller; Put overwrites an existing
+// object and returns the URL clients should use to fetch it. Deleting an
+// object that does not exist is not an error.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	Delete(ctx context.Context, key string) error
+}
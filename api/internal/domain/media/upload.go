@@ -0,0 +1,114 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image")
+	ErrImageTooLarge    = errors.New("image too large")
+)
+
+// Variant names one of the sizes an uploaded photo is stored in.
+type Variant string
+
+const (
+	VariantThumbnail Variant = "thumbnail"
+	VariantMedium    Variant = "medium"
+	VariantFull      Variant = "full"
+)
+
+// Image is one encoded variant of an uploaded photo.
+type Image struct {
+	Variant     Variant
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// ImageProcessor turns uploaded bytes into the variants that get stored.
+// It must check the actual content rather than trust the client, return
+// ErrUnsupportedImage for anything that is not a supported image, and drop
+// all metadata (EXIF, GPS, comments) from its output.
+type ImageProcessor interface {
+	Process(data []byte) ([]Image, error)
+}
+
+// UploadedPhoto is where the variants of an upload were stored. URL is the
+// full-size variant, the one to use as a record's photo URL.
+type UploadedPhoto struct {
+	ID       string
+	URL      string
+	Variants map[Variant]string
+	Width    int
+	Height   int
+}
+
+type Uploader struct {
+	storage   Storage
+	processor ImageProcessor
+}
+
+func NewUploader(storage Storage, processor ImageProcessor) *Uploader {
+	return &Uploader{storage: storage, processor: processor}
+}
+
+// Upload processes data and stores every variant under photos/{id}/. If a
+// variant cannot be stored, the ones already stored are deleted.
+func (u *Uploader) Upload(ctx context.Context, data []byte) (*UploadedPhoto, error) {
+	images, err := u.processor.Process(data)
+	if err != nil {
+		return nil, err
+	}
+
+	photo := &UploadedPhoto{
+		ID:       uuid.NewString(),
+		Variants: make(map[Variant]string, len(images)),
+	}
+	stored := make([]string, 0, len(images))
+	for _, img := range images {
+		key := fmt.Sprintf("photos/%s/%s%s", photo.ID, img.Variant, extension(img.ContentType))
+		url, err := u.storage.Put(ctx, key, img.Data, img.ContentType)
+		if err != nil {
+			u.discard(ctx, stored)
+			return nil, fmt.Errorf("storing %s variant: %w", img.Variant, err)
+		}
+		stored = append(stored, key)
+		photo.Variants[img.Variant] = url
+		if img.Variant == VariantFull {
+			photo.URL, photo.Width, photo.Height = url, img.Width, img.Height
+		}
+	}
+	if photo.URL == "" {
+		u.discard(ctx, stored)
+		return nil, fmt.Errorf("processor returned no %s variant", VariantFull)
+	}
+	return photo, nil
+}
+
+// discard deletes the variants of an upload that failed part way. A delete
+// that fails is logged and leaves the object behind.
+func (u *Uploader) discard(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := u.storage.Delete(ctx, key); err != nil {
+			slog.Warn("deleting orphaned photo variant failed", "key", key, "error", err.Error())
+		}
+	}
+}
+
+func extension(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	default:
+		return ""
+	}
+}
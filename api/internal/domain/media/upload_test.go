@@ -0,0 +1,94 @@
+package media_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/media"
+)
+
+type mockStorage struct {
+	keys    []string
+	err     error
+	failKey string
+}
+
+func (m *mockStorage) Put(_ context.Context, key string, _ []byte, _ string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	if m.failKey != "" && strings.HasSuffix(key, m.failKey) {
+		return "", errors.New("bucket down")
+	}
+	m.keys = append(m.keys, key)
+	return "https://cdn.example.com/" + key, nil
+}
+
+func (m *mockStorage) Delete(_ context.Context, key string) error {
+	m.keys = slices.DeleteFunc(m.keys, func(k string) bool { return k == key })
+	return nil
+}
+
+type mockProcessor struct {
+	err error
+}
+
+func (m *mockProcessor) Process(_ []byte) ([]media.Image, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []media.Image{
+		{Variant: media.VariantThumbnail, ContentType: "image/jpeg", Width: 256, Height: 128},
+		{Variant: media.VariantFull, ContentType: "image/jpeg", Width: 2048, Height: 1024},
+	}, nil
+}
+
+func TestUploader_Upload(t *testing.T) {
+	store := &mockStorage{}
+	u := media.NewUploader(store, &mockProcessor{})
+
+	photo, err := u.Upload(context.Background(), []byte("img"))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"photos/" + photo.ID + "/thumbnail.jpg",
+		"photos/" + photo.ID + "/full.jpg",
+	}, store.keys)
+	assert.Equal(t, "https://cdn.example.com/photos/"+photo.ID+"/full.jpg", photo.URL)
+	assert.Equal(t, 2048, photo.Width)
+	assert.Len(t, photo.Variants, 2)
+}
+
+func TestUploader_ProcessorError(t *testing.T) {
+	store := &mockStorage{}
+	u := media.NewUploader(store, &mockProcessor{err: media.ErrUnsupportedImage})
+
+	_, err := u.Upload(context.Background(), []byte("text"))
+
+	assert.ErrorIs(t, err, media.ErrUnsupportedImage)
+	assert.Empty(t, store.keys)
+}
+
+func TestUploader_StorageError(t *testing.T) {
+	u := media.NewUploader(&mockStorage{err: errors.New("bucket down")}, &mockProcessor{})
+
+	_, err := u.Upload(context.Background(), []byte("img"))
+
+	assert.Error(t, err)
+}
+
+func TestUploader_StorageError_DeletesStoredVariants(t *testing.T) {
+	store := &mockStorage{failKey: "/full.jpg"}
+	u := media.NewUploader(store, &mockProcessor{})
+
+	_, err := u.Upload(context.Background(), []byte("img"))
+
+	assert.Error(t, err)
+	assert.Empty(t, store.keys)
+}
@@ -0,0 +1,79 @@
+package missing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/l3co/traceo-api/internal/domain/shared"
+)
+
+// duplicateCandidateLimit bounds the cases fetched per hash band for one
+// duplicate check.
+const duplicateCandidateLimit = 50
+
+// backfillPageSize is how many cases the backfills load per page.
+const backfillPageSize = 100
+
+// checkDuplicates returns a *shared.DuplicateError listing the cases whose
+// photos resemble m's primary photo, closest first. Lookup failures are
+// logged and let the case through.
+func (s *Service) checkDuplicates(ctx context.Context, m *Missing) error {
+	p := m.Photos.Primary()
+	if p == nil || p.Hash == 0 {
+		return nil
+	}
+
+	found, err := s.repo.FindByPhotoHash(ctx, p.Hash, duplicateCandidateLimit)
+	if err != nil {
+		slog.Warn("duplicate photo check failed", "error", err)
+		return nil
+	}
+
+	candidates := make([]shared.DuplicateCandidate, 0, len(found))
+	for _, c := range found {
+		candidates = append(candidates, shared.DuplicateCandidate{
+			Duplicate: shared.Duplicate{ID: c.ID, Name: c.Name, Slug: c.Slug},
+			Photos:    c.Photos,
+		})
+	}
+	return shared.FindDuplicates(p.Hash, candidates)
+}
+
+// BackfillPhotoHashes hashes the photos of every case that were stored
+// without a hash and rewrites each case's photo hash index, so that cases
+// registered before duplicate detection, or before the index last changed,
+// are found by it. It returns how many cases were rewritten.
+func (s *Service) BackfillPhotoHashes(ctx context.Context) (int, error) {
+	if s.hasher == nil {
+		return 0, nil
+	}
+
+	updated := 0
+	cursor := ""
+	for {
+		page, next, err := s.repo.FindAll(ctx, ListOptions{PageSize: backfillPageSize, After: cursor})
+		if err != nil {
+			return updated, fmt.Errorf("listing missing: %w", err)
+		}
+
+		for _, m := range page {
+			if len(m.Photos) == 0 {
+				continue
+			}
+			shared.HashUnhashedPhotos(ctx, s.hasher, m.Photos)
+			if err := s.repo.UpdatePhotos(ctx, m); err != nil {
+				return updated, fmt.Errorf("updating photos of %s: %w", m.ID, err)
+			}
+			updated++
+		}
+
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	slog.Info("missing photo hash backfill finished", "updated", updated)
+	return updated, nil
+}
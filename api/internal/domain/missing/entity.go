@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/l3co/traceo-api/internal/domain/shared"
 	"github.com/l3co/traceo-api/pkg/slug"
 )
 
@@ -26,17 +27,58 @@ type Missing struct {
 	Eyes                EyeColor
 	Hair                HairColor
 	Skin                SkinColor
-	PhotoURL            string
-	Location            GeoPoint
-	Status              Status
-	EventReport         string
-	TattooDescription   string
-	ScarDescription     string
-	WasChild            bool
-	AgeProgressionURLs  []string
-	Slug                string
-	NameLowercase       string
+	// PhotoURL mirrors the primary entry of Photos.
+	PhotoURL string
+	Photos   shared.Photos
+	// BlurredPhotoURL is a blurred copy of PhotoURL shown to anonymous
+	// visitors when the effective visibility is VisibilityBlurred.
+	BlurredPhotoURL   string
+	Visibility        Visibility
+	Location          GeoPoint
+	Status            Status
+	FoundAt           time.Time
+	FoundLocation     GeoPoint
+	StatusHistory     []StatusChange
+	EventReport       string
+	TattooDescription string
+	ScarDescription   string
+	WasChild          bool
+	AgeProgression    *AgeProgression
+	Slug              string
+	NameLowercase     string
 	Timestamps
+	// AgeProgressionRequestedAt is when an age progression was last asked
+	// for: at registration, on a new primary photo or by the refresh. The
+	// refresh orders cases by it, so a case whose generation keeps failing
+	// waits its turn like the others.
+	AgeProgressionRequestedAt time.Time
+}
+
+// AgeProgression is what the AI pipeline produced from a missing person's
+// photo: a textual description of the face and images projecting it to
+// older ages.
+type AgeProgression struct {
+	Description    string
+	Images         []AgedImage
+	Generator      string
+	SourcePhotoURL string
+	GeneratedAt    time.Time
+}
+
+// AgedImage is one projection. Age is zero for images generated before
+// target ages were recorded.
+type AgedImage struct {
+	Age int
+	URL string
+}
+
+// AgeProgressionDue reports whether m is an open case with a photo whose
+// age progression was last requested before cutoff.
+func (m *Missing) AgeProgressionDue(cutoff time.Time) bool {
+	if !m.Status.IsOpen() || m.PhotoURL == "" {
+		return false
+	}
+	return m.AgeProgressionRequestedAt.Before(cutoff)
 }
 
 func (m *Missing) CalculateWasChild() {
@@ -52,12 +94,16 @@ func (m *Missing) CalculateWasChild() {
 }
 
 func (m *Missing) Age() int {
+	return m.AgeAt(time.Now())
+}
+
+// AgeAt returns the person's age on t, or 0 when the birth date is unknown.
+func (m *Missing) AgeAt(t time.Time) int {
 	if m.BirthDate.IsZero() {
 		return 0
 	}
-	now := time.Now()
-	age := now.Year() - m.BirthDate.Year()
-	if now.YearDay() < m.BirthDate.YearDay() {
+	age := t.Year() - m.BirthDate.Year()
+	if t.YearDay() < m.BirthDate.YearDay() {
 		age--
 	}
 	return age
@@ -101,6 +147,14 @@ func (m *Missing) Validate() error {
 	if !m.BirthDate.IsZero() && m.BirthDate.After(time.Now()) {
 		return fmt.Errorf("%w: birth date cannot be in the future", ErrInvalidMissing)
 	}
+	if m.Visibility != "" && !m.Visibility.IsValid() {
+		return fmt.Errorf("%w: invalid visibility %q", ErrInvalidMissing, m.Visibility)
+	}
+	if m.PhotoURL != "" {
+		if err := shared.ValidatePhotoURL(m.PhotoURL); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidMissing, err)
+		}
+	}
 	return nil
 }
 
@@ -120,9 +174,13 @@ type CreateInput struct {
 	Skin                SkinColor
 	PhotoURL            string
 	Location            GeoPoint
+	Visibility          Visibility
 	EventReport         string
 	TattooDescription   string
 	ScarDescription     string
+	// AllowDuplicate skips the duplicate photo check, once the family has
+	// seen the possible duplicates and confirmed this is a new case.
+	AllowDuplicate bool
 }
 
 type UpdateInput struct {
@@ -138,12 +196,20 @@ type UpdateInput struct {
 	Skin                SkinColor
 	PhotoURL            string
 	Location            GeoPoint
-	Status              Status
+	Visibility          Visibility
 	EventReport         string
 	TattooDescription   string
 	ScarDescription     string
 }
 
+type StatusChangeInput struct {
+	To            Status
+	ActorID       string
+	FoundAt       time.Time
+	FoundLocation GeoPoint
+	Note          string
+}
+
 type ListOptions struct {
 	PageSize int
 	After    string
@@ -5,4 +5,9 @@ import "errors"
 var (
 	ErrMissingNotFound = errors.New("missing person not found")
 	ErrInvalidMissing  = errors.New("invalid missing person data")
+	// ErrInvalidTransition is a status change the lifecycle does not allow
+	// from the case's current status.
+	ErrInvalidTransition = errors.New("invalid status transition")
+	// ErrTransitionForbidden is a legal status change the user may not make.
+	ErrTransitionForbidden = errors.New("status transition not allowed for this user")
 )
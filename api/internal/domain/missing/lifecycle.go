@@ -0,0 +1,189 @@
+package missing
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/l3co/traceo-api/internal/domain/timeline"
+)
+
+// Role is the capacity in which a user changes a case's status.
+type Role string
+
+const (
+	// RoleOwner is the family member who registered the case.
+	RoleOwner Role = "owner"
+	// RoleModerator is a moderator or admin of the platform.
+	RoleModerator Role = "moderator"
+)
+
+// StatusChange is one entry of a case's status history. FoundAt and
+// FoundLocation are set when the person was found; Note carries the reason
+// for closing or archiving and is optional otherwise.
+type StatusChange struct {
+	From          Status
+	To            Status
+	ChangedBy     string
+	Role          Role
+	ChangedAt     time.Time
+	FoundAt       time.Time
+	FoundLocation GeoPoint
+	Note          string
+}
+
+type transition struct {
+	to Status
+	by []Role
+}
+
+var (
+	ownerOrModerator = []Role{RoleOwner, RoleModerator}
+	ownerOnly        = []Role{RoleOwner}
+	moderatorOnly    = []Role{RoleModerator}
+)
+
+// transitions lists, for each status, where a case may go next and who may
+// take it there. Closing is the family's decision; archiving is left to
+// moderators, who alone can bring an archived case back.
+var transitions = map[Status][]transition{
+	StatusDisappeared: {
+		{StatusUnderInvestigation, ownerOrModerator},
+		{StatusFoundAlive, ownerOrModerator},
+		{StatusFoundDeceased, ownerOrModerator},
+		{StatusClosedByFamily, ownerOnly},
+		{StatusArchived, moderatorOnly},
+	},
+	StatusUnderInvestigation: {
+		{StatusDisappeared, ownerOrModerator},
+		{StatusFoundAlive, ownerOrModerator},
+		{StatusFoundDeceased, ownerOrModerator},
+		{StatusClosedByFamily, ownerOnly},
+		{StatusArchived, moderatorOnly},
+	},
+	StatusFoundAlive: {
+		{StatusDisappeared, ownerOrModerator},
+		{StatusArchived, moderatorOnly},
+	},
+	StatusFound: {
+		{StatusDisappeared, ownerOrModerator},
+		{StatusArchived, moderatorOnly},
+	},
+	StatusFoundDeceased: {
+		{StatusArchived, moderatorOnly},
+	},
+	StatusClosedByFamily: {
+		{StatusDisappeared, ownerOrModerator},
+		{StatusArchived, moderatorOnly},
+	},
+	StatusArchived: {
+		{StatusDisappeared, moderatorOnly},
+	},
+}
+
+// NextStatuses returns the statuses role may move a case in st to.
+func NextStatuses(st Status, role Role) []Status {
+	var next []Status
+	for _, t := range transitions[st] {
+		if slices.Contains(t.by, role) {
+			next = append(next, t.to)
+		}
+	}
+	return next
+}
+
+// ChangeStatus moves m to c.To, checking that the transition exists, that
+// c.Role may perform it and that the data it needs is present, and records
+// c in the history. From and ChangedAt are filled in.
+func (m *Missing) ChangeStatus(c StatusChange) error {
+	if !c.To.IsValid() || c.To == StatusFound {
+		return fmt.Errorf("%w: invalid status %q", ErrInvalidMissing, c.To)
+	}
+
+	var allowed *transition
+	for _, t := range transitions[m.Status] {
+		if t.to == c.To {
+			allowed = &t
+			break
+		}
+	}
+	if allowed == nil {
+		return fmt.Errorf("%w: cannot go from %s to %s", ErrInvalidTransition, m.Status, c.To)
+	}
+	if !slices.Contains(allowed.by, c.Role) {
+		return fmt.Errorf("%w: only %s may move a case to %s", ErrTransitionForbidden, rolesLabel(allowed.by), c.To)
+	}
+
+	c.Note = strings.TrimSpace(c.Note)
+	if err := m.checkStatusData(c); err != nil {
+		return err
+	}
+
+	c.From = m.Status
+	if c.ChangedAt.IsZero() {
+		c.ChangedAt = time.Now()
+	}
+	if !c.To.IsFound() {
+		c.FoundAt, c.FoundLocation = time.Time{}, GeoPoint{}
+	}
+
+	m.Status = c.To
+	m.FoundAt, m.FoundLocation = c.FoundAt, c.FoundLocation
+	m.StatusHistory = append(m.StatusHistory, c)
+	m.UpdatedAt = c.ChangedAt
+	return nil
+}
+
+func (m *Missing) checkStatusData(c StatusChange) error {
+	switch {
+	case c.To.IsFound():
+		if c.FoundAt.IsZero() {
+			return fmt.Errorf("%w: found_at is required", ErrInvalidMissing)
+		}
+		if c.FoundAt.After(time.Now()) {
+			return fmt.Errorf("%w: found_at cannot be in the future", ErrInvalidMissing)
+		}
+		if !m.DateOfDisappearance.IsZero() && c.FoundAt.Before(m.DateOfDisappearance) {
+			return fmt.Errorf("%w: found_at cannot be before the disappearance", ErrInvalidMissing)
+		}
+		if c.FoundLocation.Address == "" && c.FoundLocation.Lat == 0 && c.FoundLocation.Lng == 0 {
+			return fmt.Errorf("%w: found location is required", ErrInvalidMissing)
+		}
+	case c.To == StatusClosedByFamily || c.To == StatusArchived:
+		if c.Note == "" {
+			return fmt.Errorf("%w: a note explaining why is required", ErrInvalidMissing)
+		}
+	}
+	return nil
+}
+
+func rolesLabel(roles []Role) string {
+	names := make([]string, len(roles))
+	for i, r := range roles {
+		names[i] = string(r)
+	}
+	return strings.Join(names, " or ")
+}
+
+// StatusChangedEvent is the timeline entry for c. It is shared with the
+// matching service, which marks cases found on its own.
+func StatusChangedEvent(missingID string, c StatusChange) *timeline.Event {
+	details := map[string]string{"from": string(c.From), "to": string(c.To), "role": string(c.Role)}
+	if c.Note != "" {
+		details["note"] = c.Note
+	}
+	if !c.FoundAt.IsZero() {
+		details["found_at"] = c.FoundAt.Format(time.DateOnly)
+		if c.FoundLocation.Address != "" {
+			details["found_address"] = c.FoundLocation.Address
+		}
+	}
+	return &timeline.Event{
+		MissingID:  missingID,
+		Type:       timeline.TypeStatusChanged,
+		ActorID:    c.ChangedBy,
+		OccurredAt: c.ChangedAt,
+		Details:    details,
+	}
+}
@@ -0,0 +1,123 @@
+package missing
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/l3co/traceo-api/internal/domain/event"
+	"github.com/l3co/traceo-api/internal/domain/shared"
+	"github.com/l3co/traceo-api/internal/domain/timeline"
+)
+
+// AddPhoto adds p to the case owned by userID. The age at the photo is
+// derived from TakenAt when it is not given.
+func (s *Service) AddPhoto(ctx context.Context, id, userID string, p shared.Photo) (*shared.Photo, error) {
+	m, err := s.findOwned(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := shared.CheckPhotoSource(s.sources, p.URL); err != nil {
+		return nil, err
+	}
+
+	p = shared.HashPhoto(ctx, s.hasher, s.newPhoto(m, p))
+	previous := m.PhotoURL
+	if err := m.Photos.Add(p); err != nil {
+		return nil, err
+	}
+
+	if err := s.savePhotos(ctx, m, previous, true); err != nil {
+		return nil, err
+	}
+	return m.Photos.Find(p.ID), nil
+}
+
+func (s *Service) UpdatePhoto(ctx context.Context, id, userID, photoID string, u shared.PhotoUpdate) (*shared.Photo, error) {
+	m, err := s.findOwned(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := m.PhotoURL
+	if _, err := m.Photos.Update(photoID, u); err != nil {
+		return nil, err
+	}
+
+	if err := s.savePhotos(ctx, m, previous, false); err != nil {
+		return nil, err
+	}
+	return m.Photos.Find(photoID), nil
+}
+
+func (s *Service) RemovePhoto(ctx context.Context, id, userID, photoID string) error {
+	m, err := s.findOwned(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	previous := m.PhotoURL
+	if err := m.Photos.Remove(photoID); err != nil {
+		return err
+	}
+
+	return s.savePhotos(ctx, m, previous, false)
+}
+
+func (s *Service) findOwned(ctx context.Context, id, userID string) (*Missing, error) {
+	m, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if m.UserID != userID {
+		return nil, fmt.Errorf("%w: not the owner", ErrInvalidMissing)
+	}
+	return m, nil
+}
+
+func (s *Service) newPhoto(m *Missing, p shared.Photo) shared.Photo {
+	p.ID = uuid.NewString()
+	p.AddedAt = time.Now()
+	if p.AgeAtPhoto == 0 && !p.TakenAt.IsZero() {
+		p.AgeAtPhoto = max(m.AgeAt(p.TakenAt), 0)
+	}
+	return p
+}
+
+// savePhotos persists m's photos. A new primary photo is announced like a
+// photo change through Update; any other added photo only asks for matching
+// to run again.
+func (s *Service) savePhotos(ctx context.Context, m *Missing, previousURL string, added bool) error {
+	m.PhotoURL = m.Photos.PrimaryURL()
+	m.UpdatedAt = time.Now()
+	if m.PhotoURL != previousURL {
+		s.refreshBlurredPhoto(ctx, m)
+	}
+
+	if err := s.repo.UpdatePhotos(ctx, m); err != nil {
+		return fmt.Errorf("updating photos: %w", err)
+	}
+
+	switch {
+	case m.PhotoURL != previousURL:
+		s.publish(ctx, event.MissingPhotoChanged, m)
+		s.recordPhotoEvent(ctx, m, timeline.TypePhotoChanged)
+	case added:
+		s.publish(ctx, event.MissingPhotoAdded, m)
+		s.recordPhotoEvent(ctx, m, timeline.TypePhotoAdded)
+	}
+	return nil
+}
+
+func (s *Service) recordPhotoEvent(ctx context.Context, m *Missing, t timeline.Type) {
+	timeline.RecordOrLog(ctx, s.timeline, &timeline.Event{
+		MissingID:  m.ID,
+		Type:       t,
+		ActorID:    m.UserID,
+		OccurredAt: m.UpdatedAt,
+		Details:    map[string]string{"photos": strconv.Itoa(len(m.Photos))},
+	})
+}
@@ -1,6 +1,12 @@
 package missing
 
-import "context"
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/l3co/traceo-api/internal/domain/shared"
+)
 
 type GenderStat struct {
 	Gender string
@@ -20,13 +26,40 @@ type LocationPoint struct {
 	Status Status
 }
 
-type CandidateFilter struct {
-	Gender Gender
-	Skin   SkinColor
-	MinAge int
-	MaxAge int
+type StatusStat struct {
 	Status Status
-	Limit  int
+	Count  int64
+}
+
+// CandidateFilter selects missing persons that may match a homeless record.
+// An empty Gender or Skin matches any value. Limit caps the number of matching
+// records returned, counted after every other criterion is applied.
+type CandidateFilter struct {
+	Gender   Gender
+	Skin     SkinColor
+	MinAge   int
+	MaxAge   int
+	Statuses []Status
+	Limit    int
+}
+
+// Matches reports whether m satisfies every criterion of the filter except
+// Limit. A zero MinAge or MaxAge leaves that side of the age window open.
+func (f CandidateFilter) Matches(m *Missing) bool {
+	if !slices.Contains(f.Statuses, m.Status) {
+		return false
+	}
+	if (f.Gender != "" && m.Gender != f.Gender) || (f.Skin != "" && m.Skin != f.Skin) {
+		return false
+	}
+	age := m.Age()
+	if f.MinAge > 0 && age < f.MinAge {
+		return false
+	}
+	if f.MaxAge > 0 && age > f.MaxAge {
+		return false
+	}
+	return true
 }
 
 type Repository interface {
@@ -41,7 +74,22 @@ type Repository interface {
 	CountByGender(ctx context.Context) ([]GenderStat, error)
 	CountByYear(ctx context.Context) ([]YearStat, error)
 	CountChildren(ctx context.Context) (int64, error)
+	CountByStatus(ctx context.Context) ([]StatusStat, error)
 	FindLocations(ctx context.Context, limit int) ([]LocationPoint, error)
 	FindCandidates(ctx context.Context, filter CandidateFilter) ([]*Missing, error)
-	UpdateAgeProgressionURLs(ctx context.Context, id string, urls []string) error
+	UpdateAgeProgression(ctx context.Context, id string, p *AgeProgression) error
+	// UpdatePhotos writes m's Photos, PhotoURL and BlurredPhotoURL, leaving
+	// other fields untouched.
+	UpdatePhotos(ctx context.Context, m *Missing) error
+	// FindByPhotoHash returns cases with a photo that may be within
+	// DuplicateMaxDistance of h, up to limit per hash band. Callers check the
+	// actual distance.
+	FindByPhotoHash(ctx context.Context, h shared.PerceptualHash, limit int) ([]*Missing, error)
+	// FindAgeProgressionDue returns up to limit open cases whose age
+	// progression was last requested before cutoff, oldest request first.
+	// Some may have no photo; callers check AgeProgressionDue.
+	FindAgeProgressionDue(ctx context.Context, cutoff time.Time, limit int) ([]*Missing, error)
+	// MarkAgeProgressionRequested sets the case's AgeProgressionRequestedAt
+	// to at, leaving other fields untouched.
+	MarkAgeProgressionRequested(ctx context.Context, id string, at time.Time) error
 }
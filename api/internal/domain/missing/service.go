@@ -3,21 +3,74 @@ package missing
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/microcosm-cc/bluemonday"
+
+	"github.com/l3co/traceo-api/internal/domain/event"
+	"github.com/l3co/traceo-api/internal/domain/shared"
+	"github.com/l3co/traceo-api/internal/domain/timeline"
 )
 
 var sanitizer = bluemonday.StrictPolicy()
 
 type Service struct {
-	repo Repository
+	repo       Repository
+	publisher  event.Publisher
+	blurrer    PhotoBlurrer
+	hasher     shared.PerceptualHasher
+	moderators map[string]bool
+	timeline   timeline.Recorder
+	sources    shared.PhotoSources
+}
+
+type Option func(*Service)
+
+// WithPhotoBlurrer makes the service keep a blurred copy of the primary
+// photo for cases shown blurred to anonymous visitors. Without one those
+// cases show no photo to them at all.
+func WithPhotoBlurrer(b PhotoBlurrer) Option {
+	return func(s *Service) { s.blurrer = b }
+}
+
+// WithPerceptualHasher makes the service hash every new photo and refuse
+// cases whose photo resembles an existing case's, unless the duplicate
+// check is overridden.
+func WithPerceptualHasher(h shared.PerceptualHasher) Option {
+	return func(s *Service) { s.hasher = h }
+}
+
+// WithPhotoSources refuses photo URLs that do not point at sources, checked
+// before anything is downloaded from them.
+func WithPhotoSources(sources shared.PhotoSources) Option {
+	return func(s *Service) { s.sources = sources }
+}
+
+// WithModerators lets the given user IDs change the status of any case, as
+// RoleModerator.
+func WithModerators(ids []string) Option {
+	return func(s *Service) {
+		for _, id := range ids {
+			s.moderators[id] = true
+		}
+	}
+}
+
+// WithTimeline records the case's creation, photo changes and status
+// changes in its timeline.
+func WithTimeline(r timeline.Recorder) Option {
+	return func(s *Service) { s.timeline = r }
 }
 
-func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+func NewService(repo Repository, publisher event.Publisher, opts ...Option) *Service {
+	s := &Service{repo: repo, publisher: publisher, moderators: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *Service) Create(ctx context.Context, input *CreateInput) (*Missing, error) {
@@ -34,9 +87,9 @@ func (s *Service) Create(ctx context.Context, input *CreateInput) (*Missing, err
 		Eyes:                input.Eyes,
 		Hair:                input.Hair,
 		Skin:                input.Skin,
-		PhotoURL:            input.PhotoURL,
 		Location:            input.Location,
 		Status:              StatusDisappeared,
+		Visibility:          input.Visibility,
 		EventReport:         sanitizer.Sanitize(input.EventReport),
 		TattooDescription:   sanitizer.Sanitize(input.TattooDescription),
 		ScarDescription:     sanitizer.Sanitize(input.ScarDescription),
@@ -46,17 +99,49 @@ func (s *Service) Create(ctx context.Context, input *CreateInput) (*Missing, err
 		},
 	}
 
+	if input.PhotoURL != "" {
+		if err := shared.CheckPhotoSource(s.sources, input.PhotoURL); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidMissing, err)
+		}
+		if err := m.Photos.Add(shared.HashPhoto(ctx, s.hasher, s.newPhoto(m, shared.Photo{URL: input.PhotoURL}))); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidMissing, err)
+		}
+		m.PhotoURL = m.Photos.PrimaryURL()
+	}
+
+	m.StatusHistory = []StatusChange{{
+		To:        StatusDisappeared,
+		ChangedBy: m.UserID,
+		Role:      RoleOwner,
+		ChangedAt: m.CreatedAt,
+	}}
+
+	m.AgeProgressionRequestedAt = m.CreatedAt
 	m.CalculateWasChild()
 	m.GenerateSlug()
 
 	if err := m.Validate(); err != nil {
 		return nil, err
 	}
+	if !input.AllowDuplicate {
+		if err := s.checkDuplicates(ctx, m); err != nil {
+			return nil, err
+		}
+	}
+	s.refreshBlurredPhoto(ctx, m)
 
 	if err := s.repo.Create(ctx, m); err != nil {
 		return nil, fmt.Errorf("creating missing person: %w", err)
 	}
 
+	s.publish(ctx, event.MissingCreated, m)
+	timeline.RecordOrLog(ctx, s.timeline, &timeline.Event{
+		MissingID:  m.ID,
+		Type:       timeline.TypeCaseCreated,
+		ActorID:    m.UserID,
+		OccurredAt: m.CreatedAt,
+	})
+
 	return m, nil
 }
 
@@ -73,6 +158,11 @@ func (s *Service) FindByID(ctx context.Context, id string) (*Missing, error) {
 	return m, nil
 }
 
+// CanManage reports whether userID owns m or is a moderator.
+func (s *Service) CanManage(m *Missing, userID string) bool {
+	return userID != "" && (m.UserID == userID || s.moderators[userID])
+}
+
 func (s *Service) Update(ctx context.Context, id, userID string, input *UpdateInput) (*Missing, error) {
 	m, err := s.repo.FindByID(ctx, id)
 	if err != nil {
@@ -82,6 +172,7 @@ func (s *Service) Update(ctx context.Context, id, userID string, input *UpdateIn
 	if m.UserID != userID {
 		return nil, fmt.Errorf("%w: not the owner", ErrInvalidMissing)
 	}
+	previousVisibility := m.EffectiveVisibility()
 
 	m.Name = sanitizer.Sanitize(input.Name)
 	m.Nickname = sanitizer.Sanitize(input.Nickname)
@@ -99,12 +190,20 @@ func (s *Service) Update(ctx context.Context, id, userID string, input *UpdateIn
 	m.ScarDescription = sanitizer.Sanitize(input.ScarDescription)
 	m.UpdatedAt = time.Now()
 
-	if input.PhotoURL != "" {
-		m.PhotoURL = input.PhotoURL
+	photoChanged := input.PhotoURL != "" && input.PhotoURL != m.PhotoURL
+	if photoChanged {
+		if err := shared.CheckPhotoSource(s.sources, input.PhotoURL); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidMissing, err)
+		}
+		if err := m.Photos.UsePrimaryURL(shared.HashPhoto(ctx, s.hasher, s.newPhoto(m, shared.Photo{URL: input.PhotoURL}))); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidMissing, err)
+		}
+		m.PhotoURL = m.Photos.PrimaryURL()
+		m.AgeProgressionRequestedAt = m.UpdatedAt
 	}
 
-	if input.Status != "" && input.Status.IsValid() {
-		m.Status = input.Status
+	if input.Visibility != "" {
+		m.Visibility = input.Visibility
 	}
 
 	m.CalculateWasChild()
@@ -113,17 +212,165 @@ func (s *Service) Update(ctx context.Context, id, userID string, input *UpdateIn
 	if err := m.Validate(); err != nil {
 		return nil, err
 	}
+	if photoChanged || m.EffectiveVisibility() != previousVisibility || m.BlurredPhotoURL == "" {
+		s.refreshBlurredPhoto(ctx, m)
+	}
 
 	if err := s.repo.Update(ctx, m); err != nil {
 		return nil, fmt.Errorf("updating missing person: %w", err)
 	}
 
+	if photoChanged {
+		s.publish(ctx, event.MissingPhotoChanged, m)
+		timeline.RecordOrLog(ctx, s.timeline, &timeline.Event{
+			MissingID: m.ID,
+			Type:      timeline.TypePhotoChanged,
+			ActorID:   userID,
+		})
+	}
+
 	return m, nil
 }
 
-func (s *Service) UpdateEntity(ctx context.Context, m *Missing) error {
-	m.UpdatedAt = time.Now()
-	return s.repo.Update(ctx, m)
+func (s *Service) publish(ctx context.Context, t event.Type, m *Missing) {
+	if s.publisher == nil {
+		return
+	}
+
+	err := s.publisher.Publish(ctx, event.Event{
+		Type:        t,
+		AggregateID: m.ID,
+		PhotoURL:    m.PhotoURL,
+		BirthDate:   m.BirthDate,
+		OccurredAt:  time.Now(),
+	})
+	if err != nil {
+		slog.Error("failed to publish missing event",
+			"type", string(t),
+			"missing_id", m.ID,
+			"error", err,
+		)
+	}
+}
+
+// RefreshAgeProgressions asks for a new age progression of up to limit open
+// cases whose last request is older than maxAge, and returns how many were
+// requested. Processing happens asynchronously through the publisher. Each
+// case is marked as requested first, whether or not it has a photo or the
+// generation later succeeds, so the next run moves on to other cases.
+func (s *Service) RefreshAgeProgressions(ctx context.Context, maxAge time.Duration, limit int) (int, error) {
+	if s.publisher == nil {
+		return 0, nil
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-maxAge)
+	found, err := s.repo.FindAgeProgressionDue(ctx, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("finding cases due for age progression: %w", err)
+	}
+
+	requested := 0
+	for _, m := range found {
+		due := m.AgeProgressionDue(cutoff)
+		if err := s.repo.MarkAgeProgressionRequested(ctx, m.ID, now); err != nil {
+			slog.Warn("failed to mark age progression requested", "missing_id", m.ID, "error", err)
+			continue
+		}
+		if due {
+			s.publish(ctx, event.MissingAgeProgressionDue, m)
+			requested++
+		}
+	}
+	if requested > 0 {
+		slog.Info("age progression refresh requested", "cases", requested)
+	}
+	return requested, nil
+}
+
+// BackfillAgeProgressionRequests sets AgeProgressionRequestedAt on cases
+// stored before it existed, to when their progression was generated, so the
+// refresh can find them. Cases without a progression are left at the zero
+// time and come first. It returns how many cases were updated.
+func (s *Service) BackfillAgeProgressionRequests(ctx context.Context) (int, error) {
+	updated := 0
+	cursor := ""
+	for {
+		page, next, err := s.repo.FindAll(ctx, ListOptions{PageSize: backfillPageSize, After: cursor})
+		if err != nil {
+			return updated, fmt.Errorf("listing missing: %w", err)
+		}
+
+		for _, m := range page {
+			if !m.AgeProgressionRequestedAt.IsZero() {
+				continue
+			}
+			var at time.Time
+			if m.AgeProgression != nil {
+				at = m.AgeProgression.GeneratedAt
+			}
+			if err := s.repo.MarkAgeProgressionRequested(ctx, m.ID, at); err != nil {
+				return updated, fmt.Errorf("updating %s: %w", m.ID, err)
+			}
+			updated++
+		}
+
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	slog.Info("age progression request backfill finished", "updated", updated)
+	return updated, nil
+}
+
+// ChangeStatus moves case id through the lifecycle on behalf of
+// input.ActorID, who acts as the owner when the case is theirs and as a
+// moderator otherwise. Anyone else gets ErrTransitionForbidden.
+func (s *Service) ChangeStatus(ctx context.Context, id string, input StatusChangeInput) (*Missing, error) {
+	m, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var role Role
+	switch {
+	case m.UserID == input.ActorID:
+		role = RoleOwner
+	case s.moderators[input.ActorID]:
+		role = RoleModerator
+	default:
+		return nil, fmt.Errorf("%w: not the owner or a moderator", ErrTransitionForbidden)
+	}
+
+	location := input.FoundLocation
+	location.Address = sanitizer.Sanitize(location.Address)
+	if err := m.ChangeStatus(StatusChange{
+		To:            input.To,
+		ChangedBy:     input.ActorID,
+		Role:          role,
+		FoundAt:       input.FoundAt,
+		FoundLocation: location,
+		Note:          sanitizer.Sanitize(input.Note),
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, m); err != nil {
+		return nil, fmt.Errorf("changing missing person status: %w", err)
+	}
+
+	change := m.StatusHistory[len(m.StatusHistory)-1]
+	timeline.RecordOrLog(ctx, s.timeline, StatusChangedEvent(m.ID, change))
+
+	slog.Info("missing status changed",
+		slog.String("missing_id", m.ID),
+		slog.String("status", string(m.Status)),
+		slog.String("role", string(role)),
+	)
+
+	return m, nil
 }
 
 func (s *Service) Delete(ctx context.Context, id, userID string) error {
@@ -140,6 +387,7 @@ func (s *Service) Delete(ctx context.Context, id, userID string) error {
 		return fmt.Errorf("deleting missing person: %w", err)
 	}
 
+	s.publish(ctx, event.MissingDeleted, m)
 	return nil
 }
 
@@ -178,6 +426,7 @@ type DashboardStats struct {
 	ByGender   []GenderStat `json:"by_gender"`
 	ChildCount int64        `json:"child_count"`
 	ByYear     []YearStat   `json:"by_year"`
+	ByStatus   []StatusStat `json:"by_status"`
 }
 
 func (s *Service) GetStats(ctx context.Context) (*DashboardStats, error) {
@@ -187,10 +436,11 @@ func (s *Service) GetStats(ctx context.Context) (*DashboardStats, error) {
 		byGender   []GenderStat
 		childCount int64
 		byYear     []YearStat
-		errCh      = make(chan error, 4)
+		byStatus   []StatusStat
+		errCh      = make(chan error, 5)
 	)
 
-	wg.Add(4)
+	wg.Add(5)
 
 	go func() {
 		defer wg.Done()
@@ -232,6 +482,16 @@ func (s *Service) GetStats(ctx context.Context) (*DashboardStats, error) {
 		byYear = ys
 	}()
 
+	go func() {
+		defer wg.Done()
+		ss, err := s.repo.CountByStatus(ctx)
+		if err != nil {
+			errCh <- fmt.Errorf("counting by status: %w", err)
+			return
+		}
+		byStatus = ss
+	}()
+
 	wg.Wait()
 	close(errCh)
 
@@ -246,6 +506,7 @@ func (s *Service) GetStats(ctx context.Context) (*DashboardStats, error) {
 		ByGender:   byGender,
 		ChildCount: childCount,
 		ByYear:     byYear,
+		ByStatus:   byStatus,
 	}, nil
 }
 
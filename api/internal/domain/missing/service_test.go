@@ -2,6 +2,8 @@ package missing_test
 
 import (
 	"context"
+	"errors"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -9,7 +11,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/l3co/traceo-api/internal/domain/event"
 	"github.com/l3co/traceo-api/internal/domain/missing"
+	"github.com/l3co/traceo-api/internal/domain/shared"
+	"github.com/l3co/traceo-api/internal/domain/timeline"
+	"github.com/l3co/traceo-api/pkg/safefetch"
 )
 
 // --- Mock Repository ---
@@ -130,6 +136,18 @@ func (m *mockRepo) CountChildren(_ context.Context) (int64, error) {
 	return count, nil
 }
 
+func (m *mockRepo) CountByStatus(_ context.Context) ([]missing.StatusStat, error) {
+	counts := map[missing.Status]int64{}
+	for _, item := range m.items {
+		counts[item.Status]++
+	}
+	var result []missing.StatusStat
+	for st, c := range counts {
+		result = append(result, missing.StatusStat{Status: st, Count: c})
+	}
+	return result, nil
+}
+
 func (m *mockRepo) FindLocations(_ context.Context, limit int) ([]missing.LocationPoint, error) {
 	var result []missing.LocationPoint
 	for _, item := range m.items {
@@ -157,16 +175,118 @@ func (m *mockRepo) FindCandidates(_ context.Context, _ missing.CandidateFilter)
 	return result, nil
 }
 
-func (m *mockRepo) UpdateAgeProgressionURLs(_ context.Context, id string, urls []string) error {
+func (m *mockRepo) FindAgeProgressionDue(_ context.Context, cutoff time.Time, limit int) ([]*missing.Missing, error) {
+	var result []*missing.Missing
+	for _, item := range m.items {
+		if item.Status.IsOpen() && item.AgeProgressionRequestedAt.Before(cutoff) {
+			result = append(result, item)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].AgeProgressionRequestedAt.Before(result[j].AgeProgressionRequestedAt)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *mockRepo) MarkAgeProgressionRequested(_ context.Context, id string, at time.Time) error {
+	item, ok := m.items[id]
+	if !ok {
+		return missing.ErrMissingNotFound
+	}
+	item.AgeProgressionRequestedAt = at
+	return nil
+}
+
+func (m *mockRepo) UpdateAgeProgression(_ context.Context, id string, p *missing.AgeProgression) error {
 	item, ok := m.items[id]
 	if !ok {
 		return missing.ErrMissingNotFound
 	}
-	_ = item
-	_ = urls
+	item.AgeProgression = p
+	return nil
+}
+
+func (m *mockRepo) UpdatePhotos(_ context.Context, mm *missing.Missing) error {
+	item, ok := m.items[mm.ID]
+	if !ok {
+		return missing.ErrMissingNotFound
+	}
+	item.Photos = mm.Photos
+	item.PhotoURL = mm.PhotoURL
+	item.BlurredPhotoURL = mm.BlurredPhotoURL
+	return nil
+}
+
+func (m *mockRepo) FindByPhotoHash(_ context.Context, h shared.PerceptualHash, limit int) ([]*missing.Missing, error) {
+	var result []*missing.Missing
+	for _, item := range m.items {
+		if item.Photos.HashDistance(h) >= 0 && len(result) < limit {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// --- Mock Publisher ---
+
+type mockPublisher struct {
+	events []event.Event
+}
+
+func (m *mockPublisher) Publish(_ context.Context, e event.Event) error {
+	m.events = append(m.events, e)
 	return nil
 }
 
+// --- Mock Timeline ---
+
+type mockTimeline struct {
+	events []*timeline.Event
+}
+
+func (m *mockTimeline) Record(_ context.Context, e *timeline.Event) error {
+	m.events = append(m.events, e)
+	return nil
+}
+
+func (m *mockTimeline) types() []timeline.Type {
+	var types []timeline.Type
+	for _, e := range m.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+// --- Mock Blurrer ---
+
+type mockBlurrer struct {
+	calls []string
+	err   error
+}
+
+func (m *mockBlurrer) BlurPhoto(_ context.Context, photoURL string) (string, error) {
+	m.calls = append(m.calls, photoURL)
+	if m.err != nil {
+		return "", m.err
+	}
+	return photoURL + "?blurred", nil
+}
+
+// --- Mock PerceptualHasher ---
+
+type mockHasher map[string]shared.PerceptualHash
+
+func (m mockHasher) PerceptualHash(_ context.Context, url string) (shared.PerceptualHash, error) {
+	h, ok := m[url]
+	if !ok {
+		return 0, errors.New("not found")
+	}
+	return h, nil
+}
+
 // --- Helpers ---
 
 func validInput() *missing.CreateInput {
@@ -193,7 +313,7 @@ func validInput() *missing.CreateInput {
 
 func TestCreate_Success(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	result, err := svc.Create(context.Background(), validInput())
 
@@ -211,7 +331,7 @@ func TestCreate_Success(t *testing.T) {
 
 func TestCreate_WasChild(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	input := validInput()
 	input.BirthDate = time.Date(2010, 6, 1, 0, 0, 0, 0, time.UTC)
@@ -225,7 +345,7 @@ func TestCreate_WasChild(t *testing.T) {
 
 func TestCreate_SanitizesInput(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	input := validInput()
 	input.Name = "<script>alert('xss')</script>João"
@@ -238,7 +358,7 @@ func TestCreate_SanitizesInput(t *testing.T) {
 
 func TestCreate_MissingName(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	input := validInput()
 	input.Name = ""
@@ -250,7 +370,7 @@ func TestCreate_MissingName(t *testing.T) {
 
 func TestCreate_MissingUserID(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	input := validInput()
 	input.UserID = ""
@@ -262,7 +382,7 @@ func TestCreate_MissingUserID(t *testing.T) {
 
 func TestCreate_InvalidGender(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	input := validInput()
 	input.Gender = "banana"
@@ -274,7 +394,7 @@ func TestCreate_InvalidGender(t *testing.T) {
 
 func TestCreate_FutureDateOfDisappearance(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	input := validInput()
 	input.DateOfDisappearance = time.Now().Add(24 * time.Hour)
@@ -288,7 +408,7 @@ func TestCreate_FutureDateOfDisappearance(t *testing.T) {
 
 func TestFindByID_Success(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	created, _ := svc.Create(context.Background(), validInput())
 
@@ -300,7 +420,7 @@ func TestFindByID_Success(t *testing.T) {
 
 func TestFindByID_NotFound(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	_, err := svc.FindByID(context.Background(), "nonexistent")
 
@@ -309,7 +429,7 @@ func TestFindByID_NotFound(t *testing.T) {
 
 func TestFindByID_EmptyID(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	_, err := svc.FindByID(context.Background(), "")
 
@@ -320,7 +440,7 @@ func TestFindByID_EmptyID(t *testing.T) {
 
 func TestUpdate_Success(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	created, _ := svc.Create(context.Background(), validInput())
 
@@ -336,9 +456,71 @@ func TestUpdate_Success(t *testing.T) {
 	assert.Equal(t, "João Silva Atualizado", updated.Name)
 }
 
+func TestCreate_PublishesEvent(t *testing.T) {
+	publisher := &mockPublisher{}
+	svc := missing.NewService(newMockRepo(), publisher)
+
+	input := validInput()
+	input.PhotoURL = "https://example.com/joao.jpg"
+
+	created, err := svc.Create(context.Background(), input)
+
+	require.NoError(t, err)
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, event.MissingCreated, publisher.events[0].Type)
+	assert.Equal(t, created.ID, publisher.events[0].AggregateID)
+	assert.Equal(t, "https://example.com/joao.jpg", publisher.events[0].PhotoURL)
+}
+
+func TestPhotoURLs_MustPointAtSources(t *testing.T) {
+	svc := missing.NewService(newMockRepo(), nil,
+		missing.WithPhotoSources(safefetch.URLList{"firebasestorage.googleapis.com/v0/b/traceo/"}))
+	ctx := context.Background()
+
+	input := validInput()
+	input.PhotoURL = "https://firebasestorage.googleapis.com/v0/b/attacker/o/a.jpg"
+	_, err := svc.Create(ctx, input)
+	assert.ErrorIs(t, err, shared.ErrInvalidPhoto)
+
+	input.PhotoURL = "https://firebasestorage.googleapis.com/v0/b/traceo/o/a.jpg"
+	created, err := svc.Create(ctx, input)
+	require.NoError(t, err)
+
+	_, err = svc.AddPhoto(ctx, created.ID, "user-123", shared.Photo{URL: "https://storage.googleapis.com/attacker/b.jpg"})
+	assert.ErrorIs(t, err, shared.ErrInvalidPhoto)
+	assert.Len(t, created.Photos, 1)
+}
+
+func TestUpdate_PhotoChanged_PublishesEvent(t *testing.T) {
+	publisher := &mockPublisher{}
+	svc := missing.NewService(newMockRepo(), publisher)
+
+	input := validInput()
+	input.PhotoURL = "https://example.com/old.jpg"
+	created, _ := svc.Create(context.Background(), input)
+
+	update := &missing.UpdateInput{
+		Name:     "João Silva",
+		Gender:   missing.GenderMale,
+		Eyes:     missing.EyeBrown,
+		Hair:     missing.HairBlack,
+		Skin:     missing.SkinBrown,
+		PhotoURL: "https://example.com/new.jpg",
+	}
+	_, err := svc.Update(context.Background(), created.ID, "user-123", update)
+	require.NoError(t, err)
+
+	_, err = svc.Update(context.Background(), created.ID, "user-123", update)
+	require.NoError(t, err)
+
+	require.Len(t, publisher.events, 2)
+	assert.Equal(t, event.MissingPhotoChanged, publisher.events[1].Type)
+	assert.Equal(t, "https://example.com/new.jpg", publisher.events[1].PhotoURL)
+}
+
 func TestUpdate_NotOwner(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	created, _ := svc.Create(context.Background(), validInput())
 
@@ -355,7 +537,7 @@ func TestUpdate_NotOwner(t *testing.T) {
 
 func TestUpdate_NotFound(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	_, err := svc.Update(context.Background(), "nonexistent", "user-123", &missing.UpdateInput{
 		Name:   "Test",
@@ -372,7 +554,7 @@ func TestUpdate_NotFound(t *testing.T) {
 
 func TestDelete_Success(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	created, _ := svc.Create(context.Background(), validInput())
 
@@ -382,9 +564,21 @@ func TestDelete_Success(t *testing.T) {
 	assert.Empty(t, repo.items)
 }
 
+func TestDelete_PublishesEvent(t *testing.T) {
+	publisher := &mockPublisher{}
+	svc := missing.NewService(newMockRepo(), publisher)
+
+	created, _ := svc.Create(context.Background(), validInput())
+	require.NoError(t, svc.Delete(context.Background(), created.ID, "user-123"))
+
+	last := publisher.events[len(publisher.events)-1]
+	assert.Equal(t, event.MissingDeleted, last.Type)
+	assert.Equal(t, created.ID, last.AggregateID)
+}
+
 func TestDelete_NotOwner(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	created, _ := svc.Create(context.Background(), validInput())
 
@@ -397,7 +591,7 @@ func TestDelete_NotOwner(t *testing.T) {
 
 func TestFindByUserID_Success(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	svc.Create(context.Background(), validInput())
 
@@ -413,7 +607,7 @@ func TestFindByUserID_Success(t *testing.T) {
 
 func TestFindByUserID_EmptyID(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	_, err := svc.FindByUserID(context.Background(), "")
 
@@ -424,7 +618,7 @@ func TestFindByUserID_EmptyID(t *testing.T) {
 
 func TestList_DefaultPageSize(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	svc.Create(context.Background(), validInput())
 
@@ -438,7 +632,7 @@ func TestList_DefaultPageSize(t *testing.T) {
 
 func TestCount_Success(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	svc.Create(context.Background(), validInput())
 	svc.Create(context.Background(), validInput())
@@ -490,7 +684,7 @@ func TestStatus_IsValid(t *testing.T) {
 
 func TestSearch_Success(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	svc.Create(context.Background(), validInput())
 
@@ -502,7 +696,7 @@ func TestSearch_Success(t *testing.T) {
 
 func TestSearch_EmptyQuery(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	_, err := svc.Search(context.Background(), "", 20)
 
@@ -511,7 +705,7 @@ func TestSearch_EmptyQuery(t *testing.T) {
 
 func TestSearch_NoResults(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	svc.Create(context.Background(), validInput())
 
@@ -525,7 +719,7 @@ func TestSearch_NoResults(t *testing.T) {
 
 func TestGetStats_Success(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	svc.Create(context.Background(), validInput())
 
@@ -540,11 +734,12 @@ func TestGetStats_Success(t *testing.T) {
 	assert.Equal(t, int64(2), stats.Total)
 	assert.NotEmpty(t, stats.ByGender)
 	assert.NotEmpty(t, stats.ByYear)
+	assert.Equal(t, []missing.StatusStat{{Status: missing.StatusDisappeared, Count: 2}}, stats.ByStatus)
 }
 
 func TestGetStats_Empty(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	stats, err := svc.GetStats(context.Background())
 
@@ -557,7 +752,7 @@ func TestGetStats_Empty(t *testing.T) {
 
 func TestFindLocations_Success(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	svc.Create(context.Background(), validInput())
 
@@ -570,7 +765,7 @@ func TestFindLocations_Success(t *testing.T) {
 
 func TestFindLocations_DefaultLimit(t *testing.T) {
 	repo := newMockRepo()
-	svc := missing.NewService(repo)
+	svc := missing.NewService(repo, nil)
 
 	locs, err := svc.FindLocations(context.Background(), 0)
 
@@ -603,3 +798,565 @@ func TestEntity_HasScar(t *testing.T) {
 	m2 := &missing.Missing{}
 	assert.False(t, m2.HasScar())
 }
+
+func TestCandidateFilter_Matches(t *testing.T) {
+	filter := missing.CandidateFilter{
+		Gender:   missing.GenderMale,
+		Skin:     missing.SkinBrown,
+		Statuses: missing.OpenStatuses(),
+		MinAge:   20,
+		MaxAge:   40,
+	}
+	m := &missing.Missing{
+		Gender:    missing.GenderMale,
+		Skin:      missing.SkinBrown,
+		Status:    missing.StatusDisappeared,
+		BirthDate: time.Now().AddDate(-30, 0, 0),
+	}
+	assert.True(t, filter.Matches(m))
+
+	investigated := *m
+	investigated.Status = missing.StatusUnderInvestigation
+	assert.True(t, filter.Matches(&investigated))
+
+	found := *m
+	found.Status = missing.StatusFound
+	assert.False(t, filter.Matches(&found))
+
+	old := *m
+	old.BirthDate = time.Now().AddDate(-60, 0, 0)
+	assert.False(t, filter.Matches(&old))
+}
+
+// --- Tests: RefreshAgeProgressions ---
+
+func TestRefreshAgeProgressions_PublishesStaleOpenCases(t *testing.T) {
+	repo := newMockRepo()
+	old := time.Now().AddDate(-3, 0, 0)
+	repo.items["never"] = &missing.Missing{ID: "never", Status: missing.StatusDisappeared, PhotoURL: "https://example.com/a.jpg"}
+	repo.items["stale"] = &missing.Missing{ID: "stale", Status: missing.StatusDisappeared, PhotoURL: "https://example.com/b.jpg",
+		AgeProgressionRequestedAt: old}
+	repo.items["fresh"] = &missing.Missing{ID: "fresh", Status: missing.StatusDisappeared, PhotoURL: "https://example.com/c.jpg",
+		AgeProgressionRequestedAt: time.Now()}
+	repo.items["found"] = &missing.Missing{ID: "found", Status: missing.StatusFound, PhotoURL: "https://example.com/d.jpg"}
+	repo.items["nophoto"] = &missing.Missing{ID: "nophoto", Status: missing.StatusDisappeared}
+	publisher := &mockPublisher{}
+	svc := missing.NewService(repo, publisher)
+
+	n, err := svc.RefreshAgeProgressions(context.Background(), 2*365*24*time.Hour, 10)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, n)
+	ids := []string{publisher.events[0].AggregateID, publisher.events[1].AggregateID}
+	assert.ElementsMatch(t, []string{"never", "stale"}, ids)
+	assert.Equal(t, event.MissingAgeProgressionDue, publisher.events[0].Type)
+	assert.WithinDuration(t, time.Now(), repo.items["nophoto"].AgeProgressionRequestedAt, time.Second,
+		"cases without a photo are marked too, so they do not hold up the next run")
+}
+
+func TestRefreshAgeProgressions_FailingCasesDoNotStarveOthers(t *testing.T) {
+	repo := newMockRepo()
+	for i, id := range []string{"a", "b", "c"} {
+		repo.items[id] = &missing.Missing{ID: id, Status: missing.StatusDisappeared, PhotoURL: "https://example.com/" + id + ".jpg",
+			AgeProgressionRequestedAt: time.Now().AddDate(-3, 0, i)}
+	}
+	publisher := &mockPublisher{}
+	svc := missing.NewService(repo, publisher)
+
+	// Nothing ever writes a progression, as when no generator is configured.
+	for range 3 {
+		_, err := svc.RefreshAgeProgressions(context.Background(), 2*365*24*time.Hour, 1)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, publisher.events, 3)
+	assert.Equal(t, "a", publisher.events[0].AggregateID)
+	assert.Equal(t, "b", publisher.events[1].AggregateID)
+	assert.Equal(t, "c", publisher.events[2].AggregateID)
+}
+
+func TestBackfillAgeProgressionRequests(t *testing.T) {
+	repo := newMockRepo()
+	generated := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	repo.items["progressed"] = &missing.Missing{ID: "progressed", AgeProgression: &missing.AgeProgression{GeneratedAt: generated}}
+	repo.items["never"] = &missing.Missing{ID: "never"}
+	svc := missing.NewService(repo, nil)
+
+	n, err := svc.BackfillAgeProgressionRequests(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, generated, repo.items["progressed"].AgeProgressionRequestedAt)
+	assert.True(t, repo.items["never"].AgeProgressionRequestedAt.IsZero())
+}
+
+// --- Tests: Photos ---
+
+func createWithPhoto(t *testing.T, svc *missing.Service) *missing.Missing {
+	t.Helper()
+	input := validInput()
+	input.PhotoURL = "https://example.com/joao.jpg"
+	created, err := svc.Create(context.Background(), input)
+	require.NoError(t, err)
+	return created
+}
+
+func TestCreate_AddsPrimaryPhoto(t *testing.T) {
+	svc := missing.NewService(newMockRepo(), nil)
+
+	created := createWithPhoto(t, svc)
+
+	require.Len(t, created.Photos, 1)
+	assert.True(t, created.Photos[0].Primary)
+	assert.Equal(t, created.PhotoURL, created.Photos[0].URL)
+}
+
+func TestAddPhoto_DerivesAgeAndPublishesPhotoAdded(t *testing.T) {
+	publisher := &mockPublisher{}
+	svc := missing.NewService(newMockRepo(), publisher)
+	created := createWithPhoto(t, svc)
+
+	p, err := svc.AddPhoto(context.Background(), created.ID, "user-123", shared.Photo{
+		URL:     "https://example.com/joao-2000.jpg",
+		TakenAt: time.Date(2000, 6, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, p.ID)
+	assert.Equal(t, 10, p.AgeAtPhoto)
+	assert.False(t, p.Primary)
+	require.Len(t, publisher.events, 2)
+	assert.Equal(t, event.MissingPhotoAdded, publisher.events[1].Type)
+	assert.Equal(t, "https://example.com/joao.jpg", publisher.events[1].PhotoURL)
+}
+
+func TestAddPhoto_Primary_PublishesPhotoChanged(t *testing.T) {
+	publisher := &mockPublisher{}
+	repo := newMockRepo()
+	svc := missing.NewService(repo, publisher)
+	created := createWithPhoto(t, svc)
+
+	_, err := svc.AddPhoto(context.Background(), created.ID, "user-123", shared.Photo{
+		URL:     "https://example.com/recent.jpg",
+		Primary: true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/recent.jpg", repo.items[created.ID].PhotoURL)
+	require.Len(t, publisher.events, 2)
+	assert.Equal(t, event.MissingPhotoChanged, publisher.events[1].Type)
+}
+
+func TestAddPhoto_NotOwner(t *testing.T) {
+	svc := missing.NewService(newMockRepo(), nil)
+	created := createWithPhoto(t, svc)
+
+	_, err := svc.AddPhoto(context.Background(), created.ID, "other-user", shared.Photo{
+		URL: "https://example.com/other.jpg",
+	})
+
+	assert.ErrorIs(t, err, missing.ErrInvalidMissing)
+}
+
+func TestAddPhoto_InvalidURL(t *testing.T) {
+	svc := missing.NewService(newMockRepo(), nil)
+	created := createWithPhoto(t, svc)
+
+	_, err := svc.AddPhoto(context.Background(), created.ID, "user-123", shared.Photo{URL: "ftp://example.com/a.jpg"})
+
+	assert.ErrorIs(t, err, shared.ErrInvalidPhoto)
+}
+
+func TestRemovePhoto_PrimaryPromotesNext(t *testing.T) {
+	publisher := &mockPublisher{}
+	repo := newMockRepo()
+	svc := missing.NewService(repo, publisher)
+	created := createWithPhoto(t, svc)
+	_, err := svc.AddPhoto(context.Background(), created.ID, "user-123", shared.Photo{URL: "https://example.com/second.jpg"})
+	require.NoError(t, err)
+
+	err = svc.RemovePhoto(context.Background(), created.ID, "user-123", created.Photos[0].ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/second.jpg", repo.items[created.ID].PhotoURL)
+	assert.Equal(t, event.MissingPhotoChanged, publisher.events[len(publisher.events)-1].Type)
+}
+
+func TestRemovePhoto_NotFound(t *testing.T) {
+	svc := missing.NewService(newMockRepo(), nil)
+	created := createWithPhoto(t, svc)
+
+	err := svc.RemovePhoto(context.Background(), created.ID, "user-123", "nope")
+
+	assert.ErrorIs(t, err, shared.ErrPhotoNotFound)
+}
+
+// --- Tests: Visibility ---
+
+func childInput() *missing.CreateInput {
+	input := validInput()
+	input.BirthDate = time.Date(2012, 3, 1, 0, 0, 0, 0, time.UTC)
+	input.PhotoURL = "https://example.com/child.jpg"
+	return input
+}
+
+func TestEffectiveVisibility_DefaultsToBlurredForChildren(t *testing.T) {
+	assert.Equal(t, missing.VisibilityPublic, (&missing.Missing{}).EffectiveVisibility())
+	assert.Equal(t, missing.VisibilityBlurred, (&missing.Missing{WasChild: true}).EffectiveVisibility())
+	assert.Equal(t, missing.VisibilityPublic, (&missing.Missing{WasChild: true, Visibility: missing.VisibilityPublic}).EffectiveVisibility())
+}
+
+func TestPhotoAccessFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		m      missing.Missing
+		viewer string
+		want   missing.PhotoAccess
+	}{
+		{"public", missing.Missing{Visibility: missing.VisibilityPublic}, "", missing.PhotoAccessFull},
+		{"registered anonymous", missing.Missing{Visibility: missing.VisibilityRegistered}, "", missing.PhotoAccessNone},
+		{"registered signed in", missing.Missing{Visibility: missing.VisibilityRegistered}, "user-1", missing.PhotoAccessFull},
+		{"blurred", missing.Missing{Visibility: missing.VisibilityBlurred, BlurredPhotoURL: "b.jpg"}, "", missing.PhotoAccessBlurred},
+		{"blurred without copy", missing.Missing{Visibility: missing.VisibilityBlurred}, "", missing.PhotoAccessNone},
+		{"child default", missing.Missing{WasChild: true, BlurredPhotoURL: "b.jpg"}, "", missing.PhotoAccessBlurred},
+		{"child signed in", missing.Missing{WasChild: true}, "user-1", missing.PhotoAccessFull},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.m.PhotoAccessFor(tt.viewer))
+		})
+	}
+}
+
+func TestCreate_InvalidVisibility(t *testing.T) {
+	svc := missing.NewService(newMockRepo(), nil)
+
+	input := validInput()
+	input.Visibility = "secret"
+	_, err := svc.Create(context.Background(), input)
+
+	assert.ErrorIs(t, err, missing.ErrInvalidMissing)
+}
+
+func TestCreate_Child_StoresBlurredCopy(t *testing.T) {
+	blurrer := &mockBlurrer{}
+	repo := newMockRepo()
+	svc := missing.NewService(repo, nil, missing.WithPhotoBlurrer(blurrer))
+
+	created, err := svc.Create(context.Background(), childInput())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/child.jpg"}, blurrer.calls)
+	assert.Equal(t, "https://example.com/child.jpg?blurred", repo.items[created.ID].BlurredPhotoURL)
+}
+
+func TestCreate_Public_SkipsBlur(t *testing.T) {
+	blurrer := &mockBlurrer{}
+	svc := missing.NewService(newMockRepo(), nil, missing.WithPhotoBlurrer(blurrer))
+
+	input := childInput()
+	input.Visibility = missing.VisibilityPublic
+	created, err := svc.Create(context.Background(), input)
+
+	require.NoError(t, err)
+	assert.Empty(t, blurrer.calls)
+	assert.Empty(t, created.BlurredPhotoURL)
+}
+
+func TestCreate_BlurFailure_HidesPhoto(t *testing.T) {
+	svc := missing.NewService(newMockRepo(), nil, missing.WithPhotoBlurrer(&mockBlurrer{err: errors.New("boom")}))
+
+	created, err := svc.Create(context.Background(), childInput())
+
+	require.NoError(t, err)
+	assert.Empty(t, created.BlurredPhotoURL)
+	assert.Equal(t, missing.PhotoAccessNone, created.PhotoAccessFor(""))
+}
+
+func TestAddPhoto_NewPrimary_RefreshesBlurredCopy(t *testing.T) {
+	blurrer := &mockBlurrer{}
+	repo := newMockRepo()
+	svc := missing.NewService(repo, nil, missing.WithPhotoBlurrer(blurrer))
+	created, err := svc.Create(context.Background(), childInput())
+	require.NoError(t, err)
+
+	_, err = svc.AddPhoto(context.Background(), created.ID, "user-123", shared.Photo{URL: "https://example.com/other.jpg"})
+	require.NoError(t, err)
+	assert.Len(t, blurrer.calls, 1, "a non-primary photo does not change the blurred copy")
+
+	_, err = svc.AddPhoto(context.Background(), created.ID, "user-123", shared.Photo{URL: "https://example.com/new.jpg", Primary: true})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/new.jpg?blurred", repo.items[created.ID].BlurredPhotoURL)
+}
+
+func TestBackfillBlurredPhotos(t *testing.T) {
+	repo := newMockRepo()
+	child, err := missing.NewService(repo, nil).Create(context.Background(), childInput())
+	require.NoError(t, err)
+	adult, err := missing.NewService(repo, nil).Create(context.Background(), validInput())
+	require.NoError(t, err)
+	require.Empty(t, repo.items[child.ID].BlurredPhotoURL)
+
+	blurrer := &mockBlurrer{}
+	n, err := missing.NewService(repo, nil, missing.WithPhotoBlurrer(blurrer)).BackfillBlurredPhotos(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"https://example.com/child.jpg"}, blurrer.calls)
+	assert.Equal(t, "https://example.com/child.jpg?blurred", repo.items[child.ID].BlurredPhotoURL)
+	assert.Empty(t, repo.items[adult.ID].BlurredPhotoURL)
+}
+
+// --- Tests: Duplicates ---
+
+func TestCreate_PossibleDuplicate(t *testing.T) {
+	hasher := mockHasher{
+		"https://example.com/joao.jpg":      0x123456789ABCDEF0,
+		"https://example.com/joao-copy.jpg": 0x123456789ABCDEF1,
+	}
+	repo := newMockRepo()
+	svc := missing.NewService(repo, nil, missing.WithPerceptualHasher(hasher))
+	first := createWithPhoto(t, svc)
+
+	input := validInput()
+	input.PhotoURL = "https://example.com/joao-copy.jpg"
+	_, err := svc.Create(context.Background(), input)
+
+	var dupErr *shared.DuplicateError
+	require.ErrorAs(t, err, &dupErr)
+	assert.Equal(t, []shared.Duplicate{{ID: first.ID, Name: first.Name, Slug: first.Slug, Distance: 1}}, dupErr.Duplicates)
+	assert.Len(t, repo.items, 1)
+
+	input.AllowDuplicate = true
+	_, err = svc.Create(context.Background(), input)
+
+	require.NoError(t, err)
+	assert.Len(t, repo.items, 2)
+}
+
+func TestAddPhoto_StoresHash(t *testing.T) {
+	hasher := mockHasher{"https://example.com/joao.jpg": 1, "https://example.com/second.jpg": 2}
+	repo := newMockRepo()
+	svc := missing.NewService(repo, nil, missing.WithPerceptualHasher(hasher))
+	created := createWithPhoto(t, svc)
+
+	p, err := svc.AddPhoto(context.Background(), created.ID, "user-123", shared.Photo{URL: "https://example.com/second.jpg"})
+
+	require.NoError(t, err)
+	assert.Equal(t, shared.PerceptualHash(2), p.Hash)
+	assert.Equal(t, shared.PerceptualHash(2), repo.items[created.ID].Photos.Find(p.ID).Hash)
+}
+
+func TestBackfillPhotoHashes(t *testing.T) {
+	repo := newMockRepo()
+	created := createWithPhoto(t, missing.NewService(repo, nil))
+	require.Zero(t, repo.items[created.ID].Photos[0].Hash)
+
+	svc := missing.NewService(repo, nil, missing.WithPerceptualHasher(mockHasher{"https://example.com/joao.jpg": 7}))
+	n, err := svc.BackfillPhotoHashes(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, shared.PerceptualHash(7), repo.items[created.ID].Photos[0].Hash)
+
+	input := validInput()
+	input.PhotoURL = "https://example.com/joao.jpg"
+	_, err = svc.Create(context.Background(), input)
+	assert.ErrorIs(t, err, shared.ErrPossibleDuplicate, "backfilled cases take part in duplicate checks")
+}
+
+// --- Tests: Lifecycle ---
+
+func foundChange(role missing.Role) missing.StatusChange {
+	return missing.StatusChange{
+		To:            missing.StatusFoundAlive,
+		ChangedBy:     "user-123",
+		Role:          role,
+		FoundAt:       time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC),
+		FoundLocation: missing.GeoPoint{Address: "Rodoviária do Tietê"},
+	}
+}
+
+func TestCreate_RecordsInitialStatus(t *testing.T) {
+	svc := missing.NewService(newMockRepo(), nil)
+
+	m, err := svc.Create(context.Background(), validInput())
+
+	require.NoError(t, err)
+	require.Len(t, m.StatusHistory, 1)
+	assert.Equal(t, missing.StatusDisappeared, m.StatusHistory[0].To)
+	assert.Equal(t, missing.RoleOwner, m.StatusHistory[0].Role)
+}
+
+func TestChangeStatus_Transitions(t *testing.T) {
+	tests := []struct {
+		from, to missing.Status
+		role     missing.Role
+		err      error
+	}{
+		{missing.StatusDisappeared, missing.StatusUnderInvestigation, missing.RoleOwner, nil},
+		{missing.StatusUnderInvestigation, missing.StatusDisappeared, missing.RoleModerator, nil},
+		{missing.StatusFound, missing.StatusDisappeared, missing.RoleOwner, nil},
+		{missing.StatusArchived, missing.StatusDisappeared, missing.RoleModerator, nil},
+		{missing.StatusArchived, missing.StatusDisappeared, missing.RoleOwner, missing.ErrTransitionForbidden},
+		{missing.StatusDisappeared, missing.StatusArchived, missing.RoleOwner, missing.ErrTransitionForbidden},
+		{missing.StatusDisappeared, missing.StatusClosedByFamily, missing.RoleModerator, missing.ErrTransitionForbidden},
+		{missing.StatusFoundDeceased, missing.StatusDisappeared, missing.RoleModerator, missing.ErrInvalidTransition},
+		{missing.StatusDisappeared, missing.StatusDisappeared, missing.RoleOwner, missing.ErrInvalidTransition},
+		{missing.StatusDisappeared, missing.StatusFound, missing.RoleOwner, missing.ErrInvalidMissing},
+	}
+	for _, tt := range tests {
+		m := &missing.Missing{Status: tt.from}
+
+		err := m.ChangeStatus(missing.StatusChange{To: tt.to, Role: tt.role})
+
+		if tt.err != nil {
+			assert.ErrorIs(t, err, tt.err, "%s -> %s by %s", tt.from, tt.to, tt.role)
+			assert.Equal(t, tt.from, m.Status)
+			assert.Empty(t, m.StatusHistory)
+			continue
+		}
+		require.NoError(t, err, "%s -> %s by %s", tt.from, tt.to, tt.role)
+		assert.Equal(t, tt.to, m.Status)
+	}
+}
+
+func TestChangeStatus_FoundRequiresDateAndPlace(t *testing.T) {
+	disappeared := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	for name, edit := range map[string]func(*missing.StatusChange){
+		"no date":        func(c *missing.StatusChange) { c.FoundAt = time.Time{} },
+		"future date":    func(c *missing.StatusChange) { c.FoundAt = time.Now().Add(48 * time.Hour) },
+		"before missing": func(c *missing.StatusChange) { c.FoundAt = disappeared.AddDate(0, 0, -1) },
+		"no place":       func(c *missing.StatusChange) { c.FoundLocation = missing.GeoPoint{} },
+	} {
+		m := &missing.Missing{Status: missing.StatusDisappeared, DateOfDisappearance: disappeared}
+		c := foundChange(missing.RoleOwner)
+		edit(&c)
+
+		assert.ErrorIs(t, m.ChangeStatus(c), missing.ErrInvalidMissing, name)
+		assert.Equal(t, missing.StatusDisappeared, m.Status, name)
+	}
+}
+
+func TestChangeStatus_ClosingRequiresNote(t *testing.T) {
+	m := &missing.Missing{Status: missing.StatusDisappeared}
+
+	err := m.ChangeStatus(missing.StatusChange{To: missing.StatusClosedByFamily, Role: missing.RoleOwner, Note: "  "})
+	assert.ErrorIs(t, err, missing.ErrInvalidMissing)
+
+	err = m.ChangeStatus(missing.StatusChange{To: missing.StatusClosedByFamily, Role: missing.RoleOwner, Note: "Voltou para casa"})
+	require.NoError(t, err)
+	assert.Equal(t, "Voltou para casa", m.StatusHistory[0].Note)
+}
+
+func TestChangeStatus_ReopeningClearsFoundData(t *testing.T) {
+	m := &missing.Missing{Status: missing.StatusDisappeared}
+	require.NoError(t, m.ChangeStatus(foundChange(missing.RoleOwner)))
+	assert.Equal(t, "Rodoviária do Tietê", m.FoundLocation.Address)
+
+	require.NoError(t, m.ChangeStatus(missing.StatusChange{To: missing.StatusDisappeared, Role: missing.RoleOwner}))
+
+	assert.True(t, m.FoundAt.IsZero())
+	assert.Empty(t, m.FoundLocation.Address)
+	require.Len(t, m.StatusHistory, 2)
+	assert.Equal(t, missing.StatusFoundAlive, m.StatusHistory[1].From)
+	assert.False(t, m.StatusHistory[0].FoundAt.IsZero(), "history keeps when the person was found")
+}
+
+func TestNextStatuses(t *testing.T) {
+	assert.Equal(t, []missing.Status{missing.StatusDisappeared}, missing.NextStatuses(missing.StatusArchived, missing.RoleModerator))
+	assert.Empty(t, missing.NextStatuses(missing.StatusArchived, missing.RoleOwner))
+	assert.Empty(t, missing.NextStatuses(missing.StatusFoundDeceased, missing.RoleOwner))
+}
+
+func TestCanManage(t *testing.T) {
+	svc := missing.NewService(newMockRepo(), nil, missing.WithModerators([]string{"mod-1"}))
+	m := &missing.Missing{UserID: "user-123"}
+
+	assert.True(t, svc.CanManage(m, "user-123"))
+	assert.True(t, svc.CanManage(m, "mod-1"))
+	assert.False(t, svc.CanManage(m, "someone-else"))
+	assert.False(t, svc.CanManage(&missing.Missing{}, ""))
+}
+
+func TestServiceChangeStatus_Roles(t *testing.T) {
+	repo := newMockRepo()
+	svc := missing.NewService(repo, nil, missing.WithModerators([]string{"mod"}))
+	m, err := svc.Create(context.Background(), validInput())
+	require.NoError(t, err)
+
+	_, err = svc.ChangeStatus(context.Background(), m.ID, missing.StatusChangeInput{To: missing.StatusUnderInvestigation, ActorID: "stranger"})
+	assert.ErrorIs(t, err, missing.ErrTransitionForbidden)
+
+	_, err = svc.ChangeStatus(context.Background(), m.ID, missing.StatusChangeInput{To: missing.StatusArchived, ActorID: "user-123", Note: "Duplicado"})
+	assert.ErrorIs(t, err, missing.ErrTransitionForbidden)
+
+	updated, err := svc.ChangeStatus(context.Background(), m.ID, missing.StatusChangeInput{To: missing.StatusArchived, ActorID: "mod", Note: "Duplicado"})
+	require.NoError(t, err)
+	assert.Equal(t, missing.StatusArchived, repo.items[m.ID].Status)
+	last := updated.StatusHistory[len(updated.StatusHistory)-1]
+	assert.Equal(t, "mod", last.ChangedBy)
+	assert.Equal(t, missing.RoleModerator, last.Role)
+	assert.Equal(t, missing.StatusDisappeared, last.From)
+}
+
+func TestServiceChangeStatus_NotFound(t *testing.T) {
+	svc := missing.NewService(newMockRepo(), nil)
+
+	_, err := svc.ChangeStatus(context.Background(), "nope", missing.StatusChangeInput{To: missing.StatusUnderInvestigation, ActorID: "user-123"})
+
+	assert.ErrorIs(t, err, missing.ErrMissingNotFound)
+}
+
+// --- Tests: Timeline ---
+
+func TestTimeline_RecordsCaseHistory(t *testing.T) {
+	repo := newMockRepo()
+	tl := &mockTimeline{}
+	svc := missing.NewService(repo, nil, missing.WithTimeline(tl))
+	ctx := context.Background()
+
+	m, err := svc.Create(ctx, validInput())
+	require.NoError(t, err)
+	_, err = svc.AddPhoto(ctx, m.ID, "user-123", shared.Photo{URL: "https://example.com/a.jpg"})
+	require.NoError(t, err)
+	_, err = svc.AddPhoto(ctx, m.ID, "user-123", shared.Photo{URL: "https://example.com/b.jpg"})
+	require.NoError(t, err)
+	_, err = svc.ChangeStatus(ctx, m.ID, missing.StatusChangeInput{
+		To:            missing.StatusFoundAlive,
+		ActorID:       "user-123",
+		FoundAt:       time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC),
+		FoundLocation: missing.GeoPoint{Address: "Rodoviária do Tietê"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []timeline.Type{
+		timeline.TypeCaseCreated,
+		timeline.TypePhotoChanged,
+		timeline.TypePhotoAdded,
+		timeline.TypeStatusChanged,
+	}, tl.types())
+	for _, e := range tl.events {
+		assert.Equal(t, m.ID, e.MissingID)
+		assert.Equal(t, "user-123", e.ActorID)
+	}
+	status := tl.events[3].Details
+	assert.Equal(t, "disappeared", status["from"])
+	assert.Equal(t, "found_alive", status["to"])
+	assert.Equal(t, "2025-03-02", status["found_at"])
+	assert.Equal(t, "Rodoviária do Tietê", status["found_address"])
+}
+
+func TestTimeline_FailedChangeRecordsNothing(t *testing.T) {
+	repo := newMockRepo()
+	tl := &mockTimeline{}
+	svc := missing.NewService(repo, nil, missing.WithTimeline(tl))
+	m, err := svc.Create(context.Background(), validInput())
+	require.NoError(t, err)
+
+	_, err = svc.ChangeStatus(context.Background(), m.ID, missing.StatusChangeInput{To: missing.StatusArchived, ActorID: "user-123", Note: "x"})
+
+	assert.ErrorIs(t, err, missing.ErrTransitionForbidden)
+	assert.Equal(t, []timeline.Type{timeline.TypeCaseCreated}, tl.types())
+}
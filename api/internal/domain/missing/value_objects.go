@@ -36,25 +36,61 @@ const (
 
 // --- Status (Missing-specific) ---
 
+// Status is where a case stands. The legal changes between statuses are in
+// lifecycle.go.
 type Status string
 
 const (
-	StatusDisappeared Status = "disappeared"
-	StatusFound       Status = "found"
+	StatusDisappeared        Status = "disappeared"
+	StatusUnderInvestigation Status = "under_investigation"
+	StatusFoundAlive         Status = "found_alive"
+	StatusFoundDeceased      Status = "found_deceased"
+	StatusClosedByFamily     Status = "closed_by_family"
+	StatusArchived           Status = "archived"
+	// StatusFound predates the distinction between found alive and
+	// deceased. Existing cases keep it; new changes never set it.
+	StatusFound Status = "found"
 )
 
 func (st Status) IsValid() bool {
 	switch st {
-	case StatusDisappeared, StatusFound:
+	case StatusDisappeared, StatusUnderInvestigation, StatusFoundAlive, StatusFoundDeceased,
+		StatusClosedByFamily, StatusArchived, StatusFound:
 		return true
 	}
 	return false
 }
 
+// IsOpen reports whether the person is still being searched for, which is
+// when matching and age progression run.
+func (st Status) IsOpen() bool {
+	return st == StatusDisappeared || st == StatusUnderInvestigation
+}
+
+// IsFound reports whether the person was located, alive or not.
+func (st Status) IsFound() bool {
+	return st == StatusFoundAlive || st == StatusFoundDeceased || st == StatusFound
+}
+
+// OpenStatuses lists the statuses for which IsOpen holds.
+func OpenStatuses() []Status {
+	return []Status{StatusDisappeared, StatusUnderInvestigation}
+}
+
 func (st Status) Label() string {
 	switch st {
 	case StatusDisappeared:
 		return "Desaparecido"
+	case StatusUnderInvestigation:
+		return "Em investigação policial"
+	case StatusFoundAlive:
+		return "Encontrado com vida"
+	case StatusFoundDeceased:
+		return "Encontrado sem vida"
+	case StatusClosedByFamily:
+		return "Encerrado pela família"
+	case StatusArchived:
+		return "Arquivado"
 	case StatusFound:
 		return "Encontrado"
 	}
@@ -0,0 +1,135 @@
+package missing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Visibility decides who sees a case's photos. The rest of the case is
+// public either way.
+type Visibility string
+
+const (
+	VisibilityPublic Visibility = "public"
+	// VisibilityRegistered shows photos to signed-in users only.
+	VisibilityRegistered Visibility = "registered"
+	// VisibilityBlurred shows anonymous visitors a blurred copy.
+	VisibilityBlurred Visibility = "blurred"
+)
+
+func (v Visibility) IsValid() bool {
+	switch v {
+	case VisibilityPublic, VisibilityRegistered, VisibilityBlurred:
+		return true
+	}
+	return false
+}
+
+// PhotoAccess is how much of a case's photos a viewer may see.
+type PhotoAccess int
+
+const (
+	PhotoAccessFull PhotoAccess = iota
+	PhotoAccessBlurred
+	PhotoAccessNone
+)
+
+// PhotoBlurrer stores a blurred copy of a photo and returns its URL.
+type PhotoBlurrer interface {
+	BlurPhoto(ctx context.Context, photoURL string) (string, error)
+}
+
+// EffectiveVisibility is the visibility set on the case or, when none was
+// chosen, the default: blurred for people who disappeared as children,
+// public otherwise.
+func (m *Missing) EffectiveVisibility() Visibility {
+	if m.Visibility != "" {
+		return m.Visibility
+	}
+	if m.WasChild {
+		return VisibilityBlurred
+	}
+	return VisibilityPublic
+}
+
+// PhotoAccessFor returns what viewerID, empty for anonymous visitors, may
+// see. Signed-in users see every photo. A blurred case without a blurred
+// copy shows nothing rather than the original.
+func (m *Missing) PhotoAccessFor(viewerID string) PhotoAccess {
+	if viewerID != "" {
+		return PhotoAccessFull
+	}
+	switch m.EffectiveVisibility() {
+	case VisibilityRegistered:
+		return PhotoAccessNone
+	case VisibilityBlurred:
+		if m.BlurredPhotoURL == "" {
+			return PhotoAccessNone
+		}
+		return PhotoAccessBlurred
+	default:
+		return PhotoAccessFull
+	}
+}
+
+// refreshBlurredPhoto regenerates m's blurred copy when anonymous visitors
+// are to see one. A failure is logged and leaves the copy empty, which hides
+// the photo instead of showing it unblurred.
+func (s *Service) refreshBlurredPhoto(ctx context.Context, m *Missing) {
+	m.BlurredPhotoURL = ""
+	if s.blurrer == nil || m.PhotoURL == "" || m.EffectiveVisibility() != VisibilityBlurred {
+		return
+	}
+
+	url, err := s.blurrer.BlurPhoto(ctx, m.PhotoURL)
+	if err != nil {
+		slog.Warn("blurring photo failed, hiding it from anonymous visitors",
+			"missing_id", m.ID,
+			"error", err,
+		)
+		return
+	}
+	m.BlurredPhotoURL = url
+}
+
+// BackfillBlurredPhotos generates the missing blurred copies of cases shown
+// blurred to anonymous visitors, such as children's cases registered before
+// blurring existed, whose photos are hidden until then. It returns how many
+// cases got a copy.
+func (s *Service) BackfillBlurredPhotos(ctx context.Context) (int, error) {
+	if s.blurrer == nil {
+		return 0, nil
+	}
+
+	updated := 0
+	cursor := ""
+	for {
+		page, next, err := s.repo.FindAll(ctx, ListOptions{PageSize: backfillPageSize, After: cursor})
+		if err != nil {
+			return updated, fmt.Errorf("listing missing: %w", err)
+		}
+
+		for _, m := range page {
+			if m.BlurredPhotoURL != "" || m.PhotoURL == "" || m.EffectiveVisibility() != VisibilityBlurred {
+				continue
+			}
+			s.refreshBlurredPhoto(ctx, m)
+			if m.BlurredPhotoURL == "" {
+				continue
+			}
+			if err := s.repo.UpdatePhotos(ctx, m); err != nil {
+				return updated, fmt.Errorf("updating photos of %s: %w", m.ID, err)
+			}
+			updated++
+		}
+
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	slog.Info("blurred photo backfill finished", "updated", updated)
+	return updated, nil
+}
@@ -0,0 +1,177 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/bits"
+	"sort"
+	"strconv"
+)
+
+// PerceptualHash is a 64-bit difference hash (dHash) of a photo. Copies of a
+// picture that were re-encoded, resized or lightly edited hash within a few
+// bits of each other. Zero means the photo has not been hashed.
+type PerceptualHash uint64
+
+// DuplicateMaxDistance is the largest Hamming distance at which two photos
+// are reported as possible duplicates. It stays below hashBands so that the
+// band index finds every one of them.
+const DuplicateMaxDistance = hashBands - 1
+
+// hashBands is how many 16-bit slices the hash is indexed by. Two hashes
+// within hashBands-1 bits agree on at least one whole slice, so looking
+// records up by each band finds every possible duplicate. Slices this wide
+// are shared by few unrelated photos, which keeps the lookups small.
+const hashBands = 4
+
+var ErrPossibleDuplicate = errors.New("possible duplicate")
+
+// PerceptualHasher computes the perceptual hash of the photo at url.
+type PerceptualHasher interface {
+	PerceptualHash(ctx context.Context, url string) (PerceptualHash, error)
+}
+
+func (h PerceptualHash) Distance(other PerceptualHash) int {
+	return bits.OnesCount64(uint64(h ^ other))
+}
+
+func (h PerceptualHash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+// ParsePerceptualHash reads the form produced by String. An empty string is
+// the zero hash.
+func ParsePerceptualHash(s string) (PerceptualHash, error) {
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid perceptual hash %q: %w", s, err)
+	}
+	return PerceptualHash(v), nil
+}
+
+// Bands returns the index keys of h, one per 16-bit slice, such as
+// "3:a7f0".
+func (h PerceptualHash) Bands() []string {
+	bands := make([]string, hashBands)
+	for i := range bands {
+		bands[i] = fmt.Sprintf("%d:%04x", i, uint16(h>>(16*i)))
+	}
+	return bands
+}
+
+// HashPhoto sets p.Hash using hasher, if any. Invalid photos are left for
+// Photos.Add to reject; a failed hash is logged and only leaves the photo out
+// of duplicate checks.
+func HashPhoto(ctx context.Context, hasher PerceptualHasher, p Photo) Photo {
+	if hasher == nil || p.Validate() != nil {
+		return p
+	}
+
+	hash, err := hasher.PerceptualHash(ctx, p.URL)
+	if err != nil {
+		slog.Warn("hashing photo failed", "url", p.URL, "error", err)
+		return p
+	}
+	p.Hash = hash
+	return p
+}
+
+// HashUnhashedPhotos hashes the photos of ps that have no hash yet and
+// reports whether any was hashed.
+func HashUnhashedPhotos(ctx context.Context, hasher PerceptualHasher, ps Photos) bool {
+	changed := false
+	for i := range ps {
+		if ps[i].Hash != 0 {
+			continue
+		}
+		ps[i] = HashPhoto(ctx, hasher, ps[i])
+		changed = changed || ps[i].Hash != 0
+	}
+	return changed
+}
+
+// HashBands returns the index keys of every hashed photo, without repeats.
+func (ps Photos) HashBands() []string {
+	seen := make(map[string]bool)
+	var bands []string
+	for _, p := range ps {
+		if p.Hash == 0 {
+			continue
+		}
+		for _, b := range p.Hash.Bands() {
+			if !seen[b] {
+				seen[b] = true
+				bands = append(bands, b)
+			}
+		}
+	}
+	return bands
+}
+
+// HashDistance returns the smallest distance between h and a hashed photo,
+// or -1 when no photo is hashed.
+func (ps Photos) HashDistance(h PerceptualHash) int {
+	best := -1
+	for _, p := range ps {
+		if p.Hash == 0 {
+			continue
+		}
+		if d := p.Hash.Distance(h); best < 0 || d < best {
+			best = d
+		}
+	}
+	return best
+}
+
+// Duplicate is an existing record whose photos resemble a new one.
+type Duplicate struct {
+	ID       string
+	Name     string
+	Slug     string
+	Distance int
+}
+
+// DuplicateCandidate is an existing record returned by a photo hash lookup.
+// Its Distance is filled in by FindDuplicates.
+type DuplicateCandidate struct {
+	Duplicate
+	Photos Photos
+}
+
+// FindDuplicates returns a *DuplicateError listing the candidates with a
+// photo within DuplicateMaxDistance of h, closest first, or nil when there
+// are none.
+func FindDuplicates(h PerceptualHash, candidates []DuplicateCandidate) error {
+	var duplicates []Duplicate
+	for _, c := range candidates {
+		if d := c.Photos.HashDistance(h); d >= 0 && d <= DuplicateMaxDistance {
+			c.Distance = d
+			duplicates = append(duplicates, c.Duplicate)
+		}
+	}
+	if len(duplicates) == 0 {
+		return nil
+	}
+	sort.SliceStable(duplicates, func(i, j int) bool { return duplicates[i].Distance < duplicates[j].Distance })
+	return &DuplicateError{Duplicates: duplicates}
+}
+
+// DuplicateError lists the records that a new registration may duplicate.
+// It matches ErrPossibleDuplicate; callers may retry with the duplicate
+// check overridden.
+type DuplicateError struct {
+	Duplicates []Duplicate
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("%s: photo resembles %d existing record(s)", ErrPossibleDuplicate, len(e.Duplicates))
+}
+
+func (e *DuplicateError) Unwrap() error {
+	return ErrPossibleDuplicate
+}
@@ -0,0 +1,78 @@
+package shared_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/shared"
+)
+
+func TestPerceptualHash_Distance(t *testing.T) {
+	assert.Equal(t, 0, shared.PerceptualHash(0xABCD).Distance(0xABCD))
+	assert.Equal(t, 1, shared.PerceptualHash(0b1000).Distance(0b1001))
+	assert.Equal(t, 64, shared.PerceptualHash(0).Distance(^shared.PerceptualHash(0)))
+}
+
+func TestPerceptualHash_StringRoundTrip(t *testing.T) {
+	h := shared.PerceptualHash(0x00ff00ff12345678)
+
+	parsed, err := shared.ParsePerceptualHash(h.String())
+
+	require.NoError(t, err)
+	assert.Equal(t, "00ff00ff12345678", h.String())
+	assert.Equal(t, h, parsed)
+
+	_, err = shared.ParsePerceptualHash("not hex")
+	assert.Error(t, err)
+}
+
+func TestPerceptualHash_Bands_FindNearHashes(t *testing.T) {
+	h := shared.PerceptualHash(0x8badf00ddeadbeef)
+	// Flipping up to 3 bits, one per band, still leaves one band intact.
+	near := h ^ 0x0000000100010001
+
+	assert.Equal(t, 3, h.Distance(near))
+	assert.Equal(t, shared.DuplicateMaxDistance, h.Distance(near))
+	assert.Len(t, h.Bands(), 4)
+	assert.Contains(t, h.Bands(), "3:8bad")
+	assert.Contains(t, near.Bands(), "3:8bad")
+}
+
+func TestPhotos_HashDistance(t *testing.T) {
+	ps := shared.Photos{{ID: "a"}, {ID: "b", Hash: 0b1111}, {ID: "c", Hash: 0b0111}}
+
+	assert.Equal(t, 0, ps.HashDistance(0b0111))
+	assert.Equal(t, 2, ps.HashDistance(0b0001))
+	assert.Equal(t, -1, shared.Photos{{ID: "a"}}.HashDistance(1))
+	assert.Len(t, ps.HashBands(), 5, "bands shared by both hashes are listed once")
+}
+
+func TestFindDuplicates(t *testing.T) {
+	h := shared.PerceptualHash(0x8badf00ddeadbeef)
+	candidates := []shared.DuplicateCandidate{
+		{Duplicate: shared.Duplicate{ID: "far"}, Photos: shared.Photos{{Hash: h ^ 0xff}}},
+		{Duplicate: shared.Duplicate{ID: "near"}, Photos: shared.Photos{{Hash: h ^ 0b11}}},
+		{Duplicate: shared.Duplicate{ID: "same"}, Photos: shared.Photos{{Hash: h ^ 0xf0}, {Hash: h}}},
+		{Duplicate: shared.Duplicate{ID: "unhashed"}, Photos: shared.Photos{{}}},
+	}
+
+	err := shared.FindDuplicates(h, candidates)
+
+	var dup *shared.DuplicateError
+	require.ErrorAs(t, err, &dup)
+	require.Len(t, dup.Duplicates, 2)
+	assert.Equal(t, "same", dup.Duplicates[0].ID)
+	assert.Equal(t, 0, dup.Duplicates[0].Distance)
+	assert.Equal(t, "near", dup.Duplicates[1].ID)
+	assert.Equal(t, 2, dup.Duplicates[1].Distance)
+
+	assert.NoError(t, shared.FindDuplicates(h, candidates[:1]))
+}
+
+func TestDuplicateError_IsPossibleDuplicate(t *testing.T) {
+	var err error = &shared.DuplicateError{Duplicates: []shared.Duplicate{{ID: "a"}}}
+
+	assert.ErrorIs(t, err, shared.ErrPossibleDuplicate)
+}
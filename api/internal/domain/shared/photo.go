@@ -0,0 +1,199 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// MaxPhotos caps the photos kept per person.
+const MaxPhotos = 10
+
+var (
+	ErrInvalidPhoto  = errors.New("invalid photo")
+	ErrPhotoNotFound = errors.New("photo not found")
+)
+
+// Photo is one picture of a person. AgeAtPhoto and TakenAt are zero when
+// unknown. FaceDetected is reported by whoever adds the photo and is only
+// informative; matching does not rely on it. Hash is computed by the server.
+type Photo struct {
+	ID           string
+	URL          string
+	TakenAt      time.Time
+	AgeAtPhoto   int
+	Primary      bool
+	FaceDetected bool
+	AddedAt      time.Time
+	Hash         PerceptualHash
+}
+
+// PhotoSources tells whether a URL points at storage photos may be kept on,
+// normally our own media bucket.
+type PhotoSources interface {
+	Allows(u *url.URL) bool
+}
+
+// ValidatePhotoURL checks that raw is an absolute http(s) URL.
+func ValidatePhotoURL(raw string) error {
+	_, err := parsePhotoURL(raw)
+	return err
+}
+
+// CheckPhotoSource checks that raw is a valid photo URL on one of sources.
+// A nil sources accepts any valid URL.
+func CheckPhotoSource(sources PhotoSources, raw string) error {
+	u, err := parsePhotoURL(raw)
+	if err != nil {
+		return err
+	}
+	if sources != nil && !sources.Allows(u) {
+		return fmt.Errorf("%w: url must point at our photo storage, upload the photo first", ErrInvalidPhoto)
+	}
+	return nil
+}
+
+func parsePhotoURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return nil, fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidPhoto)
+	}
+	return u, nil
+}
+
+func (p Photo) Validate() error {
+	if err := ValidatePhotoURL(p.URL); err != nil {
+		return err
+	}
+	if p.AgeAtPhoto < 0 || p.AgeAtPhoto > 130 {
+		return fmt.Errorf("%w: age_at_photo must be between 0 and 130", ErrInvalidPhoto)
+	}
+	if p.TakenAt.After(time.Now()) {
+		return fmt.Errorf("%w: taken_at cannot be in the future", ErrInvalidPhoto)
+	}
+	return nil
+}
+
+// PhotoUpdate changes the fields that are set.
+type PhotoUpdate struct {
+	TakenAt      *time.Time
+	AgeAtPhoto   *int
+	Primary      *bool
+	FaceDetected *bool
+}
+
+// Photos keeps exactly one primary photo whenever it is not empty.
+type Photos []Photo
+
+// Primary returns the primary photo, or nil when there are none.
+func (ps Photos) Primary() *Photo {
+	for i := range ps {
+		if ps[i].Primary {
+			return &ps[i]
+		}
+	}
+	return nil
+}
+
+// PrimaryURL is the URL mirrored in the record's PhotoURL.
+func (ps Photos) PrimaryURL() string {
+	if p := ps.Primary(); p != nil {
+		return p.URL
+	}
+	return ""
+}
+
+func (ps Photos) Find(id string) *Photo {
+	for i := range ps {
+		if ps[i].ID == id {
+			return &ps[i]
+		}
+	}
+	return nil
+}
+
+// Add validates and appends p. The first photo is always primary; a later
+// one only when p.Primary is set.
+func (ps *Photos) Add(p Photo) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if len(*ps) >= MaxPhotos {
+		return fmt.Errorf("%w: at most %d photos per person", ErrInvalidPhoto, MaxPhotos)
+	}
+	if len(*ps) == 0 {
+		p.Primary = true
+	}
+	if p.Primary {
+		ps.clearPrimary()
+	}
+	*ps = append(*ps, p)
+	return nil
+}
+
+// Update applies u to the photo with the given id. Unsetting Primary is
+// ignored: another photo must be made primary instead.
+func (ps Photos) Update(id string, u PhotoUpdate) (*Photo, error) {
+	p := ps.Find(id)
+	if p == nil {
+		return nil, ErrPhotoNotFound
+	}
+
+	updated := *p
+	if u.TakenAt != nil {
+		updated.TakenAt = *u.TakenAt
+	}
+	if u.AgeAtPhoto != nil {
+		updated.AgeAtPhoto = *u.AgeAtPhoto
+	}
+	if u.FaceDetected != nil {
+		updated.FaceDetected = *u.FaceDetected
+	}
+	if err := updated.Validate(); err != nil {
+		return nil, err
+	}
+
+	if u.Primary != nil && *u.Primary {
+		ps.clearPrimary()
+		updated.Primary = true
+	}
+	*p = updated
+	return p, nil
+}
+
+// Remove deletes the photo with the given id. If it was primary, the first
+// remaining photo takes its place.
+func (ps *Photos) Remove(id string) error {
+	for i, p := range *ps {
+		if p.ID != id {
+			continue
+		}
+		*ps = append((*ps)[:i], (*ps)[i+1:]...)
+		if p.Primary && len(*ps) > 0 {
+			(*ps)[0].Primary = true
+		}
+		return nil
+	}
+	return ErrPhotoNotFound
+}
+
+// UsePrimaryURL makes the photo at p.URL primary, adding p when no photo has
+// that URL. It is how a record's PhotoURL, set directly, is kept in sync.
+func (ps *Photos) UsePrimaryURL(p Photo) error {
+	for i := range *ps {
+		if (*ps)[i].URL == p.URL {
+			ps.clearPrimary()
+			(*ps)[i].Primary = true
+			return nil
+		}
+	}
+	p.Primary = true
+	return ps.Add(p)
+}
+
+func (ps Photos) clearPrimary() {
+	for i := range ps {
+		ps[i].Primary = false
+	}
+}
@@ -0,0 +1,124 @@
+package shared_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/shared"
+	"github.com/l3co/traceo-api/pkg/safefetch"
+)
+
+func TestPhotos_Add_FirstIsPrimary(t *testing.T) {
+	var ps shared.Photos
+
+	require.NoError(t, ps.Add(shared.Photo{ID: "a", URL: "https://example.com/a.jpg"}))
+	require.NoError(t, ps.Add(shared.Photo{ID: "b", URL: "https://example.com/b.jpg"}))
+
+	assert.Equal(t, "https://example.com/a.jpg", ps.PrimaryURL())
+}
+
+func TestPhotos_Add_PrimaryReplacesPrevious(t *testing.T) {
+	var ps shared.Photos
+	require.NoError(t, ps.Add(shared.Photo{ID: "a", URL: "https://example.com/a.jpg"}))
+
+	require.NoError(t, ps.Add(shared.Photo{ID: "b", URL: "https://example.com/b.jpg", Primary: true}))
+
+	assert.False(t, ps.Find("a").Primary)
+	assert.Equal(t, "b", ps.Primary().ID)
+}
+
+func TestPhotos_Add_Limit(t *testing.T) {
+	var ps shared.Photos
+	for i := 0; i < shared.MaxPhotos; i++ {
+		require.NoError(t, ps.Add(shared.Photo{URL: "https://example.com/a.jpg"}))
+	}
+
+	err := ps.Add(shared.Photo{URL: "https://example.com/a.jpg"})
+
+	assert.ErrorIs(t, err, shared.ErrInvalidPhoto)
+}
+
+func TestPhoto_Validate(t *testing.T) {
+	valid := shared.Photo{URL: "https://example.com/a.jpg", AgeAtPhoto: 12}
+	assert.NoError(t, valid.Validate())
+
+	for name, p := range map[string]shared.Photo{
+		"relative url": {URL: "/a.jpg"},
+		"bad scheme":   {URL: "javascript:alert(1)"},
+		"age":          {URL: "https://example.com/a.jpg", AgeAtPhoto: 131},
+		"future":       {URL: "https://example.com/a.jpg", TakenAt: time.Now().Add(time.Hour)},
+	} {
+		assert.ErrorIs(t, p.Validate(), shared.ErrInvalidPhoto, name)
+	}
+}
+
+func TestPhotos_Update(t *testing.T) {
+	ps := shared.Photos{
+		{ID: "a", URL: "https://example.com/a.jpg", Primary: true},
+		{ID: "b", URL: "https://example.com/b.jpg"},
+	}
+	primary, age := true, 20
+
+	p, err := ps.Update("b", shared.PhotoUpdate{Primary: &primary, AgeAtPhoto: &age})
+
+	require.NoError(t, err)
+	assert.Equal(t, 20, p.AgeAtPhoto)
+	assert.Equal(t, "b", ps.Primary().ID)
+
+	bad := -1
+	_, err = ps.Update("a", shared.PhotoUpdate{AgeAtPhoto: &bad})
+	assert.ErrorIs(t, err, shared.ErrInvalidPhoto)
+	assert.Equal(t, 0, ps.Find("a").AgeAtPhoto)
+
+	_, err = ps.Update("x", shared.PhotoUpdate{})
+	assert.ErrorIs(t, err, shared.ErrPhotoNotFound)
+}
+
+func TestPhotos_Remove_PromotesFirst(t *testing.T) {
+	ps := shared.Photos{
+		{ID: "a", URL: "https://example.com/a.jpg"},
+		{ID: "b", URL: "https://example.com/b.jpg", Primary: true},
+	}
+
+	require.NoError(t, ps.Remove("b"))
+
+	assert.Equal(t, "a", ps.Primary().ID)
+	assert.ErrorIs(t, ps.Remove("b"), shared.ErrPhotoNotFound)
+}
+
+func TestPhotos_UsePrimaryURL(t *testing.T) {
+	ps := shared.Photos{
+		{ID: "a", URL: "https://example.com/a.jpg", Primary: true},
+		{ID: "b", URL: "https://example.com/b.jpg"},
+	}
+
+	require.NoError(t, ps.UsePrimaryURL(shared.Photo{URL: "https://example.com/b.jpg"}))
+	assert.Equal(t, "b", ps.Primary().ID)
+	assert.Len(t, ps, 2)
+
+	require.NoError(t, ps.UsePrimaryURL(shared.Photo{ID: "c", URL: "https://example.com/c.jpg"}))
+	assert.Equal(t, "c", ps.Primary().ID)
+	assert.Len(t, ps, 3)
+}
+
+func TestCheckPhotoSource(t *testing.T) {
+	sources := safefetch.URLList{"firebasestorage.googleapis.com/v0/b/traceo/"}
+
+	assert.NoError(t, shared.CheckPhotoSource(sources, "https://firebasestorage.googleapis.com/v0/b/traceo/o/a.jpg"))
+	for _, u := range []string{
+		"http://169.254.169.254/latest/meta-data/",
+		"http://localhost:8080/admin",
+		"https://evil.example.com/a.jpg",
+		"https://firebasestorage.googleapis.com/v0/b/attacker/o/a.jpg",
+		"https://user@firebasestorage.googleapis.com/v0/b/traceo/o/a.jpg",
+		"/v0/b/traceo/o/a.jpg",
+	} {
+		assert.ErrorIs(t, shared.CheckPhotoSource(sources, u), shared.ErrInvalidPhoto, u)
+	}
+
+	assert.NoError(t, shared.CheckPhotoSource(nil, "https://evil.example.com/a.jpg"))
+	assert.ErrorIs(t, shared.CheckPhotoSource(nil, "ftp://evil.example.com/a.jpg"), shared.ErrInvalidPhoto)
+}
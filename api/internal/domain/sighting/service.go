@@ -11,6 +11,7 @@ import (
 
 	"github.com/l3co/traceo-api/internal/domain/missing"
 	"github.com/l3co/traceo-api/internal/domain/notification"
+	"github.com/l3co/traceo-api/internal/domain/timeline"
 )
 
 type Service struct {
@@ -18,15 +19,27 @@ type Service struct {
 	missingRepo missing.Repository
 	notifier    notification.Notifier
 	sanitizer   *bluemonday.Policy
+	timeline    timeline.Recorder
 }
 
-func NewService(repo Repository, missingRepo missing.Repository, notifier notification.Notifier) *Service {
-	return &Service{
+type Option func(*Service)
+
+// WithTimeline records every sighting in the timeline of its case.
+func WithTimeline(r timeline.Recorder) Option {
+	return func(s *Service) { s.timeline = r }
+}
+
+func NewService(repo Repository, missingRepo missing.Repository, notifier notification.Notifier, opts ...Option) *Service {
+	s := &Service{
 		repo:        repo,
 		missingRepo: missingRepo,
 		notifier:    notifier,
 		sanitizer:   bluemonday.StrictPolicy(),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 type CreateInput struct {
@@ -63,6 +76,13 @@ func (s *Service) Create(ctx context.Context, input CreateInput) (*Sighting, err
 		return nil, fmt.Errorf("creating sighting: %w", err)
 	}
 
+	timeline.RecordOrLog(ctx, s.timeline, &timeline.Event{
+		MissingID:  sighting.MissingID,
+		Type:       timeline.TypeSightingReported,
+		OccurredAt: sighting.CreatedAt,
+		Details:    map[string]string{"sighting_id": sighting.ID, "observation": observation},
+	})
+
 	if s.notifier != nil {
 		go func() {
 			bgCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -10,7 +10,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/l3co/traceo-api/internal/domain/missing"
+	"github.com/l3co/traceo-api/internal/domain/shared"
 	"github.com/l3co/traceo-api/internal/domain/sighting"
+	"github.com/l3co/traceo-api/internal/domain/timeline"
 )
 
 // --- Mock Notifier ---
@@ -106,13 +108,39 @@ func (m *mockMissingRepo) CountByYear(_ context.Context) ([]missing.YearStat, er
 	return nil, nil
 }
 func (m *mockMissingRepo) CountChildren(_ context.Context) (int64, error) { return 0, nil }
+func (m *mockMissingRepo) CountByStatus(_ context.Context) ([]missing.StatusStat, error) {
+	return nil, nil
+}
 func (m *mockMissingRepo) FindLocations(_ context.Context, l int) ([]missing.LocationPoint, error) {
 	return nil, nil
 }
 func (m *mockMissingRepo) FindCandidates(_ context.Context, _ missing.CandidateFilter) ([]*missing.Missing, error) {
 	return nil, nil
 }
-func (m *mockMissingRepo) UpdateAgeProgressionURLs(_ context.Context, _ string, _ []string) error {
+func (m *mockMissingRepo) FindAgeProgressionDue(_ context.Context, _ time.Time, _ int) ([]*missing.Missing, error) {
+	return nil, nil
+}
+func (m *mockMissingRepo) MarkAgeProgressionRequested(_ context.Context, _ string, _ time.Time) error {
+	return nil
+}
+func (m *mockMissingRepo) UpdateAgeProgression(_ context.Context, _ string, _ *missing.AgeProgression) error {
+	return nil
+}
+func (m *mockMissingRepo) UpdatePhotos(_ context.Context, _ *missing.Missing) error {
+	return nil
+}
+func (m *mockMissingRepo) FindByPhotoHash(_ context.Context, _ shared.PerceptualHash, _ int) ([]*missing.Missing, error) {
+	return nil, nil
+}
+
+// --- Mock Timeline ---
+
+type mockTimeline struct {
+	events []*timeline.Event
+}
+
+func (m *mockTimeline) Record(_ context.Context, e *timeline.Event) error {
+	m.events = append(m.events, e)
 	return nil
 }
 
@@ -216,6 +244,22 @@ func TestCreate_NotifierCalled(t *testing.T) {
 	assert.Equal(t, 1, notifier.CallCount())
 }
 
+func TestCreate_RecordsTimeline(t *testing.T) {
+	tl := &mockTimeline{}
+	mRepo := &mockMissingRepo{items: []*missing.Missing{{ID: "missing-1", UserID: "user-1"}}}
+	svc := sighting.NewService(&mockSightingRepo{}, mRepo, nil, sighting.WithTimeline(tl))
+
+	result, err := svc.Create(context.Background(), validSightingInput())
+
+	require.NoError(t, err)
+	require.Len(t, tl.events, 1)
+	e := tl.events[0]
+	assert.Equal(t, timeline.TypeSightingReported, e.Type)
+	assert.Equal(t, "missing-1", e.MissingID)
+	assert.Equal(t, result.ID, e.Details["sighting_id"])
+	assert.Equal(t, result.CreatedAt, e.OccurredAt)
+}
+
 // --- Tests: FindByID ---
 
 func TestFindByID_Success(t *testing.T) {
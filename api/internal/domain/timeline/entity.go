@@ -0,0 +1,108 @@
+package timeline
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Type is what happened on a case.
+type Type string
+
+const (
+	TypeCaseCreated             Type = "case_created"
+	TypePhotoChanged            Type = "photo_changed"
+	TypePhotoAdded              Type = "photo_added"
+	TypeSightingReported        Type = "sighting_reported"
+	TypeMatchProposed           Type = "match_proposed"
+	TypeMatchReviewed           Type = "match_reviewed"
+	TypeStatusChanged           Type = "status_changed"
+	TypeAgeProgressionGenerated Type = "age_progression_generated"
+)
+
+func (t Type) IsValid() bool {
+	switch t {
+	case TypeCaseCreated, TypePhotoChanged, TypePhotoAdded, TypeSightingReported,
+		TypeMatchProposed, TypeMatchReviewed, TypeStatusChanged, TypeAgeProgressionGenerated:
+		return true
+	}
+	return false
+}
+
+// publicDetails lists the types every visitor may see and, for each, the
+// details shown to visitors who neither own the case nor moderate. Match
+// events name homeless people, scores and review decisions, and status
+// changes may carry notes and the address a person was found at, so those
+// stay with the family and the moderators.
+var publicDetails = map[Type][]string{
+	TypeCaseCreated:             nil,
+	TypePhotoChanged:            nil,
+	TypePhotoAdded:              {"photos"},
+	TypeSightingReported:        {"sighting_id", "observation"},
+	TypeStatusChanged:           {"from", "to", "found_at"},
+	TypeAgeProgressionGenerated: {"images", "generator"},
+}
+
+// IsPublic reports whether events of type t are shown to every visitor.
+func (t Type) IsPublic() bool {
+	_, ok := publicDetails[t]
+	return ok
+}
+
+// PublicTypes returns the types shown to every visitor.
+func PublicTypes() []Type {
+	types := make([]Type, 0, len(publicDetails))
+	for t := range publicDetails {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// Event is one entry of a missing person's timeline. Events are never
+// changed once recorded. ActorID is empty for events the system produced on
+// its own, such as a proposed match. Details holds the few values each type
+// needs to be shown, such as the match ID and score; photo URLs are left out
+// so that the timeline never reveals a restricted photo.
+type Event struct {
+	ID         string
+	MissingID  string
+	Type       Type
+	ActorID    string
+	OccurredAt time.Time
+	Details    map[string]string
+}
+
+// Redacted returns a copy of e with only the details of its type that every
+// visitor may see, and without the actor.
+func (e *Event) Redacted() *Event {
+	r := *e
+	r.ActorID = ""
+	r.Details = nil
+	for _, k := range publicDetails[e.Type] {
+		if v, ok := e.Details[k]; ok {
+			if r.Details == nil {
+				r.Details = make(map[string]string)
+			}
+			r.Details[k] = v
+		}
+	}
+	return &r
+}
+
+func (e *Event) Validate() error {
+	if e.MissingID == "" {
+		return fmt.Errorf("%w: missing_id is required", ErrInvalidEvent)
+	}
+	if !e.Type.IsValid() {
+		return fmt.Errorf("%w: invalid type %q", ErrInvalidEvent, e.Type)
+	}
+	return nil
+}
+
+// ListOptions pages through a timeline. An empty Types selects every type.
+type ListOptions struct {
+	Types    []Type
+	PageSize int
+	After    string
+}
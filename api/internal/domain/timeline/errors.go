@@ -0,0 +1,5 @@
+package timeline
+
+import "errors"
+
+var ErrInvalidEvent = errors.New("invalid timeline event")
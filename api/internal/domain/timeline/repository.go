@@ -0,0 +1,12 @@
+package timeline
+
+import "context"
+
+type Repository interface {
+	// Append stores e. There is no update or delete: the timeline is
+	// append-only.
+	Append(ctx context.Context, e *Event) error
+	// FindByMissingID returns a page of the case's events, oldest first, and
+	// the cursor of the next page, empty on the last one.
+	FindByMissingID(ctx context.Context, missingID string, opts ListOptions) ([]*Event, string, error)
+}
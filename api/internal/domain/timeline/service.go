@@ -0,0 +1,107 @@
+package timeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
+
+// Recorder is what the services that write to the timeline depend on.
+type Recorder interface {
+	Record(ctx context.Context, e *Event) error
+}
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Record assigns e an ID, and the current time when OccurredAt is zero, and
+// appends it to the case's timeline.
+func (s *Service) Record(ctx context.Context, e *Event) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	e.ID = uuid.NewString()
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+
+	if err := s.repo.Append(ctx, e); err != nil {
+		return fmt.Errorf("appending timeline event: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) List(ctx context.Context, missingID string, opts ListOptions) ([]*Event, string, error) {
+	if missingID == "" {
+		return nil, "", fmt.Errorf("%w: missing_id is required", ErrInvalidEvent)
+	}
+	for _, t := range opts.Types {
+		if !t.IsValid() {
+			return nil, "", fmt.Errorf("%w: invalid type %q", ErrInvalidEvent, t)
+		}
+	}
+	if opts.PageSize <= 0 || opts.PageSize > maxPageSize {
+		opts.PageSize = defaultPageSize
+	}
+
+	return s.repo.FindByMissingID(ctx, missingID, opts)
+}
+
+// ListPublic is List for visitors who neither own the case nor moderate. It
+// leaves out the event types that are not public and redacts the rest.
+func (s *Service) ListPublic(ctx context.Context, missingID string, opts ListOptions) ([]*Event, string, error) {
+	requested := opts.Types
+	if len(requested) == 0 {
+		requested = PublicTypes()
+	}
+	opts.Types = nil
+	for _, t := range requested {
+		if !t.IsValid() {
+			return nil, "", fmt.Errorf("%w: invalid type %q", ErrInvalidEvent, t)
+		}
+		if t.IsPublic() {
+			opts.Types = append(opts.Types, t)
+		}
+	}
+	if len(opts.Types) == 0 {
+		return nil, "", nil
+	}
+
+	events, next, err := s.List(ctx, missingID, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	for i, e := range events {
+		events[i] = e.Redacted()
+	}
+	return events, next, nil
+}
+
+// RecordOrLog records e through r, when r is set. The timeline is a record
+// of what already happened, so a failure is logged rather than undoing the
+// change it describes.
+func RecordOrLog(ctx context.Context, r Recorder, e *Event) {
+	if r == nil {
+		return
+	}
+	if err := r.Record(ctx, e); err != nil {
+		slog.Error("failed to record timeline event",
+			"type", string(e.Type),
+			"missing_id", e.MissingID,
+			"error", err,
+		)
+	}
+}
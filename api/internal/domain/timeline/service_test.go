@@ -0,0 +1,194 @@
+package timeline_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/timeline"
+)
+
+// --- Mock Repository ---
+
+type mockRepo struct {
+	events []*timeline.Event
+	err    error
+}
+
+func (m *mockRepo) Append(_ context.Context, e *timeline.Event) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.events = append(m.events, e)
+	return nil
+}
+
+func (m *mockRepo) FindByMissingID(_ context.Context, missingID string, opts timeline.ListOptions) ([]*timeline.Event, string, error) {
+	var page []*timeline.Event
+	started := opts.After == ""
+	for _, e := range m.events {
+		if !started {
+			started = e.ID == opts.After
+			continue
+		}
+		if e.MissingID != missingID || (len(opts.Types) > 0 && !slices.Contains(opts.Types, e.Type)) {
+			continue
+		}
+		page = append(page, e)
+		if len(page) == opts.PageSize {
+			return page, e.ID, nil
+		}
+	}
+	return page, "", nil
+}
+
+// --- Mock Recorder ---
+
+type failingRecorder struct{ calls int }
+
+func (f *failingRecorder) Record(_ context.Context, _ *timeline.Event) error {
+	f.calls++
+	return errors.New("unavailable")
+}
+
+// --- Tests: Record ---
+
+func TestRecord_AssignsIDAndTime(t *testing.T) {
+	repo := &mockRepo{}
+	svc := timeline.NewService(repo)
+
+	e := &timeline.Event{MissingID: "m1", Type: timeline.TypeCaseCreated, ActorID: "u1"}
+	require.NoError(t, svc.Record(context.Background(), e))
+
+	require.Len(t, repo.events, 1)
+	assert.NotEmpty(t, e.ID)
+	assert.WithinDuration(t, time.Now(), e.OccurredAt, time.Second)
+}
+
+func TestRecord_KeepsOccurredAt(t *testing.T) {
+	repo := &mockRepo{}
+	at := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	e := &timeline.Event{MissingID: "m1", Type: timeline.TypeSightingReported, OccurredAt: at}
+	require.NoError(t, timeline.NewService(repo).Record(context.Background(), e))
+
+	assert.Equal(t, at, repo.events[0].OccurredAt)
+}
+
+func TestRecord_Invalid(t *testing.T) {
+	svc := timeline.NewService(&mockRepo{})
+
+	err := svc.Record(context.Background(), &timeline.Event{Type: timeline.TypeCaseCreated})
+	assert.ErrorIs(t, err, timeline.ErrInvalidEvent)
+
+	err = svc.Record(context.Background(), &timeline.Event{MissingID: "m1", Type: "deleted"})
+	assert.ErrorIs(t, err, timeline.ErrInvalidEvent)
+}
+
+func TestRecordOrLog(t *testing.T) {
+	e := &timeline.Event{MissingID: "m1", Type: timeline.TypeCaseCreated}
+
+	timeline.RecordOrLog(context.Background(), nil, e)
+
+	f := &failingRecorder{}
+	timeline.RecordOrLog(context.Background(), f, e)
+	assert.Equal(t, 1, f.calls)
+}
+
+// --- Tests: List ---
+
+func seed(t *testing.T, svc *timeline.Service) {
+	t.Helper()
+	for _, typ := range []timeline.Type{
+		timeline.TypeCaseCreated,
+		timeline.TypeSightingReported,
+		timeline.TypeMatchProposed,
+		timeline.TypeSightingReported,
+		timeline.TypeStatusChanged,
+	} {
+		require.NoError(t, svc.Record(context.Background(), &timeline.Event{MissingID: "m1", Type: typ}))
+	}
+	require.NoError(t, svc.Record(context.Background(), &timeline.Event{MissingID: "m2", Type: timeline.TypeCaseCreated}))
+}
+
+func TestList_Paginates(t *testing.T) {
+	svc := timeline.NewService(&mockRepo{})
+	seed(t, svc)
+
+	page, cursor, err := svc.List(context.Background(), "m1", timeline.ListOptions{PageSize: 3})
+	require.NoError(t, err)
+	require.Len(t, page, 3)
+	assert.Equal(t, timeline.TypeCaseCreated, page[0].Type)
+	require.NotEmpty(t, cursor)
+
+	page, cursor, err = svc.List(context.Background(), "m1", timeline.ListOptions{PageSize: 3, After: cursor})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, timeline.TypeStatusChanged, page[1].Type)
+	assert.Empty(t, cursor)
+}
+
+func TestList_FiltersByType(t *testing.T) {
+	svc := timeline.NewService(&mockRepo{})
+	seed(t, svc)
+
+	page, _, err := svc.List(context.Background(), "m1", timeline.ListOptions{Types: []timeline.Type{timeline.TypeSightingReported}})
+
+	require.NoError(t, err)
+	assert.Len(t, page, 2)
+}
+
+func TestList_DefaultPageSize(t *testing.T) {
+	svc := timeline.NewService(&mockRepo{})
+	seed(t, svc)
+
+	page, cursor, err := svc.List(context.Background(), "m1", timeline.ListOptions{PageSize: 1000})
+
+	require.NoError(t, err)
+	assert.Len(t, page, 5)
+	assert.Empty(t, cursor)
+}
+
+func TestList_Invalid(t *testing.T) {
+	svc := timeline.NewService(&mockRepo{})
+
+	_, _, err := svc.List(context.Background(), "", timeline.ListOptions{})
+	assert.ErrorIs(t, err, timeline.ErrInvalidEvent)
+
+	_, _, err = svc.List(context.Background(), "m1", timeline.ListOptions{Types: []timeline.Type{"bogus"}})
+	assert.ErrorIs(t, err, timeline.ErrInvalidEvent)
+}
+
+func TestListPublic_HidesMatchesAndPrivateDetails(t *testing.T) {
+	svc := timeline.NewService(&mockRepo{})
+	seed(t, svc)
+	require.NoError(t, svc.Record(context.Background(), &timeline.Event{
+		MissingID: "m1",
+		Type:      timeline.TypeStatusChanged,
+		ActorID:   "u1",
+		Details:   map[string]string{"from": "disappeared", "to": "found_alive", "note": "at the shelter", "found_address": "Rua A, 10"},
+	}))
+
+	page, _, err := svc.ListPublic(context.Background(), "m1", timeline.ListOptions{})
+
+	require.NoError(t, err)
+	require.Len(t, page, 5)
+	for _, e := range page {
+		assert.NotEqual(t, timeline.TypeMatchProposed, e.Type)
+		assert.Empty(t, e.ActorID)
+	}
+	assert.Equal(t, map[string]string{"from": "disappeared", "to": "found_alive"}, page[4].Details)
+
+	page, cursor, err := svc.ListPublic(context.Background(), "m1", timeline.ListOptions{Types: []timeline.Type{timeline.TypeMatchReviewed}})
+	require.NoError(t, err)
+	assert.Empty(t, page)
+	assert.Empty(t, cursor)
+
+	_, _, err = svc.ListPublic(context.Background(), "m1", timeline.ListOptions{Types: []timeline.Type{"bogus"}})
+	assert.ErrorIs(t, err, timeline.ErrInvalidEvent)
+}
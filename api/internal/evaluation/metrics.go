@@ -0,0 +1,195 @@
+package evaluation
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+)
+
+// Result is the comparer's score for one labelled pair. Pairs the comparer
+// failed on carry Err and are left out of the metrics.
+type Result struct {
+	Pair
+	Score    float64
+	Analysis string
+	Err      error
+}
+
+// Run scores every pair with comparer, using up to concurrency calls at once.
+// Results are returned in the order of pairs.
+func Run(ctx context.Context, comparer matching.FaceComparer, pairs []Pair, concurrency int) []Result {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	results := make([]Result, len(pairs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, p := range pairs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			results[i] = Result{Pair: p}
+			r, err := comparer.CompareFaces(ctx, p.Photo1URL, p.Photo2URL)
+			if err != nil {
+				results[i].Err = err
+				return
+			}
+			results[i].Score = r.SimilarityScore
+			results[i].Analysis = r.Analysis
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// Point holds the confusion counts and derived metrics when pairs scoring at
+// least Threshold are predicted to be the same person. Recall doubles as the
+// true positive rate of the ROC curve.
+type Point struct {
+	Threshold float64 `json:"threshold"`
+	TP        int     `json:"tp"`
+	FP        int     `json:"fp"`
+	TN        int     `json:"tn"`
+	FN        int     `json:"fn"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	FPR       float64 `json:"fpr"`
+	F1        float64 `json:"f1"`
+}
+
+// Thresholds returns 0, step, 2*step, … up to 1.
+func Thresholds(step float64) []float64 {
+	if step <= 0 || step > 1 {
+		step = 0.05
+	}
+	n := int(math.Round(1 / step))
+	ts := make([]float64, 0, n+1)
+	for i := 0; i <= n; i++ {
+		ts = append(ts, math.Round(float64(i)*step*1000)/1000)
+	}
+	return ts
+}
+
+// PrecisionRecall evaluates results at each threshold. Precision is 1 when
+// nothing is predicted positive, so the curve starts at a defined point.
+func PrecisionRecall(results []Result, thresholds []float64) []Point {
+	points := make([]Point, 0, len(thresholds))
+	for _, t := range thresholds {
+		p := Point{Threshold: t}
+		for _, r := range results {
+			if r.Err != nil {
+				continue
+			}
+			predicted := r.Score >= t
+			switch {
+			case predicted && r.Same:
+				p.TP++
+			case predicted && !r.Same:
+				p.FP++
+			case !predicted && r.Same:
+				p.FN++
+			default:
+				p.TN++
+			}
+		}
+		p.Precision = ratio(p.TP, p.TP+p.FP, 1)
+		p.Recall = ratio(p.TP, p.TP+p.FN, 0)
+		p.FPR = ratio(p.FP, p.FP+p.TN, 0)
+		if p.TP > 0 {
+			p.F1 = 2 * p.Precision * p.Recall / (p.Precision + p.Recall)
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+// AUC is the area under the ROC curve traced by points, by the trapezoid
+// rule. Points should span thresholds 0 to 1 for a meaningful value.
+func AUC(points []Point) float64 {
+	roc := make([]Point, len(points))
+	copy(roc, points)
+	sort.Slice(roc, func(i, j int) bool {
+		if roc[i].FPR != roc[j].FPR {
+			return roc[i].FPR < roc[j].FPR
+		}
+		return roc[i].Recall < roc[j].Recall
+	})
+
+	area := 0.0
+	prevX, prevY := 0.0, 0.0
+	for _, p := range roc {
+		area += (p.FPR - prevX) * (p.Recall + prevY) / 2
+		prevX, prevY = p.FPR, p.Recall
+	}
+	area += (1 - prevX) * (1 + prevY) / 2
+	return area
+}
+
+// BestF1 returns the point with the highest F1, preferring the higher
+// threshold on ties.
+func BestF1(points []Point) Point {
+	var best Point
+	for _, p := range points {
+		if p.F1 > best.F1 || (p.F1 == best.F1 && p.Threshold > best.Threshold) {
+			best = p
+		}
+	}
+	return best
+}
+
+// Confusion lists the misclassified pairs at threshold, worst first: false
+// positives by descending score and false negatives by ascending score.
+type Confusion struct {
+	Point
+	FalsePositives []Result
+	FalseNegatives []Result
+}
+
+func ConfusionAt(results []Result, threshold float64) Confusion {
+	c := Confusion{Point: PrecisionRecall(results, []float64{threshold})[0]}
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		switch {
+		case r.Score >= threshold && !r.Same:
+			c.FalsePositives = append(c.FalsePositives, r)
+		case r.Score < threshold && r.Same:
+			c.FalseNegatives = append(c.FalseNegatives, r)
+		}
+	}
+	sort.SliceStable(c.FalsePositives, func(i, j int) bool {
+		return c.FalsePositives[i].Score > c.FalsePositives[j].Score
+	})
+	sort.SliceStable(c.FalseNegatives, func(i, j int) bool {
+		return c.FalseNegatives[i].Score < c.FalseNegatives[j].Score
+	})
+	return c
+}
+
+// Failures counts the results that carry an error.
+func Failures(results []Result) int {
+	n := 0
+	for _, r := range results {
+		if r.Err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func ratio(num, den int, empty float64) float64 {
+	if den == 0 {
+		return empty
+	}
+	return float64(num) / float64(den)
+}
@@ -0,0 +1,104 @@
+package evaluation_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/internal/evaluation"
+)
+
+type scoreTable map[string]float64
+
+func (s scoreTable) CompareFaces(_ context.Context, photo1URL, _ string) (*matching.FaceComparisonResult, error) {
+	score, ok := s[photo1URL]
+	if !ok {
+		return nil, errors.New("download failed")
+	}
+	return &matching.FaceComparisonResult{SimilarityScore: score}, nil
+}
+
+func TestLoadPairs(t *testing.T) {
+	csv := "id,photo1_url,photo2_url,same\np1,a.jpg,b.jpg,true\n,c.jpg,d.jpg,0\n"
+
+	pairs, err := evaluation.LoadPairs(strings.NewReader(csv))
+	require.NoError(t, err)
+	require.Len(t, pairs, 2)
+	assert.Equal(t, evaluation.Pair{ID: "p1", Photo1URL: "a.jpg", Photo2URL: "b.jpg", Same: true}, pairs[0])
+	assert.Equal(t, "2", pairs[1].ID)
+	assert.False(t, pairs[1].Same)
+}
+
+func TestLoadPairs_MissingColumn(t *testing.T) {
+	_, err := evaluation.LoadPairs(strings.NewReader("photo1_url,photo2_url\na,b\n"))
+	assert.Error(t, err)
+}
+
+func TestRunAndPrecisionRecall(t *testing.T) {
+	pairs := []evaluation.Pair{
+		{Photo1URL: "same-high", Same: true},
+		{Photo1URL: "same-low", Same: true},
+		{Photo1URL: "diff-high", Same: false},
+		{Photo1URL: "diff-low", Same: false},
+		{Photo1URL: "broken", Same: true},
+	}
+	comparer := scoreTable{"same-high": 0.9, "same-low": 0.4, "diff-high": 0.7, "diff-low": 0.1}
+
+	results := evaluation.Run(context.Background(), comparer, pairs, 2)
+	assert.Equal(t, 1, evaluation.Failures(results))
+
+	points := evaluation.PrecisionRecall(results, []float64{0.5, 0.8, 1})
+
+	assert.Equal(t, evaluation.Point{Threshold: 0.5, TP: 1, FP: 1, TN: 1, FN: 1, Precision: 0.5, Recall: 0.5, FPR: 0.5, F1: 0.5}, points[0])
+	assert.Equal(t, 1.0, points[1].Precision)
+	assert.Equal(t, 0.5, points[1].Recall)
+	assert.Equal(t, 1.0, points[2].Precision, "no positives predicted")
+	assert.Zero(t, points[2].Recall)
+}
+
+func TestThresholds(t *testing.T) {
+	ts := evaluation.Thresholds(0.25)
+	assert.Equal(t, []float64{0, 0.25, 0.5, 0.75, 1}, ts)
+}
+
+func TestAUC(t *testing.T) {
+	perfect := []evaluation.Point{
+		{Threshold: 0, FPR: 1, Recall: 1},
+		{Threshold: 0.5, FPR: 0, Recall: 1},
+		{Threshold: 1, FPR: 0, Recall: 0},
+	}
+	assert.InDelta(t, 1.0, evaluation.AUC(perfect), 1e-9)
+
+	random := []evaluation.Point{
+		{Threshold: 0, FPR: 1, Recall: 1},
+		{Threshold: 0.5, FPR: 0.5, Recall: 0.5},
+		{Threshold: 1, FPR: 0, Recall: 0},
+	}
+	assert.InDelta(t, 0.5, evaluation.AUC(random), 1e-9)
+}
+
+func TestConfusionAt_WorstFirst(t *testing.T) {
+	results := []evaluation.Result{
+		{Pair: evaluation.Pair{ID: "fp-mild", Same: false}, Score: 0.65},
+		{Pair: evaluation.Pair{ID: "fp-bad", Same: false}, Score: 0.95},
+		{Pair: evaluation.Pair{ID: "fn-bad", Same: true}, Score: 0.05},
+		{Pair: evaluation.Pair{ID: "fn-mild", Same: true}, Score: 0.55},
+		{Pair: evaluation.Pair{ID: "tp", Same: true}, Score: 0.9},
+	}
+
+	c := evaluation.ConfusionAt(results, 0.6)
+
+	require.Len(t, c.FalsePositives, 2)
+	require.Len(t, c.FalseNegatives, 2)
+	assert.Equal(t, "fp-bad", c.FalsePositives[0].ID)
+	assert.Equal(t, "fn-bad", c.FalseNegatives[0].ID)
+	assert.Equal(t, 1, c.TP)
+
+	best := evaluation.BestF1(evaluation.PrecisionRecall(results, evaluation.Thresholds(0.05)))
+	assert.Greater(t, best.F1, 0.0)
+}
@@ -0,0 +1,71 @@
+package evaluation
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Pair is a labelled pair of photos: Same is true when both show the same
+// person.
+type Pair struct {
+	ID        string
+	Photo1URL string
+	Photo2URL string
+	Same      bool
+}
+
+// LoadPairs reads a CSV with a header row containing photo1_url, photo2_url
+// and same (true/false or 1/0), plus an optional id column.
+func LoadPairs(r io.Reader) ([]Pair, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"photo1_url", "photo2_url", "same"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+
+	var pairs []Pair
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		same, err := strconv.ParseBool(rec[cols["same"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid same value %q", line, rec[cols["same"]])
+		}
+		p := Pair{
+			ID:        strconv.Itoa(line - 1),
+			Photo1URL: rec[cols["photo1_url"]],
+			Photo2URL: rec[cols["photo2_url"]],
+			Same:      same,
+		}
+		if i, ok := cols["id"]; ok && rec[i] != "" {
+			p.ID = rec[i]
+		}
+		pairs = append(pairs, p)
+	}
+
+	if len(pairs) == 0 {
+		return nil, errors.New("no pairs found")
+	}
+	return pairs, nil
+}
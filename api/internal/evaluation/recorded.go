@@ -0,0 +1,145 @@
+package evaluation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+)
+
+var ErrNotRecorded = errors.New("pair not in recording")
+
+// recording is the on-disk format shared by Recorder and RecordedComparer.
+type recording struct {
+	Version string          `json:"version"`
+	Entries []recordedEntry `json:"entries"`
+}
+
+type recordedEntry struct {
+	Photo1URL         string   `json:"photo1_url"`
+	Photo2URL         string   `json:"photo2_url"`
+	SimilarityScore   float64  `json:"similarity_score"`
+	Analysis          string   `json:"analysis,omitempty"`
+	MatchingFeatures  []string `json:"matching_features,omitempty"`
+	DifferentFeatures []string `json:"different_features,omitempty"`
+	Confidence        string   `json:"confidence,omitempty"`
+	PromptID          string   `json:"prompt_id,omitempty"`
+	PromptVersion     string   `json:"prompt_version,omitempty"`
+}
+
+// pairKey is symmetric, like the comparers themselves.
+func pairKey(a, b string) string {
+	if b < a {
+		a, b = b, a
+	}
+	return a + "\x00" + b
+}
+
+// RecordedComparer replays responses saved by a Recorder, so that an
+// evaluation of an expensive comparer such as Gemini can be re-run for free
+// and deterministically.
+type RecordedComparer struct {
+	version string
+	results map[string]*matching.FaceComparisonResult
+}
+
+func LoadRecording(r io.Reader) (*RecordedComparer, error) {
+	var rec recording
+	if err := json.NewDecoder(r).Decode(&rec); err != nil {
+		return nil, fmt.Errorf("decoding recording: %w", err)
+	}
+
+	c := &RecordedComparer{
+		version: rec.Version,
+		results: make(map[string]*matching.FaceComparisonResult, len(rec.Entries)),
+	}
+	for _, e := range rec.Entries {
+		c.results[pairKey(e.Photo1URL, e.Photo2URL)] = &matching.FaceComparisonResult{
+			SimilarityScore:   e.SimilarityScore,
+			Analysis:          e.Analysis,
+			MatchingFeatures:  e.MatchingFeatures,
+			DifferentFeatures: e.DifferentFeatures,
+			Confidence:        e.Confidence,
+			PromptID:          e.PromptID,
+			PromptVersion:     e.PromptVersion,
+		}
+	}
+	return c, nil
+}
+
+// Version is the version of the comparer that was recorded.
+func (c *RecordedComparer) Version() string {
+	return c.version
+}
+
+func (c *RecordedComparer) CompareFaces(_ context.Context, photo1URL, photo2URL string) (*matching.FaceComparisonResult, error) {
+	r, ok := c.results[pairKey(photo1URL, photo2URL)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s / %s", ErrNotRecorded, photo1URL, photo2URL)
+	}
+	cp := *r
+	return &cp, nil
+}
+
+// Recorder passes comparisons through to inner and keeps every successful
+// response for Save.
+type Recorder struct {
+	inner matching.FaceComparer
+
+	mu      sync.Mutex
+	entries map[string]recordedEntry
+}
+
+func NewRecorder(inner matching.FaceComparer) *Recorder {
+	return &Recorder{inner: inner, entries: make(map[string]recordedEntry)}
+}
+
+func (r *Recorder) Version() string {
+	return matching.ComparerVersion(r.inner)
+}
+
+func (r *Recorder) CompareFaces(ctx context.Context, photo1URL, photo2URL string) (*matching.FaceComparisonResult, error) {
+	res, err := r.inner.CompareFaces(ctx, photo1URL, photo2URL)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.entries[pairKey(photo1URL, photo2URL)] = recordedEntry{
+		Photo1URL:         photo1URL,
+		Photo2URL:         photo2URL,
+		SimilarityScore:   res.SimilarityScore,
+		Analysis:          res.Analysis,
+		MatchingFeatures:  res.MatchingFeatures,
+		DifferentFeatures: res.DifferentFeatures,
+		Confidence:        res.Confidence,
+		PromptID:          res.PromptID,
+		PromptVersion:     res.PromptVersion,
+	}
+	r.mu.Unlock()
+	return res, nil
+}
+
+// Save writes the recorded responses as JSON, sorted for stable diffs.
+func (r *Recorder) Save(w io.Writer) error {
+	r.mu.Lock()
+	rec := recording{Version: r.Version(), Entries: make([]recordedEntry, 0, len(r.entries))}
+	for _, e := range r.entries {
+		rec.Entries = append(rec.Entries, e)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(rec.Entries, func(i, j int) bool {
+		return pairKey(rec.Entries[i].Photo1URL, rec.Entries[i].Photo2URL) <
+			pairKey(rec.Entries[j].Photo1URL, rec.Entries[j].Photo2URL)
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rec)
+}
@@ -0,0 +1,34 @@
+package evaluation_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/evaluation"
+)
+
+func TestRecorder_RoundTrip(t *testing.T) {
+	rec := evaluation.NewRecorder(scoreTable{"a.jpg": 0.8})
+
+	_, err := rec.CompareFaces(context.Background(), "a.jpg", "b.jpg")
+	require.NoError(t, err)
+	_, err = rec.CompareFaces(context.Background(), "missing.jpg", "b.jpg")
+	require.Error(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, rec.Save(&buf))
+
+	replay, err := evaluation.LoadRecording(&buf)
+	require.NoError(t, err)
+
+	r, err := replay.CompareFaces(context.Background(), "b.jpg", "a.jpg")
+	require.NoError(t, err)
+	assert.Equal(t, 0.8, r.SimilarityScore)
+
+	_, err = replay.CompareFaces(context.Background(), "missing.jpg", "b.jpg")
+	assert.ErrorIs(t, err, evaluation.ErrNotRecorded)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/pkg/httputil"
+)
+
+type AIUsageHandler struct {
+	budget *matching.Budget
+}
+
+// NewAIUsageHandler accepts a nil budget for deployments without Gemini.
+func NewAIUsageHandler(budget *matching.Budget) *AIUsageHandler {
+	return &AIUsageHandler{budget: budget}
+}
+
+// --- DTOs ---
+
+type AIUsageResponse struct {
+	Enabled        bool   `json:"enabled"`
+	Day            string `json:"day,omitempty"`
+	DayCalls       int64  `json:"day_calls"`
+	DailyLimit     int64  `json:"daily_limit"`
+	Month          string `json:"month,omitempty"`
+	MonthCalls     int64  `json:"month_calls"`
+	MonthlyLimit   int64  `json:"monthly_limit"`
+	CallsPerMinute int    `json:"calls_per_minute"`
+	MaxConcurrency int    `json:"max_concurrency"`
+	InFlight       int    `json:"in_flight"`
+	Exhausted      bool   `json:"exhausted"`
+}
+
+// @Summary      Uso da API de IA
+// @Description  Retorna as chamadas à IA no dia e no mês (UTC) e os limites configurados (somente admin)
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  AIUsageResponse
+// @Failure      403  {object}  httputil.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/ai/usage [get]
+func (h *AIUsageHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	if h.budget == nil {
+		httputil.JSON(w, http.StatusOK, AIUsageResponse{})
+		return
+	}
+
+	u, err := h.budget.Usage(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, "failed to read ai usage")
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, AIUsageResponse{
+		Enabled:        true,
+		Day:            u.Day,
+		DayCalls:       u.DayCalls,
+		DailyLimit:     u.DailyLimit,
+		Month:          u.Month,
+		MonthCalls:     u.MonthCalls,
+		MonthlyLimit:   u.MonthlyLimit,
+		CallsPerMinute: u.CallsPerMinute,
+		MaxConcurrency: u.MaxConcurrency,
+		InFlight:       u.InFlight,
+		Exhausted:      u.Exhausted(),
+	})
+}
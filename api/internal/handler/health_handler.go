@@ -8,19 +8,24 @@ import (
 	"cloud.google.com/go/firestore"
 
 	"github.com/l3co/traceo-api/pkg/httputil"
+	"github.com/l3co/traceo-api/pkg/resilience"
 )
 
 type HealthHandler struct {
 	startTime       time.Time
 	firestoreClient *firestore.Client
 	version         string
+	breakers        []*resilience.Breaker
 }
 
-func NewHealthHandler(firestoreClient *firestore.Client, version string) *HealthHandler {
+// NewHealthHandler reports the state of each breaker; an open one marks the
+// API as degraded.
+func NewHealthHandler(firestoreClient *firestore.Client, version string, breakers ...*resilience.Breaker) *HealthHandler {
 	return &HealthHandler{
 		startTime:       time.Now(),
 		firestoreClient: firestoreClient,
 		version:         version,
+		breakers:        breakers,
 	}
 }
 
@@ -42,11 +47,30 @@ func (h *HealthHandler) Check(w http.ResponseWriter, r *http.Request) {
 		deps["firestore"] = "ok"
 	}
 
+	status := "ok"
+	var circuits []CircuitResponse
+	for _, b := range h.breakers {
+		s := b.Snapshot()
+		c := CircuitResponse{Name: s.Name, State: string(s.State), Failures: s.Failures}
+		if !s.RetryAt.IsZero() {
+			c.RetryAt = s.RetryAt.Format(time.RFC3339)
+		}
+		circuits = append(circuits, c)
+
+		if s.State == resilience.StateClosed {
+			deps[s.Name] = "ok"
+		} else {
+			deps[s.Name] = "unavailable"
+			status = "degraded"
+		}
+	}
+
 	httputil.JSON(w, http.StatusOK, HealthResponse{
-		Status:       "ok",
+		Status:       status,
 		Version:      h.version,
 		Uptime:       time.Since(h.startTime).String(),
 		Dependencies: deps,
+		Circuits:     circuits,
 	})
 }
 
@@ -55,4 +79,12 @@ type HealthResponse struct {
 	Version      string            `json:"version"`
 	Uptime       string            `json:"uptime"`
 	Dependencies map[string]string `json:"dependencies"`
+	Circuits     []CircuitResponse `json:"circuits,omitempty"`
+}
+
+type CircuitResponse struct {
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	Failures int    `json:"failures"`
+	RetryAt  string `json:"retry_at,omitempty"`
 }
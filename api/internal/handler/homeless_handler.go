@@ -23,35 +23,37 @@ func NewHomelessHandler(service *homeless.Service) *HomelessHandler {
 // --- DTOs ---
 
 type CreateHomelessRequest struct {
-	Name      string  `json:"name"`
-	Nickname  string  `json:"nickname"`
-	BirthDate string  `json:"birth_date"`
-	Gender    string  `json:"gender"`
-	Eyes      string  `json:"eyes"`
-	Hair      string  `json:"hair"`
-	Skin      string  `json:"skin"`
-	PhotoURL  string  `json:"photo_url"`
-	Lat       float64 `json:"lat"`
-	Lng       float64 `json:"lng"`
-	Address   string  `json:"address,omitempty"`
+	Name           string  `json:"name"`
+	Nickname       string  `json:"nickname"`
+	BirthDate      string  `json:"birth_date"`
+	Gender         string  `json:"gender"`
+	Eyes           string  `json:"eyes"`
+	Hair           string  `json:"hair"`
+	Skin           string  `json:"skin"`
+	PhotoURL       string  `json:"photo_url"`
+	Lat            float64 `json:"lat"`
+	Lng            float64 `json:"lng"`
+	Address        string  `json:"address,omitempty"`
+	AllowDuplicate bool    `json:"allow_duplicate,omitempty"`
 }
 
 type HomelessResponse struct {
-	ID        string  `json:"id"`
-	Name      string  `json:"name"`
-	Nickname  string  `json:"nickname,omitempty"`
-	BirthDate string  `json:"birth_date,omitempty"`
-	Age       int     `json:"age"`
-	Gender    string  `json:"gender"`
-	Eyes      string  `json:"eyes"`
-	Hair      string  `json:"hair"`
-	Skin      string  `json:"skin"`
-	PhotoURL  string  `json:"photo_url,omitempty"`
-	Lat       float64 `json:"lat"`
-	Lng       float64 `json:"lng"`
-	Address   string  `json:"address,omitempty"`
-	Slug      string  `json:"slug"`
-	CreatedAt string  `json:"created_at"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Nickname  string          `json:"nickname,omitempty"`
+	BirthDate string          `json:"birth_date,omitempty"`
+	Age       int             `json:"age"`
+	Gender    string          `json:"gender"`
+	Eyes      string          `json:"eyes"`
+	Hair      string          `json:"hair"`
+	Skin      string          `json:"skin"`
+	PhotoURL  string          `json:"photo_url,omitempty"`
+	Photos    []PhotoResponse `json:"photos,omitempty"`
+	Lat       float64         `json:"lat"`
+	Lng       float64         `json:"lng"`
+	Address   string          `json:"address,omitempty"`
+	Slug      string          `json:"slug"`
+	CreatedAt string          `json:"created_at"`
 }
 
 type HomelessStatsResponse struct {
@@ -75,6 +77,7 @@ func toHomelessResponse(h *homeless.Homeless) HomelessResponse {
 		Hair:      string(h.Hair),
 		Skin:      string(h.Skin),
 		PhotoURL:  h.PhotoURL,
+		Photos:    toPhotoResponses(h.Photos),
 		Lat:       h.Location.Lat,
 		Lng:       h.Location.Lng,
 		Address:   h.Location.Address,
@@ -84,13 +87,14 @@ func toHomelessResponse(h *homeless.Homeless) HomelessResponse {
 }
 
 // @Summary      Cadastrar morador de rua
-// @Description  Registra uma pessoa em situação de rua que quer ser encontrada
+// @Description  Registra uma pessoa em situação de rua que quer ser encontrada. Se a foto for muito parecida com a de um cadastro existente, responde 409 com os possíveis duplicados; reenvie com allow_duplicate para cadastrar mesmo assim.
 // @Tags         homeless
 // @Accept       json
 // @Produce      json
 // @Param        body  body      CreateHomelessRequest  true  "Dados"
 // @Success      201   {object}  HomelessResponse
 // @Failure      400   {object}  httputil.ErrorResponse
+// @Failure      409   {object}  DuplicateConflictResponse
 // @Router       /api/v1/homeless [post]
 func (h *HomelessHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req CreateHomelessRequest
@@ -110,21 +114,25 @@ func (h *HomelessHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	input := homeless.CreateInput{
-		Name:      req.Name,
-		Nickname:  req.Nickname,
-		BirthDate: birthDate,
-		Gender:    shared.Gender(req.Gender),
-		Eyes:      shared.EyeColor(req.Eyes),
-		Hair:      shared.HairColor(req.Hair),
-		Skin:      shared.SkinColor(req.Skin),
-		PhotoURL:  req.PhotoURL,
-		Lat:       req.Lat,
-		Lng:       req.Lng,
-		Address:   req.Address,
+		Name:           req.Name,
+		Nickname:       req.Nickname,
+		BirthDate:      birthDate,
+		Gender:         shared.Gender(req.Gender),
+		Eyes:           shared.EyeColor(req.Eyes),
+		Hair:           shared.HairColor(req.Hair),
+		Skin:           shared.SkinColor(req.Skin),
+		PhotoURL:       req.PhotoURL,
+		Lat:            req.Lat,
+		Lng:            req.Lng,
+		Address:        req.Address,
+		AllowDuplicate: req.AllowDuplicate,
 	}
 
 	result, err := h.service.Create(r.Context(), input)
 	if err != nil {
+		if writeDuplicateConflict(w, err) {
+			return
+		}
 		if errors.Is(err, homeless.ErrInvalidHomeless) {
 			httputil.Error(w, http.StatusBadRequest, err.Error())
 			return
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/l3co/traceo-api/internal/domain/job"
+	"github.com/l3co/traceo-api/internal/handler/middleware"
+	"github.com/l3co/traceo-api/pkg/httputil"
+)
+
+// JobHandler shows job errors only to moderators: they can hold internal
+// details such as upstream API responses and storage paths.
+type JobHandler struct {
+	service    *job.Service
+	moderators map[string]bool
+}
+
+func NewJobHandler(service *job.Service, moderatorIDs []string) *JobHandler {
+	h := &JobHandler{service: service, moderators: make(map[string]bool)}
+	for _, id := range moderatorIDs {
+		h.moderators[id] = true
+	}
+	return h
+}
+
+func (h *JobHandler) showsErrors(r *http.Request) bool {
+	return h.moderators[middleware.GetUserID(r.Context())]
+}
+
+// --- DTOs ---
+
+type JobResponse struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	TargetID    string `json:"target_id"`
+	CandidateID string `json:"candidate_id,omitempty"`
+	Status      string `json:"status"`
+	Attempts    int    `json:"attempts"`
+	LastError   string `json:"last_error,omitempty"`
+	RunAt       string `json:"run_at,omitempty"`
+	StartedAt   string `json:"started_at,omitempty"`
+	FinishedAt  string `json:"finished_at,omitempty"`
+	DurationMs  int64  `json:"duration_ms,omitempty"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type PurgeJobsResponse struct {
+	Purged int `json:"purged"`
+}
+
+func toJobResponse(j *job.Job, showError bool) JobResponse {
+	resp := JobResponse{
+		ID:          j.ID,
+		Type:        string(j.Type),
+		TargetID:    j.TargetID,
+		CandidateID: j.CandidateID,
+		Status:      string(j.Status),
+		Attempts:    j.Attempts,
+		CreatedAt:   j.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   j.UpdatedAt.Format(time.RFC3339),
+	}
+	if showError {
+		resp.LastError = j.LastError
+	}
+	if !j.RunAt.IsZero() && j.Status == job.StatusQueued {
+		resp.RunAt = j.RunAt.Format(time.RFC3339)
+	}
+	if !j.StartedAt.IsZero() {
+		resp.StartedAt = j.StartedAt.Format(time.RFC3339)
+		resp.DurationMs = j.Duration().Milliseconds()
+	}
+	if !j.FinishedAt.IsZero() {
+		resp.FinishedAt = j.FinishedAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+// @Summary      Status de um job de IA
+// @Description  Retorna o estado de processamento (queued, running, succeeded, failed). last_error só aparece para moderadores
+// @Tags         jobs
+// @Produce      json
+// @Param        id   path      string  true  "Job ID"
+// @Success      200  {object}  JobResponse
+// @Failure      404  {object}  httputil.ErrorResponse
+// @Router       /api/v1/jobs/{id} [get]
+func (h *JobHandler) FindByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	found, err := h.service.FindByID(r.Context(), id)
+	if err != nil {
+		writeJobError(w, err, "failed to find job")
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, toJobResponse(found, h.showsErrors(r)))
+}
+
+// @Summary      Jobs de IA de um desaparecido
+// @Description  Retorna os jobs de IA (ex.: projeção de idade) de um desaparecido, mais recentes primeiro. last_error só aparece para moderadores
+// @Tags         jobs
+// @Produce      json
+// @Param        id     path      string  true   "Missing ID"
+// @Param        limit  query     int     false  "Limite"  default(20)
+// @Success      200    {array}   JobResponse
+// @Router       /api/v1/missing/{id}/jobs [get]
+func (h *JobHandler) FindByMissingID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	items, err := h.service.FindByTargetID(r.Context(), id, limit)
+	if err != nil {
+		writeJobError(w, err, "failed to list jobs")
+		return
+	}
+
+	showError := h.showsErrors(r)
+	resp := make([]JobResponse, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toJobResponse(item, showError))
+	}
+
+	httputil.JSON(w, http.StatusOK, resp)
+}
+
+// @Summary      Listar jobs mortos
+// @Description  Retorna jobs de IA que esgotaram as tentativas (somente admin)
+// @Tags         admin
+// @Produce      json
+// @Param        limit  query     int  false  "Limite"  default(50)
+// @Success      200    {array}   JobResponse
+// @Failure      403    {object}  httputil.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/jobs/dead [get]
+func (h *JobHandler) ListDead(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	items, err := h.service.ListDead(r.Context(), limit)
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, "failed to list dead jobs")
+		return
+	}
+
+	resp := make([]JobResponse, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toJobResponse(item, true))
+	}
+
+	httputil.JSON(w, http.StatusOK, resp)
+}
+
+// @Summary      Reprocessar job morto
+// @Description  Devolve um job morto para a fila com tentativas zeradas (somente admin)
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      string  true  "Job ID"
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  httputil.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/jobs/dead/{id}/retry [post]
+func (h *JobHandler) RetryDead(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if err := h.service.RetryDead(r.Context(), id); err != nil {
+		writeJobError(w, err, "failed to retry job")
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, map[string]string{"status": "queued"})
+}
+
+// @Summary      Remover job morto
+// @Description  Remove definitivamente um job morto (somente admin)
+// @Tags         admin
+// @Param        id   path  string  true  "Job ID"
+// @Success      204  "Sem conteúdo"
+// @Failure      404  {object}  httputil.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/jobs/dead/{id} [delete]
+func (h *JobHandler) PurgeDead(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if err := h.service.PurgeDead(r.Context(), id); err != nil {
+		writeJobError(w, err, "failed to purge job")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary      Remover todos os jobs mortos
+// @Description  Esvazia a fila de dead-letter (somente admin)
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  PurgeJobsResponse
+// @Security     BearerAuth
+// @Router       /api/v1/admin/jobs/dead [delete]
+func (h *JobHandler) PurgeAllDead(w http.ResponseWriter, r *http.Request) {
+	n, err := h.service.PurgeAllDead(r.Context())
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, "failed to purge dead jobs")
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, PurgeJobsResponse{Purged: n})
+}
+
+func writeJobError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, job.ErrJobNotFound):
+		httputil.Error(w, http.StatusNotFound, "job not found")
+	case errors.Is(err, job.ErrInvalidJob):
+		httputil.Error(w, http.StatusBadRequest, err.Error())
+	default:
+		httputil.Error(w, http.StatusInternalServerError, fallback)
+	}
+}
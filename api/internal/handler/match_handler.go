@@ -7,6 +7,8 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/internal/domain/missing"
+	"github.com/l3co/traceo-api/internal/handler/middleware"
 	"github.com/l3co/traceo-api/pkg/httputil"
 )
 
@@ -20,22 +22,77 @@ func NewMatchHandler(service *matching.Service) *MatchHandler {
 
 // --- DTOs ---
 
+type MatchComparisonResponse struct {
+	Score         float64 `json:"score"`
+	RawScore      float64 `json:"raw_score"`
+	Analysis      string  `json:"analysis,omitempty"`
+	PolicyVersion string  `json:"policy_version,omitempty"`
+	PromptID      string  `json:"prompt_id,omitempty"`
+	PromptVersion string  `json:"prompt_version,omitempty"`
+	Degraded      bool    `json:"degraded,omitempty"`
+	HomelessPhoto string  `json:"homeless_photo,omitempty"`
+	MissingPhoto  string  `json:"missing_photo,omitempty"`
+	ComparedAt    string  `json:"compared_at"`
+}
+
+type MatchPolicyResponse struct {
+	SaveThreshold      float64 `json:"save_threshold"`
+	NotifyThreshold    float64 `json:"notify_threshold"`
+	CandidateLimit     int     `json:"candidate_limit"`
+	GenderFilter       string  `json:"gender_filter"`
+	SkinFilter         string  `json:"skin_filter"`
+	SoftFilterPenalty  float64 `json:"soft_filter_penalty"`
+	AgeTolerance       int     `json:"age_tolerance"`
+	AgeToleranceGrowth float64 `json:"age_tolerance_growth"`
+	MaxAgeTolerance    int     `json:"max_age_tolerance"`
+}
+
 type MatchResponse struct {
-	ID             string  `json:"id"`
-	HomelessID     string  `json:"homeless_id"`
-	MissingID      string  `json:"missing_id"`
-	Score          float64 `json:"score"`
-	Status         string  `json:"status"`
-	GeminiAnalysis string  `json:"gemini_analysis,omitempty"`
-	CreatedAt      string  `json:"created_at"`
-	ReviewedAt     string  `json:"reviewed_at,omitempty"`
+	ID             string                    `json:"id"`
+	HomelessID     string                    `json:"homeless_id"`
+	MissingID      string                    `json:"missing_id"`
+	Score          float64                   `json:"score"`
+	Status         string                    `json:"status"`
+	GeminiAnalysis string                    `json:"gemini_analysis,omitempty"`
+	Comparisons    []MatchComparisonResponse `json:"comparisons,omitempty"`
+	Reviews        []MatchReviewResponse     `json:"reviews,omitempty"`
+	PolicyVersion  string                    `json:"policy_version,omitempty"`
+	Policy         *MatchPolicyResponse      `json:"policy,omitempty"`
+	PromptID       string                    `json:"prompt_id,omitempty"`
+	PromptVersion  string                    `json:"prompt_version,omitempty"`
+	CreatedAt      string                    `json:"created_at"`
+	UpdatedAt      string                    `json:"updated_at,omitempty"`
+	ReviewedAt     string                    `json:"reviewed_at,omitempty"`
+	ReviewedBy     string                    `json:"reviewed_by,omitempty"`
+}
+
+type MatchReviewResponse struct {
+	ReviewerID string `json:"reviewer_id,omitempty"`
+	Decision   string `json:"decision"`
+	Notes      string `json:"notes,omitempty"`
+	ReviewedAt string `json:"reviewed_at"`
 }
 
-type UpdateMatchStatusRequest struct {
-	Status string `json:"status"`
+type ReviewMatchRequest struct {
+	Status           string `json:"status"`
+	Notes            string `json:"notes"`
+	MarkMissingFound bool   `json:"mark_missing_found"`
+	FoundAt          string `json:"found_at,omitempty"`
 }
 
-func toMatchResponse(m *matching.Match) MatchResponse {
+// ReviewMatchResponse is the reviewed match and, when mark_missing_found was
+// asked for, whether the case was moved to found or why not.
+type ReviewMatchResponse struct {
+	MatchResponse
+	MissingFound bool   `json:"missing_found"`
+	StatusError  string `json:"status_error,omitempty"`
+}
+
+// toMatchResponse leaves the missing person's photos out for anonymous
+// viewers: the comparison does not know the case's visibility, and a
+// restricted photo must not leak through it. Who reviewed the match and
+// their notes are only shown to those who may review it themselves.
+func toMatchResponse(m *matching.Match, viewerID string, canReview bool) MatchResponse {
 	resp := MatchResponse{
 		ID:             m.ID,
 		HomelessID:     m.HomelessID,
@@ -43,16 +100,88 @@ func toMatchResponse(m *matching.Match) MatchResponse {
 		Score:          m.Score,
 		Status:         string(m.Status),
 		GeminiAnalysis: m.GeminiAnalysis,
+		PromptID:       m.PromptID,
+		PromptVersion:  m.PromptVersion,
 		CreatedAt:      m.CreatedAt.Format("2006-01-02T15:04:05Z"),
 	}
+	for _, c := range m.Comparisons {
+		missingPhoto := c.MissingPhoto
+		if viewerID == "" {
+			missingPhoto = ""
+		}
+		resp.Comparisons = append(resp.Comparisons, MatchComparisonResponse{
+			Score:         c.Score,
+			RawScore:      c.RawScore,
+			Analysis:      c.Analysis,
+			PolicyVersion: c.PolicyVersion,
+			PromptID:      c.PromptID,
+			PromptVersion: c.PromptVersion,
+			Degraded:      c.Degraded,
+			HomelessPhoto: c.HomelessPhoto,
+			MissingPhoto:  missingPhoto,
+			ComparedAt:    c.ComparedAt.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+	if m.PolicyVersion != "" {
+		resp.PolicyVersion = m.PolicyVersion
+		resp.Policy = &MatchPolicyResponse{
+			SaveThreshold:      m.Policy.SaveThreshold,
+			NotifyThreshold:    m.Policy.NotifyThreshold,
+			CandidateLimit:     m.Policy.CandidateLimit,
+			GenderFilter:       string(m.Policy.GenderFilter),
+			SkinFilter:         string(m.Policy.SkinFilter),
+			SoftFilterPenalty:  m.Policy.SoftFilterPenalty,
+			AgeTolerance:       m.Policy.AgeTolerance,
+			AgeToleranceGrowth: m.Policy.AgeToleranceGrowth,
+			MaxAgeTolerance:    m.Policy.MaxAgeTolerance,
+		}
+	}
+	for _, rv := range m.Reviews {
+		review := MatchReviewResponse{
+			Decision:   string(rv.Decision),
+			ReviewedAt: rv.ReviewedAt.Format("2006-01-02T15:04:05Z"),
+		}
+		if canReview {
+			review.ReviewerID = rv.ReviewerID
+			review.Notes = rv.Notes
+		}
+		resp.Reviews = append(resp.Reviews, review)
+	}
+	if !m.UpdatedAt.IsZero() {
+		resp.UpdatedAt = m.UpdatedAt.Format("2006-01-02T15:04:05Z")
+	}
 	if !m.ReviewedAt.IsZero() {
 		resp.ReviewedAt = m.ReviewedAt.Format("2006-01-02T15:04:05Z")
+		if canReview {
+			resp.ReviewedBy = m.ReviewedBy
+		}
 	}
 	return resp
 }
 
+// toMatchResponses converts items for the requesting viewer, checking once
+// per case whether they may see its reviewers.
+func (h *MatchHandler) toMatchResponses(r *http.Request, items []*matching.Match) ([]MatchResponse, error) {
+	viewerID := middleware.GetUserID(r.Context())
+	canReview := make(map[string]bool)
+	resp := make([]MatchResponse, 0, len(items))
+	for _, item := range items {
+		allowed, ok := canReview[item.MissingID]
+		if !ok {
+			var err error
+			allowed, err = h.service.CanReview(r.Context(), item.MissingID, viewerID)
+			if err != nil {
+				return nil, err
+			}
+			canReview[item.MissingID] = allowed
+		}
+		resp = append(resp, toMatchResponse(item, viewerID, allowed))
+	}
+	return resp, nil
+}
+
 // @Summary      Listar matches de um homeless
-// @Description  Retorna candidatos de match para um homeless
+// @Description  Retorna candidatos de match para um homeless. Revisores e notas das revisões só aparecem para o dono do caso e moderadores.
 // @Tags         matches
 // @Produce      json
 // @Param        id   path      string  true  "Homeless ID"
@@ -67,16 +196,17 @@ func (h *MatchHandler) FindByHomelessID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	resp := make([]MatchResponse, 0, len(items))
-	for _, item := range items {
-		resp = append(resp, toMatchResponse(item))
+	resp, err := h.toMatchResponses(r, items)
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, "failed to list matches")
+		return
 	}
 
 	httputil.JSON(w, http.StatusOK, resp)
 }
 
 // @Summary      Listar matches de um desaparecido
-// @Description  Retorna matches encontrados para um missing
+// @Description  Retorna matches encontrados para um missing. Revisores e notas das revisões só aparecem para o dono do caso e moderadores.
 // @Tags         matches
 // @Produce      json
 // @Param        id   path      string  true  "Missing ID"
@@ -91,47 +221,76 @@ func (h *MatchHandler) FindByMissingID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp := make([]MatchResponse, 0, len(items))
-	for _, item := range items {
-		resp = append(resp, toMatchResponse(item))
+	resp, err := h.toMatchResponses(r, items)
+	if err != nil {
+		httputil.Error(w, http.StatusInternalServerError, "failed to list matches")
+		return
 	}
 
 	httputil.JSON(w, http.StatusOK, resp)
 }
 
-// @Summary      Atualizar status do match
-// @Description  Confirmar ou rejeitar match
+// @Summary      Revisar match
+// @Description  Confirma, rejeita ou reabre um match (somente o dono do caso ou moderadores). Cada decisão fica no histórico. mark_missing_found exige found_at (dd/mm/aaaa); a revisão é salva mesmo quando o caso não pode ser marcado como encontrado, e status_error explica o motivo.
 // @Tags         matches
 // @Accept       json
 // @Produce      json
-// @Param        id    path      string                    true  "Match ID"
-// @Param        body  body      UpdateMatchStatusRequest  true  "Status"
-// @Success      200   {object}  map[string]string
+// @Param        id    path      string              true  "Match ID"
+// @Param        body  body      ReviewMatchRequest  true  "Decisão"
+// @Success      200   {object}  ReviewMatchResponse
 // @Failure      400   {object}  httputil.ErrorResponse
+// @Failure      403   {object}  httputil.ErrorResponse
 // @Failure      404   {object}  httputil.ErrorResponse
+// @Security     BearerAuth
 // @Router       /api/v1/matches/{id} [patch]
-func (h *MatchHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+func (h *MatchHandler) Review(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	var req UpdateMatchStatusRequest
+	var req ReviewMatchRequest
 	if err := httputil.DecodeAndValidate(r, &req); err != nil {
 		httputil.Error(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
-	err := h.service.UpdateStatus(r.Context(), id, matching.MatchStatus(req.Status))
+	foundAt := parseDate(req.FoundAt)
+	if req.FoundAt != "" && foundAt.IsZero() {
+		httputil.Error(w, http.StatusBadRequest, "invalid found_at, use dd/mm/yyyy")
+		return
+	}
+
+	result, err := h.service.Review(r.Context(), id, matching.ReviewInput{
+		ReviewerID:       middleware.GetUserID(r.Context()),
+		Decision:         matching.MatchStatus(req.Status),
+		Notes:            req.Notes,
+		MarkMissingFound: req.MarkMissingFound,
+		FoundAt:          foundAt,
+	})
 	if err != nil {
-		if errors.Is(err, matching.ErrInvalidMatch) {
+		switch {
+		case errors.Is(err, matching.ErrInvalidMatch):
 			httputil.Error(w, http.StatusBadRequest, err.Error())
-			return
-		}
-		if errors.Is(err, matching.ErrMatchNotFound) {
+		case errors.Is(err, matching.ErrForbidden):
+			httputil.Error(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, matching.ErrMatchNotFound):
 			httputil.Error(w, http.StatusNotFound, "match not found")
-			return
+		default:
+			httputil.Error(w, http.StatusInternalServerError, "failed to review match")
 		}
-		httputil.Error(w, http.StatusInternalServerError, "failed to update match")
 		return
 	}
 
-	httputil.JSON(w, http.StatusOK, map[string]string{"status": "updated"})
+	resp := ReviewMatchResponse{
+		MatchResponse: toMatchResponse(result.Match, middleware.GetUserID(r.Context()), true),
+		MissingFound:  result.MissingFound,
+	}
+	if result.StatusError != nil {
+		resp.StatusError = "failed to mark missing as found"
+		if errors.Is(result.StatusError, missing.ErrInvalidMissing) ||
+			errors.Is(result.StatusError, missing.ErrInvalidTransition) ||
+			errors.Is(result.StatusError, missing.ErrTransitionForbidden) {
+			resp.StatusError = result.StatusError.Error()
+		}
+	}
+
+	httputil.JSON(w, http.StatusOK, resp)
 }
@@ -54,6 +54,10 @@ func (h *MetaHandler) ServeMissingMeta(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Crawlers are anonymous, so restricted cases share the blurred copy
+	// or no image at all.
+	image := toMissingResponse(m, "").PhotoURL
+
 	dateStr := ""
 	if !m.DateOfDisappearance.IsZero() {
 		dateStr = m.DateOfDisappearance.Format("02/01/2006")
@@ -77,7 +81,7 @@ func (h *MetaHandler) ServeMissingMeta(w http.ResponseWriter, r *http.Request) {
     <script>window.location.href='/missing/%s';</script>
     <noscript><a href="/missing/%s">Ver perfil de %s</a></noscript>
 </body>
-</html>`, m.Name, dateStr, m.Name, dateStr, m.PhotoURL, m.ID, m.ID, m.ID, m.Name)
+</html>`, m.Name, dateStr, m.Name, dateStr, image, m.ID, m.ID, m.ID, m.Name)
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,22 @@
+package middleware
+
+import "net/http"
+
+// RequireAdmin must run after Auth. It only lets through users whose ID is
+// in adminIDs (configured via ADMIN_USER_IDS).
+func RequireAdmin(adminIDs []string) func(http.Handler) http.Handler {
+	admins := make(map[string]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !admins[GetUserID(r.Context())] {
+				http.Error(w, `{"error":"admin access required"}`, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -43,3 +43,27 @@ func GetUserID(ctx context.Context) string {
 	uid, _ := ctx.Value(UserIDKey).(string)
 	return uid
 }
+
+// OptionalAuth identifies the user like Auth when a valid bearer token is
+// sent and otherwise lets the request through as anonymous, for public
+// routes that show signed-in users more.
+func OptionalAuth(auth user.AuthService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			uid, err := auth.VerifyToken(r.Context(), token)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), UserIDKey, uid)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"slices"
 )
 
 func SecurityHeaders(next http.Handler) http.Handler {
@@ -16,10 +17,12 @@ func SecurityHeaders(next http.Handler) http.Handler {
 	})
 }
 
-func BodyLimit(maxBytes int64) func(http.Handler) http.Handler {
+// BodyLimit caps request bodies at maxBytes. Requests to the exempt paths
+// are left alone; their handlers apply a limit of their own.
+func BodyLimit(maxBytes int64, exempt ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if r.Body != nil {
+			if r.Body != nil && !slices.Contains(exempt, r.URL.Path) {
 				r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
 			}
 			next.ServeHTTP(w, r)
@@ -35,12 +35,14 @@ type CreateMissingRequest struct {
 	Hair                string  `json:"hair" validate:"required"`
 	Skin                string  `json:"skin" validate:"required"`
 	PhotoURL            string  `json:"photo_url,omitempty"`
+	Visibility          string  `json:"visibility,omitempty" enums:"public,registered,blurred"`
 	Lat                 float64 `json:"lat"`
 	Lng                 float64 `json:"lng"`
 	Address             string  `json:"address,omitempty" validate:"omitempty,max=500"`
 	EventReport         string  `json:"event_report,omitempty" validate:"omitempty,max=2000"`
 	TattooDescription   string  `json:"tattoo_description,omitempty" validate:"omitempty,max=500"`
 	ScarDescription     string  `json:"scar_description,omitempty" validate:"omitempty,max=500"`
+	AllowDuplicate      bool    `json:"allow_duplicate,omitempty"`
 }
 
 type UpdateMissingRequest struct {
@@ -55,43 +57,49 @@ type UpdateMissingRequest struct {
 	Hair                string  `json:"hair" validate:"required"`
 	Skin                string  `json:"skin" validate:"required"`
 	PhotoURL            string  `json:"photo_url,omitempty"`
+	Visibility          string  `json:"visibility,omitempty" enums:"public,registered,blurred"`
 	Lat                 float64 `json:"lat"`
 	Lng                 float64 `json:"lng"`
 	Address             string  `json:"address,omitempty" validate:"omitempty,max=500"`
-	Status              string  `json:"status,omitempty"`
 	EventReport         string  `json:"event_report,omitempty" validate:"omitempty,max=2000"`
 	TattooDescription   string  `json:"tattoo_description,omitempty" validate:"omitempty,max=500"`
 	ScarDescription     string  `json:"scar_description,omitempty" validate:"omitempty,max=500"`
 }
 
 type MissingResponse struct {
-	ID                  string  `json:"id"`
-	UserID              string  `json:"user_id"`
-	Name                string  `json:"name"`
-	Nickname            string  `json:"nickname,omitempty"`
-	BirthDate           string  `json:"birth_date,omitempty"`
-	DateOfDisappearance string  `json:"date_of_disappearance,omitempty"`
-	Height              string  `json:"height,omitempty"`
-	Clothes             string  `json:"clothes,omitempty"`
-	Gender              string  `json:"gender"`
-	Eyes                string  `json:"eyes"`
-	Hair                string  `json:"hair"`
-	Skin                string  `json:"skin"`
-	PhotoURL            string  `json:"photo_url,omitempty"`
-	Lat                 float64 `json:"lat"`
-	Lng                 float64 `json:"lng"`
-	Address             string  `json:"address,omitempty"`
-	Status              string  `json:"status"`
-	EventReport         string  `json:"event_report,omitempty"`
-	TattooDescription   string  `json:"tattoo_description,omitempty"`
-	ScarDescription     string  `json:"scar_description,omitempty"`
-	WasChild            bool    `json:"was_child"`
-	Slug                string  `json:"slug"`
-	HasTattoo           bool    `json:"has_tattoo"`
-	HasScar             bool    `json:"has_scar"`
-	Age                 int     `json:"age"`
-	CreatedAt           string  `json:"created_at"`
-	UpdatedAt           string  `json:"updated_at"`
+	ID                  string          `json:"id"`
+	UserID              string          `json:"user_id"`
+	Name                string          `json:"name"`
+	Nickname            string          `json:"nickname,omitempty"`
+	BirthDate           string          `json:"birth_date,omitempty"`
+	DateOfDisappearance string          `json:"date_of_disappearance,omitempty"`
+	Height              string          `json:"height,omitempty"`
+	Clothes             string          `json:"clothes,omitempty"`
+	Gender              string          `json:"gender"`
+	Eyes                string          `json:"eyes"`
+	Hair                string          `json:"hair"`
+	Skin                string          `json:"skin"`
+	PhotoURL            string          `json:"photo_url,omitempty"`
+	Photos              []PhotoResponse `json:"photos,omitempty"`
+	Visibility          string          `json:"visibility"`
+	PhotoRestricted     bool            `json:"photo_restricted,omitempty"`
+	Lat                 float64         `json:"lat"`
+	Lng                 float64         `json:"lng"`
+	Address             string          `json:"address,omitempty"`
+	Status              string          `json:"status"`
+	StatusLabel         string          `json:"status_label"`
+	FoundAt             string          `json:"found_at,omitempty"`
+	FoundAddress        string          `json:"found_address,omitempty"`
+	EventReport         string          `json:"event_report,omitempty"`
+	TattooDescription   string          `json:"tattoo_description,omitempty"`
+	ScarDescription     string          `json:"scar_description,omitempty"`
+	WasChild            bool            `json:"was_child"`
+	Slug                string          `json:"slug"`
+	HasTattoo           bool            `json:"has_tattoo"`
+	HasScar             bool            `json:"has_scar"`
+	Age                 int             `json:"age"`
+	CreatedAt           string          `json:"created_at"`
+	UpdatedAt           string          `json:"updated_at"`
 }
 
 type MissingListResponse struct {
@@ -101,7 +109,9 @@ type MissingListResponse struct {
 
 const dateFormat = "02/01/2006"
 
-func toMissingResponse(m *missing.Missing) MissingResponse {
+// toMissingResponse shows the photos viewerID may see: the blurred copy or
+// none at all for anonymous visitors of restricted cases.
+func toMissingResponse(m *missing.Missing, viewerID string) MissingResponse {
 	resp := MissingResponse{
 		ID:                m.ID,
 		UserID:            m.UserID,
@@ -114,10 +124,14 @@ func toMissingResponse(m *missing.Missing) MissingResponse {
 		Hair:              string(m.Hair),
 		Skin:              string(m.Skin),
 		PhotoURL:          m.PhotoURL,
+		Photos:            toPhotoResponses(m.Photos),
+		Visibility:        string(m.EffectiveVisibility()),
 		Lat:               m.Location.Lat,
 		Lng:               m.Location.Lng,
 		Address:           m.Location.Address,
 		Status:            string(m.Status),
+		StatusLabel:       m.Status.Label(),
+		FoundAddress:      m.FoundLocation.Address,
 		EventReport:       m.EventReport,
 		TattooDescription: m.TattooDescription,
 		ScarDescription:   m.ScarDescription,
@@ -135,6 +149,15 @@ func toMissingResponse(m *missing.Missing) MissingResponse {
 	if !m.DateOfDisappearance.IsZero() {
 		resp.DateOfDisappearance = m.DateOfDisappearance.Format(dateFormat)
 	}
+	if !m.FoundAt.IsZero() {
+		resp.FoundAt = m.FoundAt.Format(dateFormat)
+	}
+	switch m.PhotoAccessFor(viewerID) {
+	case missing.PhotoAccessBlurred:
+		resp.PhotoURL, resp.Photos, resp.PhotoRestricted = m.BlurredPhotoURL, nil, true
+	case missing.PhotoAccessNone:
+		resp.PhotoURL, resp.Photos, resp.PhotoRestricted = "", nil, true
+	}
 	return resp
 }
 
@@ -150,13 +173,14 @@ func parseDate(s string) time.Time {
 }
 
 // @Summary      Cadastrar desaparecido
-// @Description  Registra uma nova pessoa desaparecida
+// @Description  Registra uma nova pessoa desaparecida. Se a foto for muito parecida com a de um caso existente, responde 409 com os possíveis duplicados; reenvie com allow_duplicate para cadastrar mesmo assim.
 // @Tags         missing
 // @Accept       json
 // @Produce      json
 // @Param        body  body      CreateMissingRequest  true  "Dados do desaparecido"
 // @Success      201   {object}  MissingResponse
 // @Failure      400   {object}  httputil.ErrorResponse
+// @Failure      409   {object}  DuplicateConflictResponse
 // @Security     BearerAuth
 // @Router       /api/v1/missing [post]
 func (h *MissingHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -181,14 +205,19 @@ func (h *MissingHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Hair:                missing.HairColor(req.Hair),
 		Skin:                missing.SkinColor(req.Skin),
 		PhotoURL:            req.PhotoURL,
+		Visibility:          missing.Visibility(req.Visibility),
 		Location:            missing.GeoPoint{Lat: req.Lat, Lng: req.Lng, Address: req.Address},
 		EventReport:         httputil.SanitizeString(req.EventReport),
 		TattooDescription:   httputil.SanitizeString(req.TattooDescription),
 		ScarDescription:     httputil.SanitizeString(req.ScarDescription),
+		AllowDuplicate:      req.AllowDuplicate,
 	}
 
 	created, err := h.service.Create(r.Context(), input)
 	if err != nil {
+		if writeDuplicateConflict(w, err) {
+			return
+		}
 		if errors.Is(err, missing.ErrInvalidMissing) {
 			httputil.Error(w, http.StatusBadRequest, err.Error())
 			return
@@ -197,11 +226,11 @@ func (h *MissingHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httputil.JSON(w, http.StatusCreated, toMissingResponse(created))
+	httputil.JSON(w, http.StatusCreated, toMissingResponse(created, userID))
 }
 
 // @Summary      Buscar desaparecido por ID
-// @Description  Retorna dados de um desaparecido
+// @Description  Retorna dados de um desaparecido. Para visitantes anônimos, casos com visibilidade "blurred" (padrão para quem desapareceu criança) trazem a foto desfocada e casos "registered" vêm sem foto.
 // @Tags         missing
 // @Produce      json
 // @Param        id   path      string  true  "ID do desaparecido"
@@ -221,7 +250,7 @@ func (h *MissingHandler) FindByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httputil.JSON(w, http.StatusOK, toMissingResponse(found))
+	httputil.JSON(w, http.StatusOK, toMissingResponse(found, middleware.GetUserID(r.Context())))
 }
 
 // @Summary      Listar desaparecidos
@@ -247,12 +276,13 @@ func (h *MissingHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	viewerID := middleware.GetUserID(r.Context())
 	resp := MissingListResponse{
 		Items:      make([]MissingResponse, 0, len(items)),
 		NextCursor: nextCursor,
 	}
 	for _, item := range items {
-		resp.Items = append(resp.Items, toMissingResponse(item))
+		resp.Items = append(resp.Items, toMissingResponse(item, viewerID))
 	}
 
 	httputil.JSON(w, http.StatusOK, resp)
@@ -293,8 +323,8 @@ func (h *MissingHandler) Update(w http.ResponseWriter, r *http.Request) {
 		Hair:                missing.HairColor(req.Hair),
 		Skin:                missing.SkinColor(req.Skin),
 		PhotoURL:            req.PhotoURL,
+		Visibility:          missing.Visibility(req.Visibility),
 		Location:            missing.GeoPoint{Lat: req.Lat, Lng: req.Lng, Address: req.Address},
-		Status:              missing.Status(req.Status),
 		EventReport:         httputil.SanitizeString(req.EventReport),
 		TattooDescription:   httputil.SanitizeString(req.TattooDescription),
 		ScarDescription:     httputil.SanitizeString(req.ScarDescription),
@@ -313,7 +343,7 @@ func (h *MissingHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httputil.JSON(w, http.StatusOK, toMissingResponse(updated))
+	httputil.JSON(w, http.StatusOK, toMissingResponse(updated, userID))
 }
 
 // @Summary      Deletar desaparecido
@@ -362,9 +392,10 @@ func (h *MissingHandler) FindByUserID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	viewerID := middleware.GetUserID(r.Context())
 	resp := make([]MissingResponse, 0, len(items))
 	for _, item := range items {
-		resp = append(resp, toMissingResponse(item))
+		resp = append(resp, toMissingResponse(item, viewerID))
 	}
 
 	httputil.JSON(w, http.StatusOK, resp)
@@ -392,9 +423,10 @@ func (h *MissingHandler) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	viewerID := middleware.GetUserID(r.Context())
 	resp := make([]MissingResponse, 0, len(items))
 	for _, item := range items {
-		resp = append(resp, toMissingResponse(item))
+		resp = append(resp, toMissingResponse(item, viewerID))
 	}
 
 	httputil.JSON(w, http.StatusOK, resp)
@@ -407,6 +439,13 @@ type StatsResponse struct {
 	ByGender   []GenderStatDTO `json:"by_gender"`
 	ChildCount int64           `json:"child_count"`
 	ByYear     []YearStatDTO   `json:"by_year"`
+	ByStatus   []StatusStatDTO `json:"by_status"`
+}
+
+type StatusStatDTO struct {
+	Status string `json:"status"`
+	Label  string `json:"label"`
+	Count  int64  `json:"count"`
 }
 
 type GenderStatDTO struct {
@@ -420,7 +459,7 @@ type YearStatDTO struct {
 }
 
 // @Summary      Estatísticas de desaparecidos
-// @Description  Retorna totais, por gênero, crianças e por ano (goroutines paralelas)
+// @Description  Retorna totais, por gênero, crianças, por ano e por status (goroutines paralelas)
 // @Tags         missing
 // @Produce      json
 // @Success      200  {object}  StatsResponse
@@ -437,6 +476,7 @@ func (h *MissingHandler) Stats(w http.ResponseWriter, r *http.Request) {
 		ChildCount: stats.ChildCount,
 		ByGender:   make([]GenderStatDTO, 0, len(stats.ByGender)),
 		ByYear:     make([]YearStatDTO, 0, len(stats.ByYear)),
+		ByStatus:   make([]StatusStatDTO, 0, len(stats.ByStatus)),
 	}
 	for _, g := range stats.ByGender {
 		resp.ByGender = append(resp.ByGender, GenderStatDTO{Gender: g.Gender, Count: g.Count})
@@ -444,6 +484,9 @@ func (h *MissingHandler) Stats(w http.ResponseWriter, r *http.Request) {
 	for _, y := range stats.ByYear {
 		resp.ByYear = append(resp.ByYear, YearStatDTO{Year: y.Year, Count: y.Count})
 	}
+	for _, st := range stats.ByStatus {
+		resp.ByStatus = append(resp.ByStatus, StatusStatDTO{Status: string(st.Status), Label: st.Status.Label(), Count: st.Count})
+	}
 
 	httputil.JSON(w, http.StatusOK, resp)
 }
@@ -496,13 +539,22 @@ func (h *MissingHandler) Locations(w http.ResponseWriter, r *http.Request) {
 
 // --- Age Progression ---
 
+type AgeProgressionImageResponse struct {
+	TargetAge int    `json:"target_age,omitempty"`
+	URL       string `json:"url"`
+}
+
 type AgeProgressionResponse struct {
-	MissingID string   `json:"missing_id"`
-	URLs      []string `json:"urls"`
+	MissingID   string                        `json:"missing_id"`
+	Description string                        `json:"description,omitempty"`
+	Images      []AgeProgressionImageResponse `json:"images"`
+	URLs        []string                      `json:"urls"`
+	Generator   string                        `json:"generator,omitempty"`
+	GeneratedAt string                        `json:"generated_at,omitempty"`
 }
 
 // @Summary      Obter projeções de idade
-// @Description  Retorna URLs das imagens de age progression geradas por IA
+// @Description  Retorna a descrição facial gerada por IA e as imagens de age progression com a idade projetada de cada uma
 // @Tags         missing
 // @Produce      json
 // @Param        id  path  string  true  "Missing ID"
@@ -522,68 +574,91 @@ func (h *MissingHandler) GetAgeProgression(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	urls := m.AgeProgressionURLs
-	if urls == nil {
-		urls = []string{}
+	resp := AgeProgressionResponse{
+		MissingID: id,
+		Images:    []AgeProgressionImageResponse{},
+		URLs:      []string{},
+	}
+	if p := m.AgeProgression; p != nil {
+		resp.Description = p.Description
+		resp.Generator = p.Generator
+		if !p.GeneratedAt.IsZero() {
+			resp.GeneratedAt = p.GeneratedAt.Format("2006-01-02T15:04:05Z")
+		}
+		// Projections are derived from the photo and withheld along with it.
+		if m.PhotoAccessFor(middleware.GetUserID(r.Context())) != missing.PhotoAccessFull {
+			p = &missing.AgeProgression{}
+		}
+		for _, img := range p.Images {
+			resp.Images = append(resp.Images, AgeProgressionImageResponse{TargetAge: img.Age, URL: img.URL})
+			resp.URLs = append(resp.URLs, img.URL)
+		}
 	}
 
-	httputil.JSON(w, http.StatusOK, AgeProgressionResponse{
-		MissingID: id,
-		URLs:      urls,
-	})
+	httputil.JSON(w, http.StatusOK, resp)
 }
 
 type UpdateStatusRequest struct {
-	Status string `json:"status" validate:"required"`
+	Status  string  `json:"status" validate:"required" enums:"disappeared,under_investigation,found_alive,found_deceased,closed_by_family,archived"`
+	FoundAt string  `json:"found_at,omitempty"`
+	Lat     float64 `json:"lat,omitempty"`
+	Lng     float64 `json:"lng,omitempty"`
+	Address string  `json:"address,omitempty" validate:"omitempty,max=500"`
+	Note    string  `json:"note,omitempty" validate:"omitempty,max=1000"`
 }
 
 // @Summary      Alterar status do desaparecido
-// @Description  Marca como encontrado ou reativa busca
+// @Description  Move o caso pelo ciclo de vida. Encontrado com vida ou sem vida exige found_at (dd/mm/aaaa) e o local; encerrar pela família ou arquivar exige note. A família encerra o caso; só moderadores arquivam ou reabrem casos arquivados.
 // @Tags         missing
 // @Accept       json
 // @Produce      json
 // @Param        id    path      string               true  "Missing ID"
 // @Param        body  body      UpdateStatusRequest   true  "Status"
-// @Success      200   {object}  map[string]string
+// @Success      200   {object}  MissingResponse
 // @Failure      400   {object}  httputil.ErrorResponse
+// @Failure      403   {object}  httputil.ErrorResponse
 // @Failure      404   {object}  httputil.ErrorResponse
+// @Failure      409   {object}  httputil.ErrorResponse
+// @Security     BearerAuth
 // @Router       /api/v1/missing/{id}/status [patch]
 func (h *MissingHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
+	userID := middleware.GetUserID(r.Context())
 
 	var req UpdateStatusRequest
 	if err := httputil.DecodeAndValidate(r, &req); err != nil {
-		httputil.Error(w, http.StatusBadRequest, "invalid request body")
+		httputil.Error(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	status := missing.Status(req.Status)
-	if !status.IsValid() {
-		httputil.Error(w, http.StatusBadRequest, "invalid status, use 'disappeared' or 'found'")
+	foundAt := parseDate(req.FoundAt)
+	if req.FoundAt != "" && foundAt.IsZero() {
+		httputil.Error(w, http.StatusBadRequest, "invalid found_at, use dd/mm/yyyy")
 		return
 	}
 
-	existing, err := h.service.FindByID(r.Context(), id)
+	updated, err := h.service.ChangeStatus(r.Context(), id, missing.StatusChangeInput{
+		To:            missing.Status(req.Status),
+		ActorID:       userID,
+		FoundAt:       foundAt,
+		FoundLocation: missing.GeoPoint{Lat: req.Lat, Lng: req.Lng, Address: req.Address},
+		Note:          req.Note,
+	})
 	if err != nil {
-		if errors.Is(err, missing.ErrMissingNotFound) {
+		switch {
+		case errors.Is(err, missing.ErrMissingNotFound):
 			httputil.Error(w, http.StatusNotFound, "missing not found")
-			return
+		case errors.Is(err, missing.ErrInvalidMissing):
+			httputil.Error(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, missing.ErrTransitionForbidden):
+			httputil.Error(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, missing.ErrInvalidTransition):
+			httputil.Error(w, http.StatusConflict, err.Error())
+		default:
+			httputil.Error(w, http.StatusInternalServerError, "failed to update status")
 		}
-		httputil.Error(w, http.StatusInternalServerError, "failed to find missing")
-		return
-	}
-
-	userID := middleware.GetUserID(r.Context())
-	if existing.UserID != userID {
-		httputil.Error(w, http.StatusForbidden, "only the owner can change status")
-		return
-	}
-
-	existing.Status = status
-	if err := h.service.UpdateEntity(r.Context(), existing); err != nil {
-		httputil.Error(w, http.StatusInternalServerError, "failed to update status")
 		return
 	}
 
-	httputil.JSON(w, http.StatusOK, map[string]string{"status": string(status)})
+	httputil.JSON(w, http.StatusOK, toMissingResponse(updated, userID))
 }
@@ -0,0 +1,360 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/l3co/traceo-api/internal/domain/homeless"
+	"github.com/l3co/traceo-api/internal/domain/missing"
+	"github.com/l3co/traceo-api/internal/domain/shared"
+	"github.com/l3co/traceo-api/internal/handler/middleware"
+	"github.com/l3co/traceo-api/pkg/httputil"
+)
+
+// --- DTOs ---
+
+type AddPhotoRequest struct {
+	URL          string `json:"url" validate:"required,url,max=2048"`
+	TakenAt      string `json:"taken_at,omitempty"`
+	AgeAtPhoto   int    `json:"age_at_photo,omitempty" validate:"min=0,max=130"`
+	Primary      bool   `json:"primary,omitempty"`
+	FaceDetected bool   `json:"face_detected,omitempty"`
+}
+
+// UpdatePhotoRequest changes only the fields present in the body.
+type UpdatePhotoRequest struct {
+	TakenAt      *string `json:"taken_at,omitempty"`
+	AgeAtPhoto   *int    `json:"age_at_photo,omitempty" validate:"omitempty,min=0,max=130"`
+	Primary      *bool   `json:"primary,omitempty"`
+	FaceDetected *bool   `json:"face_detected,omitempty"`
+}
+
+type PhotoResponse struct {
+	ID           string `json:"id"`
+	URL          string `json:"url"`
+	TakenAt      string `json:"taken_at,omitempty"`
+	AgeAtPhoto   int    `json:"age_at_photo,omitempty"`
+	Primary      bool   `json:"primary"`
+	FaceDetected bool   `json:"face_detected"`
+	AddedAt      string `json:"added_at,omitempty"`
+}
+
+func toPhotoResponse(p *shared.Photo) PhotoResponse {
+	resp := PhotoResponse{
+		ID:           p.ID,
+		URL:          p.URL,
+		AgeAtPhoto:   p.AgeAtPhoto,
+		Primary:      p.Primary,
+		FaceDetected: p.FaceDetected,
+	}
+	if !p.TakenAt.IsZero() {
+		resp.TakenAt = p.TakenAt.Format(dateFormat)
+	}
+	if !p.AddedAt.IsZero() {
+		resp.AddedAt = p.AddedAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+func toPhotoResponses(photos shared.Photos) []PhotoResponse {
+	resp := make([]PhotoResponse, 0, len(photos))
+	for i := range photos {
+		resp = append(resp, toPhotoResponse(&photos[i]))
+	}
+	return resp
+}
+
+type DuplicateResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	Distance int    `json:"distance"`
+}
+
+// DuplicateConflictResponse is an httputil.ErrorResponse listing the
+// existing records whose photo resembles the submitted one.
+type DuplicateConflictResponse struct {
+	Error      string              `json:"error"`
+	Message    string              `json:"message"`
+	Status     int                 `json:"status"`
+	Duplicates []DuplicateResponse `json:"duplicates"`
+}
+
+// writeDuplicateConflict answers 409 when err is a *shared.DuplicateError and
+// reports whether it did.
+func writeDuplicateConflict(w http.ResponseWriter, err error) bool {
+	var dupErr *shared.DuplicateError
+	if !errors.As(err, &dupErr) {
+		return false
+	}
+
+	resp := DuplicateConflictResponse{
+		Error:      http.StatusText(http.StatusConflict),
+		Message:    "photo resembles an existing record, resend with allow_duplicate to register anyway",
+		Status:     http.StatusConflict,
+		Duplicates: make([]DuplicateResponse, 0, len(dupErr.Duplicates)),
+	}
+	for _, d := range dupErr.Duplicates {
+		resp.Duplicates = append(resp.Duplicates, DuplicateResponse{ID: d.ID, Name: d.Name, Slug: d.Slug, Distance: d.Distance})
+	}
+	httputil.JSON(w, http.StatusConflict, resp)
+	return true
+}
+
+func (req AddPhotoRequest) toPhoto() shared.Photo {
+	return shared.Photo{
+		URL:          req.URL,
+		TakenAt:      parseDate(req.TakenAt),
+		AgeAtPhoto:   req.AgeAtPhoto,
+		Primary:      req.Primary,
+		FaceDetected: req.FaceDetected,
+	}
+}
+
+func (req UpdatePhotoRequest) toUpdate() shared.PhotoUpdate {
+	u := shared.PhotoUpdate{
+		AgeAtPhoto:   req.AgeAtPhoto,
+		Primary:      req.Primary,
+		FaceDetected: req.FaceDetected,
+	}
+	if req.TakenAt != nil {
+		t := parseDate(*req.TakenAt)
+		u.TakenAt = &t
+	}
+	return u
+}
+
+// --- Missing ---
+
+// @Summary      Listar fotos do desaparecido
+// @Description  Retorna as fotos de um desaparecido; a principal vem marcada com primary. Visitantes anônimos recebem uma lista vazia quando as fotos do caso são restritas.
+// @Tags         missing
+// @Produce      json
+// @Param        id   path      string  true  "ID do desaparecido"
+// @Success      200  {array}   PhotoResponse
+// @Failure      404  {object}  httputil.ErrorResponse
+// @Router       /api/v1/missing/{id}/photos [get]
+func (h *MissingHandler) ListPhotos(w http.ResponseWriter, r *http.Request) {
+	found, err := h.service.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeMissingPhotoError(w, err)
+		return
+	}
+	if found.PhotoAccessFor(middleware.GetUserID(r.Context())) != missing.PhotoAccessFull {
+		httputil.JSON(w, http.StatusOK, []PhotoResponse{})
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, toPhotoResponses(found.Photos))
+}
+
+// @Summary      Adicionar foto ao desaparecido
+// @Description  Adiciona uma foto (somente o dono). A primeira foto, ou uma enviada com primary, vira a principal.
+// @Tags         missing
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string           true  "ID do desaparecido"
+// @Param        body  body      AddPhotoRequest  true  "Foto"
+// @Success      201   {object}  PhotoResponse
+// @Failure      400   {object}  httputil.ErrorResponse
+// @Failure      403   {object}  httputil.ErrorResponse
+// @Failure      404   {object}  httputil.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/missing/{id}/photos [post]
+func (h *MissingHandler) AddPhoto(w http.ResponseWriter, r *http.Request) {
+	var req AddPhotoRequest
+	if err := httputil.DecodeAndValidate(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	p, err := h.service.AddPhoto(r.Context(), chi.URLParam(r, "id"), middleware.GetUserID(r.Context()), req.toPhoto())
+	if err != nil {
+		writeMissingPhotoError(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusCreated, toPhotoResponse(p))
+}
+
+// @Summary      Atualizar foto do desaparecido
+// @Description  Altera data, idade, detecção de rosto ou torna a foto principal (somente o dono)
+// @Tags         missing
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string              true  "ID do desaparecido"
+// @Param        photoId  path      string              true  "ID da foto"
+// @Param        body     body      UpdatePhotoRequest  true  "Campos alterados"
+// @Success      200      {object}  PhotoResponse
+// @Failure      400      {object}  httputil.ErrorResponse
+// @Failure      403      {object}  httputil.ErrorResponse
+// @Failure      404      {object}  httputil.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/missing/{id}/photos/{photoId} [patch]
+func (h *MissingHandler) UpdatePhoto(w http.ResponseWriter, r *http.Request) {
+	var req UpdatePhotoRequest
+	if err := httputil.DecodeAndValidate(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	p, err := h.service.UpdatePhoto(r.Context(), chi.URLParam(r, "id"), middleware.GetUserID(r.Context()), chi.URLParam(r, "photoId"), req.toUpdate())
+	if err != nil {
+		writeMissingPhotoError(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, toPhotoResponse(p))
+}
+
+// @Summary      Remover foto do desaparecido
+// @Description  Remove uma foto (somente o dono). Se era a principal, a próxima foto assume.
+// @Tags         missing
+// @Param        id       path  string  true  "ID do desaparecido"
+// @Param        photoId  path  string  true  "ID da foto"
+// @Success      204      "Sem conteúdo"
+// @Failure      403      {object}  httputil.ErrorResponse
+// @Failure      404      {object}  httputil.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/missing/{id}/photos/{photoId} [delete]
+func (h *MissingHandler) DeletePhoto(w http.ResponseWriter, r *http.Request) {
+	err := h.service.RemovePhoto(r.Context(), chi.URLParam(r, "id"), middleware.GetUserID(r.Context()), chi.URLParam(r, "photoId"))
+	if err != nil {
+		writeMissingPhotoError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeMissingPhotoError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, missing.ErrMissingNotFound):
+		httputil.Error(w, http.StatusNotFound, "missing person not found")
+	case errors.Is(err, shared.ErrPhotoNotFound):
+		httputil.Error(w, http.StatusNotFound, "photo not found")
+	case errors.Is(err, shared.ErrInvalidPhoto):
+		httputil.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, missing.ErrInvalidMissing):
+		httputil.Error(w, http.StatusForbidden, err.Error())
+	default:
+		httputil.Error(w, http.StatusInternalServerError, "failed to update photos")
+	}
+}
+
+// --- Homeless ---
+
+// @Summary      Listar fotos do morador de rua
+// @Description  Retorna as fotos de um registro; a principal vem marcada com primary
+// @Tags         homeless
+// @Produce      json
+// @Param        id   path      string  true  "ID"
+// @Success      200  {array}   PhotoResponse
+// @Failure      404  {object}  httputil.ErrorResponse
+// @Router       /api/v1/homeless/{id}/photos [get]
+func (h *HomelessHandler) ListPhotos(w http.ResponseWriter, r *http.Request) {
+	found, err := h.service.FindByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeHomelessPhotoError(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, toPhotoResponses(found.Photos))
+}
+
+// @Summary      Adicionar foto ao morador de rua
+// @Description  Adiciona uma foto (somente moderadores). A primeira foto, ou uma enviada com primary, vira a principal.
+// @Tags         homeless
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string           true  "ID"
+// @Param        body  body      AddPhotoRequest  true  "Foto"
+// @Success      201   {object}  PhotoResponse
+// @Failure      400   {object}  httputil.ErrorResponse
+// @Failure      403   {object}  httputil.ErrorResponse
+// @Failure      404   {object}  httputil.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/homeless/{id}/photos [post]
+func (h *HomelessHandler) AddPhoto(w http.ResponseWriter, r *http.Request) {
+	var req AddPhotoRequest
+	if err := httputil.DecodeAndValidate(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	p, err := h.service.AddPhoto(r.Context(), chi.URLParam(r, "id"), middleware.GetUserID(r.Context()), req.toPhoto())
+	if err != nil {
+		writeHomelessPhotoError(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusCreated, toPhotoResponse(p))
+}
+
+// @Summary      Atualizar foto do morador de rua
+// @Description  Altera data, idade, detecção de rosto ou torna a foto principal (somente moderadores)
+// @Tags         homeless
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string              true  "ID"
+// @Param        photoId  path      string              true  "ID da foto"
+// @Param        body     body      UpdatePhotoRequest  true  "Campos alterados"
+// @Success      200      {object}  PhotoResponse
+// @Failure      400      {object}  httputil.ErrorResponse
+// @Failure      403      {object}  httputil.ErrorResponse
+// @Failure      404      {object}  httputil.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/homeless/{id}/photos/{photoId} [patch]
+func (h *HomelessHandler) UpdatePhoto(w http.ResponseWriter, r *http.Request) {
+	var req UpdatePhotoRequest
+	if err := httputil.DecodeAndValidate(r, &req); err != nil {
+		httputil.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	p, err := h.service.UpdatePhoto(r.Context(), chi.URLParam(r, "id"), middleware.GetUserID(r.Context()), chi.URLParam(r, "photoId"), req.toUpdate())
+	if err != nil {
+		writeHomelessPhotoError(w, err)
+		return
+	}
+
+	httputil.JSON(w, http.StatusOK, toPhotoResponse(p))
+}
+
+// @Summary      Remover foto do morador de rua
+// @Description  Remove uma foto (somente moderadores). Se era a principal, a próxima foto assume.
+// @Tags         homeless
+// @Param        id       path  string  true  "ID"
+// @Param        photoId  path  string  true  "ID da foto"
+// @Success      204      "Sem conteúdo"
+// @Failure      403      {object}  httputil.ErrorResponse
+// @Failure      404      {object}  httputil.ErrorResponse
+// @Security     BearerAuth
+// @Router       /api/v1/homeless/{id}/photos/{photoId} [delete]
+func (h *HomelessHandler) DeletePhoto(w http.ResponseWriter, r *http.Request) {
+	err := h.service.RemovePhoto(r.Context(), chi.URLParam(r, "id"), middleware.GetUserID(r.Context()), chi.URLParam(r, "photoId"))
+	if err != nil {
+		writeHomelessPhotoError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeHomelessPhotoError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, homeless.ErrHomelessNotFound):
+		httputil.Error(w, http.StatusNotFound, "homeless not found")
+	case errors.Is(err, shared.ErrPhotoNotFound):
+		httputil.Error(w, http.StatusNotFound, "photo not found")
+	case errors.Is(err, shared.ErrInvalidPhoto), errors.Is(err, homeless.ErrInvalidHomeless):
+		httputil.Error(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, homeless.ErrForbidden):
+		httputil.Error(w, http.StatusForbidden, err.Error())
+	default:
+		httputil.Error(w, http.StatusInternalServerError, "failed to update photos")
+	}
+}
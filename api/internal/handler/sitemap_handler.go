@@ -53,6 +53,9 @@ func (h *SitemapHandler) Serve(w http.ResponseWriter, r *http.Request) {
 
 	missingList, _, _ := h.missingService.List(r.Context(), missing.ListOptions{PageSize: 500})
 	for _, m := range missingList {
+		if m.EffectiveVisibility() == missing.VisibilityRegistered {
+			continue
+		}
 		lastMod := m.UpdatedAt.Format("2006-01-02")
 		urls = append(urls, sitemapURL{
 			Loc:     "https://traceo.me/missing/" + m.ID,
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/l3co/traceo-api/internal/domain/missing"
+	"github.com/l3co/traceo-api/internal/domain/timeline"
+	"github.com/l3co/traceo-api/internal/handler/middleware"
+	"github.com/l3co/traceo-api/pkg/httputil"
+)
+
+type TimelineHandler struct {
+	service        *timeline.Service
+	missingService *missing.Service
+}
+
+func NewTimelineHandler(service *timeline.Service, missingService *missing.Service) *TimelineHandler {
+	return &TimelineHandler{service: service, missingService: missingService}
+}
+
+// --- DTOs ---
+
+type TimelineEventResponse struct {
+	ID         string            `json:"id"`
+	Type       string            `json:"type"`
+	OccurredAt string            `json:"occurred_at"`
+	Details    map[string]string `json:"details,omitempty"`
+}
+
+type TimelineResponse struct {
+	MissingID  string                  `json:"missing_id"`
+	Items      []TimelineEventResponse `json:"items"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+}
+
+// @Summary      Linha do tempo do caso
+// @Description  Retorna, em ordem cronológica, os eventos do caso: cadastro, troca e inclusão de fotos, avistamentos, matches propostos e revisados, mudanças de status e projeções de idade geradas (cursor-based). Quem não é dono do caso nem moderador não vê matches nem observações e endereços das mudanças de status
+// @Tags         missing
+// @Produce      json
+// @Param        id     path      string  true   "ID do desaparecido"
+// @Param        type   query     string  false  "Tipos de evento separados por vírgula"  Enums(case_created, photo_changed, photo_added, sighting_reported, match_proposed, match_reviewed, status_changed, age_progression_generated)
+// @Param        size   query     int     false  "Tamanho da página"  default(50)
+// @Param        after  query     string  false  "Cursor para próxima página"
+// @Success      200    {object}  TimelineResponse
+// @Failure      400    {object}  httputil.ErrorResponse
+// @Failure      404    {object}  httputil.ErrorResponse
+// @Router       /api/v1/missing/{id}/timeline [get]
+func (h *TimelineHandler) List(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+
+	opts := timeline.ListOptions{
+		PageSize: size,
+		After:    r.URL.Query().Get("after"),
+	}
+	for _, param := range r.URL.Query()["type"] {
+		for _, t := range strings.Split(param, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				opts.Types = append(opts.Types, timeline.Type(t))
+			}
+		}
+	}
+
+	m, err := h.missingService.FindByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, missing.ErrMissingNotFound) {
+			httputil.Error(w, http.StatusNotFound, "missing not found")
+			return
+		}
+		httputil.Error(w, http.StatusInternalServerError, "failed to find missing")
+		return
+	}
+
+	list := h.service.ListPublic
+	if h.missingService.CanManage(m, middleware.GetUserID(r.Context())) {
+		list = h.service.List
+	}
+	events, nextCursor, err := list(r.Context(), id, opts)
+	if err != nil {
+		if errors.Is(err, timeline.ErrInvalidEvent) {
+			httputil.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		httputil.Error(w, http.StatusInternalServerError, "failed to list timeline")
+		return
+	}
+
+	resp := TimelineResponse{
+		MissingID:  id,
+		Items:      make([]TimelineEventResponse, 0, len(events)),
+		NextCursor: nextCursor,
+	}
+	for _, e := range events {
+		resp.Items = append(resp.Items, TimelineEventResponse{
+			ID:         e.ID,
+			Type:       string(e.Type),
+			OccurredAt: e.OccurredAt.Format(time.RFC3339),
+			Details:    e.Details,
+		})
+	}
+
+	httputil.JSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/l3co/traceo-api/internal/domain/media"
+	"github.com/l3co/traceo-api/pkg/httputil"
+)
+
+type UploadHandler struct {
+	uploader *media.Uploader
+	maxBytes int64
+}
+
+// NewUploadHandler accepts a nil uploader for deployments without media
+// storage; uploads then fail with 503.
+func NewUploadHandler(uploader *media.Uploader, maxBytes int64) *UploadHandler {
+	return &UploadHandler{uploader: uploader, maxBytes: maxBytes}
+}
+
+// --- DTOs ---
+
+type UploadPhotoResponse struct {
+	ID           string `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	MediumURL    string `json:"medium_url"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+// @Summary      Enviar foto
+// @Description  Recebe uma imagem (JPEG, PNG ou GIF) no campo "file", remove metadados (EXIF/GPS), corrige a orientação e gera as versões miniatura, média e completa. Use a URL retornada em photo_url.
+// @Tags         photos
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file  formData  file  true  "Imagem"
+// @Success      201   {object}  UploadPhotoResponse
+// @Failure      400   {object}  httputil.ErrorResponse
+// @Failure      413   {object}  httputil.ErrorResponse
+// @Failure      415   {object}  httputil.ErrorResponse
+// @Failure      503   {object}  httputil.ErrorResponse
+// @Router       /api/v1/photos [post]
+func (h *UploadHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	if h.uploader == nil {
+		httputil.Error(w, http.StatusServiceUnavailable, "photo uploads are not configured")
+		return
+	}
+
+	// Multipart overhead on top of the file itself.
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBytes+1<<10)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			httputil.Error(w, http.StatusRequestEntityTooLarge, "photo is too large")
+			return
+		}
+		httputil.Error(w, http.StatusBadRequest, "multipart field \"file\" is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.maxBytes+1))
+	if err != nil {
+		httputil.Error(w, http.StatusBadRequest, "failed to read photo")
+		return
+	}
+	if int64(len(data)) > h.maxBytes {
+		httputil.Error(w, http.StatusRequestEntityTooLarge, "photo is too large")
+		return
+	}
+
+	photo, err := h.uploader.Upload(r.Context(), data)
+	if err != nil {
+		switch {
+		case errors.Is(err, media.ErrUnsupportedImage):
+			httputil.Error(w, http.StatusUnsupportedMediaType, "file must be a JPEG, PNG or GIF image")
+		case errors.Is(err, media.ErrImageTooLarge):
+			httputil.Error(w, http.StatusRequestEntityTooLarge, "photo dimensions are too large")
+		default:
+			httputil.Error(w, http.StatusInternalServerError, "failed to store photo")
+		}
+		return
+	}
+
+	httputil.JSON(w, http.StatusCreated, UploadPhotoResponse{
+		ID:           photo.ID,
+		URL:          photo.URL,
+		ThumbnailURL: photo.Variants[media.VariantThumbnail],
+		MediumURL:    photo.Variants[media.VariantMedium],
+		Width:        photo.Width,
+		Height:       photo.Height,
+	})
+}
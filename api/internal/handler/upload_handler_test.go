@@ -0,0 +1,92 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/media"
+	"github.com/l3co/traceo-api/internal/handler"
+	"github.com/l3co/traceo-api/internal/infrastructure/imaging"
+	"github.com/l3co/traceo-api/internal/infrastructure/storage"
+)
+
+func multipartUpload(t *testing.T, data []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "photo.png")
+	require.NoError(t, err)
+	_, err = fw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/photos", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func pngBytes(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+func TestUpload_StoresVariants(t *testing.T) {
+	dir := t.TempDir()
+	uploader := media.NewUploader(storage.NewLocalStorage(dir, "http://localhost/media"), imaging.NewProcessor())
+	h := handler.NewUploadHandler(uploader, 1<<20)
+
+	rec := httptest.NewRecorder()
+	h.Upload(rec, multipartUpload(t, pngBytes(t, 64, 32)))
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var resp handler.UploadPhotoResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, "http://localhost/media/photos/"+resp.ID+"/full.jpg", resp.URL)
+	assert.Equal(t, "http://localhost/media/photos/"+resp.ID+"/thumbnail.jpg", resp.ThumbnailURL)
+	assert.Equal(t, 64, resp.Width)
+
+	_, err := os.Stat(filepath.Join(dir, "photos", resp.ID, "medium.jpg"))
+	assert.NoError(t, err)
+}
+
+func TestUpload_RejectsNonImage(t *testing.T) {
+	uploader := media.NewUploader(storage.NewLocalStorage(t.TempDir(), "http://localhost/media"), imaging.NewProcessor())
+	h := handler.NewUploadHandler(uploader, 1<<20)
+
+	rec := httptest.NewRecorder()
+	h.Upload(rec, multipartUpload(t, []byte("<svg onload=alert(1)>")))
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+}
+
+func TestUpload_TooLarge(t *testing.T) {
+	uploader := media.NewUploader(storage.NewLocalStorage(t.TempDir(), "http://localhost/media"), imaging.NewProcessor())
+	h := handler.NewUploadHandler(uploader, 100)
+
+	rec := httptest.NewRecorder()
+	h.Upload(rec, multipartUpload(t, make([]byte, 4096)))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestUpload_NotConfigured(t *testing.T) {
+	h := handler.NewUploadHandler(nil, 1<<20)
+
+	rec := httptest.NewRecorder()
+	h.Upload(rec, multipartUpload(t, pngBytes(t, 4, 4)))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -0,0 +1,87 @@
+package aging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/internal/infrastructure/imaging"
+	"github.com/l3co/traceo-api/pkg/safefetch"
+)
+
+// PlaceholderAger implements matching.FaceAger without a generative model.
+// It returns the source photo toned towards sepia, more strongly the more
+// years are added, so that storage, the API and the frontend can be built
+// and tested before a real generator is plugged in. It does not age the
+// face.
+type PlaceholderAger struct {
+	fetcher *safefetch.Fetcher
+}
+
+func NewPlaceholderAger(fetcher *safefetch.Fetcher) *PlaceholderAger {
+	return &PlaceholderAger{fetcher: fetcher}
+}
+
+func (a *PlaceholderAger) Version() string {
+	return "placeholder/v1"
+}
+
+func (a *PlaceholderAger) AgeFace(ctx context.Context, photoURL string, req matching.AgeRequest) (*matching.AgedFace, error) {
+	src, err := a.fetch(ctx, photoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Full sepia at 40 years or more.
+	strength := float64(req.TargetAge-req.FromAge) / 40
+	strength = min(max(strength, 0.1), 1)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, sepia(src, strength), &jpeg.Options{Quality: 85}); err != nil {
+		return nil, fmt.Errorf("encoding aged face: %w", err)
+	}
+	return &matching.AgedFace{Data: buf.Bytes(), ContentType: "image/jpeg"}, nil
+}
+
+func (a *PlaceholderAger) fetch(ctx context.Context, url string) (image.Image, error) {
+	data, _, err := a.fetcher.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decoding image: %w", err)
+	}
+	return img, nil
+}
+
+// sepia blends each pixel with its sepia tone by strength (0..1).
+func sepia(src image.Image, strength float64) image.Image {
+	b := src.Bounds()
+	dst := image.NewRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r16, g16, b16, a16 := src.At(x, y).RGBA()
+			r, g, bl := float64(r16>>8), float64(g16>>8), float64(b16>>8)
+			sr := 0.393*r + 0.769*g + 0.189*bl
+			sg := 0.349*r + 0.686*g + 0.168*bl
+			sb := 0.272*r + 0.534*g + 0.131*bl
+			dst.Set(x, y, color.RGBA{
+				R: blend(r, sr, strength),
+				G: blend(g, sg, strength),
+				B: blend(bl, sb, strength),
+				A: uint8(a16 >> 8),
+			})
+		}
+	}
+	return dst
+}
+
+func blend(from, to, t float64) uint8 {
+	return uint8(min(from+(to-from)*t, 255))
+}
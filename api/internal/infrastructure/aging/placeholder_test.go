@@ -0,0 +1,81 @@
+package aging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/internal/domain/media"
+	"github.com/l3co/traceo-api/internal/infrastructure/aging"
+	"github.com/l3co/traceo-api/pkg/safefetch"
+)
+
+func TestPlaceholderAger_AgeFace(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 24, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 24; x++ {
+			src.Set(x, y, color.RGBA{40, 90, 200, 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, src))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	face, err := aging.NewPlaceholderAger(safefetch.New(safefetch.Config{AllowPrivate: true})).AgeFace(context.Background(), srv.URL, matching.AgeRequest{FromAge: 10, TargetAge: 50})
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", face.ContentType)
+
+	out, err := jpeg.Decode(bytes.NewReader(face.Data))
+	require.NoError(t, err)
+	assert.Equal(t, src.Bounds(), out.Bounds())
+	r, _, b, _ := out.At(12, 16).RGBA()
+	assert.Greater(t, r, b, "toned towards sepia")
+}
+
+func TestPlaceholderAger_DownloadError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	_, err := aging.NewPlaceholderAger(safefetch.New(safefetch.Config{AllowPrivate: true})).AgeFace(context.Background(), srv.URL, matching.AgeRequest{TargetAge: 30})
+	assert.Error(t, err)
+}
+
+func TestPlaceholderAger_RejectsOversizedImage(t *testing.T) {
+	// A PNG header claiming 20000x20000 pixels, which would take 1.6GB to
+	// decode.
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], 20000)
+	binary.BigEndian.PutUint32(ihdr[8:], 20000)
+	ihdr[12], ihdr[13] = 8, 2
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(13))
+	buf.Write(ihdr)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(ihdr))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	_, err := aging.NewPlaceholderAger(safefetch.New(safefetch.Config{AllowPrivate: true})).AgeFace(context.Background(), srv.URL, matching.AgeRequest{TargetAge: 30})
+	assert.ErrorIs(t, err, media.ErrImageTooLarge)
+}
@@ -0,0 +1,117 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// ErrNoInteraction is returned by a replaying Cassette for a request it has
+// no recording of.
+var ErrNoInteraction = errors.New("cassette: no recorded interaction")
+
+// Interaction is one recorded request/response pair. Photos are stored by
+// hash only, so cassettes stay small and carry no images.
+type Interaction struct {
+	Key       string   `json:"key"`
+	PromptRef string   `json:"prompt_ref"`
+	Prompt    string   `json:"prompt"`
+	Images    []string `json:"images"`
+	Response  string   `json:"response"`
+}
+
+// Cassette is a Transport that replays recorded interactions. A recording
+// cassette forwards misses to the wrapped transport and keeps the replies,
+// so that a test run against the real API once can be replayed offline
+// afterwards. The key covers the rendered prompt, so editing a template
+// invalidates its recordings.
+type Cassette struct {
+	inner Transport
+
+	mu           sync.Mutex
+	interactions map[string]Interaction
+}
+
+// NewRecordingCassette returns an empty cassette that records through inner.
+func NewRecordingCassette(inner Transport) *Cassette {
+	return &Cassette{inner: inner, interactions: map[string]Interaction{}}
+}
+
+// LoadCassette reads a cassette written by Save. It only replays.
+func LoadCassette(r io.Reader) (*Cassette, error) {
+	var list []Interaction
+	if err := json.NewDecoder(r).Decode(&list); err != nil {
+		return nil, fmt.Errorf("decoding cassette: %w", err)
+	}
+
+	c := &Cassette{interactions: make(map[string]Interaction, len(list))}
+	for _, it := range list {
+		c.interactions[it.Key] = it
+	}
+	return c, nil
+}
+
+func (c *Cassette) Generate(ctx context.Context, req GenerateRequest) (string, error) {
+	it := newInteraction(req)
+
+	c.mu.Lock()
+	recorded, ok := c.interactions[it.Key]
+	c.mu.Unlock()
+	if ok {
+		return recorded.Response, nil
+	}
+	if c.inner == nil {
+		return "", fmt.Errorf("%w for %s", ErrNoInteraction, req.PromptRef)
+	}
+
+	text, err := c.inner.Generate(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	it.Response = text
+	c.mu.Lock()
+	c.interactions[it.Key] = it
+	c.mu.Unlock()
+	return text, nil
+}
+
+// Save writes the interactions sorted by key, so re-recording the same
+// requests yields a stable diff.
+func (c *Cassette) Save(w io.Writer) error {
+	c.mu.Lock()
+	list := make([]Interaction, 0, len(c.interactions))
+	for _, it := range c.interactions {
+		list = append(list, it)
+	}
+	c.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(list)
+}
+
+func newInteraction(req GenerateRequest) Interaction {
+	it := Interaction{PromptRef: req.PromptRef, Prompt: req.Prompt}
+
+	h := sha256.New()
+	h.Write([]byte(req.PromptRef))
+	h.Write([]byte{0})
+	h.Write([]byte(req.Prompt))
+	for _, img := range req.Images {
+		sum := sha256.Sum256(img.Data)
+		it.Images = append(it.Images, hex.EncodeToString(sum[:]))
+		h.Write([]byte{0})
+		h.Write(sum[:])
+	}
+	it.Key = hex.EncodeToString(h.Sum(nil))
+	return it
+}
@@ -0,0 +1,148 @@
+package ai_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/infrastructure/ai"
+	"github.com/l3co/traceo-api/pkg/safefetch"
+)
+
+// testFetcher reaches the loopback photo servers the tests start.
+var testFetcher = safefetch.New(safefetch.Config{AllowPrivate: true})
+
+var record = flag.Bool("record", false, "re-record testdata/cassettes against the live Gemini API (needs GEMINI_API_KEY)")
+
+// cassetteClient returns a client replaying testdata/cassettes/<name>.json,
+// or, with -record, one that calls Gemini and rewrites that file when the
+// test ends.
+func cassetteClient(t *testing.T, name string) *ai.GeminiClient {
+	t.Helper()
+	path := filepath.Join("testdata", "cassettes", name+".json")
+
+	var transport ai.Transport
+	if *record {
+		key := os.Getenv("GEMINI_API_KEY")
+		if key == "" {
+			t.Fatal("-record needs GEMINI_API_KEY")
+		}
+		live, err := ai.NewGeminiTransport(context.Background(), key)
+		require.NoError(t, err)
+		cassette := ai.NewRecordingCassette(live)
+		t.Cleanup(func() {
+			defer live.Close()
+			var buf bytes.Buffer
+			require.NoError(t, cassette.Save(&buf))
+			require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+		})
+		transport = cassette
+	} else {
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		transport, err = ai.LoadCassette(f)
+		require.NoError(t, err)
+	}
+
+	client, err := ai.NewGeminiClientWithTransport(transport, testFetcher)
+	require.NoError(t, err)
+	return client
+}
+
+func photoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.FileServer(http.Dir(filepath.Join("testdata", "photos"))))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// --- Mock Transport ---
+
+type scriptedTransport struct {
+	reply string
+	calls int
+}
+
+func (s *scriptedTransport) Generate(_ context.Context, _ ai.GenerateRequest) (string, error) {
+	s.calls++
+	return s.reply, nil
+}
+
+func TestGeminiClient_CompareFacesReplay(t *testing.T) {
+	client := cassetteClient(t, "face_compare")
+	srv := photoServer(t)
+	ctx := context.Background()
+
+	same, err := client.CompareFaces(ctx, srv.URL+"/person_a.png", srv.URL+"/person_a_older.png")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, same.SimilarityScore, 0.7)
+	assert.NotEmpty(t, same.MatchingFeatures)
+
+	different, err := client.CompareFaces(ctx, srv.URL+"/person_a.png", srv.URL+"/person_b.png")
+	require.NoError(t, err)
+	assert.Less(t, different.SimilarityScore, 0.3)
+	assert.NotEmpty(t, different.DifferentFeatures)
+}
+
+func TestGeminiClient_DescribeFaceReplay(t *testing.T) {
+	client := cassetteClient(t, "face_describe")
+	srv := photoServer(t)
+
+	desc, err := client.DescribeFace(context.Background(), srv.URL+"/person_a.png", 30, "female")
+	require.NoError(t, err)
+	assert.NotEmpty(t, desc)
+	assert.Equal(t, strings.TrimSpace(desc), desc)
+}
+
+func TestCassette_MissOnChangedPrompt(t *testing.T) {
+	if *record {
+		t.Skip("replay only")
+	}
+	client := cassetteClient(t, "face_describe")
+	srv := photoServer(t)
+
+	// The age is part of the rendered prompt, so this request was never
+	// recorded.
+	_, err := client.DescribeFace(context.Background(), srv.URL+"/person_a.png", 31, "female")
+	assert.True(t, errors.Is(err, ai.ErrNoInteraction))
+}
+
+func TestCassette_RecordThenReplay(t *testing.T) {
+	inner := &scriptedTransport{reply: "```json\n{\"similarity_score\": 0.42, \"confidence\": \"medium\"}\n```"}
+	cassette := ai.NewRecordingCassette(inner)
+	client, err := ai.NewGeminiClientWithTransport(cassette, testFetcher)
+	require.NoError(t, err)
+	srv := photoServer(t)
+	ctx := context.Background()
+
+	first, err := client.CompareFaces(ctx, srv.URL+"/person_a.png", srv.URL+"/person_b.png")
+	require.NoError(t, err)
+	second, err := client.CompareFaces(ctx, srv.URL+"/person_a.png", srv.URL+"/person_b.png")
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, 0.42, first.SimilarityScore)
+	assert.Equal(t, first, second)
+
+	var buf bytes.Buffer
+	require.NoError(t, cassette.Save(&buf))
+	replay, err := ai.LoadCassette(&buf)
+	require.NoError(t, err)
+	client, err = ai.NewGeminiClientWithTransport(replay, testFetcher)
+	require.NoError(t, err)
+
+	third, err := client.CompareFaces(ctx, srv.URL+"/person_a.png", srv.URL+"/person_b.png")
+	require.NoError(t, err)
+	assert.Equal(t, first, third)
+	assert.Equal(t, 1, inner.calls)
+}
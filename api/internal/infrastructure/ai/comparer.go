@@ -0,0 +1,54 @@
+package ai
+
+import (
+	"context"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+)
+
+// GeminiComparer adapts GeminiClient to matching.FaceComparer and
+// matching.FaceDescriber.
+type GeminiComparer struct {
+	client *GeminiClient
+}
+
+func NewGeminiComparer(client *GeminiClient) *GeminiComparer {
+	return &GeminiComparer{client: client}
+}
+
+// compareCacheKeys names prompt texts that were cached under another key
+// before prompts were versioned, so that those comparisons stay valid.
+var compareCacheKeys = map[string]string{
+	"c6c2bd25881c8e6e": "compare-v1", // face_compare@v1
+}
+
+// Version changes with the model (or the demo fake) or the text of the
+// compare prompt, so that cached comparisons made with another prompt are not
+// reused. Publishing the same text under a new prompt version keeps them.
+func (g *GeminiComparer) Version() string {
+	digest := g.client.ComparePrompt().Digest
+	if key, ok := compareCacheKeys[digest]; ok {
+		return g.client.Model() + "/" + key
+	}
+	return g.client.Model() + "/" + digest
+}
+
+func (g *GeminiComparer) DescribeFace(ctx context.Context, photoURL string, currentAge int, gender string) (string, error) {
+	return g.client.DescribeFace(ctx, photoURL, currentAge, gender)
+}
+
+func (g *GeminiComparer) CompareFaces(ctx context.Context, photo1URL, photo2URL string) (*matching.FaceComparisonResult, error) {
+	result, err := g.client.CompareFaces(ctx, photo1URL, photo2URL)
+	if err != nil {
+		return nil, err
+	}
+	return &matching.FaceComparisonResult{
+		SimilarityScore:   result.SimilarityScore,
+		Analysis:          result.Analysis,
+		MatchingFeatures:  result.MatchingFeatures,
+		DifferentFeatures: result.DifferentFeatures,
+		Confidence:        result.Confidence,
+		PromptID:          g.client.ComparePrompt().ID,
+		PromptVersion:     g.client.ComparePrompt().Version,
+	}, nil
+}
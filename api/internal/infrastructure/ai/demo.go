@@ -0,0 +1,75 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/l3co/traceo-api/pkg/safefetch"
+)
+
+const demoModelName = "demo"
+
+// DemoTransport answers without calling any API. Identical photos score
+// high and anything else gets a low score derived from the photo bytes, so
+// results are stable across runs. It is meant for local development only:
+// the scores say nothing about the faces.
+type DemoTransport struct{}
+
+// NewDemoClient returns a GeminiClient backed by DemoTransport.
+func NewDemoClient(fetcher *safefetch.Fetcher) *GeminiClient {
+	client, err := NewGeminiClientWithTransport(DemoTransport{}, fetcher)
+	if err != nil {
+		// The default prompts are embedded; failing to load them is a
+		// build problem.
+		panic(err)
+	}
+	client.model = demoModelName
+	return client
+}
+
+func (DemoTransport) Generate(_ context.Context, req GenerateRequest) (string, error) {
+	switch {
+	case strings.HasPrefix(req.PromptRef, PromptFaceCompare+"@"):
+		if len(req.Images) != 2 {
+			return "", fmt.Errorf("demo: compare needs 2 images, got %d", len(req.Images))
+		}
+		return demoCompare(req.Images[0].Data, req.Images[1].Data)
+	case strings.HasPrefix(req.PromptRef, PromptFaceDescribe+"@"):
+		return "Descrição de demonstração: rosto oval, cabelos escuros, sem marcas aparentes.", nil
+	default:
+		return "", fmt.Errorf("demo: unknown prompt %s", req.PromptRef)
+	}
+}
+
+func demoCompare(a, b []byte) (string, error) {
+	result := FaceComparison{
+		Analysis:   "Resultado de demonstração, sem análise real.",
+		Confidence: "low",
+	}
+	if bytes.Equal(a, b) {
+		result.SimilarityScore = 0.97
+		result.MatchingFeatures = []string{"foto idêntica"}
+	} else {
+		// Order the inputs so the score is symmetric.
+		if bytes.Compare(a, b) > 0 {
+			a, b = b, a
+		}
+		h := sha256.New()
+		h.Write(a)
+		h.Write(b)
+		n := binary.BigEndian.Uint16(h.Sum(nil))
+		result.SimilarityScore = 0.05 + 0.55*float64(n)/65535
+		result.DifferentFeatures = []string{"fotos diferentes"}
+	}
+
+	out, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,36 @@
+package ai_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/infrastructure/ai"
+)
+
+func TestDemoComparer(t *testing.T) {
+	comparer := ai.NewGeminiComparer(ai.NewDemoClient(testFetcher))
+	srv := photoServer(t)
+	ctx := context.Background()
+	a, b := srv.URL+"/person_a.png", srv.URL+"/person_b.png"
+
+	same, err := comparer.CompareFaces(ctx, a, a)
+	require.NoError(t, err)
+	assert.Equal(t, 0.97, same.SimilarityScore)
+
+	ab, err := comparer.CompareFaces(ctx, a, b)
+	require.NoError(t, err)
+	ba, err := comparer.CompareFaces(ctx, b, a)
+	require.NoError(t, err)
+	assert.Equal(t, ab.SimilarityScore, ba.SimilarityScore)
+	assert.Less(t, ab.SimilarityScore, 0.7)
+
+	desc, err := comparer.DescribeFace(ctx, a, 30, "female")
+	require.NoError(t, err)
+	assert.NotEmpty(t, desc)
+
+	assert.True(t, strings.HasPrefix(comparer.Version(), "demo/"))
+}
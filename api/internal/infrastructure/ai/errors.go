@@ -0,0 +1,66 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"google.golang.org/api/googleapi"
+
+	"github.com/l3co/traceo-api/pkg/safefetch"
+)
+
+// HTTPStatusError is returned when a photo host answers with a non-200
+// status.
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("image download status %d", e.StatusCode)
+}
+
+// retryableStatus covers throttling and server-side failures.
+func retryableStatus(code int) bool {
+	return code == http.StatusRequestTimeout ||
+		code == http.StatusTooManyRequests ||
+		code >= http.StatusInternalServerError
+}
+
+// IsUpstreamFailure reports whether err means the Gemini API itself is
+// throttling or failing, as opposed to a bad photo or a bad response.
+func IsUpstreamFailure(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && retryableStatus(apiErr.Code)
+}
+
+// IsRetryable reports whether err is likely to go away on its own: upstream
+// failures, 408/429/5xx from a photo host, timeouts and dropped connections.
+// Cancellation and photo URLs refused by the fetcher are never retryable.
+func IsRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) ||
+		errors.Is(err, safefetch.ErrHostNotAllowed) ||
+		errors.Is(err, safefetch.ErrPrivateAddress) ||
+		errors.Is(err, safefetch.ErrTooLarge) {
+		return false
+	}
+	if IsUpstreamFailure(err) {
+		return true
+	}
+
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return retryableStatus(statusErr.StatusCode)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
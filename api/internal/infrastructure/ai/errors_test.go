@@ -0,0 +1,38 @@
+package ai_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/googleapi"
+
+	"github.com/l3co/traceo-api/internal/infrastructure/ai"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+		upstream  bool
+	}{
+		{"gemini 429", fmt.Errorf("gemini compare faces: %w", &googleapi.Error{Code: 429}), true, true},
+		{"gemini 503", &googleapi.Error{Code: 503}, true, true},
+		{"gemini 400", &googleapi.Error{Code: 400}, false, false},
+		{"photo host 502", fmt.Errorf("downloading photo1: %w", &ai.HTTPStatusError{StatusCode: 502}), true, false},
+		{"photo host 404", &ai.HTTPStatusError{StatusCode: 404}, false, false},
+		{"deadline", context.DeadlineExceeded, true, false},
+		{"canceled", context.Canceled, false, false},
+		{"parse error", errors.New("parsing gemini json response"), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.retryable, ai.IsRetryable(tt.err))
+			assert.Equal(t, tt.upstream, ai.IsUpstreamFailure(tt.err))
+		})
+	}
+}
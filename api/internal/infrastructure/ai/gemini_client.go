@@ -2,40 +2,76 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
-	"time"
+	"strings"
 
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
+	"github.com/l3co/traceo-api/pkg/safefetch"
 )
 
 type GeminiClient struct {
-	client     *genai.Client
-	visionModel *genai.GenerativeModel
-	httpClient *http.Client
+	transport      Transport
+	model          string
+	fetcher        *safefetch.Fetcher
+	comparePrompt  *Prompt
+	describePrompt *Prompt
 }
 
-func NewGeminiClient(ctx context.Context, apiKey string) (*GeminiClient, error) {
-	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+// NewGeminiClient returns a client that downloads photos through fetcher.
+func NewGeminiClient(ctx context.Context, apiKey string, fetcher *safefetch.Fetcher) (*GeminiClient, error) {
+	transport, err := NewGeminiTransport(ctx, apiKey)
 	if err != nil {
-		return nil, fmt.Errorf("creating gemini client: %w", err)
+		return nil, err
 	}
+	return NewGeminiClientWithTransport(transport, fetcher)
+}
 
-	visionModel := client.GenerativeModel("gemini-2.0-flash")
-	visionModel.SetTemperature(0.4)
-	visionModel.ResponseMIMEType = "application/json"
+// NewGeminiClientWithTransport builds a client that sends its requests
+// through t, e.g. a Cassette in tests.
+func NewGeminiClientWithTransport(t Transport, fetcher *safefetch.Fetcher) (*GeminiClient, error) {
+	prompts := DefaultPrompts()
+	comparePrompt, err := prompts.Get(PromptFaceCompare, DefaultFaceCompareVersion)
+	if err != nil {
+		return nil, err
+	}
+	describePrompt, err := prompts.Get(PromptFaceDescribe, DefaultFaceDescribeVersion)
+	if err != nil {
+		return nil, err
+	}
 
 	return &GeminiClient{
-		client:      client,
-		visionModel: visionModel,
-		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		transport:      t,
+		model:          visionModelName,
+		fetcher:        fetcher,
+		comparePrompt:  comparePrompt,
+		describePrompt: describePrompt,
 	}, nil
 }
 
+// WithComparePrompt returns a client sharing g's connection that compares
+// faces with p instead.
+func (g *GeminiClient) WithComparePrompt(p *Prompt) *GeminiClient {
+	cp := *g
+	cp.comparePrompt = p
+	return &cp
+}
+
+// Model names what answers the requests; it is part of the comparer
+// version.
+func (g *GeminiClient) Model() string {
+	return g.model
+}
+
+func (g *GeminiClient) ComparePrompt() *Prompt {
+	return g.comparePrompt
+}
+
 func (g *GeminiClient) Close() error {
-	return g.client.Close()
+	if c, ok := g.transport.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
 }
 
 func (g *GeminiClient) CompareFaces(ctx context.Context, photo1URL, photo2URL string) (*FaceComparison, error) {
@@ -48,32 +84,21 @@ func (g *GeminiClient) CompareFaces(ctx context.Context, photo1URL, photo2URL st
 		return nil, fmt.Errorf("downloading photo2: %w", err)
 	}
 
-	prompt := `Analyze these two facial photos and compare them.
-Consider: facial structure, eye shape and color, nose shape, lip shape,
-skin tone, face shape, distinguishing features.
-
-Respond in JSON format:
-{
-    "similarity_score": 0.0-1.0,
-    "analysis": "detailed explanation in Portuguese",
-    "matching_features": ["feature1", "feature2"],
-    "different_features": ["feature1", "feature2"],
-    "confidence": "high" | "medium" | "low"
-}
-
-Be conservative with scores. Only score above 0.7 if there is strong facial resemblance.
-Consider that one photo may be older (age difference is expected).`
+	prompt, err := g.comparePrompt.Render(nil)
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := g.visionModel.GenerateContent(ctx,
-		genai.ImageData(mime1, img1),
-		genai.ImageData(mime2, img2),
-		genai.Text(prompt),
-	)
+	text, err := g.transport.Generate(ctx, GenerateRequest{
+		PromptRef: g.comparePrompt.Ref(),
+		Prompt:    prompt,
+		Images:    []Image{{MIMEType: mime1, Data: img1}, {MIMEType: mime2, Data: img2}},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("gemini compare faces: %w", err)
 	}
 
-	return parseJSONFromGemini[FaceComparison](resp)
+	return parseJSON[FaceComparison](text)
 }
 
 func (g *GeminiClient) DescribeFace(ctx context.Context, photoURL string, currentAge int, gender string) (string, error) {
@@ -82,48 +107,38 @@ func (g *GeminiClient) DescribeFace(ctx context.Context, photoURL string, curren
 		return "", fmt.Errorf("downloading photo: %w", err)
 	}
 
-	prompt := fmt.Sprintf(`Describe this person's facial features in detail for age progression.
-Current age: %d years old. Gender: %s.
-Focus on: bone structure, eye shape, nose shape, lip shape, skin characteristics,
-hair pattern, distinguishing marks.
-Be specific and detailed. Respond in English.`, currentAge, gender)
+	prompt, err := g.describePrompt.Render(struct {
+		CurrentAge int
+		Gender     string
+	}{currentAge, gender})
+	if err != nil {
+		return "", err
+	}
 
-	resp, err := g.visionModel.GenerateContent(ctx,
-		genai.ImageData(mime, img),
-		genai.Text(prompt),
-	)
+	text, err := g.transport.Generate(ctx, GenerateRequest{
+		PromptRef: g.describePrompt.Ref(),
+		Prompt:    prompt,
+		Images:    []Image{{MIMEType: mime, Data: img}},
+	})
 	if err != nil {
 		return "", fmt.Errorf("gemini describe face: %w", err)
 	}
 
-	return extractText(resp), nil
+	return strings.TrimSpace(text), nil
 }
 
 func (g *GeminiClient) downloadImage(ctx context.Context, url string) ([]byte, string, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	data, mime, err := g.fetcher.Get(ctx, url)
 	if err != nil {
+		var statusErr *safefetch.StatusError
+		if errors.As(err, &statusErr) {
+			return nil, "", &HTTPStatusError{URL: url, StatusCode: statusErr.StatusCode}
+		}
 		return nil, "", err
 	}
 
-	resp, err := g.httpClient.Do(req)
-	if err != nil {
-		return nil, "", fmt.Errorf("fetching image: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("image download status %d", resp.StatusCode)
-	}
-
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, "", fmt.Errorf("reading image body: %w", err)
-	}
-
-	mime := resp.Header.Get("Content-Type")
 	if mime == "" {
 		mime = "image/jpeg"
 	}
-
 	return data, mime, nil
 }
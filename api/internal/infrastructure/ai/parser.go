@@ -24,9 +24,9 @@ func extractText(resp *genai.GenerateContentResponse) string {
 
 var jsonFenceRegex = regexp.MustCompile("(?s)```(?:json)?\\s*(.+?)```")
 
-func parseJSONFromGemini[T any](resp *genai.GenerateContentResponse) (*T, error) {
-	text := extractText(resp)
-
+// parseJSON decodes a JSON reply, tolerating a surrounding markdown code
+// fence.
+func parseJSON[T any](text string) (*T, error) {
 	if matches := jsonFenceRegex.FindStringSubmatch(text); len(matches) > 1 {
 		text = matches[1]
 	}
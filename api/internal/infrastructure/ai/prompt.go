@@ -0,0 +1,128 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Prompts live in prompts/<id>/<version>.tmpl. A published version must never
+// be edited: add a new version file instead, so that every stored comparison
+// can be traced to the exact prompt that produced it.
+//
+//go:embed prompts/*/*.tmpl
+var promptFS embed.FS
+
+const (
+	PromptFaceCompare  = "face_compare"
+	PromptFaceDescribe = "face_describe"
+)
+
+// Default prompt versions used in production.
+const (
+	DefaultFaceCompareVersion  = "v1"
+	DefaultFaceDescribeVersion = "v1"
+)
+
+type Prompt struct {
+	ID      string
+	Version string
+	// Digest identifies the template text, whatever ID and version it was
+	// published under.
+	Digest string
+	tmpl   *template.Template
+}
+
+// Ref identifies the prompt as "<id>@<version>".
+func (p *Prompt) Ref() string {
+	return p.ID + "@" + p.Version
+}
+
+func (p *Prompt) Render(data any) (string, error) {
+	var sb strings.Builder
+	if err := p.tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("rendering prompt %s: %w", p.Ref(), err)
+	}
+	return sb.String(), nil
+}
+
+// PromptRegistry holds every known version of every prompt.
+type PromptRegistry struct {
+	prompts map[string]map[string]*Prompt
+}
+
+// DefaultPrompts returns the prompts embedded in the binary.
+func DefaultPrompts() *PromptRegistry {
+	r, err := LoadPrompts(promptFS, "prompts")
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// LoadPrompts reads <dir>/<id>/<version>.tmpl files from fsys.
+func LoadPrompts(fsys fs.FS, dir string) (*PromptRegistry, error) {
+	r := &PromptRegistry{prompts: make(map[string]map[string]*Prompt)}
+	if err := r.Add(fsys, dir); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Add loads the prompts under dir in fsys into r, e.g. draft versions kept
+// outside the binary for offline evaluation. A version that already exists
+// is an error.
+func (r *PromptRegistry) Add(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*", "*.tmpl"))
+	if err != nil {
+		return fmt.Errorf("listing prompts: %w", err)
+	}
+
+	for _, f := range files {
+		id := path.Base(path.Dir(f))
+		version := strings.TrimSuffix(path.Base(f), ".tmpl")
+		if _, ok := r.prompts[id][version]; ok {
+			return fmt.Errorf("prompt %s@%s defined twice", id, version)
+		}
+
+		data, err := fs.ReadFile(fsys, f)
+		if err != nil {
+			return fmt.Errorf("reading prompt %s: %w", f, err)
+		}
+		tmpl, err := template.New(id + "@" + version).Option("missingkey=error").Parse(string(data))
+		if err != nil {
+			return fmt.Errorf("parsing prompt %s: %w", f, err)
+		}
+
+		if r.prompts[id] == nil {
+			r.prompts[id] = make(map[string]*Prompt)
+		}
+		sum := sha256.Sum256(data)
+		r.prompts[id][version] = &Prompt{ID: id, Version: version, Digest: hex.EncodeToString(sum[:8]), tmpl: tmpl}
+	}
+	return nil
+}
+
+func (r *PromptRegistry) Get(id, version string) (*Prompt, error) {
+	p, ok := r.prompts[id][version]
+	if !ok {
+		return nil, fmt.Errorf("unknown prompt %s@%s", id, version)
+	}
+	return p, nil
+}
+
+// Versions lists the known versions of id in lexical order.
+func (r *PromptRegistry) Versions(id string) []string {
+	versions := make([]string, 0, len(r.prompts[id]))
+	for v := range r.prompts[id] {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
@@ -0,0 +1,73 @@
+package ai_test
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/infrastructure/ai"
+)
+
+func TestDefaultPrompts_RenderDescribe(t *testing.T) {
+	p, err := ai.DefaultPrompts().Get(ai.PromptFaceDescribe, ai.DefaultFaceDescribeVersion)
+	require.NoError(t, err)
+
+	text, err := p.Render(struct {
+		CurrentAge int
+		Gender     string
+	}{42, "female"})
+	require.NoError(t, err)
+	assert.Contains(t, text, "Current age: 42 years old. Gender: female.")
+	assert.Equal(t, "face_describe@v1", p.Ref())
+}
+
+func TestDefaultPrompts_CompareHasNoPlaceholders(t *testing.T) {
+	p, err := ai.DefaultPrompts().Get(ai.PromptFaceCompare, ai.DefaultFaceCompareVersion)
+	require.NoError(t, err)
+
+	text, err := p.Render(nil)
+	require.NoError(t, err)
+	assert.Contains(t, text, "similarity_score")
+}
+
+func TestPromptRegistry_AddDraftVersion(t *testing.T) {
+	r := ai.DefaultPrompts()
+	drafts := fstest.MapFS{
+		"face_compare/v2.tmpl": {Data: []byte("Compare these faces.")},
+	}
+
+	require.NoError(t, r.Add(drafts, "."))
+	assert.Equal(t, []string{"v1", "v2"}, r.Versions(ai.PromptFaceCompare))
+
+	dup := fstest.MapFS{"face_compare/v1.tmpl": {Data: []byte("changed")}}
+	assert.Error(t, r.Add(dup, "."), "published versions cannot be redefined")
+}
+
+func TestPromptRegistry_UnknownVersion(t *testing.T) {
+	_, err := ai.DefaultPrompts().Get(ai.PromptFaceCompare, "v99")
+	assert.Error(t, err)
+}
+
+func TestGeminiComparer_VersionFollowsPromptText(t *testing.T) {
+	client := ai.NewDemoClient(testFetcher)
+	assert.Equal(t, "demo/compare-v1", ai.NewGeminiComparer(client).Version(), "key used before prompts were versioned")
+
+	v1, err := os.ReadFile("prompts/face_compare/v1.tmpl")
+	require.NoError(t, err)
+	r := ai.DefaultPrompts()
+	require.NoError(t, r.Add(fstest.MapFS{
+		"face_compare/v2.tmpl": {Data: v1},
+		"face_compare/v3.tmpl": {Data: []byte("Compare these faces.")},
+	}, "."))
+
+	same, err := r.Get(ai.PromptFaceCompare, "v2")
+	require.NoError(t, err)
+	assert.Equal(t, "demo/compare-v1", ai.NewGeminiComparer(client.WithComparePrompt(same)).Version())
+
+	changed, err := r.Get(ai.PromptFaceCompare, "v3")
+	require.NoError(t, err)
+	assert.NotEqual(t, "demo/compare-v1", ai.NewGeminiComparer(client.WithComparePrompt(changed)).Version())
+}
@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/pkg/resilience"
+)
+
+// DefaultBackoff retries a transient failure up to twice within a few
+// seconds; longer outages are left to the breaker and the job queue.
+var DefaultBackoff = resilience.Backoff{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    4 * time.Second,
+}
+
+// ResilientComparer retries transient failures of a Gemini-backed comparer
+// and describer, and trips breaker while the Gemini API is failing. Errors
+// that persist after retrying, and calls rejected by the open breaker, are
+// wrapped in matching.ErrComparerUnavailable so matching jobs are retried
+// instead of skipping the pair.
+type ResilientComparer struct {
+	comparer  matching.FaceComparer
+	describer matching.FaceDescriber
+	backoff   resilience.Backoff
+	breaker   *resilience.Breaker
+}
+
+func NewResilientComparer(comparer matching.FaceComparer, describer matching.FaceDescriber, backoff resilience.Backoff, breaker *resilience.Breaker) *ResilientComparer {
+	return &ResilientComparer{
+		comparer:  comparer,
+		describer: describer,
+		backoff:   backoff,
+		breaker:   breaker,
+	}
+}
+
+func (r *ResilientComparer) Version() string {
+	return matching.ComparerVersion(r.comparer)
+}
+
+func (r *ResilientComparer) CompareFaces(ctx context.Context, photo1URL, photo2URL string) (*matching.FaceComparisonResult, error) {
+	var result *matching.FaceComparisonResult
+	err := r.call(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = r.comparer.CompareFaces(ctx, photo1URL, photo2URL)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *ResilientComparer) DescribeFace(ctx context.Context, photoURL string, currentAge int, gender string) (string, error) {
+	var description string
+	err := r.call(ctx, func(ctx context.Context) error {
+		var err error
+		description, err = r.describer.DescribeFace(ctx, photoURL, currentAge, gender)
+		return err
+	})
+	return description, err
+}
+
+func (r *ResilientComparer) call(ctx context.Context, fn func(context.Context) error) error {
+	err := resilience.Retry(ctx, r.backoff, IsRetryable, func(ctx context.Context) error {
+		if err := r.breaker.Allow(); err != nil {
+			return err
+		}
+		err := fn(ctx)
+		switch {
+		case err == nil:
+			r.breaker.Report(resilience.OutcomeSuccess)
+		case IsUpstreamFailure(err):
+			r.breaker.Report(resilience.OutcomeFailure)
+		default:
+			r.breaker.Report(resilience.OutcomeIgnored)
+		}
+		return err
+	})
+	if err != nil && (IsRetryable(err) || errors.Is(err, resilience.ErrCircuitOpen)) {
+		return fmt.Errorf("%w: %w", matching.ErrComparerUnavailable, err)
+	}
+	return err
+}
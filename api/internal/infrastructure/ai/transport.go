@@ -0,0 +1,70 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+const visionModelName = "gemini-2.0-flash"
+
+// Image is one photo attached to a GenerateRequest.
+type Image struct {
+	MIMEType string
+	Data     []byte
+}
+
+// GenerateRequest is a rendered prompt plus the photos it refers to.
+type GenerateRequest struct {
+	PromptRef string
+	Prompt    string
+	Images    []Image
+}
+
+// Transport sends a request to the vision model and returns its raw text
+// reply. GeminiClient does everything else (downloading photos, rendering
+// prompts, parsing replies), so swapping the transport is enough to run it
+// against a Cassette or the demo fake.
+type Transport interface {
+	Generate(ctx context.Context, req GenerateRequest) (string, error)
+}
+
+// GeminiTransport talks to the Gemini API.
+type GeminiTransport struct {
+	client *genai.Client
+	model  *genai.GenerativeModel
+}
+
+// NewGeminiTransport connects to the Gemini API. Close releases the client.
+func NewGeminiTransport(ctx context.Context, apiKey string) (*GeminiTransport, error) {
+	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("creating gemini client: %w", err)
+	}
+
+	model := client.GenerativeModel(visionModelName)
+	model.SetTemperature(0.4)
+	model.ResponseMIMEType = "application/json"
+
+	return &GeminiTransport{client: client, model: model}, nil
+}
+
+func (t *GeminiTransport) Generate(ctx context.Context, req GenerateRequest) (string, error) {
+	parts := make([]genai.Part, 0, len(req.Images)+1)
+	for _, img := range req.Images {
+		parts = append(parts, genai.ImageData(img.MIMEType, img.Data))
+	}
+	parts = append(parts, genai.Text(req.Prompt))
+
+	resp, err := t.model.GenerateContent(ctx, parts...)
+	if err != nil {
+		return "", err
+	}
+	return extractText(resp), nil
+}
+
+func (t *GeminiTransport) Close() error {
+	return t.client.Close()
+}
@@ -0,0 +1,57 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+)
+
+// Comparer implements matching.FaceComparer with local embeddings, for use as
+// the pre-filter of a matching.CascadeComparer: its scores are good enough to
+// discard clearly different photos, not to propose a match. Cosine
+// similarities at or below baseline map to a score of 0 and the rest is
+// stretched linearly to [0, 1], since unrelated faces already share a fair
+// amount of gradient structure.
+type Comparer struct {
+	embedder matching.FaceEmbedder
+	baseline float64
+}
+
+func NewComparer(embedder matching.FaceEmbedder, baseline float64) *Comparer {
+	return &Comparer{embedder: embedder, baseline: baseline}
+}
+
+func (c *Comparer) Version() string {
+	return fmt.Sprintf("%s/baseline=%.2f", embedderVersion(c.embedder), c.baseline)
+}
+
+func embedderVersion(e matching.FaceEmbedder) string {
+	if v, ok := e.(matching.Versioned); ok {
+		return v.Version()
+	}
+	return "unversioned"
+}
+
+func (c *Comparer) CompareFaces(ctx context.Context, photo1URL, photo2URL string) (*matching.FaceComparisonResult, error) {
+	e1, err := c.embedder.EmbedFace(ctx, photo1URL)
+	if err != nil {
+		return nil, fmt.Errorf("embedding photo1: %w", err)
+	}
+	e2, err := c.embedder.EmbedFace(ctx, photo2URL)
+	if err != nil {
+		return nil, fmt.Errorf("embedding photo2: %w", err)
+	}
+
+	cosine := e1.Cosine(e2)
+	score := 0.0
+	if cosine > c.baseline && c.baseline < 1 {
+		score = (cosine - c.baseline) / (1 - c.baseline)
+	}
+
+	return &matching.FaceComparisonResult{
+		SimilarityScore: score,
+		Analysis:        fmt.Sprintf("Comparação local por embeddings (similaridade de cosseno %.3f).", cosine),
+		Confidence:      "low",
+	}, nil
+}
@@ -0,0 +1,123 @@
+package embedding_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/infrastructure/embedding"
+	"github.com/l3co/traceo-api/pkg/safefetch"
+)
+
+// face draws a crude face: dark ellipse eyes and mouth on a light disc.
+func face(w, h int, eyeGap int, bright uint8) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	cx, cy := w/2, h/2
+	r := min(w, h) / 3
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dx, dy := x-cx, y-cy
+			v := bright / 4
+			if dx*dx+dy*dy < r*r {
+				v = bright
+			}
+			for _, ex := range []int{cx - eyeGap, cx + eyeGap} {
+				if (x-ex)*(x-ex)+(y-(cy-r/3))*(y-(cy-r/3)) < (r/6)*(r/6) {
+					v = bright / 8
+				}
+			}
+			if y > cy+r/3 && y < cy+r/2 && dx > -r/2 && dx < r/2 {
+				v = bright / 8
+			}
+			img.Set(x, y, color.RGBA{v, v, v, 255})
+		}
+	}
+	return img
+}
+
+func stripes(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(0)
+			if (x/6)%2 == 0 {
+				v = 255
+			}
+			img.Set(x, y, color.RGBA{v, v, v, 255})
+		}
+	}
+	return img
+}
+
+func TestEmbed_Dimensions(t *testing.T) {
+	e := embedding.Embed(face(120, 160, 18, 220))
+	assert.Len(t, e, embedding.Dimensions)
+}
+
+func TestEmbed_SameImage_CosineOne(t *testing.T) {
+	img := face(120, 160, 18, 220)
+	assert.InDelta(t, 1.0, embedding.Embed(img).Cosine(embedding.Embed(img)), 1e-6)
+}
+
+func TestEmbed_InvariantToExposure(t *testing.T) {
+	a := embedding.Embed(face(120, 160, 18, 220))
+	b := embedding.Embed(face(120, 160, 18, 140))
+	assert.Greater(t, a.Cosine(b), 0.95)
+}
+
+func TestEmbed_InvariantToResolution(t *testing.T) {
+	a := embedding.Embed(face(128, 128, 20, 220))
+	b := embedding.Embed(face(256, 256, 40, 220))
+	assert.Greater(t, a.Cosine(b), 0.9)
+}
+
+func TestEmbed_DifferentPatternsScoreLower(t *testing.T) {
+	ref := embedding.Embed(face(120, 160, 18, 220))
+	similar := embedding.Embed(face(120, 160, 20, 220))
+	different := embedding.Embed(stripes(120, 160))
+	assert.Greater(t, ref.Cosine(similar), ref.Cosine(different))
+}
+
+func TestComparer_CompareFaces(t *testing.T) {
+	images := map[string]image.Image{
+		"/a.png": face(120, 160, 18, 220),
+		"/b.png": face(120, 160, 18, 180),
+		"/c.png": stripes(120, 160),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		img, ok := images[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		var buf bytes.Buffer
+		_ = png.Encode(&buf, img)
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	c := embedding.NewComparer(embedding.NewHOGEmbedder(safefetch.New(safefetch.Config{AllowPrivate: true})), 0.5)
+	ctx := context.Background()
+
+	same, err := c.CompareFaces(ctx, srv.URL+"/a.png", srv.URL+"/b.png")
+	require.NoError(t, err)
+	other, err := c.CompareFaces(ctx, srv.URL+"/a.png", srv.URL+"/c.png")
+	require.NoError(t, err)
+
+	assert.Greater(t, same.SimilarityScore, other.SimilarityScore)
+	assert.GreaterOrEqual(t, other.SimilarityScore, 0.0)
+	assert.LessOrEqual(t, same.SimilarityScore, 1.0)
+	assert.Equal(t, "low", same.Confidence)
+
+	_, err = c.CompareFaces(ctx, srv.URL+"/a.png", srv.URL+"/missing.png")
+	assert.Error(t, err)
+}
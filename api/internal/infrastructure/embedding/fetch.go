@@ -0,0 +1,17 @@
+package embedding
+
+import (
+	"context"
+	"image"
+
+	"github.com/l3co/traceo-api/internal/infrastructure/imaging"
+	"github.com/l3co/traceo-api/pkg/safefetch"
+)
+
+func fetchImage(ctx context.Context, fetcher *safefetch.Fetcher, url string) (image.Image, error) {
+	data, _, err := fetcher.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return imaging.Decode(data)
+}
@@ -0,0 +1,134 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/pkg/safefetch"
+)
+
+const (
+	gridSize    = 64
+	cellSize    = 8
+	orientBins  = 9
+	cellsPerRow = gridSize / cellSize
+	// Dimensions is the length of every embedding produced by HOGEmbedder.
+	Dimensions = cellsPerRow * cellsPerRow * orientBins
+)
+
+// HOGEmbedder computes a histogram-of-oriented-gradients descriptor of the
+// central square of a photo. It runs on the CPU with no model files, which
+// makes it a cheap and deterministic pre-filter; it is not a learned face
+// embedding and its scores are not calibrated against Gemini's.
+type HOGEmbedder struct {
+	fetcher *safefetch.Fetcher
+}
+
+func NewHOGEmbedder(fetcher *safefetch.Fetcher) *HOGEmbedder {
+	return &HOGEmbedder{fetcher: fetcher}
+}
+
+// Version changes whenever the descriptor layout does.
+func (e *HOGEmbedder) Version() string {
+	return fmt.Sprintf("hog-%dx%d-%d", gridSize, cellSize, orientBins)
+}
+
+func (e *HOGEmbedder) EmbedFace(ctx context.Context, photoURL string) (matching.Embedding, error) {
+	img, err := fetchImage(ctx, e.fetcher, photoURL)
+	if err != nil {
+		return nil, fmt.Errorf("embedding %s: %w", photoURL, err)
+	}
+	return Embed(img), nil
+}
+
+// Embed returns the L2-normalised HOG descriptor of img.
+func Embed(img image.Image) matching.Embedding {
+	gray := normalizedGray(img)
+
+	vec := make(matching.Embedding, Dimensions)
+	for y := 1; y < gridSize-1; y++ {
+		for x := 1; x < gridSize-1; x++ {
+			gx := gray[y*gridSize+x+1] - gray[y*gridSize+x-1]
+			gy := gray[(y+1)*gridSize+x] - gray[(y-1)*gridSize+x]
+			mag := math.Hypot(gx, gy)
+			if mag == 0 {
+				continue
+			}
+			angle := math.Atan2(gy, gx)
+			if angle < 0 {
+				angle += math.Pi
+			}
+			bin := int(angle / math.Pi * orientBins)
+			if bin == orientBins {
+				bin = 0
+			}
+			cell := (y/cellSize)*cellsPerRow + x/cellSize
+			vec[cell*orientBins+bin] += float32(mag)
+		}
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	if norm > 0 {
+		inv := float32(1 / math.Sqrt(norm))
+		for i := range vec {
+			vec[i] *= inv
+		}
+	}
+	return vec
+}
+
+// normalizedGray crops the central square of img, box-samples it down to
+// gridSize×gridSize luminance values and standardises them to zero mean and
+// unit variance so that exposure differences between photos cancel out.
+func normalizedGray(img image.Image) []float64 {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+
+	out := make([]float64, gridSize*gridSize)
+	for gy := 0; gy < gridSize; gy++ {
+		sy0 := y0 + gy*side/gridSize
+		sy1 := max(y0+(gy+1)*side/gridSize, sy0+1)
+		for gx := 0; gx < gridSize; gx++ {
+			sx0 := x0 + gx*side/gridSize
+			sx1 := max(x0+(gx+1)*side/gridSize, sx0+1)
+
+			var sum float64
+			var n int
+			for y := sy0; y < sy1; y++ {
+				for x := sx0; x < sx1; x++ {
+					r, g, bl, _ := img.At(x, y).RGBA()
+					sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(bl)
+					n++
+				}
+			}
+			out[gy*gridSize+gx] = sum / float64(n) / 0xffff
+		}
+	}
+
+	var mean float64
+	for _, v := range out {
+		mean += v
+	}
+	mean /= float64(len(out))
+
+	var variance float64
+	for _, v := range out {
+		variance += (v - mean) * (v - mean)
+	}
+	std := math.Sqrt(variance / float64(len(out)))
+	if std == 0 {
+		std = 1
+	}
+	for i := range out {
+		out[i] = (out[i] - mean) / std
+	}
+	return out
+}
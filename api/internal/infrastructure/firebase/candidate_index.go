@@ -0,0 +1,190 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+)
+
+const (
+	faceEmbeddingCollection = "face_embeddings"
+
+	// embeddingRefreshInterval is how stale the in-process copy may get before
+	// entries written by other instances are pulled in.
+	embeddingRefreshInterval = 5 * time.Minute
+	// embeddingClockSkew widens each incremental refresh so writes stamped by
+	// an instance with a slightly late clock are not missed.
+	embeddingClockSkew = time.Minute
+)
+
+// CandidateIndex persists embeddings in Firestore and searches an in-process
+// copy by brute force. The copy is loaded in full on first use and then
+// refreshed incrementally by updated_at.
+type CandidateIndex struct {
+	client *firestore.Client
+
+	mu       sync.RWMutex
+	entries  map[matching.IndexKind]map[string]matching.IndexEntry
+	loaded   bool
+	syncedAt time.Time
+}
+
+func NewCandidateIndex(client *firestore.Client) *CandidateIndex {
+	return &CandidateIndex{
+		client:  client,
+		entries: make(map[matching.IndexKind]map[string]matching.IndexEntry),
+	}
+}
+
+type faceEmbeddingDoc struct {
+	Kind      string    `firestore:"kind"`
+	ID        string    `firestore:"id"`
+	PhotoURL  string    `firestore:"photo_url"`
+	Embedding []float64 `firestore:"embedding"`
+	UpdatedAt time.Time `firestore:"updated_at"`
+}
+
+func toFaceEmbeddingDoc(e matching.IndexEntry) faceEmbeddingDoc {
+	vec := make([]float64, len(e.Embedding))
+	for i, v := range e.Embedding {
+		vec[i] = float64(v)
+	}
+	return faceEmbeddingDoc{
+		Kind:      string(e.Kind),
+		ID:        e.ID,
+		PhotoURL:  e.PhotoURL,
+		Embedding: vec,
+		UpdatedAt: e.UpdatedAt,
+	}
+}
+
+func toIndexEntry(d faceEmbeddingDoc) matching.IndexEntry {
+	vec := make(matching.Embedding, len(d.Embedding))
+	for i, v := range d.Embedding {
+		vec[i] = float32(v)
+	}
+	return matching.IndexEntry{
+		Kind:      matching.IndexKind(d.Kind),
+		ID:        d.ID,
+		PhotoURL:  d.PhotoURL,
+		Embedding: vec,
+		UpdatedAt: d.UpdatedAt,
+	}
+}
+
+func faceEmbeddingDocID(kind matching.IndexKind, id string) string {
+	return string(kind) + "_" + id
+}
+
+func (i *CandidateIndex) Upsert(ctx context.Context, e matching.IndexEntry) error {
+	if e.UpdatedAt.IsZero() {
+		e.UpdatedAt = time.Now()
+	}
+	_, err := i.client.Collection(faceEmbeddingCollection).
+		Doc(faceEmbeddingDocID(e.Kind, e.ID)).
+		Set(ctx, toFaceEmbeddingDoc(e))
+	if err != nil {
+		return fmt.Errorf("firestore: saving face embedding: %w", err)
+	}
+
+	i.mu.Lock()
+	i.put(e)
+	i.mu.Unlock()
+	return nil
+}
+
+func (i *CandidateIndex) Remove(ctx context.Context, kind matching.IndexKind, id string) error {
+	_, err := i.client.Collection(faceEmbeddingCollection).
+		Doc(faceEmbeddingDocID(kind, id)).
+		Delete(ctx)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return fmt.Errorf("firestore: removing face embedding: %w", err)
+	}
+
+	i.mu.Lock()
+	delete(i.entries[kind], id)
+	i.mu.Unlock()
+	return nil
+}
+
+func (i *CandidateIndex) Nearest(ctx context.Context, kind matching.IndexKind, query matching.Embedding, k int) ([]matching.Neighbor, error) {
+	if err := i.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	i.mu.RLock()
+	entries := make([]matching.IndexEntry, 0, len(i.entries[kind]))
+	for _, e := range i.entries[kind] {
+		entries = append(entries, e)
+	}
+	i.mu.RUnlock()
+
+	return matching.RankNearest(entries, query, k), nil
+}
+
+func (i *CandidateIndex) Indexed(ctx context.Context, kind matching.IndexKind) (map[string]string, error) {
+	if err := i.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	result := make(map[string]string, len(i.entries[kind]))
+	for id, e := range i.entries[kind] {
+		result[id] = e.PhotoURL
+	}
+	return result, nil
+}
+
+func (i *CandidateIndex) refresh(ctx context.Context) error {
+	i.mu.RLock()
+	loaded, syncedAt := i.loaded, i.syncedAt
+	i.mu.RUnlock()
+
+	if loaded && time.Since(syncedAt) < embeddingRefreshInterval {
+		return nil
+	}
+
+	started := time.Now()
+	query := i.client.Collection(faceEmbeddingCollection).Query
+	if loaded {
+		query = query.Where("updated_at", ">", syncedAt.Add(-embeddingClockSkew))
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return fmt.Errorf("firestore: loading face embeddings: %w", err)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, doc := range docs {
+		var d faceEmbeddingDoc
+		if err := doc.DataTo(&d); err != nil {
+			continue
+		}
+		i.put(toIndexEntry(d))
+	}
+	i.loaded = true
+	i.syncedAt = started
+	return nil
+}
+
+// put stores e unless a newer entry for the same record is already cached.
+// The caller must hold mu.
+func (i *CandidateIndex) put(e matching.IndexEntry) {
+	if i.entries[e.Kind] == nil {
+		i.entries[e.Kind] = make(map[string]matching.IndexEntry)
+	}
+	if cur, ok := i.entries[e.Kind][e.ID]; ok && cur.UpdatedAt.After(e.UpdatedAt) {
+		return
+	}
+	i.entries[e.Kind][e.ID] = e
+}
@@ -7,15 +7,17 @@ import (
 	"cloud.google.com/go/firestore"
 	fb "firebase.google.com/go/v4"
 	"firebase.google.com/go/v4/auth"
+	"firebase.google.com/go/v4/storage"
 )
 
 type Client struct {
 	Auth      *auth.Client
 	Firestore *firestore.Client
+	Storage   *storage.Client
 }
 
-func NewClient(ctx context.Context, projectID string) (*Client, error) {
-	app, err := fb.NewApp(ctx, &fb.Config{ProjectID: projectID})
+func NewClient(ctx context.Context, projectID, storageBucket string) (*Client, error) {
+	app, err := fb.NewApp(ctx, &fb.Config{ProjectID: projectID, StorageBucket: storageBucket})
 	if err != nil {
 		return nil, fmt.Errorf("firebase: initializing app: %w", err)
 	}
@@ -30,9 +32,15 @@ func NewClient(ctx context.Context, projectID string) (*Client, error) {
 		return nil, fmt.Errorf("firebase: initializing firestore: %w", err)
 	}
 
+	storageClient, err := app.Storage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("firebase: initializing storage: %w", err)
+	}
+
 	return &Client{
 		Auth:      authClient,
 		Firestore: firestoreClient,
+		Storage:   storageClient,
 	}, nil
 }
 
@@ -0,0 +1,76 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+)
+
+const comparisonCacheCollection = "comparison_cache"
+
+type ComparisonCache struct {
+	client *firestore.Client
+}
+
+func NewComparisonCache(client *firestore.Client) *ComparisonCache {
+	return &ComparisonCache{client: client}
+}
+
+type comparisonCacheDoc struct {
+	SimilarityScore   float64   `firestore:"similarity_score"`
+	Analysis          string    `firestore:"analysis"`
+	MatchingFeatures  []string  `firestore:"matching_features"`
+	DifferentFeatures []string  `firestore:"different_features"`
+	Confidence        string    `firestore:"confidence"`
+	PromptID          string    `firestore:"prompt_id,omitempty"`
+	PromptVersion     string    `firestore:"prompt_version,omitempty"`
+	CreatedAt         time.Time `firestore:"created_at"`
+}
+
+func (c *ComparisonCache) Get(ctx context.Context, key string) (*matching.FaceComparisonResult, error) {
+	doc, err := c.client.Collection(comparisonCacheCollection).Doc(key).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("firestore: reading comparison cache: %w", err)
+	}
+
+	var d comparisonCacheDoc
+	if err := doc.DataTo(&d); err != nil {
+		return nil, fmt.Errorf("firestore: decoding comparison cache: %w", err)
+	}
+
+	return &matching.FaceComparisonResult{
+		SimilarityScore:   d.SimilarityScore,
+		Analysis:          d.Analysis,
+		MatchingFeatures:  d.MatchingFeatures,
+		DifferentFeatures: d.DifferentFeatures,
+		Confidence:        d.Confidence,
+		PromptID:          d.PromptID,
+		PromptVersion:     d.PromptVersion,
+	}, nil
+}
+
+func (c *ComparisonCache) Put(ctx context.Context, key string, r *matching.FaceComparisonResult) error {
+	_, err := c.client.Collection(comparisonCacheCollection).Doc(key).Set(ctx, comparisonCacheDoc{
+		SimilarityScore:   r.SimilarityScore,
+		Analysis:          r.Analysis,
+		MatchingFeatures:  r.MatchingFeatures,
+		DifferentFeatures: r.DifferentFeatures,
+		Confidence:        r.Confidence,
+		PromptID:          r.PromptID,
+		PromptVersion:     r.PromptVersion,
+		CreatedAt:         time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("firestore: writing comparison cache: %w", err)
+	}
+	return nil
+}
@@ -24,21 +24,23 @@ func NewHomelessRepository(client *firestore.Client) *HomelessRepository {
 }
 
 type homelessDoc struct {
-	ID        string    `firestore:"id"`
-	Name      string    `firestore:"name"`
-	Nickname  string    `firestore:"nickname,omitempty"`
-	BirthDate time.Time `firestore:"birth_date"`
-	Gender    string    `firestore:"gender"`
-	Eyes      string    `firestore:"eyes"`
-	Hair      string    `firestore:"hair"`
-	Skin      string    `firestore:"skin"`
-	PhotoURL  string    `firestore:"photo_url,omitempty"`
-	Lat       float64   `firestore:"lat"`
-	Lng       float64   `firestore:"lng"`
-	Address   string    `firestore:"address,omitempty"`
-	Slug      string    `firestore:"slug"`
-	CreatedAt time.Time `firestore:"created_at"`
-	UpdatedAt time.Time `firestore:"updated_at"`
+	ID        string     `firestore:"id"`
+	Name      string     `firestore:"name"`
+	Nickname  string     `firestore:"nickname,omitempty"`
+	BirthDate time.Time  `firestore:"birth_date"`
+	Gender    string     `firestore:"gender"`
+	Eyes      string     `firestore:"eyes"`
+	Hair      string     `firestore:"hair"`
+	Skin      string     `firestore:"skin"`
+	PhotoURL  string     `firestore:"photo_url,omitempty"`
+	Photos    []photoDoc `firestore:"photos,omitempty"`
+	HashBands []string   `firestore:"photo_hash_bands,omitempty"`
+	Lat       float64    `firestore:"lat"`
+	Lng       float64    `firestore:"lng"`
+	Address   string     `firestore:"address,omitempty"`
+	Slug      string     `firestore:"slug"`
+	CreatedAt time.Time  `firestore:"created_at"`
+	UpdatedAt time.Time  `firestore:"updated_at"`
 }
 
 func toHomelessDoc(h *homeless.Homeless) homelessDoc {
@@ -52,6 +54,8 @@ func toHomelessDoc(h *homeless.Homeless) homelessDoc {
 		Hair:      string(h.Hair),
 		Skin:      string(h.Skin),
 		PhotoURL:  h.PhotoURL,
+		Photos:    toPhotoDocs(h.Photos),
+		HashBands: h.Photos.HashBands(),
 		Lat:       h.Location.Lat,
 		Lng:       h.Location.Lng,
 		Address:   h.Location.Address,
@@ -72,6 +76,7 @@ func toHomelessEntity(d homelessDoc) *homeless.Homeless {
 		Hair:      shared.HairColor(d.Hair),
 		Skin:      shared.SkinColor(d.Skin),
 		PhotoURL:  d.PhotoURL,
+		Photos:    toPhotoEntities(d.Photos, d.PhotoURL, d.CreatedAt),
 		Location:  shared.GeoPoint{Lat: d.Lat, Lng: d.Lng, Address: d.Address},
 		Slug:      d.Slug,
 		CreatedAt: d.CreatedAt,
@@ -153,3 +158,73 @@ func (r *HomelessRepository) CountByGender(ctx context.Context) ([]homeless.Gend
 	}
 	return result, nil
 }
+
+func (r *HomelessRepository) FindCandidates(ctx context.Context, filter homeless.CandidateFilter) ([]*homeless.Homeless, error) {
+	query := r.client.Collection(homelessCollection).Query
+	if filter.Gender != "" {
+		query = query.Where("gender", "==", string(filter.Gender))
+	}
+	if filter.Skin != "" {
+		query = query.Where("skin", "==", string(filter.Skin))
+	}
+
+	result, err := findMatching(ctx, query, filter.After, filter.Limit, func(doc *firestore.DocumentSnapshot) (*homeless.Homeless, bool) {
+		var d homelessDoc
+		if err := doc.DataTo(&d); err != nil {
+			return nil, false
+		}
+		entity := toHomelessEntity(d)
+		return entity, filter.Matches(entity)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("firestore: finding homeless candidates: %w", err)
+	}
+	return result, nil
+}
+
+func (r *HomelessRepository) UpdatePhotos(ctx context.Context, id string, photos shared.Photos) error {
+	_, err := r.client.Collection(homelessCollection).Doc(id).Update(ctx, []firestore.Update{
+		{Path: "photos", Value: toPhotoDocs(photos)},
+		{Path: "photo_url", Value: photos.PrimaryURL()},
+		{Path: "photo_hash_bands", Value: photos.HashBands()},
+		{Path: "updated_at", Value: time.Now()},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return homeless.ErrHomelessNotFound
+		}
+		return fmt.Errorf("firestore: updating homeless photos: %w", err)
+	}
+	return nil
+}
+
+// FindByPhotoHash returns the records with a photo that shares an index
+// band with h, up to limit per band. Each band is queried on its own so that
+// a band shared by many unrelated photos cannot crowd out the others. Some
+// results may be farther from h than DuplicateMaxDistance.
+func (r *HomelessRepository) FindByPhotoHash(ctx context.Context, h shared.PerceptualHash, limit int) ([]*homeless.Homeless, error) {
+	var result []*homeless.Homeless
+	seen := make(map[string]bool)
+	for _, band := range h.Bands() {
+		docs, err := r.client.Collection(homelessCollection).
+			Where("photo_hash_bands", "array-contains", band).
+			Limit(limit).
+			Documents(ctx).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("firestore: finding homeless by photo hash: %w", err)
+		}
+
+		for _, doc := range docs {
+			if seen[doc.Ref.ID] {
+				continue
+			}
+			seen[doc.Ref.ID] = true
+			var d homelessDoc
+			if err := doc.DataTo(&d); err != nil {
+				continue
+			}
+			result = append(result, toHomelessEntity(d))
+		}
+	}
+	return result, nil
+}
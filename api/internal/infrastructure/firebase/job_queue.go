@@ -0,0 +1,430 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/l3co/traceo-api/internal/domain/job"
+)
+
+const (
+	jobCollection     = "ai_jobs"
+	deadJobCollection = "ai_jobs_dead"
+)
+
+type JobQueue struct {
+	client *firestore.Client
+}
+
+func NewJobQueue(client *firestore.Client) *JobQueue {
+	return &JobQueue{client: client}
+}
+
+type jobDoc struct {
+	ID          string    `firestore:"id"`
+	Type        string    `firestore:"type"`
+	TargetID    string    `firestore:"target_id"`
+	CandidateID string    `firestore:"candidate_id,omitempty"`
+	PhotoURL    string    `firestore:"photo_url,omitempty"`
+	BirthDate   time.Time `firestore:"birth_date"`
+	Status      string    `firestore:"status"`
+	Attempts    int       `firestore:"attempts"`
+	LastError   string    `firestore:"last_error,omitempty"`
+	RunAt       time.Time `firestore:"run_at"`
+	LeaseOwner  string    `firestore:"lease_owner,omitempty"`
+	LeasedUntil time.Time `firestore:"leased_until"`
+	StartedAt   time.Time `firestore:"started_at"`
+	FinishedAt  time.Time `firestore:"finished_at"`
+	CreatedAt   time.Time `firestore:"created_at"`
+	UpdatedAt   time.Time `firestore:"updated_at"`
+}
+
+func toJobDoc(j *job.Job) jobDoc {
+	return jobDoc{
+		ID:          j.ID,
+		Type:        string(j.Type),
+		TargetID:    j.TargetID,
+		CandidateID: j.CandidateID,
+		PhotoURL:    j.PhotoURL,
+		BirthDate:   j.BirthDate,
+		Status:      string(j.Status),
+		Attempts:    j.Attempts,
+		LastError:   j.LastError,
+		RunAt:       j.RunAt,
+		LeaseOwner:  j.LeaseOwner,
+		LeasedUntil: j.LeasedUntil,
+		StartedAt:   j.StartedAt,
+		FinishedAt:  j.FinishedAt,
+		CreatedAt:   j.CreatedAt,
+		UpdatedAt:   j.UpdatedAt,
+	}
+}
+
+func toJobEntity(d jobDoc) *job.Job {
+	return &job.Job{
+		ID:          d.ID,
+		Type:        job.Type(d.Type),
+		TargetID:    d.TargetID,
+		CandidateID: d.CandidateID,
+		PhotoURL:    d.PhotoURL,
+		BirthDate:   d.BirthDate,
+		Status:      job.Status(d.Status),
+		Attempts:    d.Attempts,
+		LastError:   d.LastError,
+		RunAt:       d.RunAt,
+		LeaseOwner:  d.LeaseOwner,
+		LeasedUntil: d.LeasedUntil,
+		StartedAt:   d.StartedAt,
+		FinishedAt:  d.FinishedAt,
+		CreatedAt:   d.CreatedAt,
+		UpdatedAt:   d.UpdatedAt,
+	}
+}
+
+func (q *JobQueue) Enqueue(ctx context.Context, j *job.Job) error {
+	_, err := q.client.Collection(jobCollection).Doc(j.ID).Create(ctx, toJobDoc(j))
+	if err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			return job.ErrDuplicateJob
+		}
+		return fmt.Errorf("firestore: enqueueing job %s: %w", j.ID, err)
+	}
+	return nil
+}
+
+func (q *JobQueue) FindByID(ctx context.Context, id string) (*job.Job, error) {
+	for _, col := range []string{jobCollection, deadJobCollection} {
+		doc, err := q.client.Collection(col).Doc(id).Get(ctx)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				continue
+			}
+			return nil, fmt.Errorf("firestore: finding job %s: %w", id, err)
+		}
+
+		var d jobDoc
+		if err := doc.DataTo(&d); err != nil {
+			return nil, fmt.Errorf("firestore: decoding job %s: %w", id, err)
+		}
+		return toJobEntity(d), nil
+	}
+	return nil, job.ErrJobNotFound
+}
+
+func (q *JobQueue) FindByTargetID(ctx context.Context, targetID string, limit int) ([]*job.Job, error) {
+	var result []*job.Job
+	for _, col := range []string{jobCollection, deadJobCollection} {
+		docs, err := q.client.Collection(col).
+			Where("target_id", "==", targetID).
+			OrderBy("created_at", firestore.Desc).
+			Limit(limit).
+			Documents(ctx).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("firestore: finding jobs by target %s: %w", targetID, err)
+		}
+
+		for _, doc := range docs {
+			var d jobDoc
+			if err := doc.DataTo(&d); err != nil {
+				continue
+			}
+			result = append(result, toJobEntity(d))
+		}
+	}
+
+	sort.Slice(result, func(a, b int) bool {
+		return result[a].CreatedAt.After(result[b].CreatedAt)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (q *JobQueue) Lease(ctx context.Context, owner string, ttl time.Duration) (*job.Job, error) {
+	var leased *job.Job
+
+	err := q.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		leased = nil
+		now := time.Now()
+
+		doc, err := q.nextReady(tx, now)
+		if err != nil || doc == nil {
+			return err
+		}
+
+		var d jobDoc
+		if err := doc.DataTo(&d); err != nil {
+			return fmt.Errorf("decoding job %s: %w", doc.Ref.ID, err)
+		}
+
+		d.Status = string(job.StatusRunning)
+		d.Attempts++
+		d.LeaseOwner = owner
+		d.LeasedUntil = now.Add(ttl)
+		d.UpdatedAt = now
+		if d.StartedAt.IsZero() {
+			d.StartedAt = now
+		}
+
+		if err := tx.Set(doc.Ref, d); err != nil {
+			return err
+		}
+		leased = toJobEntity(d)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("firestore: leasing job: %w", err)
+	}
+
+	return leased, nil
+}
+
+// nextReady returns the oldest runnable job: either queued and due, or
+// running with an expired lease (its worker died mid-flight).
+func (q *JobQueue) nextReady(tx *firestore.Transaction, now time.Time) (*firestore.DocumentSnapshot, error) {
+	col := q.client.Collection(jobCollection)
+	queries := []firestore.Query{
+		col.Where("status", "==", string(job.StatusQueued)).
+			Where("run_at", "<=", now).
+			OrderBy("run_at", firestore.Asc).
+			Limit(1),
+		col.Where("status", "==", string(job.StatusRunning)).
+			Where("leased_until", "<=", now).
+			OrderBy("leased_until", firestore.Asc).
+			Limit(1),
+	}
+
+	for _, query := range queries {
+		docs, err := tx.Documents(query).GetAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(docs) > 0 {
+			return docs[0], nil
+		}
+	}
+	return nil, nil
+}
+
+func (q *JobQueue) Complete(ctx context.Context, id, owner string) error {
+	return q.updateLeased(ctx, id, owner, "completing", func(d *jobDoc) {
+		now := time.Now()
+		d.Status = string(job.StatusSucceeded)
+		d.LeaseOwner = ""
+		d.LeasedUntil = time.Time{}
+		d.FinishedAt = now
+		d.UpdatedAt = now
+	})
+}
+
+func (q *JobQueue) Retry(ctx context.Context, id, owner, lastError string, runAt time.Time) error {
+	return q.updateLeased(ctx, id, owner, "rescheduling", func(d *jobDoc) {
+		d.Status = string(job.StatusQueued)
+		d.LastError = lastError
+		d.RunAt = runAt
+		d.LeaseOwner = ""
+		d.LeasedUntil = time.Time{}
+		d.UpdatedAt = time.Now()
+	})
+}
+
+// Defer gives back the attempt taken by Lease, so deferrals never push a job
+// towards the dead-letter queue.
+func (q *JobQueue) Defer(ctx context.Context, id, owner, reason string, runAt time.Time) error {
+	return q.updateLeased(ctx, id, owner, "deferring", func(d *jobDoc) {
+		d.Status = string(job.StatusQueued)
+		if d.Attempts > 0 {
+			d.Attempts--
+		}
+		d.LastError = reason
+		d.RunAt = runAt
+		d.LeaseOwner = ""
+		d.LeasedUntil = time.Time{}
+		d.UpdatedAt = time.Now()
+	})
+}
+
+func (q *JobQueue) Bury(ctx context.Context, id, owner, lastError string) error {
+	return q.move(ctx, id, jobCollection, deadJobCollection, func(d *jobDoc) error {
+		if err := checkLease(*d, owner); err != nil {
+			return err
+		}
+		now := time.Now()
+		d.Status = string(job.StatusFailed)
+		d.LastError = lastError
+		d.LeaseOwner = ""
+		d.LeasedUntil = time.Time{}
+		d.FinishedAt = now
+		d.UpdatedAt = now
+		return nil
+	})
+}
+
+// updateLeased applies mutate to a job in a transaction, provided owner still
+// holds its lease. action names the operation in errors.
+func (q *JobQueue) updateLeased(ctx context.Context, id, owner, action string, mutate func(*jobDoc)) error {
+	ref := q.client.Collection(jobCollection).Doc(id)
+
+	err := q.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+
+		var d jobDoc
+		if err := doc.DataTo(&d); err != nil {
+			return err
+		}
+		if err := checkLease(d, owner); err != nil {
+			return err
+		}
+		mutate(&d)
+		return tx.Set(ref, d)
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return job.ErrJobNotFound
+		}
+		return fmt.Errorf("firestore: %s job %s: %w", action, id, err)
+	}
+	return nil
+}
+
+// checkLease returns job.ErrLeaseLost unless d is running under owner's lease.
+func checkLease(d jobDoc, owner string) error {
+	if d.Status != string(job.StatusRunning) || d.LeaseOwner != owner {
+		return job.ErrLeaseLost
+	}
+	return nil
+}
+
+// purgeBatch is how many succeeded jobs PurgeSucceeded deletes per batch.
+const purgeBatch = 200
+
+func (q *JobQueue) PurgeSucceeded(ctx context.Context, before time.Time) (int, error) {
+	purged := 0
+	for {
+		refs, err := q.client.Collection(jobCollection).
+			Where("status", "==", string(job.StatusSucceeded)).
+			Where("finished_at", "<", before).
+			Limit(purgeBatch).
+			Documents(ctx).GetAll()
+		if err != nil {
+			return purged, fmt.Errorf("firestore: listing succeeded jobs: %w", err)
+		}
+		if len(refs) == 0 {
+			return purged, nil
+		}
+
+		batch := q.client.Batch()
+		for _, doc := range refs {
+			batch.Delete(doc.Ref)
+		}
+		if _, err := batch.Commit(ctx); err != nil {
+			return purged, fmt.Errorf("firestore: purging succeeded jobs: %w", err)
+		}
+		purged += len(refs)
+	}
+}
+
+func (q *JobQueue) ListDead(ctx context.Context, limit int) ([]*job.Job, error) {
+	docs, err := q.client.Collection(deadJobCollection).
+		OrderBy("updated_at", firestore.Desc).
+		Limit(limit).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("firestore: listing dead jobs: %w", err)
+	}
+
+	result := make([]*job.Job, 0, len(docs))
+	for _, doc := range docs {
+		var d jobDoc
+		if err := doc.DataTo(&d); err != nil {
+			continue
+		}
+		result = append(result, toJobEntity(d))
+	}
+	return result, nil
+}
+
+func (q *JobQueue) RequeueDead(ctx context.Context, id string) error {
+	return q.move(ctx, id, deadJobCollection, jobCollection, func(d *jobDoc) error {
+		now := time.Now()
+		d.Status = string(job.StatusQueued)
+		d.Attempts = 0
+		d.RunAt = now
+		d.StartedAt = time.Time{}
+		d.FinishedAt = time.Time{}
+		d.UpdatedAt = now
+		return nil
+	})
+}
+
+func (q *JobQueue) PurgeDead(ctx context.Context, id string) error {
+	ref := q.client.Collection(deadJobCollection).Doc(id)
+	if _, err := ref.Get(ctx); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return job.ErrJobNotFound
+		}
+		return fmt.Errorf("firestore: finding dead job %s: %w", id, err)
+	}
+	if _, err := ref.Delete(ctx); err != nil {
+		return fmt.Errorf("firestore: purging dead job %s: %w", id, err)
+	}
+	return nil
+}
+
+func (q *JobQueue) PurgeAllDead(ctx context.Context) (int, error) {
+	refs, err := q.client.Collection(deadJobCollection).DocumentRefs(ctx).GetAll()
+	if err != nil {
+		return 0, fmt.Errorf("firestore: listing dead jobs: %w", err)
+	}
+
+	purged := 0
+	for _, ref := range refs {
+		if _, err := ref.Delete(ctx); err != nil {
+			return purged, fmt.Errorf("firestore: purging dead job %s: %w", ref.ID, err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (q *JobQueue) move(ctx context.Context, id, from, to string, mutate func(*jobDoc) error) error {
+	src := q.client.Collection(from).Doc(id)
+	dst := q.client.Collection(to).Doc(id)
+
+	err := q.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(src)
+		if err != nil {
+			return err
+		}
+
+		var d jobDoc
+		if err := doc.DataTo(&d); err != nil {
+			return err
+		}
+		if err := mutate(&d); err != nil {
+			return err
+		}
+
+		if err := tx.Set(dst, d); err != nil {
+			return err
+		}
+		return tx.Delete(src)
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return job.ErrJobNotFound
+		}
+		return fmt.Errorf("firestore: moving job %s from %s to %s: %w", id, from, to, err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package firebase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -22,18 +23,117 @@ func NewMatchRepository(client *firestore.Client) *MatchRepository {
 	return &MatchRepository{client: client}
 }
 
+type comparisonDoc struct {
+	Score         float64   `firestore:"score"`
+	RawScore      float64   `firestore:"raw_score"`
+	Analysis      string    `firestore:"analysis"`
+	PolicyVersion string    `firestore:"policy_version,omitempty"`
+	PromptID      string    `firestore:"prompt_id,omitempty"`
+	PromptVersion string    `firestore:"prompt_version,omitempty"`
+	Degraded      bool      `firestore:"degraded,omitempty"`
+	HomelessPhoto string    `firestore:"homeless_photo,omitempty"`
+	MissingPhoto  string    `firestore:"missing_photo,omitempty"`
+	ComparedAt    time.Time `firestore:"compared_at"`
+}
+
+type matchPolicyDoc struct {
+	SaveThreshold      float64 `firestore:"save_threshold"`
+	NotifyThreshold    float64 `firestore:"notify_threshold"`
+	CandidateLimit     int     `firestore:"candidate_limit"`
+	GenderFilter       string  `firestore:"gender_filter"`
+	SkinFilter         string  `firestore:"skin_filter"`
+	SoftFilterPenalty  float64 `firestore:"soft_filter_penalty"`
+	AgeTolerance       int     `firestore:"age_tolerance"`
+	AgeToleranceGrowth float64 `firestore:"age_tolerance_growth"`
+	MaxAgeTolerance    int     `firestore:"max_age_tolerance"`
+}
+
+func toMatchPolicyDoc(p matching.Policy) *matchPolicyDoc {
+	return &matchPolicyDoc{
+		SaveThreshold:      p.SaveThreshold,
+		NotifyThreshold:    p.NotifyThreshold,
+		CandidateLimit:     p.CandidateLimit,
+		GenderFilter:       string(p.GenderFilter),
+		SkinFilter:         string(p.SkinFilter),
+		SoftFilterPenalty:  p.SoftFilterPenalty,
+		AgeTolerance:       p.AgeTolerance,
+		AgeToleranceGrowth: p.AgeToleranceGrowth,
+		MaxAgeTolerance:    p.MaxAgeTolerance,
+	}
+}
+
+func toMatchPolicy(d *matchPolicyDoc) matching.Policy {
+	if d == nil {
+		return matching.Policy{}
+	}
+	return matching.Policy{
+		SaveThreshold:      d.SaveThreshold,
+		NotifyThreshold:    d.NotifyThreshold,
+		CandidateLimit:     d.CandidateLimit,
+		GenderFilter:       matching.FilterMode(d.GenderFilter),
+		SkinFilter:         matching.FilterMode(d.SkinFilter),
+		SoftFilterPenalty:  d.SoftFilterPenalty,
+		AgeTolerance:       d.AgeTolerance,
+		AgeToleranceGrowth: d.AgeToleranceGrowth,
+		MaxAgeTolerance:    d.MaxAgeTolerance,
+	}
+}
+
+type reviewDoc struct {
+	ReviewerID string    `firestore:"reviewer_id"`
+	Decision   string    `firestore:"decision"`
+	Notes      string    `firestore:"notes,omitempty"`
+	ReviewedAt time.Time `firestore:"reviewed_at"`
+}
+
 type matchDoc struct {
-	ID             string    `firestore:"id"`
-	HomelessID     string    `firestore:"homeless_id"`
-	MissingID      string    `firestore:"missing_id"`
-	Score          float64   `firestore:"score"`
-	Status         string    `firestore:"status"`
-	GeminiAnalysis string    `firestore:"gemini_analysis"`
-	CreatedAt      time.Time `firestore:"created_at"`
-	ReviewedAt     time.Time `firestore:"reviewed_at,omitempty"`
+	ID             string          `firestore:"id"`
+	HomelessID     string          `firestore:"homeless_id"`
+	MissingID      string          `firestore:"missing_id"`
+	Score          float64         `firestore:"score"`
+	Status         string          `firestore:"status"`
+	GeminiAnalysis string          `firestore:"gemini_analysis"`
+	Comparisons    []comparisonDoc `firestore:"comparisons,omitempty"`
+	Reviews        []reviewDoc     `firestore:"reviews,omitempty"`
+	PolicyVersion  string          `firestore:"policy_version,omitempty"`
+	Policy         *matchPolicyDoc `firestore:"policy,omitempty"`
+	PromptID       string          `firestore:"prompt_id,omitempty"`
+	PromptVersion  string          `firestore:"prompt_version,omitempty"`
+	CreatedAt      time.Time       `firestore:"created_at"`
+	UpdatedAt      time.Time       `firestore:"updated_at,omitempty"`
+	ReviewedAt     time.Time       `firestore:"reviewed_at,omitempty"`
+	ReviewedBy     string          `firestore:"reviewed_by,omitempty"`
 }
 
 func toMatchDoc(m *matching.Match) matchDoc {
+	comparisons := make([]comparisonDoc, 0, len(m.Comparisons))
+	for _, c := range m.Comparisons {
+		comparisons = append(comparisons, comparisonDoc{
+			Score:         c.Score,
+			RawScore:      c.RawScore,
+			Analysis:      c.Analysis,
+			PolicyVersion: c.PolicyVersion,
+			PromptID:      c.PromptID,
+			PromptVersion: c.PromptVersion,
+			Degraded:      c.Degraded,
+			HomelessPhoto: c.HomelessPhoto,
+			MissingPhoto:  c.MissingPhoto,
+			ComparedAt:    c.ComparedAt,
+		})
+	}
+	reviews := make([]reviewDoc, 0, len(m.Reviews))
+	for _, rv := range m.Reviews {
+		reviews = append(reviews, reviewDoc{
+			ReviewerID: rv.ReviewerID,
+			Decision:   string(rv.Decision),
+			Notes:      rv.Notes,
+			ReviewedAt: rv.ReviewedAt,
+		})
+	}
+	var policy *matchPolicyDoc
+	if m.PolicyVersion != "" {
+		policy = toMatchPolicyDoc(m.Policy)
+	}
 	return matchDoc{
 		ID:             m.ID,
 		HomelessID:     m.HomelessID,
@@ -41,12 +141,44 @@ func toMatchDoc(m *matching.Match) matchDoc {
 		Score:          m.Score,
 		Status:         string(m.Status),
 		GeminiAnalysis: m.GeminiAnalysis,
+		Comparisons:    comparisons,
+		Reviews:        reviews,
+		PolicyVersion:  m.PolicyVersion,
+		Policy:         policy,
+		PromptID:       m.PromptID,
+		PromptVersion:  m.PromptVersion,
 		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
 		ReviewedAt:     m.ReviewedAt,
+		ReviewedBy:     m.ReviewedBy,
 	}
 }
 
 func toMatchEntity(d matchDoc) *matching.Match {
+	comparisons := make([]matching.Comparison, 0, len(d.Comparisons))
+	for _, c := range d.Comparisons {
+		comparisons = append(comparisons, matching.Comparison{
+			Score:         c.Score,
+			RawScore:      c.RawScore,
+			Analysis:      c.Analysis,
+			PolicyVersion: c.PolicyVersion,
+			PromptID:      c.PromptID,
+			PromptVersion: c.PromptVersion,
+			Degraded:      c.Degraded,
+			HomelessPhoto: c.HomelessPhoto,
+			MissingPhoto:  c.MissingPhoto,
+			ComparedAt:    c.ComparedAt,
+		})
+	}
+	reviews := make([]matching.Review, 0, len(d.Reviews))
+	for _, rv := range d.Reviews {
+		reviews = append(reviews, matching.Review{
+			ReviewerID: rv.ReviewerID,
+			Decision:   matching.MatchStatus(rv.Decision),
+			Notes:      rv.Notes,
+			ReviewedAt: rv.ReviewedAt,
+		})
+	}
 	return &matching.Match{
 		ID:             d.ID,
 		HomelessID:     d.HomelessID,
@@ -54,17 +186,57 @@ func toMatchEntity(d matchDoc) *matching.Match {
 		Score:          d.Score,
 		Status:         matching.MatchStatus(d.Status),
 		GeminiAnalysis: d.GeminiAnalysis,
+		Comparisons:    comparisons,
+		Reviews:        reviews,
+		PolicyVersion:  d.PolicyVersion,
+		Policy:         toMatchPolicy(d.Policy),
+		PromptID:       d.PromptID,
+		PromptVersion:  d.PromptVersion,
 		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
 		ReviewedAt:     d.ReviewedAt,
+		ReviewedBy:     d.ReviewedBy,
 	}
 }
 
-func (r *MatchRepository) Create(ctx context.Context, m *matching.Match) error {
-	_, err := r.client.Collection(matchCollection).Doc(m.ID).Set(ctx, toMatchDoc(m))
+// Upsert runs in a transaction so concurrent workers comparing the same pair
+// end up with a single document holding both comparisons. Matches stored
+// before IDs were derived from the pair have a random ID, so when no document
+// exists under the pair ID the pair is looked up by its fields and merged into
+// that older document instead of creating a second one.
+func (r *MatchRepository) Upsert(ctx context.Context, m *matching.Match) (*matching.Match, error) {
+	ref := r.client.Collection(matchCollection).Doc(m.ID)
+
+	var stored *matching.Match
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			if status.Code(err) != codes.NotFound {
+				return err
+			}
+			docs, err := tx.Documents(r.pairQuery(m.HomelessID, m.MissingID)).GetAll()
+			if err != nil {
+				return err
+			}
+			if len(docs) == 0 {
+				stored = m
+				return tx.Create(ref, toMatchDoc(m))
+			}
+			doc = docs[0]
+		}
+
+		var d matchDoc
+		if err := doc.DataTo(&d); err != nil {
+			return err
+		}
+		stored = toMatchEntity(d)
+		stored.MergeComparisons(m)
+		return tx.Set(doc.Ref, toMatchDoc(stored))
+	})
 	if err != nil {
-		return fmt.Errorf("firestore: creating match: %w", err)
+		return nil, fmt.Errorf("firestore: upserting match: %w", err)
 	}
-	return nil
+	return stored, nil
 }
 
 func (r *MatchRepository) FindByID(ctx context.Context, id string) (*matching.Match, error) {
@@ -84,6 +256,36 @@ func (r *MatchRepository) FindByID(ctx context.Context, id string) (*matching.Ma
 	return toMatchEntity(d), nil
 }
 
+func (r *MatchRepository) FindByPair(ctx context.Context, homelessID, missingID string) (*matching.Match, error) {
+	m, err := r.FindByID(ctx, matching.PairID(homelessID, missingID))
+	if !errors.Is(err, matching.ErrMatchNotFound) {
+		return m, err
+	}
+
+	docs, err := r.pairQuery(homelessID, missingID).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("firestore: finding match by pair: %w", err)
+	}
+	if len(docs) == 0 {
+		return nil, matching.ErrMatchNotFound
+	}
+
+	var d matchDoc
+	if err := docs[0].DataTo(&d); err != nil {
+		return nil, fmt.Errorf("firestore: decoding match: %w", err)
+	}
+	return toMatchEntity(d), nil
+}
+
+// pairQuery finds the match of a pair by its fields, which also covers
+// matches stored under a random ID.
+func (r *MatchRepository) pairQuery(homelessID, missingID string) firestore.Query {
+	return r.client.Collection(matchCollection).
+		Where("homeless_id", "==", homelessID).
+		Where("missing_id", "==", missingID).
+		Limit(1)
+}
+
 func (r *MatchRepository) FindByHomelessID(ctx context.Context, homelessID string) ([]*matching.Match, error) {
 	docs, err := r.client.Collection(matchCollection).
 		Where("homeless_id", "==", homelessID).
@@ -124,13 +326,29 @@ func (r *MatchRepository) FindByMissingID(ctx context.Context, missingID string)
 	return result, nil
 }
 
-func (r *MatchRepository) UpdateStatus(ctx context.Context, id string, status matching.MatchStatus) error {
-	_, err := r.client.Collection(matchCollection).Doc(id).Update(ctx, []firestore.Update{
-		{Path: "status", Value: string(status)},
-		{Path: "reviewed_at", Value: time.Now()},
+func (r *MatchRepository) AddReview(ctx context.Context, id string, rv matching.Review) (*matching.Match, error) {
+	ref := r.client.Collection(matchCollection).Doc(id)
+
+	var updated *matching.Match
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return err
+		}
+
+		var d matchDoc
+		if err := doc.DataTo(&d); err != nil {
+			return err
+		}
+		updated = toMatchEntity(d)
+		updated.ApplyReview(rv)
+		return tx.Set(ref, toMatchDoc(updated))
 	})
 	if err != nil {
-		return fmt.Errorf("firestore: updating match status: %w", err)
+		if status.Code(err) == codes.NotFound {
+			return nil, matching.ErrMatchNotFound
+		}
+		return nil, fmt.Errorf("firestore: adding match review: %w", err)
 	}
-	return nil
+	return updated, nil
 }
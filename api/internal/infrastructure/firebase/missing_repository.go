@@ -11,6 +11,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/l3co/traceo-api/internal/domain/missing"
+	"github.com/l3co/traceo-api/internal/domain/shared"
 )
 
 const missingCollection = "missing"
@@ -24,32 +25,40 @@ func NewMissingRepository(client *firestore.Client) *MissingRepository {
 }
 
 type missingDoc struct {
-	ID                  string    `firestore:"id"`
-	UserID              string    `firestore:"user_id"`
-	Name                string    `firestore:"name"`
-	Nickname            string    `firestore:"nickname,omitempty"`
-	BirthDate           time.Time `firestore:"birth_date"`
-	DateOfDisappearance time.Time `firestore:"date_of_disappearance"`
-	Height              string    `firestore:"height,omitempty"`
-	Clothes             string    `firestore:"clothes,omitempty"`
-	Gender              string    `firestore:"gender"`
-	Eyes                string    `firestore:"eyes"`
-	Hair                string    `firestore:"hair"`
-	Skin                string    `firestore:"skin"`
-	PhotoURL            string    `firestore:"photo_url,omitempty"`
-	Lat                 float64   `firestore:"lat"`
-	Lng                 float64   `firestore:"lng"`
-	Address             string    `firestore:"address,omitempty"`
-	Status              string    `firestore:"status"`
-	EventReport         string    `firestore:"event_report,omitempty"`
-	TattooDescription   string    `firestore:"tattoo_description,omitempty"`
-	ScarDescription     string    `firestore:"scar_description,omitempty"`
-	WasChild            bool      `firestore:"was_child"`
-	AgeProgressionURLs  []string  `firestore:"age_progression_urls,omitempty"`
-	Slug                string    `firestore:"slug"`
-	NameLowercase       string    `firestore:"name_lowercase"`
-	CreatedAt           time.Time `firestore:"created_at"`
-	UpdatedAt           time.Time `firestore:"updated_at"`
+	ID                  string             `firestore:"id"`
+	UserID              string             `firestore:"user_id"`
+	Name                string             `firestore:"name"`
+	Nickname            string             `firestore:"nickname,omitempty"`
+	BirthDate           time.Time          `firestore:"birth_date"`
+	DateOfDisappearance time.Time          `firestore:"date_of_disappearance"`
+	Height              string             `firestore:"height,omitempty"`
+	Clothes             string             `firestore:"clothes,omitempty"`
+	Gender              string             `firestore:"gender"`
+	Eyes                string             `firestore:"eyes"`
+	Hair                string             `firestore:"hair"`
+	Skin                string             `firestore:"skin"`
+	PhotoURL            string             `firestore:"photo_url,omitempty"`
+	Photos              []photoDoc         `firestore:"photos,omitempty"`
+	HashBands           []string           `firestore:"photo_hash_bands,omitempty"`
+	BlurredPhotoURL     string             `firestore:"blurred_photo_url,omitempty"`
+	Visibility          string             `firestore:"visibility,omitempty"`
+	Lat                 float64            `firestore:"lat"`
+	Lng                 float64            `firestore:"lng"`
+	Address             string             `firestore:"address,omitempty"`
+	Status              string             `firestore:"status"`
+	Found               *foundDoc          `firestore:"found,omitempty"`
+	StatusHistory       []statusChangeDoc  `firestore:"status_history,omitempty"`
+	EventReport         string             `firestore:"event_report,omitempty"`
+	TattooDescription   string             `firestore:"tattoo_description,omitempty"`
+	ScarDescription     string             `firestore:"scar_description,omitempty"`
+	WasChild            bool               `firestore:"was_child"`
+	AgeProgression      *ageProgressionDoc `firestore:"age_progression,omitempty"`
+	AgeProgressionURLs  []string           `firestore:"age_progression_urls,omitempty"`
+	AgeRequestedAt      time.Time          `firestore:"age_progression_requested_at"`
+	Slug                string             `firestore:"slug"`
+	NameLowercase       string             `firestore:"name_lowercase"`
+	CreatedAt           time.Time          `firestore:"created_at"`
+	UpdatedAt           time.Time          `firestore:"updated_at"`
 }
 
 func toMissingDoc(m *missing.Missing) missingDoc {
@@ -67,15 +76,23 @@ func toMissingDoc(m *missing.Missing) missingDoc {
 		Hair:                string(m.Hair),
 		Skin:                string(m.Skin),
 		PhotoURL:            m.PhotoURL,
+		Photos:              toPhotoDocs(m.Photos),
+		HashBands:           m.Photos.HashBands(),
+		BlurredPhotoURL:     m.BlurredPhotoURL,
+		Visibility:          string(m.Visibility),
 		Lat:                 m.Location.Lat,
 		Lng:                 m.Location.Lng,
 		Address:             m.Location.Address,
 		Status:              string(m.Status),
+		Found:               toFoundDoc(m.FoundAt, m.FoundLocation),
+		StatusHistory:       toStatusChangeDocs(m.StatusHistory),
 		EventReport:         m.EventReport,
 		TattooDescription:   m.TattooDescription,
 		ScarDescription:     m.ScarDescription,
 		WasChild:            m.WasChild,
-		AgeProgressionURLs:  m.AgeProgressionURLs,
+		AgeProgression:      toAgeProgressionDoc(m.AgeProgression),
+		AgeProgressionURLs:  ageProgressionURLs(m.AgeProgression),
+		AgeRequestedAt:      m.AgeProgressionRequestedAt,
 		Slug:                m.Slug,
 		NameLowercase:       m.NameLowercase,
 		CreatedAt:           m.CreatedAt,
@@ -84,7 +101,7 @@ func toMissingDoc(m *missing.Missing) missingDoc {
 }
 
 func toMissingEntity(d missingDoc) *missing.Missing {
-	return &missing.Missing{
+	m := &missing.Missing{
 		ID:                  d.ID,
 		UserID:              d.UserID,
 		Name:                d.Name,
@@ -98,13 +115,17 @@ func toMissingEntity(d missingDoc) *missing.Missing {
 		Hair:                missing.HairColor(d.Hair),
 		Skin:                missing.SkinColor(d.Skin),
 		PhotoURL:            d.PhotoURL,
+		Photos:              toPhotoEntities(d.Photos, d.PhotoURL, d.CreatedAt),
+		BlurredPhotoURL:     d.BlurredPhotoURL,
+		Visibility:          missing.Visibility(d.Visibility),
 		Location:            missing.GeoPoint{Lat: d.Lat, Lng: d.Lng, Address: d.Address},
 		Status:              missing.Status(d.Status),
+		StatusHistory:       toStatusChangeEntities(d.StatusHistory),
 		EventReport:         d.EventReport,
 		TattooDescription:   d.TattooDescription,
 		ScarDescription:     d.ScarDescription,
 		WasChild:            d.WasChild,
-		AgeProgressionURLs:  d.AgeProgressionURLs,
+		AgeProgression:      toAgeProgressionEntity(d.AgeProgression, d.AgeProgressionURLs),
 		Slug:                d.Slug,
 		NameLowercase:       d.NameLowercase,
 		Timestamps: missing.Timestamps{
@@ -112,6 +133,151 @@ func toMissingEntity(d missingDoc) *missing.Missing {
 			UpdatedAt: d.UpdatedAt,
 		},
 	}
+	m.FoundAt, m.FoundLocation = d.Found.entity()
+	m.AgeProgressionRequestedAt = d.AgeRequestedAt
+	return m
+}
+
+// foundDoc is when and where a person was found.
+type foundDoc struct {
+	At      time.Time `firestore:"at"`
+	Lat     float64   `firestore:"lat"`
+	Lng     float64   `firestore:"lng"`
+	Address string    `firestore:"address,omitempty"`
+}
+
+type statusChangeDoc struct {
+	From      string    `firestore:"from,omitempty"`
+	To        string    `firestore:"to"`
+	ChangedBy string    `firestore:"changed_by"`
+	Role      string    `firestore:"role"`
+	ChangedAt time.Time `firestore:"changed_at"`
+	Found     *foundDoc `firestore:"found,omitempty"`
+	Note      string    `firestore:"note,omitempty"`
+}
+
+func toFoundDoc(at time.Time, loc missing.GeoPoint) *foundDoc {
+	if at.IsZero() {
+		return nil
+	}
+	return &foundDoc{At: at, Lat: loc.Lat, Lng: loc.Lng, Address: loc.Address}
+}
+
+func (d *foundDoc) entity() (time.Time, missing.GeoPoint) {
+	if d == nil {
+		return time.Time{}, missing.GeoPoint{}
+	}
+	return d.At, missing.GeoPoint{Lat: d.Lat, Lng: d.Lng, Address: d.Address}
+}
+
+func toStatusChangeDocs(changes []missing.StatusChange) []statusChangeDoc {
+	docs := make([]statusChangeDoc, 0, len(changes))
+	for _, c := range changes {
+		docs = append(docs, statusChangeDoc{
+			From:      string(c.From),
+			To:        string(c.To),
+			ChangedBy: c.ChangedBy,
+			Role:      string(c.Role),
+			ChangedAt: c.ChangedAt,
+			Found:     toFoundDoc(c.FoundAt, c.FoundLocation),
+			Note:      c.Note,
+		})
+	}
+	return docs
+}
+
+func toStatusChangeEntities(docs []statusChangeDoc) []missing.StatusChange {
+	changes := make([]missing.StatusChange, 0, len(docs))
+	for _, d := range docs {
+		c := missing.StatusChange{
+			From:      missing.Status(d.From),
+			To:        missing.Status(d.To),
+			ChangedBy: d.ChangedBy,
+			Role:      missing.Role(d.Role),
+			ChangedAt: d.ChangedAt,
+			Note:      d.Note,
+		}
+		c.FoundAt, c.FoundLocation = d.Found.entity()
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// openStatuses is missing.OpenStatuses in the form "in" queries take.
+func openStatuses() []string {
+	var statuses []string
+	for _, st := range missing.OpenStatuses() {
+		statuses = append(statuses, string(st))
+	}
+	return statuses
+}
+
+type ageProgressionDoc struct {
+	Description    string         `firestore:"description,omitempty"`
+	Images         []agedImageDoc `firestore:"images"`
+	Generator      string         `firestore:"generator,omitempty"`
+	SourcePhotoURL string         `firestore:"source_photo_url"`
+	GeneratedAt    time.Time      `firestore:"generated_at"`
+}
+
+type agedImageDoc struct {
+	Age int    `firestore:"age"`
+	URL string `firestore:"url"`
+}
+
+func toAgeProgressionDoc(p *missing.AgeProgression) *ageProgressionDoc {
+	if p == nil {
+		return nil
+	}
+	d := &ageProgressionDoc{
+		Description:    p.Description,
+		Images:         make([]agedImageDoc, 0, len(p.Images)),
+		Generator:      p.Generator,
+		SourcePhotoURL: p.SourcePhotoURL,
+		GeneratedAt:    p.GeneratedAt,
+	}
+	for _, img := range p.Images {
+		d.Images = append(d.Images, agedImageDoc{Age: img.Age, URL: img.URL})
+	}
+	return d
+}
+
+// ageProgressionURLs is kept in age_progression_urls for clients that read
+// the bare list.
+func ageProgressionURLs(p *missing.AgeProgression) []string {
+	if p == nil {
+		return nil
+	}
+	urls := make([]string, 0, len(p.Images))
+	for _, img := range p.Images {
+		urls = append(urls, img.URL)
+	}
+	return urls
+}
+
+// toAgeProgressionEntity falls back to the bare URL list written before
+// descriptions and target ages were stored.
+func toAgeProgressionEntity(d *ageProgressionDoc, legacyURLs []string) *missing.AgeProgression {
+	if d == nil {
+		if len(legacyURLs) == 0 {
+			return nil
+		}
+		p := &missing.AgeProgression{}
+		for _, url := range legacyURLs {
+			p.Images = append(p.Images, missing.AgedImage{URL: url})
+		}
+		return p
+	}
+	p := &missing.AgeProgression{
+		Description:    d.Description,
+		Generator:      d.Generator,
+		SourcePhotoURL: d.SourcePhotoURL,
+		GeneratedAt:    d.GeneratedAt,
+	}
+	for _, img := range d.Images {
+		p.Images = append(p.Images, missing.AgedImage{Age: img.Age, URL: img.URL})
+	}
+	return p
 }
 
 func (r *MissingRepository) Create(ctx context.Context, m *missing.Missing) error {
@@ -268,6 +434,28 @@ func (r *MissingRepository) CountByGender(ctx context.Context) ([]missing.Gender
 	return result, nil
 }
 
+func (r *MissingRepository) CountByStatus(ctx context.Context) ([]missing.StatusStat, error) {
+	docs, err := r.client.Collection(missingCollection).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("firestore: counting by status: %w", err)
+	}
+
+	counts := map[string]int64{}
+	for _, doc := range docs {
+		var d missingDoc
+		if err := doc.DataTo(&d); err != nil {
+			continue
+		}
+		counts[d.Status]++
+	}
+
+	result := make([]missing.StatusStat, 0, len(counts))
+	for st, c := range counts {
+		result = append(result, missing.StatusStat{Status: missing.Status(st), Count: c})
+	}
+	return result, nil
+}
+
 func (r *MissingRepository) CountByYear(ctx context.Context) ([]missing.YearStat, error) {
 	docs, err := r.client.Collection(missingCollection).Documents(ctx).GetAll()
 	if err != nil {
@@ -331,19 +519,109 @@ func (r *MissingRepository) FindLocations(ctx context.Context, limit int) ([]mis
 }
 
 func (r *MissingRepository) FindCandidates(ctx context.Context, filter missing.CandidateFilter) ([]*missing.Missing, error) {
+	statuses := make([]string, 0, len(filter.Statuses))
+	for _, st := range filter.Statuses {
+		statuses = append(statuses, string(st))
+	}
 	query := r.client.Collection(missingCollection).
-		Where("status", "==", string(filter.Status)).
-		Where("gender", "==", string(filter.Gender)).
-		Where("skin", "==", string(filter.Skin))
-
-	if filter.Limit > 0 {
-		query = query.Limit(filter.Limit)
+		Where("status", "in", statuses)
+	if filter.Gender != "" {
+		query = query.Where("gender", "==", string(filter.Gender))
+	}
+	if filter.Skin != "" {
+		query = query.Where("skin", "==", string(filter.Skin))
 	}
 
-	docs, err := query.Documents(ctx).GetAll()
+	result, err := findMatching(ctx, query, "", filter.Limit, func(doc *firestore.DocumentSnapshot) (*missing.Missing, bool) {
+		var d missingDoc
+		if err := doc.DataTo(&d); err != nil {
+			return nil, false
+		}
+		entity := toMissingEntity(d)
+		return entity, filter.Matches(entity)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("firestore: finding candidates: %w", err)
 	}
+	return result, nil
+}
+
+func (r *MissingRepository) UpdateAgeProgression(ctx context.Context, id string, p *missing.AgeProgression) error {
+	_, err := r.client.Collection(missingCollection).Doc(id).Update(ctx, []firestore.Update{
+		{Path: "age_progression", Value: toAgeProgressionDoc(p)},
+		{Path: "age_progression_urls", Value: ageProgressionURLs(p)},
+		{Path: "updated_at", Value: time.Now()},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return missing.ErrMissingNotFound
+		}
+		return fmt.Errorf("firestore: updating age progression for %s: %w", id, err)
+	}
+	return nil
+}
+
+func (r *MissingRepository) UpdatePhotos(ctx context.Context, m *missing.Missing) error {
+	_, err := r.client.Collection(missingCollection).Doc(m.ID).Update(ctx, []firestore.Update{
+		{Path: "photos", Value: toPhotoDocs(m.Photos)},
+		{Path: "photo_url", Value: m.PhotoURL},
+		{Path: "photo_hash_bands", Value: m.Photos.HashBands()},
+		{Path: "blurred_photo_url", Value: m.BlurredPhotoURL},
+		{Path: "updated_at", Value: time.Now()},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return missing.ErrMissingNotFound
+		}
+		return fmt.Errorf("firestore: updating photos for %s: %w", m.ID, err)
+	}
+	return nil
+}
+
+// FindByPhotoHash returns the cases with a photo that shares an index band
+// with h, up to limit per band. Each band is queried on its own so that a
+// band shared by many unrelated photos cannot crowd out the others. Some
+// results may be farther from h than DuplicateMaxDistance.
+func (r *MissingRepository) FindByPhotoHash(ctx context.Context, h shared.PerceptualHash, limit int) ([]*missing.Missing, error) {
+	var result []*missing.Missing
+	seen := make(map[string]bool)
+	for _, band := range h.Bands() {
+		docs, err := r.client.Collection(missingCollection).
+			Where("photo_hash_bands", "array-contains", band).
+			Limit(limit).
+			Documents(ctx).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("firestore: finding missing by photo hash: %w", err)
+		}
+
+		for _, doc := range docs {
+			if seen[doc.Ref.ID] {
+				continue
+			}
+			seen[doc.Ref.ID] = true
+			var d missingDoc
+			if err := doc.DataTo(&d); err != nil {
+				continue
+			}
+			result = append(result, toMissingEntity(d))
+		}
+	}
+	return result, nil
+}
+
+// FindAgeProgressionDue needs a composite index on status and
+// age_progression_requested_at. Cases stored before the field existed are
+// not found until cmd/backfill -task age-progression-requests has run.
+func (r *MissingRepository) FindAgeProgressionDue(ctx context.Context, cutoff time.Time, limit int) ([]*missing.Missing, error) {
+	docs, err := r.client.Collection(missingCollection).
+		Where("status", "in", openStatuses()).
+		Where("age_progression_requested_at", "<", cutoff).
+		OrderBy("age_progression_requested_at", firestore.Asc).
+		Limit(limit).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("firestore: finding cases due for age progression: %w", err)
+	}
 
 	result := make([]*missing.Missing, 0, len(docs))
 	for _, doc := range docs {
@@ -351,27 +629,20 @@ func (r *MissingRepository) FindCandidates(ctx context.Context, filter missing.C
 		if err := doc.DataTo(&d); err != nil {
 			continue
 		}
-		entity := toMissingEntity(d)
-		age := entity.Age()
-		if filter.MinAge > 0 && age < filter.MinAge {
-			continue
-		}
-		if filter.MaxAge > 0 && age > filter.MaxAge {
-			continue
-		}
-		result = append(result, entity)
+		result = append(result, toMissingEntity(d))
 	}
-
 	return result, nil
 }
 
-func (r *MissingRepository) UpdateAgeProgressionURLs(ctx context.Context, id string, urls []string) error {
+func (r *MissingRepository) MarkAgeProgressionRequested(ctx context.Context, id string, at time.Time) error {
 	_, err := r.client.Collection(missingCollection).Doc(id).Update(ctx, []firestore.Update{
-		{Path: "age_progression_urls", Value: urls},
-		{Path: "updated_at", Value: time.Now()},
+		{Path: "age_progression_requested_at", Value: at},
 	})
 	if err != nil {
-		return fmt.Errorf("firestore: updating age progression urls for %s: %w", id, err)
+		if status.Code(err) == codes.NotFound {
+			return missing.ErrMissingNotFound
+		}
+		return fmt.Errorf("firestore: marking age progression requested for %s: %w", id, err)
 	}
 	return nil
 }
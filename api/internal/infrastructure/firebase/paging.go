@@ -0,0 +1,51 @@
+package firebase
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+)
+
+// matchPageSize is how many documents findMatching reads per round trip.
+const matchPageSize = 100
+
+// findMatching pages through query in document ID order, starting after the
+// document ID after when it is set, and keeps the documents that decode
+// accepts, until limit are kept or the query runs out. A limit of zero or
+// less keeps every match. It lets a filter that Firestore cannot express,
+// such as an age range that must also admit records without a birth date,
+// run in memory without the query limit cutting off matches further down the
+// collection.
+func findMatching[T any](ctx context.Context, query firestore.Query, after string, limit int, decode func(*firestore.DocumentSnapshot) (T, bool)) ([]T, error) {
+	query = query.OrderBy(firestore.DocumentID, firestore.Asc).Limit(matchPageSize)
+
+	result := make([]T, 0)
+	var last *firestore.DocumentSnapshot
+	for {
+		page := query
+		switch {
+		case last != nil:
+			page = page.StartAfter(last)
+		case after != "":
+			page = page.StartAfter(after)
+		}
+		docs, err := page.Documents(ctx).GetAll()
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			v, ok := decode(doc)
+			if !ok {
+				continue
+			}
+			result = append(result, v)
+			if limit > 0 && len(result) == limit {
+				return result, nil
+			}
+		}
+		if len(docs) < matchPageSize {
+			return result, nil
+		}
+		last = docs[len(docs)-1]
+	}
+}
@@ -0,0 +1,55 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+const pairLedgerCollection = "pair_ledger"
+
+// PairLedger is a matching.PairLedger with one document per pair key.
+type PairLedger struct {
+	client *firestore.Client
+}
+
+func NewPairLedger(client *firestore.Client) *PairLedger {
+	return &PairLedger{client: client}
+}
+
+type pairLedgerDoc struct {
+	ComparedAt time.Time `firestore:"compared_at"`
+}
+
+func (l *PairLedger) Compared(ctx context.Context, keys []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	refs := make([]*firestore.DocumentRef, len(keys))
+	for i, key := range keys {
+		refs[i] = l.client.Collection(pairLedgerCollection).Doc(key)
+	}
+
+	docs, err := l.client.GetAll(ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("firestore: reading pair ledger: %w", err)
+	}
+	for _, doc := range docs {
+		if doc.Exists() {
+			result[doc.Ref.ID] = true
+		}
+	}
+	return result, nil
+}
+
+func (l *PairLedger) Record(ctx context.Context, key string, comparedAt time.Time) error {
+	_, err := l.client.Collection(pairLedgerCollection).Doc(key).Set(ctx, pairLedgerDoc{ComparedAt: comparedAt})
+	if err != nil {
+		return fmt.Errorf("firestore: writing pair ledger: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package firebase
+
+import (
+	"time"
+
+	"github.com/l3co/traceo-api/internal/domain/shared"
+)
+
+type photoDoc struct {
+	ID           string    `firestore:"id"`
+	URL          string    `firestore:"url"`
+	TakenAt      time.Time `firestore:"taken_at"`
+	AgeAtPhoto   int       `firestore:"age_at_photo"`
+	Primary      bool      `firestore:"primary"`
+	FaceDetected bool      `firestore:"face_detected"`
+	AddedAt      time.Time `firestore:"added_at"`
+	Hash         string    `firestore:"hash,omitempty"`
+}
+
+func toPhotoDocs(photos shared.Photos) []photoDoc {
+	docs := make([]photoDoc, 0, len(photos))
+	for _, p := range photos {
+		docs = append(docs, photoDoc{
+			ID:           p.ID,
+			URL:          p.URL,
+			TakenAt:      p.TakenAt,
+			AgeAtPhoto:   p.AgeAtPhoto,
+			Primary:      p.Primary,
+			FaceDetected: p.FaceDetected,
+			AddedAt:      p.AddedAt,
+			Hash:         photoHashString(p.Hash),
+		})
+	}
+	return docs
+}
+
+// toPhotoEntities turns a record written before photo lists existed, which
+// only has photo_url, into a single primary photo.
+func toPhotoEntities(docs []photoDoc, legacyURL string, createdAt time.Time) shared.Photos {
+	if len(docs) == 0 {
+		if legacyURL == "" {
+			return nil
+		}
+		return shared.Photos{{ID: "primary", URL: legacyURL, Primary: true, AddedAt: createdAt}}
+	}
+	photos := make(shared.Photos, 0, len(docs))
+	for _, d := range docs {
+		// An unreadable hash only drops the photo from duplicate checks.
+		hash, _ := shared.ParsePerceptualHash(d.Hash)
+		photos = append(photos, shared.Photo{
+			ID:           d.ID,
+			URL:          d.URL,
+			TakenAt:      d.TakenAt,
+			AgeAtPhoto:   d.AgeAtPhoto,
+			Primary:      d.Primary,
+			FaceDetected: d.FaceDetected,
+			AddedAt:      d.AddedAt,
+			Hash:         hash,
+		})
+	}
+	return photos
+}
+
+func photoHashString(h shared.PerceptualHash) string {
+	if h == 0 {
+		return ""
+	}
+	return h.String()
+}
@@ -0,0 +1,62 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const schedulerLeaseCollection = "scheduler_leases"
+
+// SchedulerLeases implements scheduler.Leases with one document per task
+// holding the last claimed slot.
+type SchedulerLeases struct {
+	client *firestore.Client
+}
+
+func NewSchedulerLeases(client *firestore.Client) *SchedulerLeases {
+	return &SchedulerLeases{client: client}
+}
+
+type schedulerLeaseDoc struct {
+	Slot      time.Time `firestore:"slot"`
+	Holder    string    `firestore:"holder"`
+	ClaimedAt time.Time `firestore:"claimed_at"`
+}
+
+func (l *SchedulerLeases) Claim(ctx context.Context, task string, slot time.Time, holder string) (bool, error) {
+	ref := l.client.Collection(schedulerLeaseCollection).Doc(task)
+
+	var won bool
+	err := l.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		won = false
+
+		doc, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			var d schedulerLeaseDoc
+			if err := doc.DataTo(&d); err != nil {
+				return err
+			}
+			if !slot.After(d.Slot) {
+				return nil
+			}
+		}
+
+		if err := tx.Set(ref, schedulerLeaseDoc{Slot: slot, Holder: holder, ClaimedAt: time.Now()}); err != nil {
+			return err
+		}
+		won = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("firestore: claiming %s: %w", task, err)
+	}
+	return won, nil
+}
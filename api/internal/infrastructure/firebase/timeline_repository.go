@@ -0,0 +1,102 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/l3co/traceo-api/internal/domain/timeline"
+)
+
+const timelineCollection = "timeline_events"
+
+type TimelineRepository struct {
+	client *firestore.Client
+}
+
+func NewTimelineRepository(client *firestore.Client) *TimelineRepository {
+	return &TimelineRepository{client: client}
+}
+
+type timelineEventDoc struct {
+	ID         string            `firestore:"id"`
+	MissingID  string            `firestore:"missing_id"`
+	Type       string            `firestore:"type"`
+	ActorID    string            `firestore:"actor_id,omitempty"`
+	OccurredAt time.Time         `firestore:"occurred_at"`
+	Details    map[string]string `firestore:"details,omitempty"`
+}
+
+func toTimelineEventDoc(e *timeline.Event) timelineEventDoc {
+	return timelineEventDoc{
+		ID:         e.ID,
+		MissingID:  e.MissingID,
+		Type:       string(e.Type),
+		ActorID:    e.ActorID,
+		OccurredAt: e.OccurredAt,
+		Details:    e.Details,
+	}
+}
+
+func toTimelineEventEntity(d timelineEventDoc) *timeline.Event {
+	return &timeline.Event{
+		ID:         d.ID,
+		MissingID:  d.MissingID,
+		Type:       timeline.Type(d.Type),
+		ActorID:    d.ActorID,
+		OccurredAt: d.OccurredAt,
+		Details:    d.Details,
+	}
+}
+
+// Append uses Create so that an existing event is never overwritten.
+func (r *TimelineRepository) Append(ctx context.Context, e *timeline.Event) error {
+	_, err := r.client.Collection(timelineCollection).Doc(e.ID).Create(ctx, toTimelineEventDoc(e))
+	if err != nil {
+		return fmt.Errorf("firestore: appending timeline event: %w", err)
+	}
+	return nil
+}
+
+func (r *TimelineRepository) FindByMissingID(ctx context.Context, missingID string, opts timeline.ListOptions) ([]*timeline.Event, string, error) {
+	query := r.client.Collection(timelineCollection).
+		Where("missing_id", "==", missingID)
+	if len(opts.Types) > 0 {
+		types := make([]string, 0, len(opts.Types))
+		for _, t := range opts.Types {
+			types = append(types, string(t))
+		}
+		query = query.Where("type", "in", types)
+	}
+	query = query.OrderBy("occurred_at", firestore.Asc).Limit(opts.PageSize)
+
+	if opts.After != "" {
+		cursorDoc, err := r.client.Collection(timelineCollection).Doc(opts.After).Get(ctx)
+		if err == nil && cursorDoc.Data()["missing_id"] == missingID {
+			query = query.StartAfter(cursorDoc)
+		}
+	}
+
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, "", fmt.Errorf("firestore: listing timeline events: %w", err)
+	}
+
+	result := make([]*timeline.Event, 0, len(docs))
+	for _, doc := range docs {
+		var d timelineEventDoc
+		if err := doc.DataTo(&d); err != nil {
+			continue
+		}
+		result = append(result, toTimelineEventEntity(d))
+	}
+
+	var nextCursor string
+	if len(docs) == opts.PageSize {
+		nextCursor = docs[len(docs)-1].Ref.ID
+	}
+
+	return result, nextCursor, nil
+}
@@ -0,0 +1,93 @@
+package firebase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const usageCollection = "ai_usage"
+
+// UsageCounter keeps one document per UTC day ("day_2006-01-02") and month
+// ("month_2006-01") in the ai_usage collection.
+type UsageCounter struct {
+	client *firestore.Client
+}
+
+func NewUsageCounter(client *firestore.Client) *UsageCounter {
+	return &UsageCounter{client: client}
+}
+
+type usageDoc struct {
+	Calls     int64     `firestore:"calls"`
+	UpdatedAt time.Time `firestore:"updated_at"`
+}
+
+func (c *UsageCounter) Reserve(ctx context.Context, day, month string, dailyLimit, monthlyLimit int64) (bool, error) {
+	col := c.client.Collection(usageCollection)
+	dayRef, monthRef := col.Doc("day_"+day), col.Doc("month_"+month)
+
+	var reserved bool
+	err := c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		reserved = false
+
+		dayCalls, err := readUsage(tx.Get(dayRef))
+		if err != nil {
+			return err
+		}
+		monthCalls, err := readUsage(tx.Get(monthRef))
+		if err != nil {
+			return err
+		}
+		if (dailyLimit > 0 && dayCalls >= dailyLimit) || (monthlyLimit > 0 && monthCalls >= monthlyLimit) {
+			return nil
+		}
+
+		now := time.Now()
+		if err := tx.Set(dayRef, usageDoc{Calls: dayCalls + 1, UpdatedAt: now}); err != nil {
+			return err
+		}
+		if err := tx.Set(monthRef, usageDoc{Calls: monthCalls + 1, UpdatedAt: now}); err != nil {
+			return err
+		}
+		reserved = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("firestore: reserving ai call: %w", err)
+	}
+	return reserved, nil
+}
+
+func (c *UsageCounter) Counts(ctx context.Context, day, month string) (int64, int64, error) {
+	col := c.client.Collection(usageCollection)
+
+	dayCalls, err := readUsage(col.Doc("day_" + day).Get(ctx))
+	if err != nil {
+		return 0, 0, fmt.Errorf("firestore: reading ai usage: %w", err)
+	}
+	monthCalls, err := readUsage(col.Doc("month_" + month).Get(ctx))
+	if err != nil {
+		return 0, 0, fmt.Errorf("firestore: reading ai usage: %w", err)
+	}
+	return dayCalls, monthCalls, nil
+}
+
+// readUsage treats a missing document as zero calls.
+func readUsage(doc *firestore.DocumentSnapshot, err error) (int64, error) {
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	var d usageDoc
+	if err := doc.DataTo(&d); err != nil {
+		return 0, fmt.Errorf("decoding usage %s: %w", doc.Ref.ID, err)
+	}
+	return d.Calls, nil
+}
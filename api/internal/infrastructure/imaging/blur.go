@@ -0,0 +1,72 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image/jpeg"
+
+	"github.com/l3co/traceo-api/internal/domain/media"
+	"github.com/l3co/traceo-api/pkg/safefetch"
+)
+
+const (
+	// blurSide is the longest side the photo is shrunk to. At this size a
+	// face is a handful of pixels and cannot be recognised.
+	blurSide = 16
+	// blurOutputSide is the longest side of the stored copy, large enough
+	// to fill a card without looking pixelated.
+	blurOutputSide = 512
+)
+
+// Blurrer implements missing.PhotoBlurrer. It downloads the photo, shrinks
+// it to a few pixels and scales it back up smoothly, then stores the result
+// under a key derived from the source URL.
+type Blurrer struct {
+	fetcher *safefetch.Fetcher
+	storage media.Storage
+}
+
+func NewBlurrer(fetcher *safefetch.Fetcher, storage media.Storage) *Blurrer {
+	return &Blurrer{fetcher: fetcher, storage: storage}
+}
+
+func (b *Blurrer) BlurPhoto(ctx context.Context, photoURL string) (string, error) {
+	data, _, err := b.fetcher.Get(ctx, photoURL)
+	if err != nil {
+		return "", fmt.Errorf("downloading photo: %w", err)
+	}
+
+	blurred, err := Blur(data)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(photoURL))
+	key := "blurred/" + hex.EncodeToString(sum[:]) + ".jpg"
+	url, err := b.storage.Put(ctx, key, blurred, "image/jpeg")
+	if err != nil {
+		return "", fmt.Errorf("storing blurred photo: %w", err)
+	}
+	return url, nil
+}
+
+// Blur returns a heavily blurred JPEG of the image in data. Like Process, it
+// leaves all metadata behind.
+func Blur(data []byte) ([]byte, error) {
+	img, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	b := fit(img, blurOutputSide).Bounds()
+	blurred := enlarge(fit(img, blurSide), b.Dx(), b.Dy())
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, blurred, &jpeg.Options{Quality: 75}); err != nil {
+		return nil, fmt.Errorf("encoding blurred photo: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,63 @@
+package imaging_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/media"
+	"github.com/l3co/traceo-api/internal/infrastructure/imaging"
+)
+
+func TestBlur_SizeAndDetail(t *testing.T) {
+	// A fine checkerboard: any detail surviving the blur shows up as
+	// neighbouring pixels far apart in brightness.
+	src := image.NewGray(image.Rect(0, 0, 1200, 600))
+	for y := 0; y < 600; y++ {
+		for x := 0; x < 1200; x++ {
+			if (x/4+y/4)%2 == 0 {
+				src.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+
+	data, err := imaging.Blur(encodeJPEG(t, src))
+	require.NoError(t, err)
+
+	out, err := jpeg.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(512, 256), out.Bounds().Size())
+
+	gray := func(x, y int) int {
+		r, _, _, _ := out.At(x, y).RGBA()
+		return int(r >> 8)
+	}
+	for y := 10; y < 240; y += 7 {
+		for x := 10; x < 500; x += 7 {
+			assert.InDelta(t, gray(x, y), gray(x+1, y), 12, "detail at %d,%d", x, y)
+		}
+	}
+}
+
+func TestBlur_KeepsColours(t *testing.T) {
+	data, err := imaging.Blur(encodeJPEG(t, landscape(800, 400)))
+	require.NoError(t, err)
+
+	out, err := jpeg.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	r, _, b, _ := out.At(10, 128).RGBA()
+	assert.Greater(t, r>>8, b>>8)
+	r, _, b, _ = out.At(500, 128).RGBA()
+	assert.Greater(t, b>>8, r>>8)
+}
+
+func TestBlur_RejectsNonImages(t *testing.T) {
+	_, err := imaging.Blur([]byte("not an image"))
+
+	assert.ErrorIs(t, err, media.ErrUnsupportedImage)
+}
@@ -0,0 +1,90 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const orientationTag = 0x0112
+
+// exifOrientation returns the EXIF orientation (1-8) of a JPEG, or 1 when
+// the file has none or it cannot be read.
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xD8 || (marker >= 0xD0 && marker <= 0xD7) || marker == 0x01 || marker == 0xFF {
+			i++
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			// Start of scan or end of image: no metadata after this.
+			return 1
+		}
+
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + size
+		if size < 2 || end > len(data) {
+			return 1
+		}
+		if marker == 0xE1 {
+			if o, ok := tiffOrientation(data[i+4 : end]); ok {
+				return o
+			}
+		}
+		i = end
+	}
+	return 1
+}
+
+// tiffOrientation reads the orientation tag from IFD0 of an APP1 Exif
+// payload.
+func tiffOrientation(seg []byte) (int, bool) {
+	if !bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+		return 0, false
+	}
+	tiff := seg[6:]
+	if len(tiff) < 8 {
+		return 0, false
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, false
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 0, false
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0, false
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 0, false
+		}
+		if order.Uint16(tiff[entry:]) != orientationTag {
+			continue
+		}
+		o := int(order.Uint16(tiff[entry+8:]))
+		if o < 1 || o > 8 {
+			return 0, false
+		}
+		return o, true
+	}
+	return 0, false
+}
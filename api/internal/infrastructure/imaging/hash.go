@@ -0,0 +1,41 @@
+package imaging
+
+import (
+	"image"
+
+	"github.com/l3co/traceo-api/internal/domain/shared"
+)
+
+// hashMaxPixels bounds the images DifferenceHash decodes. Photos are hashed
+// from the stored variants, whose longest side is at most 2048 pixels, so
+// anything much larger did not come through the upload pipeline.
+const hashMaxPixels = 2048 * 2048
+
+// DifferenceHash returns the dHash of the image in data: the picture is
+// shrunk to 9×8 grey pixels and each bit records whether a pixel is
+// brighter than its right-hand neighbour. The hash survives re-encoding,
+// resizing and small edits, so near-identical photos differ in few bits.
+func DifferenceHash(data []byte) (shared.PerceptualHash, error) {
+	img, err := decode(data, hashMaxPixels)
+	if err != nil {
+		return 0, err
+	}
+
+	small := shrink(img, 9, 8)
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if luma(small, x, y) > luma(small, x+1, y) {
+				hash |= 1
+			}
+		}
+	}
+	return shared.PerceptualHash(hash), nil
+}
+
+// luma is the Rec. 601 brightness of a pixel, scaled by 1000.
+func luma(img *image.RGBA, x, y int) int {
+	i := img.PixOffset(x, y)
+	return 299*int(img.Pix[i]) + 587*int(img.Pix[i+1]) + 114*int(img.Pix[i+2])
+}
@@ -0,0 +1,54 @@
+package imaging_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/media"
+	"github.com/l3co/traceo-api/internal/infrastructure/imaging"
+)
+
+// gradient is a diagonal brightness ramp with a dark square, which gives
+// dHash a mix of set and unset bits.
+func gradient(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8((x*255/w + y*255/h) / 2)
+			if x > w/3 && x < w/2 && y > h/4 && y < h/2 {
+				v = 20
+			}
+			img.Set(x, y, color.RGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+	return img
+}
+
+func TestDifferenceHash_NearDuplicates(t *testing.T) {
+	original, err := imaging.DifferenceHash(encodeJPEG(t, gradient(900, 800)))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, gradient(300, 267)))
+	resized, err := imaging.DifferenceHash(buf.Bytes())
+	require.NoError(t, err)
+
+	other, err := imaging.DifferenceHash(encodeJPEG(t, landscape(900, 800)))
+	require.NoError(t, err)
+
+	assert.NotZero(t, original)
+	assert.LessOrEqual(t, original.Distance(resized), 4)
+	assert.Greater(t, original.Distance(other), 10)
+}
+
+func TestDifferenceHash_RejectsNonImages(t *testing.T) {
+	_, err := imaging.DifferenceHash([]byte("not an image"))
+
+	assert.ErrorIs(t, err, media.ErrUnsupportedImage)
+}
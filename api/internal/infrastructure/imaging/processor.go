@@ -0,0 +1,123 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+
+	"github.com/l3co/traceo-api/internal/domain/media"
+)
+
+// maxPixels bounds the decoded size, so a small file claiming huge
+// dimensions cannot exhaust memory. Decoding holds the source image and a
+// flattened RGBA copy at once, up to 8 bytes per pixel, so 24MP (6000x4000)
+// keeps a decode under 200MB.
+const maxPixels = 24_000_000
+
+// maxSide is the longest side of the largest variant. Decode scales larger
+// images down to it before turning them upright, so the rotated copy is never
+// made at full size.
+const maxSide = 2048
+
+// variants maps each stored size to the length of its longest side. Smaller
+// photos are never upscaled.
+var variants = []struct {
+	name    media.Variant
+	maxSide int
+}{
+	{media.VariantThumbnail, 256},
+	{media.VariantMedium, 1024},
+	{media.VariantFull, maxSide},
+}
+
+// Processor implements media.ImageProcessor with the standard library. It
+// accepts JPEG, PNG and GIF (first frame), applies the EXIF orientation and
+// re-encodes every variant as JPEG, which leaves all metadata behind.
+type Processor struct {
+	quality int
+}
+
+func NewProcessor() *Processor {
+	return &Processor{quality: 85}
+}
+
+func (p *Processor) Process(data []byte) ([]media.Image, error) {
+	img, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]media.Image, 0, len(variants))
+	for _, v := range variants {
+		scaled := fit(img, v.maxSide)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: p.quality}); err != nil {
+			return nil, fmt.Errorf("encoding %s variant: %w", v.name, err)
+		}
+		b := scaled.Bounds()
+		images = append(images, media.Image{
+			Variant:     v.name,
+			Data:        buf.Bytes(),
+			ContentType: "image/jpeg",
+			Width:       b.Dx(),
+			Height:      b.Dy(),
+		})
+	}
+	return images, nil
+}
+
+// Decode checks that data is a supported image of reasonable size and
+// returns it flattened, upright and no larger than maxSide. Anything decoding
+// untrusted photos should go through it rather than image.Decode.
+func Decode(data []byte) (*image.RGBA, error) {
+	return decode(data, maxPixels)
+}
+
+// decode is Decode with a caller-chosen pixel limit.
+func decode(data []byte, limit int) (*image.RGBA, error) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return nil, fmt.Errorf("%w: %s", media.ErrUnsupportedImage, contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", media.ErrUnsupportedImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, fmt.Errorf("%w: empty image", media.ErrUnsupportedImage)
+	}
+	if cfg.Width*cfg.Height > limit {
+		return nil, fmt.Errorf("%w: %dx%d pixels", media.ErrImageTooLarge, cfg.Width, cfg.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", media.ErrUnsupportedImage, err)
+	}
+
+	img := fit(flatten(src), maxSide)
+	if contentType == "image/jpeg" {
+		img = orient(img, exifOrientation(data))
+	}
+	return img, nil
+}
+
+// flatten draws src onto a white RGBA canvas at the origin, so that
+// transparent PNG and GIF areas do not turn black in the JPEG output.
+func flatten(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
@@ -0,0 +1,151 @@
+package imaging_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/media"
+	"github.com/l3co/traceo-api/internal/infrastructure/imaging"
+)
+
+// landscape is red on the left half and blue on the right.
+func landscape(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+// withExif inserts an APP1 segment carrying an orientation tag and a dummy
+// GPS marker right after the SOI marker.
+func withExif(data []byte, orientation uint16) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	tiff = append(tiff, "GPS-23.5505,-46.6333"...)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	seg := []byte{0xFF, 0xE1}
+	seg = binary.BigEndian.AppendUint16(seg, uint16(len(payload)+2))
+	seg = append(seg, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, seg...)
+	return append(out, data[2:]...)
+}
+
+func variant(t *testing.T, images []media.Image, v media.Variant) image.Image {
+	t.Helper()
+	for _, img := range images {
+		if img.Variant == v {
+			decoded, err := jpeg.Decode(bytes.NewReader(img.Data))
+			require.NoError(t, err)
+			assert.Equal(t, img.Width, decoded.Bounds().Dx())
+			assert.Equal(t, img.Height, decoded.Bounds().Dy())
+			return decoded
+		}
+	}
+	t.Fatalf("no %s variant", v)
+	return nil
+}
+
+func TestProcess_Variants(t *testing.T) {
+	images, err := imaging.NewProcessor().Process(encodeJPEG(t, landscape(3000, 1500)))
+	require.NoError(t, err)
+	require.Len(t, images, 3)
+
+	assert.Equal(t, image.Pt(256, 128), variant(t, images, media.VariantThumbnail).Bounds().Size())
+	assert.Equal(t, image.Pt(1024, 512), variant(t, images, media.VariantMedium).Bounds().Size())
+	assert.Equal(t, image.Pt(2048, 1024), variant(t, images, media.VariantFull).Bounds().Size())
+}
+
+func TestProcess_DoesNotUpscale(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, landscape(100, 50)))
+
+	images, err := imaging.NewProcessor().Process(buf.Bytes())
+	require.NoError(t, err)
+
+	assert.Equal(t, image.Pt(100, 50), variant(t, images, media.VariantFull).Bounds().Size())
+	assert.Equal(t, "image/jpeg", images[0].ContentType)
+}
+
+func TestProcess_AppliesOrientationAndStripsExif(t *testing.T) {
+	data := withExif(encodeJPEG(t, landscape(80, 40)), 6)
+
+	images, err := imaging.NewProcessor().Process(data)
+	require.NoError(t, err)
+
+	full := variant(t, images, media.VariantFull)
+	assert.Equal(t, image.Pt(40, 80), full.Bounds().Size())
+
+	// Rotated clockwise, the red left half ends up on top.
+	r, _, b, _ := full.At(20, 5).RGBA()
+	assert.Greater(t, r, b)
+	r, _, b, _ = full.At(20, 75).RGBA()
+	assert.Greater(t, b, r)
+
+	for _, img := range images {
+		assert.False(t, bytes.Contains(img.Data, []byte("Exif")), img.Variant)
+		assert.False(t, bytes.Contains(img.Data, []byte("GPS")), img.Variant)
+	}
+}
+
+func TestDecode_ScalesDownBeforeOrienting(t *testing.T) {
+	data := withExif(encodeJPEG(t, landscape(3000, 1000)), 6)
+
+	img, err := imaging.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(682, 2048), img.Bounds().Size())
+}
+
+func TestProcess_RejectsNonImages(t *testing.T) {
+	for _, data := range [][]byte{
+		[]byte("<html><script>alert(1)</script></html>"),
+		[]byte("%PDF-1.4"),
+		{0xFF, 0xD8, 0xFF, 0xE0, 0x00},
+	} {
+		_, err := imaging.NewProcessor().Process(data)
+		assert.ErrorIs(t, err, media.ErrUnsupportedImage)
+	}
+}
+
+func TestProcess_RejectsHugeDimensions(t *testing.T) {
+	// A valid PNG header claiming 10000x10000 pixels.
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))))
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[16:], 10000)
+	binary.BigEndian.PutUint32(data[20:], 10000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+	_, err := imaging.NewProcessor().Process(data)
+	assert.ErrorIs(t, err, media.ErrImageTooLarge)
+}
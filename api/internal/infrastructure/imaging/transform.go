@@ -0,0 +1,124 @@
+package imaging
+
+import "image"
+
+// orient turns img upright according to an EXIF orientation value.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // flip horizontally
+				sx, sy = w-1-x, y
+			case 3: // rotate 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // flip vertically
+				sx, sy = x, h-1-y
+			case 5: // transpose
+				sx, sy = y, x
+			case 6: // rotate 90° clockwise
+				sx, sy = y, h-1-x
+			case 7: // transverse
+				sx, sy = w-1-y, h-1-x
+			case 8: // rotate 90° counter-clockwise
+				sx, sy = w-1-y, x
+			}
+			si := img.PixOffset(sx, sy)
+			di := dst.PixOffset(x, y)
+			copy(dst.Pix[di:di+4], img.Pix[si:si+4])
+		}
+	}
+	return dst
+}
+
+// fit scales img down, keeping its aspect ratio, so that its longest side is
+// at most maxSide.
+func fit(img *image.RGBA, maxSide int) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w <= maxSide && h <= maxSide {
+		return img
+	}
+
+	dw, dh := maxSide, maxSide
+	if w >= h {
+		dh = max(h*maxSide/w, 1)
+	} else {
+		dw = max(w*maxSide/h, 1)
+	}
+	return shrink(img, dw, dh)
+}
+
+// shrink scales img down to dw×dh. Each destination pixel averages the
+// source pixels it covers, which avoids the aliasing of nearest-neighbour
+// sampling.
+func shrink(img *image.RGBA, dw, dh int) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				i := img.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += int(img.Pix[i])
+					g += int(img.Pix[i+1])
+					b += int(img.Pix[i+2])
+					a += int(img.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+			di := dst.PixOffset(x, y)
+			dst.Pix[di] = uint8(r / n)
+			dst.Pix[di+1] = uint8(g / n)
+			dst.Pix[di+2] = uint8(b / n)
+			dst.Pix[di+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// enlarge scales img up to dw×dh with bilinear interpolation, so that the
+// few pixels of a shrunken image blend into smooth gradients.
+func enlarge(img *image.RGBA, dw, dh int) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		fy := max((float64(y)+0.5)*float64(h)/float64(dh)-0.5, 0)
+		y0 := min(int(fy), h-1)
+		y1 := min(y0+1, h-1)
+		ty := fy - float64(y0)
+		for x := 0; x < dw; x++ {
+			fx := max((float64(x)+0.5)*float64(w)/float64(dw)-0.5, 0)
+			x0 := min(int(fx), w-1)
+			x1 := min(x0+1, w-1)
+			tx := fx - float64(x0)
+
+			i00, i10 := img.PixOffset(x0, y0), img.PixOffset(x1, y0)
+			i01, i11 := img.PixOffset(x0, y1), img.PixOffset(x1, y1)
+			di := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				top := float64(img.Pix[i00+c])*(1-tx) + float64(img.Pix[i10+c])*tx
+				bottom := float64(img.Pix[i01+c])*(1-tx) + float64(img.Pix[i11+c])*tx
+				dst.Pix[di+c] = uint8(top*(1-ty) + bottom*ty + 0.5)
+			}
+		}
+	}
+	return dst
+}
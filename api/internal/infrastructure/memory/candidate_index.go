@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+)
+
+// CandidateIndex is a process-local, brute-force matching.CandidateIndex.
+type CandidateIndex struct {
+	mu      sync.RWMutex
+	entries map[matching.IndexKind]map[string]matching.IndexEntry
+}
+
+func NewCandidateIndex() *CandidateIndex {
+	return &CandidateIndex{entries: make(map[matching.IndexKind]map[string]matching.IndexEntry)}
+}
+
+func (i *CandidateIndex) Upsert(_ context.Context, e matching.IndexEntry) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.entries[e.Kind] == nil {
+		i.entries[e.Kind] = make(map[string]matching.IndexEntry)
+	}
+	i.entries[e.Kind][e.ID] = e
+	return nil
+}
+
+func (i *CandidateIndex) Remove(_ context.Context, kind matching.IndexKind, id string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delete(i.entries[kind], id)
+	return nil
+}
+
+func (i *CandidateIndex) Nearest(_ context.Context, kind matching.IndexKind, query matching.Embedding, k int) ([]matching.Neighbor, error) {
+	i.mu.RLock()
+	entries := make([]matching.IndexEntry, 0, len(i.entries[kind]))
+	for _, e := range i.entries[kind] {
+		entries = append(entries, e)
+	}
+	i.mu.RUnlock()
+
+	return matching.RankNearest(entries, query, k), nil
+}
+
+func (i *CandidateIndex) Indexed(_ context.Context, kind matching.IndexKind) (map[string]string, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	result := make(map[string]string, len(i.entries[kind]))
+	for id, e := range i.entries[kind] {
+		result[id] = e.PhotoURL
+	}
+	return result, nil
+}
@@ -0,0 +1,46 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/internal/infrastructure/memory"
+)
+
+func TestCandidateIndex_NearestByKind(t *testing.T) {
+	idx := memory.NewCandidateIndex()
+	ctx := context.Background()
+
+	require.NoError(t, idx.Upsert(ctx, matching.IndexEntry{Kind: matching.IndexMissing, ID: "m1", Embedding: matching.Embedding{1, 0}}))
+	require.NoError(t, idx.Upsert(ctx, matching.IndexEntry{Kind: matching.IndexMissing, ID: "m2", Embedding: matching.Embedding{0.7, 0.7}}))
+	require.NoError(t, idx.Upsert(ctx, matching.IndexEntry{Kind: matching.IndexMissing, ID: "m3", Embedding: matching.Embedding{0, 1}}))
+	require.NoError(t, idx.Upsert(ctx, matching.IndexEntry{Kind: matching.IndexHomeless, ID: "h1", Embedding: matching.Embedding{1, 0}}))
+
+	got, err := idx.Nearest(ctx, matching.IndexMissing, matching.Embedding{1, 0.1}, 2)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "m1", got[0].ID)
+	assert.Equal(t, "m2", got[1].ID)
+}
+
+func TestCandidateIndex_UpsertReplacesAndRemove(t *testing.T) {
+	idx := memory.NewCandidateIndex()
+	ctx := context.Background()
+
+	require.NoError(t, idx.Upsert(ctx, matching.IndexEntry{Kind: matching.IndexHomeless, ID: "h1", Embedding: matching.Embedding{1, 0}}))
+	require.NoError(t, idx.Upsert(ctx, matching.IndexEntry{Kind: matching.IndexHomeless, ID: "h1", Embedding: matching.Embedding{0, 1}}))
+
+	got, err := idx.Nearest(ctx, matching.IndexHomeless, matching.Embedding{0, 1}, 5)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.InDelta(t, 1.0, got[0].Similarity, 1e-9)
+
+	require.NoError(t, idx.Remove(ctx, matching.IndexHomeless, "h1"))
+	got, err = idx.Nearest(ctx, matching.IndexHomeless, matching.Embedding{0, 1}, 5)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
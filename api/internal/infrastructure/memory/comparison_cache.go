@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+)
+
+// ComparisonCache is a process-local matching.ComparisonCache.
+type ComparisonCache struct {
+	mu      sync.RWMutex
+	entries map[string]matching.FaceComparisonResult
+}
+
+func NewComparisonCache() *ComparisonCache {
+	return &ComparisonCache{entries: make(map[string]matching.FaceComparisonResult)}
+}
+
+func (c *ComparisonCache) Get(_ context.Context, key string) (*matching.FaceComparisonResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	r, ok := c.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	return &r, nil
+}
+
+func (c *ComparisonCache) Put(_ context.Context, key string, r *matching.FaceComparisonResult) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = *r
+	return nil
+}
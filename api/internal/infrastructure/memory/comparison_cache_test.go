@@ -0,0 +1,27 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/internal/infrastructure/memory"
+)
+
+func TestComparisonCache_GetPut(t *testing.T) {
+	c := memory.NewComparisonCache()
+	ctx := context.Background()
+
+	got, err := c.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	require.NoError(t, c.Put(ctx, "k", &matching.FaceComparisonResult{SimilarityScore: 0.7}))
+	got, err = c.Get(ctx, "k")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, 0.7, got.SimilarityScore)
+}
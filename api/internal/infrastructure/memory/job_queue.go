@@ -0,0 +1,266 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/l3co/traceo-api/internal/domain/job"
+)
+
+// JobQueue is a process-local job.Queue. Jobs do not survive restarts; it is
+// meant for development and tests.
+type JobQueue struct {
+	mu   sync.Mutex
+	jobs map[string]*job.Job
+	dead map[string]*job.Job
+}
+
+func NewJobQueue() *JobQueue {
+	return &JobQueue{
+		jobs: make(map[string]*job.Job),
+		dead: make(map[string]*job.Job),
+	}
+}
+
+func (q *JobQueue) Enqueue(_ context.Context, j *job.Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, exists := q.jobs[j.ID]; exists {
+		return job.ErrDuplicateJob
+	}
+	cp := *j
+	q.jobs[j.ID] = &cp
+	return nil
+}
+
+func (q *JobQueue) FindByID(_ context.Context, id string) (*job.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if j, ok := q.jobs[id]; ok {
+		cp := *j
+		return &cp, nil
+	}
+	if j, ok := q.dead[id]; ok {
+		cp := *j
+		return &cp, nil
+	}
+	return nil, job.ErrJobNotFound
+}
+
+func (q *JobQueue) FindByTargetID(_ context.Context, targetID string, limit int) ([]*job.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var result []*job.Job
+	for _, store := range []map[string]*job.Job{q.jobs, q.dead} {
+		for _, j := range store {
+			if j.TargetID == targetID {
+				cp := *j
+				result = append(result, &cp)
+			}
+		}
+	}
+	sort.Slice(result, func(a, b int) bool {
+		return result[a].CreatedAt.After(result[b].CreatedAt)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (q *JobQueue) Lease(_ context.Context, owner string, ttl time.Duration) (*job.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	var next *job.Job
+	for _, j := range q.jobs {
+		ready := (j.Status == job.StatusQueued && !j.RunAt.After(now)) ||
+			(j.Status == job.StatusRunning && j.LeasedUntil.Before(now))
+		if !ready {
+			continue
+		}
+		if next == nil || j.RunAt.Before(next.RunAt) {
+			next = j
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+
+	next.Status = job.StatusRunning
+	next.Attempts++
+	next.LeaseOwner = owner
+	next.LeasedUntil = now.Add(ttl)
+	next.UpdatedAt = now
+	if next.StartedAt.IsZero() {
+		next.StartedAt = now
+	}
+
+	cp := *next
+	return &cp, nil
+}
+
+func (q *JobQueue) Complete(_ context.Context, id, owner string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, err := q.leased(id, owner)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	j.Status = job.StatusSucceeded
+	j.LeaseOwner = ""
+	j.LeasedUntil = time.Time{}
+	j.FinishedAt = now
+	j.UpdatedAt = now
+	return nil
+}
+
+func (q *JobQueue) Retry(_ context.Context, id, owner, lastError string, runAt time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, err := q.leased(id, owner)
+	if err != nil {
+		return err
+	}
+	j.Status = job.StatusQueued
+	j.LastError = lastError
+	j.RunAt = runAt
+	j.LeaseOwner = ""
+	j.LeasedUntil = time.Time{}
+	j.UpdatedAt = time.Now()
+	return nil
+}
+
+func (q *JobQueue) Defer(_ context.Context, id, owner, reason string, runAt time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, err := q.leased(id, owner)
+	if err != nil {
+		return err
+	}
+	j.Status = job.StatusQueued
+	if j.Attempts > 0 {
+		j.Attempts--
+	}
+	j.LastError = reason
+	j.RunAt = runAt
+	j.LeaseOwner = ""
+	j.LeasedUntil = time.Time{}
+	j.UpdatedAt = time.Now()
+	return nil
+}
+
+func (q *JobQueue) Bury(_ context.Context, id, owner, lastError string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, err := q.leased(id, owner)
+	if err != nil {
+		return err
+	}
+	delete(q.jobs, id)
+	now := time.Now()
+	j.Status = job.StatusFailed
+	j.LastError = lastError
+	j.LeaseOwner = ""
+	j.LeasedUntil = time.Time{}
+	j.FinishedAt = now
+	j.UpdatedAt = now
+	q.dead[id] = j
+	return nil
+}
+
+// leased returns the job with the given id if owner still holds its lease.
+// The caller must hold q.mu.
+func (q *JobQueue) leased(id, owner string) (*job.Job, error) {
+	j, ok := q.jobs[id]
+	if !ok {
+		return nil, job.ErrJobNotFound
+	}
+	if j.Status != job.StatusRunning || j.LeaseOwner != owner {
+		return nil, job.ErrLeaseLost
+	}
+	return j, nil
+}
+
+func (q *JobQueue) ListDead(_ context.Context, limit int) ([]*job.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	result := make([]*job.Job, 0, len(q.dead))
+	for _, j := range q.dead {
+		cp := *j
+		result = append(result, &cp)
+	}
+	sort.Slice(result, func(a, b int) bool {
+		return result[a].UpdatedAt.After(result[b].UpdatedAt)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (q *JobQueue) RequeueDead(_ context.Context, id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	j, ok := q.dead[id]
+	if !ok {
+		return job.ErrJobNotFound
+	}
+	delete(q.dead, id)
+	now := time.Now()
+	j.Status = job.StatusQueued
+	j.Attempts = 0
+	j.RunAt = now
+	j.StartedAt = time.Time{}
+	j.FinishedAt = time.Time{}
+	j.UpdatedAt = now
+	q.jobs[id] = j
+	return nil
+}
+
+func (q *JobQueue) PurgeSucceeded(_ context.Context, before time.Time) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	purged := 0
+	for id, j := range q.jobs {
+		if j.Status == job.StatusSucceeded && j.FinishedAt.Before(before) {
+			delete(q.jobs, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (q *JobQueue) PurgeDead(_ context.Context, id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.dead[id]; !ok {
+		return job.ErrJobNotFound
+	}
+	delete(q.dead, id)
+	return nil
+}
+
+func (q *JobQueue) PurgeAllDead(_ context.Context) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	n := len(q.dead)
+	q.dead = make(map[string]*job.Job)
+	return n, nil
+}
@@ -0,0 +1,194 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/job"
+	"github.com/l3co/traceo-api/internal/infrastructure/memory"
+)
+
+func newJob(id string, runAt time.Time) *job.Job {
+	return &job.Job{
+		ID:       id,
+		Type:     job.TypeFaceMatching,
+		TargetID: "target-" + id,
+		Status:   job.StatusQueued,
+		RunAt:    runAt,
+	}
+}
+
+func TestJobQueue_LeaseOldestReadyJob(t *testing.T) {
+	q := memory.NewJobQueue()
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, q.Enqueue(ctx, newJob("later", now.Add(-time.Second))))
+	require.NoError(t, q.Enqueue(ctx, newJob("first", now.Add(-time.Minute))))
+	require.NoError(t, q.Enqueue(ctx, newJob("future", now.Add(time.Hour))))
+
+	j, err := q.Lease(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, j)
+	assert.Equal(t, "first", j.ID)
+	assert.Equal(t, job.StatusRunning, j.Status)
+	assert.Equal(t, 1, j.Attempts)
+
+	j, err = q.Lease(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "later", j.ID)
+
+	j, err = q.Lease(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, j)
+}
+
+func TestJobQueue_ExpiredLeaseIsReleased(t *testing.T) {
+	q := memory.NewJobQueue()
+	ctx := context.Background()
+
+	require.NoError(t, q.Enqueue(ctx, newJob("j1", time.Now())))
+
+	_, err := q.Lease(ctx, "w1", -time.Second)
+	require.NoError(t, err)
+
+	j, err := q.Lease(ctx, "w2", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, j)
+	assert.Equal(t, "w2", j.LeaseOwner)
+	assert.Equal(t, 2, j.Attempts)
+}
+
+func TestJobQueue_StaleLeaseCannotSettleJob(t *testing.T) {
+	q := memory.NewJobQueue()
+	ctx := context.Background()
+
+	require.NoError(t, q.Enqueue(ctx, newJob("j1", time.Now())))
+	_, err := q.Lease(ctx, "w1", -time.Second)
+	require.NoError(t, err)
+	_, err = q.Lease(ctx, "w2", time.Minute)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, q.Complete(ctx, "j1", "w1"), job.ErrLeaseLost)
+	assert.ErrorIs(t, q.Retry(ctx, "j1", "w1", "boom", time.Now()), job.ErrLeaseLost)
+	assert.ErrorIs(t, q.Defer(ctx, "j1", "w1", "budget exhausted", time.Now()), job.ErrLeaseLost)
+	assert.ErrorIs(t, q.Bury(ctx, "j1", "w1", "boom"), job.ErrLeaseLost)
+
+	j, err := q.FindByID(ctx, "j1")
+	require.NoError(t, err)
+	assert.Equal(t, job.StatusRunning, j.Status)
+	assert.Equal(t, "w2", j.LeaseOwner)
+	assert.Equal(t, 2, j.Attempts)
+
+	require.NoError(t, q.Complete(ctx, "j1", "w2"))
+	assert.ErrorIs(t, q.Complete(ctx, "j1", "w2"), job.ErrLeaseLost, "a finished job is no longer leased")
+}
+
+func TestJobQueue_DeferReturnsAttempt(t *testing.T) {
+	q := memory.NewJobQueue()
+	ctx := context.Background()
+
+	require.NoError(t, q.Enqueue(ctx, newJob("j1", time.Now())))
+
+	_, err := q.Lease(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, q.Defer(ctx, "j1", "w1", "budget exhausted", time.Now().Add(time.Hour)))
+
+	j, err := q.FindByID(ctx, "j1")
+	require.NoError(t, err)
+	assert.Equal(t, job.StatusQueued, j.Status)
+	assert.Equal(t, 0, j.Attempts)
+	assert.Equal(t, "budget exhausted", j.LastError)
+
+	leased, err := q.Lease(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, leased)
+}
+
+func TestJobQueue_DeadLetterLifecycle(t *testing.T) {
+	q := memory.NewJobQueue()
+	ctx := context.Background()
+
+	require.NoError(t, q.Enqueue(ctx, newJob("j1", time.Now())))
+	_, _ = q.Lease(ctx, "w1", time.Minute)
+	require.NoError(t, q.Bury(ctx, "j1", "w1", "boom"))
+
+	dead, err := q.ListDead(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, job.StatusFailed, dead[0].Status)
+	assert.Equal(t, "boom", dead[0].LastError)
+
+	require.NoError(t, q.RequeueDead(ctx, "j1"))
+	j, err := q.Lease(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, j)
+	assert.Equal(t, 1, j.Attempts)
+
+	assert.ErrorIs(t, q.PurgeDead(ctx, "j1"), job.ErrJobNotFound)
+}
+
+func TestJobQueue_EnqueueIsIdempotent(t *testing.T) {
+	q := memory.NewJobQueue()
+	ctx := context.Background()
+
+	require.NoError(t, q.Enqueue(ctx, newJob("j1", time.Now())))
+	assert.ErrorIs(t, q.Enqueue(ctx, newJob("j1", time.Now())), job.ErrDuplicateJob)
+
+	_, _ = q.Lease(ctx, "w1", time.Minute)
+	j, err := q.Lease(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, j)
+}
+
+func TestJobQueue_CompleteKeepsStatusRecord(t *testing.T) {
+	q := memory.NewJobQueue()
+	ctx := context.Background()
+
+	j := newJob("j1", time.Now())
+	j.CreatedAt = time.Now()
+	require.NoError(t, q.Enqueue(ctx, j))
+	_, _ = q.Lease(ctx, "w1", time.Minute)
+	require.NoError(t, q.Complete(ctx, "j1", "w1"))
+
+	found, err := q.FindByID(ctx, "j1")
+	require.NoError(t, err)
+	assert.Equal(t, job.StatusSucceeded, found.Status)
+	assert.False(t, found.StartedAt.IsZero())
+	assert.False(t, found.FinishedAt.IsZero())
+
+	byTarget, err := q.FindByTargetID(ctx, "target-j1", 10)
+	require.NoError(t, err)
+	assert.Len(t, byTarget, 1)
+
+	next, err := q.Lease(ctx, "w1", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, next)
+}
+
+func TestJobQueue_PurgeSucceeded(t *testing.T) {
+	q := memory.NewJobQueue()
+	ctx := context.Background()
+
+	require.NoError(t, q.Enqueue(ctx, newJob("done", time.Now())))
+	_, _ = q.Lease(ctx, "w1", time.Minute)
+	require.NoError(t, q.Complete(ctx, "done", "w1"))
+	require.NoError(t, q.Enqueue(ctx, newJob("waiting", time.Now().Add(time.Hour))))
+
+	n, err := q.PurgeSucceeded(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n, "recently finished jobs are kept")
+
+	n, err = q.PurgeSucceeded(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = q.FindByID(ctx, "done")
+	assert.ErrorIs(t, err, job.ErrJobNotFound)
+	_, err = q.FindByID(ctx, "waiting")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// PairLedger is a process-local matching.PairLedger.
+type PairLedger struct {
+	mu      sync.RWMutex
+	entries map[string]time.Time
+}
+
+func NewPairLedger() *PairLedger {
+	return &PairLedger{entries: make(map[string]time.Time)}
+}
+
+func (l *PairLedger) Compared(_ context.Context, keys []string) (map[string]bool, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	result := make(map[string]bool)
+	for _, key := range keys {
+		if _, ok := l.entries[key]; ok {
+			result[key] = true
+		}
+	}
+	return result, nil
+}
+
+func (l *PairLedger) Record(_ context.Context, key string, comparedAt time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries[key] = comparedAt
+	return nil
+}
@@ -0,0 +1,26 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/infrastructure/memory"
+)
+
+func TestPairLedger_RecordAndCompared(t *testing.T) {
+	l := memory.NewPairLedger()
+	ctx := context.Background()
+
+	got, err := l.Compared(ctx, []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	require.NoError(t, l.Record(ctx, "a", time.Now()))
+	got, err = l.Compared(ctx, []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"a": true}, got)
+}
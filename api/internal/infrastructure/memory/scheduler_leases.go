@@ -0,0 +1,29 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SchedulerLeases is a process-local scheduler.Leases. It only prevents
+// double runs within one process, so use it with a single instance.
+type SchedulerLeases struct {
+	mu    sync.Mutex
+	slots map[string]time.Time
+}
+
+func NewSchedulerLeases() *SchedulerLeases {
+	return &SchedulerLeases{slots: make(map[string]time.Time)}
+}
+
+func (l *SchedulerLeases) Claim(_ context.Context, task string, slot time.Time, _ string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if last, ok := l.slots[task]; ok && !slot.After(last) {
+		return false, nil
+	}
+	l.slots[task] = slot
+	return true, nil
+}
@@ -0,0 +1,28 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/l3co/traceo-api/internal/infrastructure/memory"
+)
+
+func TestSchedulerLeases_ClaimOncePerSlot(t *testing.T) {
+	l := memory.NewSchedulerLeases()
+	ctx := context.Background()
+	slot := time.Date(2026, 5, 10, 3, 0, 0, 0, time.UTC)
+
+	ok, _ := l.Claim(ctx, "refresh", slot, "a")
+	assert.True(t, ok)
+	ok, _ = l.Claim(ctx, "refresh", slot, "b")
+	assert.False(t, ok)
+	ok, _ = l.Claim(ctx, "refresh", slot.Add(-time.Hour), "b")
+	assert.False(t, ok, "earlier slot")
+	ok, _ = l.Claim(ctx, "other", slot, "b")
+	assert.True(t, ok, "tasks are independent")
+	ok, _ = l.Claim(ctx, "refresh", slot.Add(24*time.Hour), "b")
+	assert.True(t, ok)
+}
@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+// UsageCounter is a process-local matching.UsageCounter. Counts are lost on
+// restart and not shared between instances.
+type UsageCounter struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func NewUsageCounter() *UsageCounter {
+	return &UsageCounter{counts: make(map[string]int64)}
+}
+
+func (c *UsageCounter) Reserve(_ context.Context, day, month string, dailyLimit, monthlyLimit int64) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dayKey, monthKey := "day_"+day, "month_"+month
+	if dailyLimit > 0 && c.counts[dayKey] >= dailyLimit {
+		return false, nil
+	}
+	if monthlyLimit > 0 && c.counts[monthKey] >= monthlyLimit {
+		return false, nil
+	}
+	c.counts[dayKey]++
+	c.counts[monthKey]++
+	return true, nil
+}
+
+func (c *UsageCounter) Counts(_ context.Context, day, month string) (int64, int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.counts["day_"+day], c.counts["month_"+month], nil
+}
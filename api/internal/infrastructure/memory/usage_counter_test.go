@@ -0,0 +1,32 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/infrastructure/memory"
+)
+
+func TestUsageCounter_MonthlyLimitSpansDays(t *testing.T) {
+	c := memory.NewUsageCounter()
+	ctx := context.Background()
+
+	ok, err := c.Reserve(ctx, "2026-10-01", "2026-10", 5, 2)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, _ = c.Reserve(ctx, "2026-10-02", "2026-10", 5, 2)
+	assert.True(t, ok)
+	ok, _ = c.Reserve(ctx, "2026-10-03", "2026-10", 5, 2)
+	assert.False(t, ok)
+
+	day, month, err := c.Counts(ctx, "2026-10-03", "2026-10")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), day)
+	assert.Equal(t, int64(2), month)
+
+	ok, _ = c.Reserve(ctx, "2026-11-01", "2026-11", 5, 2)
+	assert.True(t, ok)
+}
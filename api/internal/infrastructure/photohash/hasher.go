@@ -0,0 +1,54 @@
+package photohash
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/l3co/traceo-api/pkg/safefetch"
+)
+
+// maxMemoEntries bounds the URL→hash memo; it is reset when full.
+const maxMemoEntries = 10000
+
+// Hasher implements matching.PhotoHasher with a SHA-256 of the photo bytes.
+// Hashes are memoised per URL, since stored photo URLs are immutable.
+type Hasher struct {
+	fetcher *safefetch.Fetcher
+
+	mu   sync.Mutex
+	memo map[string]string
+}
+
+func NewHasher(fetcher *safefetch.Fetcher) *Hasher {
+	return &Hasher{
+		fetcher: fetcher,
+		memo:    make(map[string]string),
+	}
+}
+
+func (h *Hasher) HashPhoto(ctx context.Context, url string) (string, error) {
+	h.mu.Lock()
+	hash, ok := h.memo[url]
+	h.mu.Unlock()
+	if ok {
+		return hash, nil
+	}
+
+	data, _, err := h.fetcher.Get(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	hash = hex.EncodeToString(sum[:])
+
+	h.mu.Lock()
+	if len(h.memo) >= maxMemoEntries {
+		h.memo = make(map[string]string)
+	}
+	h.memo[url] = hash
+	h.mu.Unlock()
+
+	return hash, nil
+}
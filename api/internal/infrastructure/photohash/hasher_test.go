@@ -0,0 +1,91 @@
+package photohash_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/infrastructure/photohash"
+	"github.com/l3co/traceo-api/pkg/safefetch"
+)
+
+func TestHasher_HashesContentAndMemoises(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/a.jpg", "/copy.jpg":
+			_, _ = w.Write([]byte("same bytes"))
+		case "/b.jpg":
+			_, _ = w.Write([]byte("other bytes"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	h := photohash.NewHasher(safefetch.New(safefetch.Config{AllowPrivate: true}))
+	ctx := context.Background()
+
+	a, err := h.HashPhoto(ctx, srv.URL+"/a.jpg")
+	require.NoError(t, err)
+	again, err := h.HashPhoto(ctx, srv.URL+"/a.jpg")
+	require.NoError(t, err)
+	copyHash, err := h.HashPhoto(ctx, srv.URL+"/copy.jpg")
+	require.NoError(t, err)
+	b, err := h.HashPhoto(ctx, srv.URL+"/b.jpg")
+	require.NoError(t, err)
+
+	assert.Equal(t, a, again)
+	assert.Equal(t, a, copyHash)
+	assert.NotEqual(t, a, b)
+	assert.Equal(t, int32(3), requests.Load())
+
+	_, err = h.HashPhoto(ctx, srv.URL+"/missing.jpg")
+	assert.Error(t, err)
+}
+
+func TestPerceptualHasher_HashesImagesAndMemoises(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.SetGray(x, y, color.Gray{Y: uint8(x * 4)})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/a.png" {
+			_, _ = w.Write([]byte("not an image"))
+			return
+		}
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	h := photohash.NewPerceptualHasher(safefetch.New(safefetch.Config{AllowPrivate: true}))
+	ctx := context.Background()
+
+	a, err := h.PerceptualHash(ctx, srv.URL+"/a.png")
+	require.NoError(t, err)
+	again, err := h.PerceptualHash(ctx, srv.URL+"/a.png")
+	require.NoError(t, err)
+
+	assert.Equal(t, a, again)
+	assert.Equal(t, int32(1), requests.Load())
+
+	_, err = h.PerceptualHash(ctx, srv.URL+"/b.txt")
+	assert.Error(t, err)
+}
@@ -0,0 +1,53 @@
+package photohash
+
+import (
+	"context"
+	"sync"
+
+	"github.com/l3co/traceo-api/internal/domain/shared"
+	"github.com/l3co/traceo-api/internal/infrastructure/imaging"
+	"github.com/l3co/traceo-api/pkg/safefetch"
+)
+
+// PerceptualHasher implements shared.PerceptualHasher with imaging's dHash.
+// Like Hasher, it memoises hashes per URL.
+type PerceptualHasher struct {
+	fetcher *safefetch.Fetcher
+
+	mu   sync.Mutex
+	memo map[string]shared.PerceptualHash
+}
+
+func NewPerceptualHasher(fetcher *safefetch.Fetcher) *PerceptualHasher {
+	return &PerceptualHasher{
+		fetcher: fetcher,
+		memo:    make(map[string]shared.PerceptualHash),
+	}
+}
+
+func (h *PerceptualHasher) PerceptualHash(ctx context.Context, url string) (shared.PerceptualHash, error) {
+	h.mu.Lock()
+	hash, ok := h.memo[url]
+	h.mu.Unlock()
+	if ok {
+		return hash, nil
+	}
+
+	data, _, err := h.fetcher.Get(ctx, url)
+	if err != nil {
+		return 0, err
+	}
+	hash, err = imaging.DifferenceHash(data)
+	if err != nil {
+		return 0, err
+	}
+
+	h.mu.Lock()
+	if len(h.memo) >= maxMemoEntries {
+		h.memo = make(map[string]shared.PerceptualHash)
+	}
+	h.memo[url] = hash
+	h.mu.Unlock()
+
+	return hash, nil
+}
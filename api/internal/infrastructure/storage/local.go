@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage implements media.Storage on the local filesystem, for
+// development. The server exposes dir under baseURL.
+type LocalStorage struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocalStorage(dir, baseURL string) *LocalStorage {
+	return &LocalStorage{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (s *LocalStorage) Put(_ context.Context, key string, data []byte, _ string) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("local storage: creating directory: %w", err)
+	}
+
+	// Write then rename, so readers never see a partial file.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("local storage: creating %s: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("local storage: writing %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("local storage: writing %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("local storage: writing %s: %w", key, err)
+	}
+
+	return s.baseURL + "/" + key, nil
+}
+
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("local storage: deleting %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("local storage: invalid key %q", key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
@@ -0,0 +1,48 @@
+package storage_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/infrastructure/storage"
+)
+
+func TestLocalStorage_Put(t *testing.T) {
+	dir := t.TempDir()
+	s := storage.NewLocalStorage(dir, "http://localhost:8080/media/")
+
+	url, err := s.Put(context.Background(), "age-progression/m1/40.jpg", []byte("img"), "image/jpeg")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/media/age-progression/m1/40.jpg", url)
+
+	data, err := os.ReadFile(filepath.Join(dir, "age-progression", "m1", "40.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "img", string(data))
+}
+
+func TestLocalStorage_Delete(t *testing.T) {
+	dir := t.TempDir()
+	s := storage.NewLocalStorage(dir, "http://localhost/media")
+	ctx := context.Background()
+
+	_, err := s.Put(ctx, "photos/p1/full.jpg", []byte("img"), "image/jpeg")
+	require.NoError(t, err)
+	require.NoError(t, s.Delete(ctx, "photos/p1/full.jpg"))
+	assert.NoFileExists(t, filepath.Join(dir, "photos", "p1", "full.jpg"))
+
+	assert.NoError(t, s.Delete(ctx, "photos/p1/full.jpg"), "deleting twice")
+}
+
+func TestLocalStorage_RejectsEscapingKeys(t *testing.T) {
+	s := storage.NewLocalStorage(t.TempDir(), "http://localhost/media")
+
+	for _, key := range []string{"", "../x.jpg", "a/../../x.jpg", "/etc/x"} {
+		_, err := s.Put(context.Background(), key, []byte("img"), "image/jpeg")
+		assert.Error(t, err, key)
+	}
+}
@@ -0,0 +1,170 @@
+package scheduler
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron spec. Times are evaluated in UTC.
+type Schedule struct {
+	spec   string
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// As in standard cron, when both day fields are restricted a day
+	// matches if either does.
+	domAny bool
+	dowAny bool
+}
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse reads a standard five-field cron spec (minute, hour, day of month,
+// month, day of week) or one of @yearly, @monthly, @weekly, @daily and
+// @hourly. Fields accept *, lists, ranges and steps; day of week is 0-7
+// with both 0 and 7 meaning Sunday.
+func Parse(spec string) (*Schedule, error) {
+	expr := strings.TrimSpace(spec)
+	if d, ok := descriptors[expr]; ok {
+		expr = d
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron spec %q: want 5 fields, got %d", spec, len(fields))
+	}
+
+	s := &Schedule{spec: spec}
+	var err error
+	if s.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("cron spec %q: minute: %w", spec, err)
+	}
+	if s.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("cron spec %q: hour: %w", spec, err)
+	}
+	if s.dom, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("cron spec %q: day of month: %w", spec, err)
+	}
+	if s.month, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("cron spec %q: month: %w", spec, err)
+	}
+	if s.dow, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("cron spec %q: day of week: %w", spec, err)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny = strings.HasPrefix(fields[2], "*")
+	s.dowAny = strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+func parseField(field string, lo, hi int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+
+		from, to := lo, hi
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if from, err = strconv.Atoi(a); err != nil {
+				return 0, fmt.Errorf("invalid value %q", a)
+			}
+			if to, err = strconv.Atoi(b); err != nil {
+				return 0, fmt.Errorf("invalid value %q", b)
+			}
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", rng)
+			}
+			from, to = n, n
+			if hasStep {
+				to = hi
+			}
+		}
+		if from < lo || to > hi || from > to {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, lo, hi)
+		}
+		for v := from; v <= to; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+func (s *Schedule) String() string {
+	return s.spec
+}
+
+// Next returns the first matching minute strictly after t, or the zero time
+// if none falls within the next five years (e.g. "0 0 30 2 *").
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			// Jump straight to the next allowed minute in this hour, if any.
+			rest := s.minute >> uint(t.Minute())
+			if rest == 0 {
+				t = t.Truncate(time.Hour).Add(time.Hour)
+			} else {
+				t = t.Add(time.Duration(bits.TrailingZeros64(rest)) * time.Minute)
+			}
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.domAny && s.dowAny:
+		return dom && dow
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
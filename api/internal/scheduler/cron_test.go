@@ -0,0 +1,56 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/scheduler"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestSchedule_Next(t *testing.T) {
+	tests := []struct {
+		spec string
+		from string
+		want string
+	}{
+		{"0 3 * * *", "2026-05-10 02:59", "2026-05-10 03:00"},
+		{"0 3 * * *", "2026-05-10 03:00", "2026-05-11 03:00"},
+		{"*/15 * * * *", "2026-05-10 10:16", "2026-05-10 10:30"},
+		{"30 4 1 * *", "2026-12-02 00:00", "2027-01-01 04:30"},
+		{"0 9 * * 1-5", "2026-05-09 12:00", "2026-05-11 09:00"}, // Saturday → Monday
+		{"0 0 * * 7", "2026-05-10 00:00", "2026-05-17 00:00"},   // 7 is Sunday
+		{"0 0 13 * 5", "2026-05-10 00:00", "2026-05-13 00:00"},  // 13th or Friday
+		{"0 0 29 2 *", "2026-03-01 00:00", "2028-02-29 00:00"},
+		{"@daily", "2026-05-10 10:00", "2026-05-11 00:00"},
+		{"@hourly", "2026-05-10 10:00", "2026-05-10 11:00"},
+	}
+	for _, tt := range tests {
+		s, err := scheduler.Parse(tt.spec)
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, at(tt.want), s.Next(at(tt.from)), tt.spec)
+	}
+}
+
+func TestSchedule_NeverFires(t *testing.T) {
+	s, err := scheduler.Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(at("2026-01-01 00:00")).IsZero())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := scheduler.Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
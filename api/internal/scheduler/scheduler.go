@@ -0,0 +1,143 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultTimeout bounds a single run of a task.
+const defaultTimeout = 10 * time.Minute
+
+// Leases elects which instance runs each slot of a task. Every instance
+// wakes up for every slot; only the one whose Claim succeeds runs it.
+type Leases interface {
+	// Claim reports whether holder won slot of task. It must fail for every
+	// caller once the slot, or a later one, has been claimed.
+	Claim(ctx context.Context, task string, slot time.Time, holder string) (bool, error)
+}
+
+// Task is a function run on a cron schedule.
+type Task struct {
+	Name     string
+	Schedule *Schedule
+	Run      func(ctx context.Context) error
+	// Timeout bounds one run. Zero means defaultTimeout.
+	Timeout time.Duration
+}
+
+// Scheduler runs tasks on their schedules on at most one instance per slot,
+// so it can be started on every replica.
+type Scheduler struct {
+	leases Leases
+	holder string
+	tasks  map[string]*Task
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func New(leases Leases) *Scheduler {
+	host, _ := os.Hostname()
+	return &Scheduler{
+		leases: leases,
+		holder: fmt.Sprintf("%s-%s", host, uuid.NewString()[:8]),
+		tasks:  make(map[string]*Task),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Add registers a task. It must be called before Start.
+func (s *Scheduler) Add(t *Task) error {
+	if t.Name == "" || t.Schedule == nil || t.Run == nil {
+		return fmt.Errorf("scheduler: task needs a name, schedule and run func")
+	}
+	if _, ok := s.tasks[t.Name]; ok {
+		return fmt.Errorf("scheduler: task %s already registered", t.Name)
+	}
+	s.tasks[t.Name] = t
+	return nil
+}
+
+func (s *Scheduler) Start() {
+	for _, t := range s.tasks {
+		s.wg.Add(1)
+		go s.loop(t)
+	}
+}
+
+func (s *Scheduler) Shutdown() {
+	close(s.stop)
+	s.wg.Wait()
+	slog.Info("scheduler shut down")
+}
+
+func (s *Scheduler) loop(t *Task) {
+	defer s.wg.Done()
+
+	for {
+		slot := t.Schedule.Next(time.Now())
+		if slot.IsZero() {
+			slog.Warn("task schedule never fires", slog.String("task", t.Name), slog.String("schedule", t.Schedule.String()))
+			return
+		}
+		slog.Debug("task scheduled", slog.String("task", t.Name), slog.Time("at", slot))
+
+		timer := time.NewTimer(time.Until(slot))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if _, err := s.RunSlot(context.Background(), t.Name, slot); err != nil {
+			slog.Error("scheduled task failed",
+				slog.String("task", t.Name),
+				slog.Time("slot", slot),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// RunSlot runs the named task for slot if this instance wins the claim, and
+// reports whether it ran.
+func (s *Scheduler) RunSlot(ctx context.Context, name string, slot time.Time) (bool, error) {
+	t, ok := s.tasks[name]
+	if !ok {
+		return false, fmt.Errorf("scheduler: unknown task %s", name)
+	}
+
+	won, err := s.leases.Claim(ctx, t.Name, slot, s.holder)
+	if err != nil {
+		return false, fmt.Errorf("claiming %s: %w", t.Name, err)
+	}
+	if !won {
+		slog.Debug("task slot claimed by another instance", slog.String("task", t.Name), slog.Time("slot", slot))
+		return false, nil
+	}
+
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := t.Run(ctx); err != nil {
+		return true, err
+	}
+	slog.Info("scheduled task completed",
+		slog.String("task", t.Name),
+		slog.Time("slot", slot),
+		slog.Duration("duration", time.Since(start)),
+	)
+	return true, nil
+}
@@ -0,0 +1,92 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/scheduler"
+)
+
+// --- Mock Leases ---
+
+type mockLeases struct {
+	mu    sync.Mutex
+	slots map[string]time.Time
+}
+
+func (m *mockLeases) Claim(_ context.Context, task string, slot time.Time, _ string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if last, ok := m.slots[task]; ok && !slot.After(last) {
+		return false, nil
+	}
+	m.slots[task] = slot
+	return true, nil
+}
+
+func newTask(t *testing.T, runs *int) *scheduler.Task {
+	t.Helper()
+	s, err := scheduler.Parse("@daily")
+	require.NoError(t, err)
+	return &scheduler.Task{
+		Name:     "refresh",
+		Schedule: s,
+		Run: func(context.Context) error {
+			*runs++
+			return nil
+		},
+	}
+}
+
+func TestScheduler_OneInstancePerSlot(t *testing.T) {
+	leases := &mockLeases{slots: map[string]time.Time{}}
+	runs := 0
+	a, b := scheduler.New(leases), scheduler.New(leases)
+	require.NoError(t, a.Add(newTask(t, &runs)))
+	require.NoError(t, b.Add(newTask(t, &runs)))
+	ctx := context.Background()
+	slot := at("2026-05-10 00:00")
+
+	ranA, err := a.RunSlot(ctx, "refresh", slot)
+	require.NoError(t, err)
+	ranB, err := b.RunSlot(ctx, "refresh", slot)
+	require.NoError(t, err)
+	assert.True(t, ranA)
+	assert.False(t, ranB)
+
+	ranB, err = b.RunSlot(ctx, "refresh", slot.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.True(t, ranB)
+	assert.Equal(t, 2, runs)
+}
+
+func TestScheduler_RunErrorIsReturned(t *testing.T) {
+	s, err := scheduler.Parse("@hourly")
+	require.NoError(t, err)
+	sch := scheduler.New(&mockLeases{slots: map[string]time.Time{}})
+	require.NoError(t, sch.Add(&scheduler.Task{
+		Name:     "broken",
+		Schedule: s,
+		Run:      func(context.Context) error { return errors.New("boom") },
+	}))
+
+	ran, err := sch.RunSlot(context.Background(), "broken", at("2026-05-10 10:00"))
+	assert.True(t, ran)
+	assert.EqualError(t, err, "boom")
+}
+
+func TestScheduler_AddRejectsDuplicates(t *testing.T) {
+	runs := 0
+	sch := scheduler.New(&mockLeases{slots: map[string]time.Time{}})
+	require.NoError(t, sch.Add(newTask(t, &runs)))
+	assert.Error(t, sch.Add(newTask(t, &runs)))
+
+	_, err := sch.RunSlot(context.Background(), "unknown", time.Now())
+	assert.Error(t, err)
+}
@@ -2,40 +2,70 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"os"
 	"sync"
 	"time"
-)
 
-type JobType string
+	"github.com/l3co/traceo-api/internal/domain/job"
+	"github.com/l3co/traceo-api/internal/domain/matching"
+	"github.com/l3co/traceo-api/pkg/resilience"
+)
 
 const (
-	JobAgeProgression JobType = "age_progression"
-	JobFaceMatching   JobType = "face_matching"
+	jobTimeout   = 2 * time.Minute
+	leaseTTL     = jobTimeout + time.Minute
+	pollInterval = 5 * time.Second
+	// budgetDeferral is how long a job waits when the AI budget has run out.
+	// Daily budgets reset at midnight UTC, so the job keeps being deferred
+	// until then without using up its attempts.
+	budgetDeferral = 30 * time.Minute
 )
 
-type AIJob struct {
-	Type      JobType
-	TargetID  string
-	PhotoURL  string
-	BirthDate time.Time
-}
-
 type JobProcessor interface {
 	ProcessAgeProgression(ctx context.Context, missingID, photoURL string, birthDate time.Time) error
 	ProcessFaceMatching(ctx context.Context, homelessID string) error
+	ProcessMissingMatching(ctx context.Context, missingID string) error
+	ProcessPairMatching(ctx context.Context, homelessID, missingID string) error
+	ForgetMissing(ctx context.Context, missingID string) error
 }
 
+// AIWorker leases jobs from the durable queue and runs them with bounded
+// concurrency. Failed jobs are rescheduled by job.Service according to its
+// retry policy; jobs whose worker dies are re-leased once the lease expires.
 type AIWorker struct {
-	jobs      chan AIJob
+	jobs      *job.Service
 	processor JobProcessor
+	breaker   *resilience.Breaker
+	owner     string
+	wake      chan struct{}
+	stop      chan struct{}
 	wg        sync.WaitGroup
 }
 
-func NewAIWorker(processor JobProcessor, concurrency int) *AIWorker {
+type Option func(*AIWorker)
+
+// WithBreaker pauses leasing while breaker is open, so that jobs stay queued
+// instead of failing against an upstream that is down.
+func WithBreaker(breaker *resilience.Breaker) Option {
+	return func(w *AIWorker) {
+		w.breaker = breaker
+	}
+}
+
+func NewAIWorker(jobs *job.Service, processor JobProcessor, concurrency int, opts ...Option) *AIWorker {
+	host, _ := os.Hostname()
 	w := &AIWorker{
-		jobs:      make(chan AIJob, 100),
+		jobs:      jobs,
 		processor: processor,
+		owner:     fmt.Sprintf("%s-%d", host, os.Getpid()),
+		wake:      make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
 	}
 
 	for i := range concurrency {
@@ -50,48 +80,152 @@ func (w *AIWorker) run(id int) {
 	defer w.wg.Done()
 	slog.Info("ai worker started", slog.Int("worker_id", id))
 
-	for job := range w.jobs {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-
-		slog.Info("processing ai job",
-			slog.String("type", string(job.Type)),
-			slog.String("target_id", job.TargetID),
-			slog.Int("worker_id", id),
-		)
+	for {
+		select {
+		case <-w.stop:
+			return
+		default:
+		}
 
-		var err error
-		switch job.Type {
-		case JobAgeProgression:
-			err = w.processor.ProcessAgeProgression(ctx, job.TargetID, job.PhotoURL, job.BirthDate)
-		case JobFaceMatching:
-			err = w.processor.ProcessFaceMatching(ctx, job.TargetID)
+		if until, open := w.pausedUntil(); open {
+			slog.Warn("ai worker paused, upstream circuit open",
+				slog.Int("worker_id", id),
+				slog.Time("until", until),
+			)
+			select {
+			case <-w.stop:
+				return
+			case <-time.After(time.Until(until)):
+			}
+			continue
 		}
 
+		j, err := w.jobs.Lease(context.Background(), w.owner, leaseTTL)
 		if err != nil {
-			slog.Error("ai job failed",
-				slog.String("type", string(job.Type)),
-				slog.String("target_id", job.TargetID),
+			slog.Error("leasing ai job failed", slog.String("error", err.Error()))
+		}
+		if j == nil {
+			select {
+			case <-w.stop:
+				return
+			case <-w.wake:
+			case <-time.After(pollInterval):
+			}
+			continue
+		}
+
+		w.process(id, j)
+	}
+}
+
+func (w *AIWorker) process(workerID int, j *job.Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	slog.Info("processing ai job",
+		slog.String("job_id", j.ID),
+		slog.String("type", string(j.Type)),
+		slog.String("target_id", j.TargetID),
+		slog.Int("attempt", j.Attempts),
+		slog.Int("worker_id", workerID),
+	)
+
+	var err error
+	switch j.Type {
+	case job.TypeAgeProgression:
+		err = w.processor.ProcessAgeProgression(ctx, j.TargetID, j.PhotoURL, j.BirthDate)
+	case job.TypeFaceMatching:
+		err = w.processor.ProcessFaceMatching(ctx, j.TargetID)
+	case job.TypeMissingMatching:
+		err = w.processor.ProcessMissingMatching(ctx, j.TargetID)
+	case job.TypePairMatching:
+		err = w.processor.ProcessPairMatching(ctx, j.CandidateID, j.TargetID)
+	case job.TypeCandidateRemoval:
+		err = w.processor.ForgetMissing(ctx, j.TargetID)
+	default:
+		err = fmt.Errorf("%w: unknown type %q", job.ErrInvalidJob, j.Type)
+	}
+
+	if err == nil {
+		if err := w.jobs.Complete(context.Background(), j); err != nil {
+			slog.Error("completing ai job failed",
+				slog.String("job_id", j.ID),
 				slog.String("error", err.Error()),
 			)
 		}
+		return
+	}
 
-		cancel()
+	if errors.Is(err, matching.ErrBudgetExhausted) {
+		slog.Warn("ai budget exhausted, deferring job",
+			slog.String("job_id", j.ID),
+			slog.String("type", string(j.Type)),
+			slog.String("target_id", j.TargetID),
+		)
+		if err := w.jobs.Defer(context.Background(), j, err, time.Now().Add(budgetDeferral)); err != nil {
+			slog.Error("deferring ai job failed",
+				slog.String("job_id", j.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+		return
 	}
+
+	if errors.Is(err, resilience.ErrCircuitOpen) {
+		until, open := w.pausedUntil()
+		if !open {
+			until = time.Now().Add(pollInterval)
+		}
+		slog.Warn("ai upstream unavailable, deferring job",
+			slog.String("job_id", j.ID),
+			slog.String("type", string(j.Type)),
+			slog.Time("run_at", until),
+		)
+		if err := w.jobs.Defer(context.Background(), j, err, until); err != nil {
+			slog.Error("deferring ai job failed",
+				slog.String("job_id", j.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+		return
+	}
+
+	slog.Error("ai job failed",
+		slog.String("job_id", j.ID),
+		slog.String("type", string(j.Type)),
+		slog.String("target_id", j.TargetID),
+		slog.String("error", err.Error()),
+	)
+
+	if err := w.jobs.Fail(context.Background(), j, err); err != nil {
+		slog.Error("rescheduling ai job failed",
+			slog.String("job_id", j.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+func (w *AIWorker) pausedUntil() (time.Time, bool) {
+	if w.breaker == nil {
+		return time.Time{}, false
+	}
+	return w.breaker.OpenUntil()
 }
 
-func (w *AIWorker) Enqueue(job AIJob) {
+func (w *AIWorker) Enqueue(ctx context.Context, j *job.Job) error {
+	if err := w.jobs.Enqueue(ctx, j); err != nil {
+		return err
+	}
+
 	select {
-	case w.jobs <- job:
+	case w.wake <- struct{}{}:
 	default:
-		slog.Warn("ai job queue full, dropping job",
-			slog.String("type", string(job.Type)),
-			slog.String("target_id", job.TargetID),
-		)
 	}
+	return nil
 }
 
 func (w *AIWorker) Shutdown() {
-	close(w.jobs)
+	close(w.stop)
 	w.wg.Wait()
 	slog.Info("ai worker shut down")
 }
@@ -0,0 +1,106 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/l3co/traceo-api/internal/domain/event"
+	"github.com/l3co/traceo-api/internal/domain/job"
+)
+
+type Enqueuer interface {
+	Enqueue(ctx context.Context, j *job.Job) error
+}
+
+// Dispatcher translates domain events into AI jobs so that domain services
+// never depend on the worker package directly.
+type Dispatcher struct {
+	queue Enqueuer
+}
+
+func NewDispatcher(queue Enqueuer) *Dispatcher {
+	return &Dispatcher{queue: queue}
+}
+
+func (d *Dispatcher) Publish(ctx context.Context, e event.Event) error {
+	err := d.dispatch(ctx, e)
+	if !errors.Is(err, job.ErrDuplicateJob) {
+		return err
+	}
+	if e.Key != "" {
+		return fmt.Errorf("%w: %s", event.ErrDuplicate, e.Key)
+	}
+	return nil
+}
+
+// dispatch enqueues the jobs for e. Age progression and matching need the
+// case's photo, so events without one only reach the jobs that do not.
+func (d *Dispatcher) dispatch(ctx context.Context, e event.Event) error {
+	switch e.Type {
+	case event.HomelessCreated, event.HomelessPhotoChanged,
+		event.MissingCreated, event.MissingPhotoChanged, event.MissingPhotoAdded,
+		event.MissingAgeProgressionDue, event.MatchPairDue:
+		if e.PhotoURL == "" {
+			slog.Debug("event without photo, no ai job dispatched",
+				slog.String("type", string(e.Type)),
+				slog.String("target_id", e.AggregateID),
+			)
+			return nil
+		}
+	}
+
+	switch e.Type {
+	case event.HomelessCreated, event.HomelessPhotoChanged:
+		return d.queue.Enqueue(ctx, &job.Job{
+			Type:     job.TypeFaceMatching,
+			TargetID: e.AggregateID,
+		})
+	case event.MissingCreated, event.MissingPhotoChanged:
+		if err := d.queue.Enqueue(ctx, &job.Job{
+			Type:      job.TypeAgeProgression,
+			TargetID:  e.AggregateID,
+			PhotoURL:  e.PhotoURL,
+			BirthDate: e.BirthDate,
+		}); err != nil {
+			return err
+		}
+		return d.queue.Enqueue(ctx, &job.Job{
+			Type:     job.TypeMissingMatching,
+			TargetID: e.AggregateID,
+		})
+	case event.MissingPhotoAdded:
+		return d.queue.Enqueue(ctx, &job.Job{
+			Type:     job.TypeMissingMatching,
+			TargetID: e.AggregateID,
+		})
+	case event.MissingAgeProgressionDue:
+		// One refresh per case per day, however often the event is sent.
+		return d.queue.Enqueue(ctx, &job.Job{
+			ID:        fmt.Sprintf("age_refresh_%s_%s", e.AggregateID, e.OccurredAt.UTC().Format("20060102")),
+			Type:      job.TypeAgeProgression,
+			TargetID:  e.AggregateID,
+			PhotoURL:  e.PhotoURL,
+			BirthDate: e.BirthDate,
+		})
+	case event.MatchPairDue:
+		// Keyed by the pair ledger key and day, so a pair enqueued by one
+		// sweep and still waiting when the next one runs is not queued twice,
+		// while a pair whose comparison left no ledger record is retried the
+		// next day instead of being shadowed by its old status record.
+		return d.queue.Enqueue(ctx, &job.Job{
+			ID:          fmt.Sprintf("pair_%s_%s", e.Key, e.OccurredAt.UTC().Format("20060102")),
+			Type:        job.TypePairMatching,
+			TargetID:    e.AggregateID,
+			CandidateID: e.CandidateID,
+		})
+	case event.MissingDeleted:
+		return d.queue.Enqueue(ctx, &job.Job{
+			Type:     job.TypeCandidateRemoval,
+			TargetID: e.AggregateID,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package worker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/internal/domain/event"
+	"github.com/l3co/traceo-api/internal/domain/job"
+	"github.com/l3co/traceo-api/internal/worker"
+)
+
+type mockEnqueuer struct {
+	jobs []*job.Job
+}
+
+func (m *mockEnqueuer) Enqueue(_ context.Context, j *job.Job) error {
+	for _, existing := range m.jobs {
+		if j.ID != "" && existing.ID == j.ID {
+			return job.ErrDuplicateJob
+		}
+	}
+	m.jobs = append(m.jobs, j)
+	return nil
+}
+
+func TestDispatcher_HomelessCreated_EnqueuesFaceMatching(t *testing.T) {
+	q := &mockEnqueuer{}
+	d := worker.NewDispatcher(q)
+
+	err := d.Publish(context.Background(), event.Event{
+		Type:        event.HomelessCreated,
+		AggregateID: "h1",
+		PhotoURL:    "https://example.com/h1.jpg",
+	})
+
+	require.NoError(t, err)
+	require.Len(t, q.jobs, 1)
+	assert.Equal(t, job.TypeFaceMatching, q.jobs[0].Type)
+	assert.Equal(t, "h1", q.jobs[0].TargetID)
+}
+
+func TestDispatcher_HomelessPhotoChanged_EnqueuesFaceMatching(t *testing.T) {
+	q := &mockEnqueuer{}
+	d := worker.NewDispatcher(q)
+
+	err := d.Publish(context.Background(), event.Event{
+		Type:        event.HomelessPhotoChanged,
+		AggregateID: "h1",
+		PhotoURL:    "https://example.com/h1.jpg",
+	})
+
+	require.NoError(t, err)
+	require.Len(t, q.jobs, 1)
+	assert.Equal(t, job.TypeFaceMatching, q.jobs[0].Type)
+}
+
+func TestDispatcher_MissingDeleted_EnqueuesCandidateRemoval(t *testing.T) {
+	q := &mockEnqueuer{}
+	d := worker.NewDispatcher(q)
+
+	err := d.Publish(context.Background(), event.Event{
+		Type:        event.MissingDeleted,
+		AggregateID: "m1",
+	})
+
+	require.NoError(t, err)
+	require.Len(t, q.jobs, 1)
+	assert.Equal(t, job.TypeCandidateRemoval, q.jobs[0].Type)
+	assert.Equal(t, "m1", q.jobs[0].TargetID)
+}
+
+func TestDispatcher_MissingPhotoAdded_EnqueuesOnlyMatching(t *testing.T) {
+	q := &mockEnqueuer{}
+	d := worker.NewDispatcher(q)
+
+	err := d.Publish(context.Background(), event.Event{
+		Type:        event.MissingPhotoAdded,
+		AggregateID: "m1",
+		PhotoURL:    "https://example.com/m1.jpg",
+	})
+
+	require.NoError(t, err)
+	require.Len(t, q.jobs, 1)
+	assert.Equal(t, job.TypeMissingMatching, q.jobs[0].Type)
+	assert.Equal(t, "m1", q.jobs[0].TargetID)
+}
+
+func TestDispatcher_MissingEvents_EnqueueAgeProgressionAndMatching(t *testing.T) {
+	birth := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, typ := range []event.Type{event.MissingCreated, event.MissingPhotoChanged} {
+		q := &mockEnqueuer{}
+		d := worker.NewDispatcher(q)
+
+		err := d.Publish(context.Background(), event.Event{
+			Type:        typ,
+			AggregateID: "m1",
+			PhotoURL:    "https://example.com/m1.jpg",
+			BirthDate:   birth,
+		})
+
+		require.NoError(t, err)
+		require.Len(t, q.jobs, 2)
+		assert.Equal(t, job.TypeAgeProgression, q.jobs[0].Type)
+		assert.Equal(t, "https://example.com/m1.jpg", q.jobs[0].PhotoURL)
+		assert.Equal(t, birth, q.jobs[0].BirthDate)
+		assert.Equal(t, job.TypeMissingMatching, q.jobs[1].Type)
+		assert.Equal(t, "m1", q.jobs[1].TargetID)
+	}
+}
+
+func TestDispatcher_AgeProgressionDue_EnqueuesDailyJob(t *testing.T) {
+	q := &mockEnqueuer{}
+	d := worker.NewDispatcher(q)
+
+	err := d.Publish(context.Background(), event.Event{
+		Type:        event.MissingAgeProgressionDue,
+		AggregateID: "m1",
+		PhotoURL:    "https://example.com/m1.jpg",
+		OccurredAt:  time.Date(2026, 3, 4, 5, 0, 0, 0, time.UTC),
+	})
+
+	require.NoError(t, err)
+	require.Len(t, q.jobs, 1)
+	assert.Equal(t, job.TypeAgeProgression, q.jobs[0].Type)
+	assert.Equal(t, "age_refresh_m1_20260304", q.jobs[0].ID)
+}
+
+func TestDispatcher_MatchPairDue_EnqueuesPairJobByKey(t *testing.T) {
+	q := &mockEnqueuer{}
+	d := worker.NewDispatcher(q)
+
+	err := d.Publish(context.Background(), event.Event{
+		Type:        event.MatchPairDue,
+		AggregateID: "m1",
+		CandidateID: "h1",
+		Key:         "abc",
+		PhotoURL:    "https://example.com/m1.jpg",
+		OccurredAt:  time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC),
+	})
+
+	require.NoError(t, err)
+	require.Len(t, q.jobs, 1)
+	assert.Equal(t, job.TypePairMatching, q.jobs[0].Type)
+	assert.Equal(t, "pair_abc_20260304", q.jobs[0].ID)
+	assert.Equal(t, "m1", q.jobs[0].TargetID)
+	assert.Equal(t, "h1", q.jobs[0].CandidateID)
+}
+
+func TestDispatcher_MatchPairDue_DuplicateSameDay(t *testing.T) {
+	q := &mockEnqueuer{}
+	d := worker.NewDispatcher(q)
+	e := event.Event{
+		Type:        event.MatchPairDue,
+		AggregateID: "m1",
+		CandidateID: "h1",
+		Key:         "abc",
+		PhotoURL:    "https://example.com/m1.jpg",
+		OccurredAt:  time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC),
+	}
+
+	require.NoError(t, d.Publish(context.Background(), e))
+	assert.ErrorIs(t, d.Publish(context.Background(), e), event.ErrDuplicate)
+
+	e.OccurredAt = e.OccurredAt.AddDate(0, 0, 1)
+	require.NoError(t, d.Publish(context.Background(), e))
+	assert.Len(t, q.jobs, 2)
+}
+
+func TestDispatcher_AgeProgressionDue_DuplicateIgnored(t *testing.T) {
+	q := &mockEnqueuer{}
+	d := worker.NewDispatcher(q)
+	e := event.Event{
+		Type:        event.MissingAgeProgressionDue,
+		AggregateID: "m1",
+		PhotoURL:    "https://example.com/m1.jpg",
+		OccurredAt:  time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC),
+	}
+
+	require.NoError(t, d.Publish(context.Background(), e))
+	require.NoError(t, d.Publish(context.Background(), e))
+	assert.Len(t, q.jobs, 1)
+}
+
+func TestDispatcher_NoPhoto_Skips(t *testing.T) {
+	q := &mockEnqueuer{}
+	d := worker.NewDispatcher(q)
+
+	err := d.Publish(context.Background(), event.Event{Type: event.HomelessCreated, AggregateID: "h1"})
+
+	require.NoError(t, err)
+	assert.Empty(t, q.jobs)
+}
+
+func TestDispatcher_NoPhoto_SkipsOnlyPhotoJobs(t *testing.T) {
+	q := &mockEnqueuer{}
+	d := worker.NewDispatcher(q)
+
+	for _, typ := range []event.Type{event.MissingCreated, event.MissingAgeProgressionDue, event.MatchPairDue} {
+		require.NoError(t, d.Publish(context.Background(), event.Event{Type: typ, AggregateID: "m1", CandidateID: "h1"}))
+	}
+	assert.Empty(t, q.jobs)
+
+	require.NoError(t, d.Publish(context.Background(), event.Event{Type: event.MissingDeleted, AggregateID: "m1"}))
+	require.Len(t, q.jobs, 1)
+	assert.Equal(t, job.TypeCandidateRemoval, q.jobs[0].Type)
+}
@@ -0,0 +1,139 @@
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half_open"
+)
+
+// Outcome is what a call let through by Allow reports back to the breaker.
+type Outcome int
+
+const (
+	// OutcomeSuccess means the upstream answered.
+	OutcomeSuccess Outcome = iota
+	// OutcomeFailure means the upstream is unhealthy (e.g. 5xx or 429).
+	OutcomeFailure
+	// OutcomeIgnored means the call failed for reasons unrelated to the
+	// upstream's health, such as a bad input.
+	OutcomeIgnored
+)
+
+// Breaker opens after Threshold consecutive failures and rejects calls with
+// ErrCircuitOpen for Cooldown. It then lets a single probe through: success
+// closes it again, failure re-opens it for another Cooldown.
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewBreaker(name string, threshold int, cooldown time.Duration) *Breaker {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	return &Breaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     StateClosed,
+	}
+}
+
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Allow returns ErrCircuitOpen if the call must not be made. Every nil return
+// must be followed by exactly one Report.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Before(b.openedAt.Add(b.cooldown)) {
+			return ErrCircuitOpen
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (b *Breaker) Report(o Outcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen {
+		b.probing = false
+	}
+
+	switch o {
+	case OutcomeSuccess:
+		b.state = StateClosed
+		b.failures = 0
+	case OutcomeFailure:
+		b.failures++
+		if b.state == StateHalfOpen || b.failures >= b.threshold {
+			b.state = StateOpen
+			b.openedAt = b.now()
+		}
+	}
+}
+
+// Snapshot is a point-in-time view of a Breaker for health reporting.
+type Snapshot struct {
+	Name     string
+	State    State
+	Failures int
+	// RetryAt is when an open breaker will let the next probe through.
+	RetryAt time.Time
+}
+
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := Snapshot{Name: b.name, State: b.state, Failures: b.failures}
+	if b.state == StateOpen {
+		s.RetryAt = b.openedAt.Add(b.cooldown)
+	}
+	return s
+}
+
+// OpenUntil reports whether the breaker is rejecting calls and, if so, until
+// when.
+func (b *Breaker) OpenUntil() (time.Time, bool) {
+	s := b.Snapshot()
+	if s.State != StateOpen || !b.now().Before(s.RetryAt) {
+		return time.Time{}, false
+	}
+	return s.RetryAt, true
+}
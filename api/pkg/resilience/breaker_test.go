@@ -0,0 +1,58 @@
+package resilience_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/l3co/traceo-api/pkg/resilience"
+)
+
+func TestBreaker_OpensAfterThreshold(t *testing.T) {
+	b := resilience.NewBreaker("gemini", 2, time.Minute)
+
+	require.NoError(t, b.Allow())
+	b.Report(resilience.OutcomeFailure)
+	require.NoError(t, b.Allow())
+	b.Report(resilience.OutcomeFailure)
+
+	assert.ErrorIs(t, b.Allow(), resilience.ErrCircuitOpen)
+	until, open := b.OpenUntil()
+	assert.True(t, open)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), until, time.Second)
+}
+
+func TestBreaker_IgnoredOutcomeDoesNotTrip(t *testing.T) {
+	b := resilience.NewBreaker("gemini", 1, time.Minute)
+
+	require.NoError(t, b.Allow())
+	b.Report(resilience.OutcomeIgnored)
+
+	assert.NoError(t, b.Allow())
+	assert.Equal(t, resilience.StateClosed, b.Snapshot().State)
+}
+
+func TestBreaker_HalfOpenProbe(t *testing.T) {
+	cooldown := 10 * time.Millisecond
+	b := resilience.NewBreaker("gemini", 1, cooldown)
+
+	require.NoError(t, b.Allow())
+	b.Report(resilience.OutcomeFailure)
+	assert.ErrorIs(t, b.Allow(), resilience.ErrCircuitOpen)
+
+	time.Sleep(2 * cooldown)
+	require.NoError(t, b.Allow())
+	assert.Equal(t, resilience.StateHalfOpen, b.Snapshot().State)
+	assert.ErrorIs(t, b.Allow(), resilience.ErrCircuitOpen, "only one probe at a time")
+
+	b.Report(resilience.OutcomeFailure)
+	assert.Equal(t, resilience.StateOpen, b.Snapshot().State)
+
+	time.Sleep(2 * cooldown)
+	require.NoError(t, b.Allow())
+	b.Report(resilience.OutcomeSuccess)
+	assert.Equal(t, resilience.StateClosed, b.Snapshot().State)
+	assert.Zero(t, b.Snapshot().Failures)
+}
@@ -0,0 +1,52 @@
+package resilience
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff configures Retry. Delays grow exponentially from BaseDelay up to
+// MaxDelay, with full jitter so that concurrent callers do not retry in
+// lockstep.
+type Backoff struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Delay returns a random wait in [0, min(MaxDelay, BaseDelay*2^(attempt-1))]
+// before the attempt following the given one.
+func (b Backoff) Delay(attempt int) time.Duration {
+	ceiling := b.BaseDelay
+	for i := 1; i < attempt && ceiling < b.MaxDelay; i++ {
+		ceiling *= 2
+	}
+	if ceiling > b.MaxDelay {
+		ceiling = b.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling + 1)
+}
+
+// Retry calls fn until it succeeds, returns an error that retryable rejects,
+// or MaxAttempts is reached. The last error is returned. Waiting stops early
+// when ctx is done.
+func Retry(ctx context.Context, b Backoff, retryable func(error) bool, fn func(context.Context) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil || !retryable(err) || attempt >= b.MaxAttempts {
+			return err
+		}
+
+		t := time.NewTimer(b.Delay(attempt))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
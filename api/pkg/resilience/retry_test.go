@@ -0,0 +1,64 @@
+package resilience_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/l3co/traceo-api/pkg/resilience"
+)
+
+var errTransient = errors.New("transient")
+
+func isTransient(err error) bool { return errors.Is(err, errTransient) }
+
+func TestRetry_StopsOnSuccess(t *testing.T) {
+	calls := 0
+	err := resilience.Retry(context.Background(), resilience.Backoff{MaxAttempts: 5}, isTransient, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errTransient
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetry_DoesNotRetryPermanentErrors(t *testing.T) {
+	permanent := errors.New("bad photo")
+	calls := 0
+	err := resilience.Retry(context.Background(), resilience.Backoff{MaxAttempts: 5}, isTransient, func(context.Context) error {
+		calls++
+		return permanent
+	})
+
+	assert.ErrorIs(t, err, permanent)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	calls := 0
+	err := resilience.Retry(context.Background(), resilience.Backoff{MaxAttempts: 3}, isTransient, func(context.Context) error {
+		calls++
+		return errTransient
+	})
+
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 3, calls)
+}
+
+func TestBackoff_DelayIsCapped(t *testing.T) {
+	b := resilience.Backoff{BaseDelay: time.Second, MaxDelay: 4 * time.Second}
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		d := b.Delay(attempt)
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.LessOrEqual(t, d, 4*time.Second)
+	}
+	assert.LessOrEqual(t, b.Delay(1), time.Second)
+}
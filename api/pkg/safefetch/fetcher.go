@@ -0,0 +1,188 @@
+package safefetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultMaxBytes     = 10 << 20
+	DefaultMaxRedirects = 3
+	DefaultTimeout      = 30 * time.Second
+)
+
+var (
+	ErrHostNotAllowed   = errors.New("host not allowed")
+	ErrPrivateAddress   = errors.New("private address not allowed")
+	ErrTooLarge         = errors.New("response too large")
+	ErrTooManyRedirects = errors.New("too many redirects")
+)
+
+// StatusError is returned when the server answers with a non-200 status.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("download status %d", e.StatusCode)
+}
+
+// Config bounds what a Fetcher may download. Zero values take the defaults.
+type Config struct {
+	AllowedHosts HostList
+	// AllowedURLs, when set, also bounds the path: a URL must match one of
+	// its "host/path/prefix/" entries, such as a single storage bucket.
+	AllowedURLs URLList
+	// AllowPrivate lets requests reach loopback, private and link-local
+	// addresses. It is meant for local development and tests.
+	AllowPrivate bool
+	MaxBytes     int64
+	MaxRedirects int
+	Timeout      time.Duration
+}
+
+// Fetcher downloads http(s) URLs on the allow-list. Every connection is
+// checked against the resolved IP address, so a public name pointing at an
+// internal address, or re-resolving to one, is refused as well. Proxies from
+// the environment are ignored for the same reason.
+type Fetcher struct {
+	client   *http.Client
+	hosts    HostList
+	urls     URLList
+	maxBytes int64
+}
+
+func New(cfg Config) *Fetcher {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultMaxBytes
+	}
+	if cfg.MaxRedirects <= 0 {
+		cfg.MaxRedirects = DefaultMaxRedirects
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !cfg.AllowPrivate {
+		dialer.Control = checkAddress
+	}
+
+	f := &Fetcher{hosts: cfg.AllowedHosts, urls: cfg.AllowedURLs, maxBytes: cfg.MaxBytes}
+	f.client = &http.Client{
+		Timeout: cfg.Timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 15 * time.Second,
+			MaxIdleConns:          20,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > cfg.MaxRedirects {
+				return ErrTooManyRedirects
+			}
+			return f.checkURL(req.URL)
+		},
+	}
+	return f
+}
+
+// Get downloads rawURL and returns its body and Content-Type.
+func (f *Fetcher) Get(ctx context.Context, rawURL string) ([]byte, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid url: %w", err)
+	}
+	if err := f.checkURL(u); err != nil {
+		return nil, "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetching %s: %w", u.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", &StatusError{URL: rawURL, StatusCode: resp.StatusCode}
+	}
+	if resp.ContentLength > f.maxBytes {
+		return nil, "", fmt.Errorf("%w: %d bytes", ErrTooLarge, resp.ContentLength)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("reading %s: %w", u.Redacted(), err)
+	}
+	if int64(len(data)) > f.maxBytes {
+		return nil, "", fmt.Errorf("%w: over %d bytes", ErrTooLarge, f.maxBytes)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+func (f *Fetcher) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q", ErrHostNotAllowed, u.Scheme)
+	}
+	if u.User != nil || !f.hosts.Allows(u.Host) {
+		return fmt.Errorf("%w: %s", ErrHostNotAllowed, u.Host)
+	}
+	if !f.urls.Allows(u) {
+		return fmt.Errorf("%w: %s%s", ErrHostNotAllowed, u.Host, u.EscapedPath())
+	}
+	return nil
+}
+
+// blockedPrefixes are ranges that are not covered by the netip.Addr
+// predicates used in checkAddress but must not be reachable either.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved, broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, embeds IPv4
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2002::/16"),      // 6to4, embeds IPv4
+}
+
+// checkAddress runs after DNS resolution, right before each connection.
+func checkAddress(_, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, address)
+	}
+	if !IsPublic(ap.Addr()) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, ap.Addr())
+	}
+	return nil
+}
+
+// IsPublic reports whether addr is a globally routable unicast address.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsMulticast() || !addr.IsGlobalUnicast() {
+		return false
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package safefetch
+
+import (
+	"net"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// HostList matches URL hosts against entries such as
+// "storage.googleapis.com", "*.example.com" (any subdomain, not the apex) or
+// "localhost:8080" (that port only). Matching ignores case. An empty list
+// allows every host.
+type HostList []string
+
+// Allows reports whether hostport, a URL's Host, matches an entry.
+func (l HostList) Allows(hostport string) bool {
+	if len(l) == 0 {
+		return true
+	}
+	hostport = strings.ToLower(hostport)
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	if host == "" {
+		return false
+	}
+
+	for _, entry := range l {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case strings.HasPrefix(entry, "*."):
+			if strings.HasSuffix(host, entry[1:]) {
+				return true
+			}
+		case hasPort(entry):
+			if hostport == entry {
+				return true
+			}
+		case host == entry:
+			return true
+		}
+	}
+	return false
+}
+
+func hasPort(entry string) bool {
+	_, port, err := net.SplitHostPort(entry)
+	return err == nil && port != ""
+}
+
+// URLList matches URLs against entries of the form "host/path/prefix/",
+// where host is a HostList entry and the optional path prefix must start the
+// URL's path, compared segment by segment after resolving "." and "..".
+// An empty list allows every URL.
+type URLList []string
+
+// Allows reports whether u matches an entry.
+func (l URLList) Allows(u *url.URL) bool {
+	if len(l) == 0 {
+		return true
+	}
+	for _, entry := range l {
+		host, prefix, _ := strings.Cut(strings.TrimSpace(entry), "/")
+		if !(HostList{host}).Allows(u.Host) {
+			continue
+		}
+		prefix = strings.Trim(prefix, "/")
+		if prefix == "" {
+			return true
+		}
+		prefix = "/" + prefix + "/"
+		if hasPathPrefix(u.Path, prefix) && hasPathPrefix(u.EscapedPath(), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Hosts returns the host part of every entry, for a Fetcher's AllowedHosts.
+func (l URLList) Hosts() HostList {
+	if len(l) == 0 {
+		return nil
+	}
+	hosts := make(HostList, 0, len(l))
+	for _, entry := range l {
+		host, _, _ := strings.Cut(strings.TrimSpace(entry), "/")
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+func hasPathPrefix(p, prefix string) bool {
+	return strings.HasPrefix(path.Clean("/"+p)+"/", prefix)
+}